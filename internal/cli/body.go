@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thetangentline/httpcl/internal/ui"
+)
+
+// bodyFlags are the mutually exclusive ways of supplying a request body,
+// plus an optional explicit content type.
+type bodyFlags struct {
+	body        string
+	bodyFile    string // read whole into the body
+	json        string
+	data        []string // field=value pairs, form-urlencoded
+	bodyDir     string   // corpus directory; loaded separately by loadBodyDir
+	bodySize    string   // generated body size; parsed separately
+	contentType string
+}
+
+// resolveBody returns the request body and the Content-Type to send with it.
+// An explicit --content-type always wins over the type implied by the body
+// flag; a -H "Content-Type: ..." header in turn wins over both (applied by the
+// engine).
+func resolveBody(f bodyFlags) ([]byte, string, error) {
+	var set []string
+	if f.body != "" {
+		set = append(set, "--body")
+	}
+	if f.bodyFile != "" {
+		set = append(set, "--body-file")
+	}
+	if f.json != "" {
+		set = append(set, "--json")
+	}
+	if len(f.data) > 0 {
+		set = append(set, "--data")
+	}
+	if f.bodyDir != "" {
+		set = append(set, "--body-dir")
+	}
+	if f.bodySize != "" {
+		set = append(set, "--body-size")
+	}
+	if len(set) > 1 {
+		return nil, "", fmt.Errorf("%s cannot be combined", strings.Join(set, " and "))
+	}
+
+	var body []byte
+	var implied string
+	switch {
+	case len(f.data) > 0:
+		form, err := parseFormData(f.data)
+		if err != nil {
+			return nil, "", err
+		}
+		body = []byte(form.Encode())
+		implied = "application/x-www-form-urlencoded"
+	case f.json != "":
+		if !json.Valid([]byte(f.json)) {
+			return nil, "", fmt.Errorf("--json: payload is not valid JSON")
+		}
+		body = []byte(f.json)
+		implied = "application/json"
+	case f.body != "":
+		body = []byte(f.body)
+	case f.bodyFile != "":
+		b, err := os.ReadFile(f.bodyFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("--body-file: %w", err)
+		}
+		body = b
+	}
+
+	if f.contentType != "" {
+		return body, f.contentType, nil
+	}
+	return body, implied, nil
+}
+
+// parseFormData turns repeated field=value flags into url.Values, keeping
+// repeated fields. Values are taken literally and percent-encoded by Encode.
+func parseFormData(pairs []string) (url.Values, error) {
+	form := make(url.Values, len(pairs))
+	for _, p := range pairs {
+		key, value, ok := strings.Cut(p, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --data %q: expected field=value", p)
+		}
+		form.Add(key, value)
+	}
+	return form, nil
+}
+
+// corpusWarnBytes is the corpus size above which --body-dir warns: the whole
+// corpus is held in memory for the run.
+const corpusWarnBytes = 256 << 20
+
+// loadBodyDir reads every regular file directly inside dir into memory, in
+// name order. Subdirectories are ignored. It fails if dir has no files, and
+// returns a warning (not an error) when the corpus is large.
+func loadBodyDir(dir string) (corpus [][]byte, warning string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "", fmt.Errorf("--body-dir: %w", err)
+	}
+	var total int
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, "", fmt.Errorf("--body-dir: %w", err)
+		}
+		corpus = append(corpus, b)
+		total += len(b)
+	}
+	if len(corpus) == 0 {
+		return nil, "", fmt.Errorf("--body-dir: no files in %s", dir)
+	}
+	if total > corpusWarnBytes {
+		warning = fmt.Sprintf("--body-dir: corpus of %d files is %s, all held in memory", len(corpus), ui.HumanizeBytes(uint64(total)))
+	}
+	return corpus, warning, nil
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveBody_JSONImpliesContentType(t *testing.T) {
+	body, ct, err := resolveBody(bodyFlags{json: `{"a":1}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"a":1}` || ct != "application/json" {
+		t.Errorf("got body=%q ct=%q", body, ct)
+	}
+}
+
+func TestResolveBody_ExplicitContentTypeWins(t *testing.T) {
+	_, ct, err := resolveBody(bodyFlags{json: `{}`, contentType: "application/vnd.api+json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct != "application/vnd.api+json" {
+		t.Errorf("explicit --content-type should win, got %q", ct)
+	}
+}
+
+func TestResolveBody_PlainBodyHasNoImpliedType(t *testing.T) {
+	_, ct, err := resolveBody(bodyFlags{body: "raw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct != "" {
+		t.Errorf("plain --body should not imply a content type, got %q", ct)
+	}
+}
+
+func TestResolveBody_Conflicts(t *testing.T) {
+	if _, _, err := resolveBody(bodyFlags{body: "x", json: "{}"}); err == nil {
+		t.Error("expected error for --body with --json")
+	}
+	if _, _, err := resolveBody(bodyFlags{json: "{not json"}); err == nil {
+		t.Error("expected error for invalid --json payload")
+	}
+}
+
+func TestResolveBody_DataEncodesForm(t *testing.T) {
+	body, ct, err := resolveBody(bodyFlags{data: []string{"q=a b&c", "name=José", "q=2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct != "application/x-www-form-urlencoded" {
+		t.Errorf("content type: got %q", ct)
+	}
+	if want := "name=Jos%C3%A9&q=a+b%26c&q=2"; string(body) != want {
+		t.Errorf("body: got %q, want %q", body, want)
+	}
+}
+
+func TestResolveBody_DataValidation(t *testing.T) {
+	if _, _, err := resolveBody(bodyFlags{data: []string{"novalue"}}); err == nil {
+		t.Error("expected error for --data without '='")
+	}
+	if _, _, err := resolveBody(bodyFlags{data: []string{"a=1"}, body: "x"}); err == nil {
+		t.Error("expected error for --data with --body")
+	}
+	if _, _, err := resolveBody(bodyFlags{data: []string{"a=1"}, json: "{}"}); err == nil {
+		t.Error("expected error for --data with --json")
+	}
+}
+
+func TestLoadBodyDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"b.json": `{"b":2}`, "a.json": `{"a":1}`} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	corpus, warning, err := loadBodyDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corpus) != 2 || string(corpus[0]) != `{"a":1}` || string(corpus[1]) != `{"b":2}` {
+		t.Errorf("corpus should hold the files in name order, skipping directories: %q", corpus)
+	}
+	if warning != "" {
+		t.Errorf("small corpus should not warn: %q", warning)
+	}
+
+	if _, _, err := loadBodyDir(t.TempDir()); err == nil {
+		t.Error("expected error for an empty directory")
+	}
+	if _, _, err := loadBodyDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for a missing directory")
+	}
+	if _, _, err := resolveBody(bodyFlags{body: "x", bodyDir: dir}); err == nil {
+		t.Error("expected error for --body with --body-dir")
+	}
+}
+
+func TestResolveBody_BodyFile(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	body, ct, err := resolveBody(bodyFlags{bodyFile: empty})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(body) != 0 || ct != "" {
+		t.Errorf("empty file: got body=%q ct=%q", body, ct)
+	}
+
+	large := make([]byte, 3<<20/2) // past 1 MiB
+	for i := range large {
+		large[i] = byte(i)
+	}
+	path := filepath.Join(dir, "large.bin")
+	if err := os.WriteFile(path, large, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	body, _, err = resolveBody(bodyFlags{bodyFile: path, contentType: "application/octet-stream"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, large) {
+		t.Errorf("large file: got %d bytes, want the %d in the file", len(body), len(large))
+	}
+}
+
+func TestResolveBody_BodyFileErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	_, _, err := resolveBody(bodyFlags{bodyFile: missing})
+	if !errors.Is(err, fs.ErrNotExist) || !strings.Contains(err.Error(), "--body-file") {
+		t.Errorf("missing file: got %v, want a wrapped not-exist error naming --body-file", err)
+	}
+	if _, _, err := resolveBody(bodyFlags{body: "x", bodyFile: missing}); err == nil || !strings.Contains(err.Error(), "--body and --body-file") {
+		t.Errorf("--body with --body-file: got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/ui"
+)
+
+// protocolPhases are the runs of --compare-protocol, baseline first.
+var protocolPhases = []struct{ label, version string }{
+	{"HTTP/1.1", "1.1"},
+	{"HTTP/2", "2"},
+}
+
+// runProtocolComparison runs cfg once per protocol phase, back to back, and
+// prints the phases side by side. Over http:// URLs HTTP/2 means h2c, which
+// the server must accept with prior knowledge. Like runBenchmark, an
+// unhealthy or slow phase still counts; its error is returned at the end.
+func runProtocolComparison(cfg engine.Config) error {
+	labels := make([]string, len(protocolPhases))
+	cfgs := make([]engine.Config, len(protocolPhases))
+	for i, phase := range protocolPhases {
+		labels[i] = phase.label
+		cfgs[i] = cfg
+		cfgs[i].HTTPVersion = phase.version
+	}
+	runs, err := runPhases(labels, cfgs)
+	if runs == nil {
+		return err
+	}
+	unit, _ := ui.ParseLatencyUnit(flagLatUnit)
+	ui.PrintComparison(unit, runs[0], runs[1])
+	return err
+}
+
+// runPhases runs cfgs back to back, each as a labelled phase with its own
+// orchestrator, so its own collector and report. A phase that could not run
+// stops the sequence and nil runs are returned; an unhealthy, slow or
+// pool-bound one still counts, and the first such error is returned with
+// the runs.
+func runPhases(labels []string, cfgs []engine.Config) ([]ui.ComparedRun, error) {
+	runs := make([]ui.ComparedRun, 0, len(cfgs))
+	var phaseErr error
+	for i, pcfg := range cfgs {
+		fmt.Println()
+		ui.PrintStepResult(os.Stdout, "Phase", fmt.Sprintf("%d of %d: %s", i+1, len(cfgs), labels[i]), true)
+		orch := engine.NewOrchestrator(pcfg, ui.NewRenderer(rendererOptions()...))
+		err := orch.Run()
+		var runErr *engine.RunError
+		var slowErr *engine.ThroughputError
+		var poolErr *engine.PoolExhaustedError
+		if err != nil && !errors.As(err, &runErr) && !errors.As(err, &slowErr) && !errors.As(err, &poolErr) {
+			return nil, fmt.Errorf("%s phase: %w", labels[i], err)
+		}
+		if err != nil && phaseErr == nil {
+			phaseErr = fmt.Errorf("%s phase: %w", labels[i], err)
+		}
+		runs = append(runs, ui.ComparedRun{Label: labels[i], Snap: orch.FinalSnapshot()})
+	}
+	return runs, phaseErr
+}
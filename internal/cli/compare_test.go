@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+func TestRunProtocolComparison_RunsBothPhases(t *testing.T) {
+	var mu sync.Mutex
+	protos := map[int]int{}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		protos[r.ProtoMajor]++
+		mu.Unlock()
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetHTTP1(true)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		URL:         srv.URL,
+		Connections: 2,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	if err := runProtocolComparison(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if protos[1] == 0 || protos[2] == 0 {
+		t.Fatalf("requests by HTTP major version = %v, want both phases to send", protos)
+	}
+}
+
+// TestRunProtocolComparison_TLS runs both phases against a TLS server that
+// offers HTTP/1.1 and HTTP/2 over ALPN, trusting its certificate with
+// Insecure.
+func TestRunProtocolComparison_TLS(t *testing.T) {
+	var mu sync.Mutex
+	protos := map[int]int{}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		protos[r.ProtoMajor]++
+		mu.Unlock()
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		URL:         srv.URL,
+		Insecure:    true,
+		Connections: 2,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	if err := runProtocolComparison(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if protos[1] == 0 || protos[2] == 0 {
+		t.Fatalf("requests by HTTP major version = %v, want both phases to send", protos)
+	}
+}
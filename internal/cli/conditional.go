@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// parseETag returns --if-none-match as an entity tag, quoting a bare value
+// so `--if-none-match abc` sends "abc". Quoted, weak (W/"...") and * values
+// pass through.
+func parseETag(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return "", nil
+	case s == "*":
+		return s, nil
+	case strings.HasPrefix(s, `"`), strings.HasPrefix(s, `W/"`):
+		quoted := strings.TrimPrefix(s, "W/")
+		if len(quoted) < 2 || !strings.HasSuffix(quoted, `"`) || strings.Contains(quoted[1:len(quoted)-1], `"`) {
+			return "", fmt.Errorf("--if-none-match: malformed entity tag %s", s)
+		}
+		return s, nil
+	case strings.Contains(s, `"`):
+		return "", fmt.Errorf("--if-none-match: entity tag %s has a stray quote", s)
+	}
+	return `"` + s + `"`, nil
+}
+
+// parseHTTPTime parses --if-modified-since as an HTTP date (e.g. "Mon, 02
+// Jan 2006 15:04:05 GMT") or RFC 3339.
+func parseHTTPTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--if-modified-since: expected an HTTP date or RFC 3339 time, got %q", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseETag(t *testing.T) {
+	for in, want := range map[string]string{
+		"":        "",
+		"abc":     `"abc"`,
+		`"abc"`:   `"abc"`,
+		`W/"abc"`: `W/"abc"`,
+		"*":       "*",
+		`"a/"`:    `"a/"`,
+		` "v1" `:  `"v1"`,
+	} {
+		got, err := parseETag(in)
+		if err != nil || got != want {
+			t.Errorf("parseETag(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{`"abc`, `W/"`, `a"b`, `"`, `"a"b"`} {
+		if _, err := parseETag(bad); err == nil {
+			t.Errorf("parseETag(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseHTTPTime(t *testing.T) {
+	want := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, in := range []string{"Fri, 01 Mar 2024 12:00:00 GMT", "2024-03-01T12:00:00Z", "2024-03-01T13:00:00+01:00"} {
+		got, err := parseHTTPTime(in)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parseHTTPTime(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseHTTPTime("yesterday"); err == nil {
+		t.Error("parseHTTPTime(yesterday) should fail")
+	}
+}
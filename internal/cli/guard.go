@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/term"
+	"github.com/thetangentline/httpcl/pkg/netutil"
+)
+
+// allowWritesEnv turns the write guard off for trusted automation.
+const allowWritesEnv = "HTTPCL_ALLOW_WRITES"
+
+// writeMethods are the methods the guard asks about: a benchmark sends
+// thousands of them, so against a real system they change real data.
+var writeMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// writeGuard decides whether a run must be confirmed before it starts.
+type writeGuard struct {
+	yes      bool // --yes
+	env      func(string) string
+	resolver netutil.HostResolver // nil for the default resolver
+	in       io.Reader            // answers to the prompt, when interactive
+	out      io.Writer
+	tty      bool // in is a terminal, so the user can be asked
+}
+
+// check returns nil if cfg may run: it only reads, it only targets
+// loopback, private or link-local addresses, or the guard was waived. A
+// host that does not resolve counts as public. Otherwise the user is asked
+// on a terminal, and the run is refused without one.
+func (g writeGuard) check(cfg engine.Config) error {
+	if g.yes || cfg.SimulateLatency != nil {
+		return nil
+	}
+	if v := g.env(allowWritesEnv); v != "" && v != "0" {
+		return nil
+	}
+	var methods, public []string
+	for _, req := range guardedRequests(cfg) {
+		// -m is sent as given, so "delete" must be caught as well.
+		method := strings.ToUpper(req.Method)
+		if !slices.Contains(writeMethods, method) {
+			continue
+		}
+		host, ok := g.publicHost(req.URL, cfg.Resolve)
+		if !ok {
+			continue
+		}
+		if !slices.Contains(methods, method) {
+			methods = append(methods, method)
+		}
+		if !slices.Contains(public, host) {
+			public = append(public, host)
+		}
+	}
+	if len(public) == 0 {
+		return nil
+	}
+	what := fmt.Sprintf("%s requests to %s, which is not a local or private address", strings.Join(methods, "/"), strings.Join(public, ", "))
+	if !g.tty {
+		return fmt.Errorf("refusing to send %s; pass --yes (or set %s=1) to run anyway", what, allowWritesEnv)
+	}
+	fmt.Fprintf(g.out, "About to send %s.\nContinue? [y/N] ", what)
+	answer, _ := bufio.NewReader(g.in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("aborted")
+}
+
+// guardedRequests lists the method and URL of every request cfg can send.
+func guardedRequests(cfg engine.Config) []engine.ReplayRequest {
+	switch {
+	case len(cfg.Replay) > 0:
+		return cfg.Replay
+	case len(cfg.Transaction) > 0:
+		return cfg.Transaction
+	}
+	// As the orchestrator defaults it.
+	method := cfg.Method
+	if method == "" && cfg.GRPC {
+		method = http.MethodPost
+	}
+	if len(cfg.Targets) == 0 {
+		return []engine.ReplayRequest{{Method: method, URL: cfg.URL}}
+	}
+	reqs := make([]engine.ReplayRequest, len(cfg.Targets))
+	for i, t := range cfg.Targets {
+		reqs[i] = engine.ReplayRequest{Method: method, URL: t.URL}
+	}
+	return reqs
+}
+
+// publicHost returns the host of rawURL and whether any address it goes to
+// is public. A --resolve pin replaces DNS.
+func (g writeGuard) publicHost(rawURL string, resolve map[string]string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, true
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	var addrs []string
+	if pinned, ok := resolve[strings.ToLower(net.JoinHostPort(u.Hostname(), port))]; ok {
+		host, _, _ := net.SplitHostPort(pinned)
+		addrs = []string{host}
+	} else if addrs, err = netutil.PreflightDNSWithResolver(rawURL, g.resolver); err != nil {
+		return u.Hostname(), true
+	}
+	for _, a := range addrs {
+		ip, err := netip.ParseAddr(a)
+		if err != nil {
+			return u.Hostname(), true
+		}
+		ip = ip.Unmap()
+		if !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsUnspecified() {
+			return u.Hostname(), true
+		}
+	}
+	return u.Hostname(), false
+}
+
+// confirmWrites runs the write guard for the run and replay-jsonl commands.
+func confirmWrites(cfg engine.Config) error {
+	return writeGuard{
+		yes: flagYes,
+		env: os.Getenv,
+		in:  os.Stdin,
+		out: runLog(),
+		tty: term.IsTerminal(os.Stdin),
+	}.check(cfg)
+}
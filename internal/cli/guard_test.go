@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// fixedResolver resolves every host from a table.
+type fixedResolver map[string][]string
+
+func (r fixedResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	return r[host], nil
+}
+
+func TestWriteGuard(t *testing.T) {
+	resolver := fixedResolver{
+		"api.example.com":  {"203.0.113.7"},
+		"staging.internal": {"10.1.2.3", "fd00::1"},
+		"localhost":        {"127.0.0.1", "::1"},
+	}
+	guard := func(env string) writeGuard {
+		return writeGuard{
+			env:      func(string) string { return env },
+			resolver: resolver,
+			out:      &bytes.Buffer{},
+		}
+	}
+	prod := engine.Config{Method: "DELETE", URL: "https://api.example.com/items/1"}
+
+	err := guard("").check(prod)
+	if err == nil || !strings.Contains(err.Error(), "DELETE requests to api.example.com") {
+		t.Fatalf("DELETE against a public address should be refused, got %v", err)
+	}
+
+	for name, cfg := range map[string]engine.Config{
+		"GET":       {Method: "GET", URL: "https://api.example.com/"},
+		"private":   {Method: "POST", URL: "http://staging.internal/items"},
+		"localhost": {Method: "PUT", URL: "http://localhost:8080/items/1"},
+		"pinned":    {Method: "PATCH", URL: "https://api.example.com/", Resolve: map[string]string{"api.example.com:443": "192.168.1.5:443"}},
+	} {
+		if err := guard("").check(cfg); err != nil {
+			t.Errorf("%s: guard fired: %v", name, err)
+		}
+	}
+
+	// Methods are matched whatever their case.
+	lower := engine.Config{Method: "delete", URL: "https://api.example.com/items/1"}
+	if err := guard("").check(lower); err == nil || !strings.Contains(err.Error(), "DELETE requests to api.example.com") {
+		t.Errorf("lowercase delete against a public address should be refused, got %v", err)
+	}
+
+	// A weighted target or a replayed request can be the public one.
+	mixed := engine.Config{Method: "POST", Targets: []engine.Target{{URL: "http://localhost/", Weight: 1}, {URL: "https://api.example.com/", Weight: 1}}}
+	if guard("").check(mixed) == nil {
+		t.Error("POST with a public weighted target should be refused")
+	}
+	replay := engine.Config{Replay: []engine.ReplayRequest{{Method: "GET", URL: "https://api.example.com/"}, {Method: "DELETE", URL: "https://api.example.com/x"}}}
+	if guard("").check(replay) == nil {
+		t.Error("replayed DELETE against a public address should be refused")
+	}
+
+	// Bypasses.
+	yes := guard("")
+	yes.yes = true
+	if err := yes.check(prod); err != nil {
+		t.Errorf("--yes: %v", err)
+	}
+	if err := guard("1").check(prod); err != nil {
+		t.Errorf("%s=1: %v", allowWritesEnv, err)
+	}
+	if guard("0").check(prod) == nil {
+		t.Errorf("%s=0 should keep the guard", allowWritesEnv)
+	}
+
+	// On a terminal the user is asked.
+	for answer, ok := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false} {
+		g := guard("")
+		g.tty = true
+		g.in = strings.NewReader(answer)
+		out := &bytes.Buffer{}
+		g.out = out
+		err := g.check(prod)
+		if (err == nil) != ok {
+			t.Errorf("answer %q: got %v, want allowed=%v", answer, err, ok)
+		}
+		if !strings.Contains(out.String(), "Continue? [y/N]") {
+			t.Errorf("answer %q: no prompt in %q", answer, out.String())
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// parseSHA256 decodes a hex SHA-256 digest, as printed by sha256sum.
+func parseSHA256(s string) ([]byte, error) {
+	sum, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex digest %q", s)
+	}
+	if len(sum) != sha256.Size {
+		return nil, fmt.Errorf("digest must be %d bytes (%d hex characters), got %d bytes", sha256.Size, 2*sha256.Size, len(sum))
+	}
+	return sum, nil
+}
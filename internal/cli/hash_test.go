@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestParseSHA256(t *testing.T) {
+	want := sha256.Sum256([]byte("hello"))
+	got, err := parseSHA256(" " + hex.EncodeToString(want[:]) + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want[:]) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+	for _, bad := range []string{"", "zz", "abcd", hex.EncodeToString(want[:]) + "00"} {
+		if _, err := parseSHA256(bad); err == nil {
+			t.Errorf("parseSHA256(%q) should fail", bad)
+		}
+	}
+}
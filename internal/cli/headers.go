@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// parseHeaders turns repeated "Key: Value" flag values into an http.Header.
+func parseHeaders(raw []string) (http.Header, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	h := make(http.Header, len(raw))
+	for _, line := range raw {
+		key, value, err := parseHeaderLine(line)
+		if err != nil {
+			return nil, err
+		}
+		h.Add(key, value)
+	}
+	return h, nil
+}
+
+func parseHeaderLine(line string) (key, value string, err error) {
+	key, value, ok := strings.Cut(line, ":")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid header %q: expected \"Key: Value\"", line)
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// parseTrailers turns --trailer values into request trailers. Fields that
+// frame the message cannot be sent as trailers, so they are refused here
+// rather than silently dropped by the transport.
+func parseTrailers(raw []string) (http.Header, error) {
+	h, err := parseHeaders(raw)
+	if err != nil {
+		return nil, fmt.Errorf("--trailer: %w", err)
+	}
+	for key := range h {
+		switch key {
+		case "Transfer-Encoding", "Content-Length", "Trailer", "Host":
+			return nil, fmt.Errorf("--trailer: %s cannot be sent as a trailer", key)
+		}
+	}
+	return h, nil
+}
+
+// envRef matches ${NAME} references expanded in header files.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// loadHeaderFile reads "Key: Value" lines from path. Blank lines and lines
+// starting with # are skipped, and ${NAME} in values is replaced by the
+// environment variable NAME, so tokens need not be stored in the file. An
+// unset variable is an error rather than an empty credential.
+func loadHeaderFile(path string) (http.Header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("--headers-file: %w", err)
+	}
+	defer f.Close()
+
+	h := make(http.Header)
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, err := parseHeaderLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		var missing string
+		value = envRef.ReplaceAllStringFunc(value, func(ref string) string {
+			name := envRef.FindStringSubmatch(ref)[1]
+			v, ok := os.LookupEnv(name)
+			if !ok && missing == "" {
+				missing = name
+			}
+			return v
+		})
+		if missing != "" {
+			return nil, fmt.Errorf("%s:%d: environment variable %s is not set", path, n, missing)
+		}
+		h.Add(key, value)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("--headers-file: %w", err)
+	}
+	return h, nil
+}
+
+// mergeHeaders combines header sets in increasing precedence: a key present
+// in a later set replaces all of its values from earlier ones.
+func mergeHeaders(sets ...http.Header) http.Header {
+	var out http.Header
+	for _, h := range sets {
+		for key, values := range h {
+			if out == nil {
+				out = make(http.Header)
+			}
+			out[http.CanonicalHeaderKey(key)] = values
+		}
+	}
+	return out
+}
+
+// resolveHeaders loads the --headers-file files in order and applies the -H
+// flags on top, so later files override earlier ones and flags override all.
+func resolveHeaders(files, flags []string) (http.Header, error) {
+	sets := make([]http.Header, 0, len(files)+1)
+	for _, path := range files {
+		h, err := loadHeaderFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, h)
+	}
+	h, err := parseHeaders(flags)
+	if err != nil {
+		return nil, err
+	}
+	return mergeHeaders(append(sets, h)...), nil
+}
+
+// parseHeaderMatches turns --expect-header/--reject-header values, either
+// "Name" or "Name: value", into header matches.
+func parseHeaderMatches(flag string, raw []string) ([]engine.HeaderMatch, error) {
+	var out []engine.HeaderMatch
+	for _, s := range raw {
+		name, value, _ := strings.Cut(s, ":")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("%s %q: expected \"Name\" or \"Name: value\"", flag, s)
+		}
+		out = append(out, engine.HeaderMatch{Name: name, Value: strings.TrimSpace(value)})
+	}
+	return out, nil
+}
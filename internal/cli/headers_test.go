@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+func writeHeaderFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "headers.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadHeaderFile_MalformedLineReportsLineNumber(t *testing.T) {
+	path := writeHeaderFile(t, "# comment\nAccept: */*\n\nno colon here\n")
+	_, err := loadHeaderFile(path)
+	if err == nil || !strings.Contains(err.Error(), path+":4:") {
+		t.Fatalf("err = %v, want it to point at line 4", err)
+	}
+}
+
+func TestLoadHeaderFile_UnsetEnvIsAnError(t *testing.T) {
+	path := writeHeaderFile(t, "Authorization: Bearer ${HTTPCL_TEST_UNSET_TOKEN}\n")
+	_, err := loadHeaderFile(path)
+	if err == nil || !strings.Contains(err.Error(), "HTTPCL_TEST_UNSET_TOKEN") {
+		t.Fatalf("err = %v, want it to name the unset variable", err)
+	}
+}
+
+// TestHeadersFile_ReachServer loads two header files plus -H flags and checks
+// what the server receives: comments skipped, ${ENV} expanded, later files
+// overriding earlier ones and flags overriding both.
+func TestHeadersFile_ReachServer(t *testing.T) {
+	t.Setenv("HTTPCL_TEST_TOKEN", "s3cret")
+	first := writeHeaderFile(t, `# shared headers
+Authorization: Bearer ${HTTPCL_TEST_TOKEN}
+X-Env: staging
+X-Tag: a
+X-Tag: b
+
+X-Flag: from-file
+`)
+	second := writeHeaderFile(t, "X-Env: prod\n")
+
+	headers, err := resolveHeaders([]string{first, second}, []string{"X-Flag: from-flag"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var got []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got = append(got, r.Header.Clone())
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Headers:     headers,
+		Connections: 1,
+		Duration:    80 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	if err := engine.NewOrchestrator(cfg, noopRenderer{}).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) == 0 {
+		t.Fatal("server received no requests")
+	}
+	h := got[0]
+	if v := h.Get("Authorization"); v != "Bearer s3cret" {
+		t.Errorf("Authorization = %q", v)
+	}
+	if v := h.Get("X-Env"); v != "prod" {
+		t.Errorf("X-Env = %q, want the later file to win", v)
+	}
+	if v := h.Values("X-Tag"); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+		t.Errorf("X-Tag = %q, want both values", v)
+	}
+	if v := h.Values("X-Flag"); len(v) != 1 || v[0] != "from-flag" {
+		t.Errorf("X-Flag = %q, want the -H value only", v)
+	}
+}
+
+func TestParseHeaderMatches(t *testing.T) {
+	got, err := parseHeaderMatches("--reject-header", []string{"X-Error", "X-Status: failed "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []engine.HeaderMatch{{Name: "X-Error"}, {Name: "X-Status", Value: "failed"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if _, err := parseHeaderMatches("--reject-header", []string{": x"}); err == nil {
+		t.Error("expected an error for an empty header name")
+	}
+}
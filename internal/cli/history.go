@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/export"
+)
+
+// checkHistoryFlags validates --db, --db-name and --db-tag. Tags are
+// stored comma separated, so they must not contain commas.
+func checkHistoryFlags(path, name string, tags []string) error {
+	if path == "" {
+		if len(tags) > 0 {
+			return fmt.Errorf("--db-tag requires --db")
+		}
+		if name != "" {
+			return fmt.Errorf("--db-name requires --db")
+		}
+		return nil
+	}
+	for _, tag := range tags {
+		if tag == "" || strings.ContainsAny(tag, ",\n") {
+			return fmt.Errorf("--db-tag %q must be non-empty without ',' or line breaks", tag)
+		}
+	}
+	return nil
+}
+
+// writeHistory appends the finished run to the --db database. A failure is
+// a warning; the run itself already succeeded.
+func writeHistory(orch *engine.Orchestrator, startedAt time.Time) {
+	cfg := orch.Config()
+	report := export.NewReport(reportMeta(cfg, orch, startedAt), orch.FinalSnapshot(), nil)
+	if err := export.AppendHistory(flagDB, historyName(cfg), report, flagDBTags); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
+
+// historyName is the benchmark name a run is stored under: --db-name, or
+// the URL (the first target's with --url-weight).
+func historyName(cfg engine.Config) string {
+	switch {
+	case flagDBName != "":
+		return flagDBName
+	case cfg.URL == "" && len(cfg.Targets) > 0:
+		return cfg.Targets[0].URL
+	}
+	return cfg.URL
+}
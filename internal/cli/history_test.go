@@ -0,0 +1,23 @@
+package cli
+
+import "testing"
+
+func TestCheckHistoryFlags(t *testing.T) {
+	for _, tt := range []struct {
+		path, name string
+		tags       []string
+		ok         bool
+	}{
+		{"", "", nil, true},
+		{"runs.db", "", nil, true},
+		{"runs.db", "checkout", []string{"env:staging", "build 42"}, true},
+		{"", "", []string{"env:ci"}, false},
+		{"", "checkout", nil, false},
+		{"runs.db", "", []string{"a,b"}, false},
+		{"runs.db", "", []string{""}, false},
+	} {
+		if err := checkHistoryFlags(tt.path, tt.name, tt.tags); (err == nil) != tt.ok {
+			t.Errorf("checkHistoryFlags(%q, %q, %q) = %v, want ok=%v", tt.path, tt.name, tt.tags, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"io"
+	"os"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/term"
+)
+
+// enableKeyControls lets an interactive run be paused with p, resumed with
+// r and stopped with q: when stdin and stdout are a terminal that supports
+// it, stdin is switched to single keypresses feeding cfg.Controls. restore
+// puts the terminal back; ok is false if keys are not read.
+func enableKeyControls(cfg *engine.Config) (restore func(), ok bool) {
+	if !term.IsTerminal(os.Stdin) || !term.IsTerminal(os.Stdout) {
+		return nil, false
+	}
+	undo, err := term.KeyMode(os.Stdin)
+	if err != nil {
+		return nil, false
+	}
+	cfg.Controls = keyControls(os.Stdin)
+	return func() { _ = undo() }, true
+}
+
+// keyControls turns keypresses read from in into run controls. Other keys
+// are ignored, and a control is dropped if the previous one is still
+// pending. The reader stops at the first read error.
+func keyControls(in io.Reader) <-chan engine.Control {
+	ch := make(chan engine.Control, 1)
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := in.Read(buf); err != nil {
+				close(ch)
+				return
+			}
+			var c engine.Control
+			switch buf[0] {
+			case 'p', 'P':
+				c = engine.ControlPause
+			case 'r', 'R':
+				c = engine.ControlResume
+			case 'q', 'Q':
+				c = engine.ControlStop
+			default:
+				continue
+			}
+			select {
+			case ch <- c:
+			default:
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"io"
+	"testing"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+func TestKeyControls(t *testing.T) {
+	r, w := io.Pipe()
+	controls := keyControls(r)
+	for _, tc := range []struct {
+		keys string
+		want engine.Control
+	}{
+		{"xp", engine.ControlPause}, // other keys are ignored
+		{"R", engine.ControlResume},
+		{"\nq", engine.ControlStop},
+	} {
+		if _, err := w.Write([]byte(tc.keys)); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-controls; got != tc.want {
+			t.Errorf("%q: got control %d, want %d", tc.keys, got, tc.want)
+		}
+	}
+	_ = w.Close()
+	if _, ok := <-controls; ok {
+		t.Error("controls should close when input ends")
+	}
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// manifestFlags may be given with --from-manifest: they change what is
+// reported or written, not what is sent. The AWS credentials are there
+// because manifests never hold them, and --run-id because a rerun is a run
+// of its own.
+var manifestFlags = []string{
+	"from-manifest", "manifest", "out-dir", "out-artifacts", "output", "template", "template-file", "raw-out",
+	"latency-unit", "precision", "full-width", "yes", "run-id", "db", "db-tag", "db-name",
+	"aws-access-key-id", "aws-secret-access-key", "aws-session-token",
+}
+
+// buildVersion describes this build for manifests, e.g.
+// "v1.2.0 go1.25.4 rev 1a2b3c4d5e6f".
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	parts := []string{info.Main.Version, info.GoVersion}
+	for _, s := range info.Settings {
+		switch {
+		case s.Key == "vcs.revision":
+			parts = append(parts, "rev "+s.Value[:min(len(s.Value), 12)])
+		case s.Key == "vcs.modified" && s.Value == "true":
+			parts = append(parts, "modified")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// writeManifest saves the finished run to --manifest. A failure is only a
+// warning: the run itself succeeded.
+func writeManifest(orch *engine.Orchestrator, startedAt time.Time) {
+	f, err := os.Create(flagManifest)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: --manifest: %v\n", err)
+		return
+	}
+	err = engine.WriteManifest(f, engine.NewManifest(orch, buildVersion(), startedAt))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: --manifest: %v\n", err)
+		return
+	}
+	fmt.Fprintf(runLog(), "Manifest written to %s\n", flagManifest)
+}
+
+// manifestConfig reads the config of a --from-manifest run. Flags that
+// would change the requests or the load are refused, so the rerun is the
+// recorded one. A signed run needs its credentials again, from the
+// --aws-* flags or the environment.
+func manifestConfig(cmd *cobra.Command, path string) (engine.Config, error) {
+	var extra []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if !slices.Contains(manifestFlags, f.Name) {
+			extra = append(extra, "--"+f.Name)
+		}
+	})
+	if len(extra) > 0 {
+		return engine.Config{}, fmt.Errorf("%s cannot be used with --from-manifest", strings.Join(extra, ", "))
+	}
+	if err := validateOutputFlags(); err != nil {
+		return engine.Config{}, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return engine.Config{}, fmt.Errorf("--from-manifest: %w", err)
+	}
+	defer f.Close()
+	m, err := engine.ReadManifest(f)
+	if err != nil {
+		return engine.Config{}, fmt.Errorf("--from-manifest %s: %w", path, err)
+	}
+	if m.Build != buildVersion() {
+		fmt.Fprintf(os.Stderr, "warning: manifest was written by httpcl %s, this is %s\n", m.Build, buildVersion())
+	}
+	cfg := m.Config
+	if cfg.RunID, err = parseRunID(flagRunID); err != nil {
+		return engine.Config{}, err
+	}
+	if cfg.AWSSigV4 != nil {
+		flags := flagSigV4
+		flags.spec = cfg.AWSSigV4.Region + "/" + cfg.AWSSigV4.Service
+		if cfg.AWSSigV4, err = parseSigV4(flags, os.Getenv); err != nil {
+			return engine.Config{}, err
+		}
+	}
+	return cfg, nil
+}
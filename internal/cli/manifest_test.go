@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+func writeTestManifest(t *testing.T, cfg engine.Config) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "run.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m := engine.Manifest{ManifestVersion: engine.ManifestVersion, Build: buildVersion(), Seed: cfg.Seed, Config: cfg}
+	if err := engine.WriteManifest(f, m); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// manifestCmd returns a command with a run flag and an output flag, parsed
+// from args.
+func manifestCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{Use: "run"}
+	cmd.Flags().IntP("connections", "c", 10, "")
+	cmd.Flags().String("from-manifest", "", "")
+	cmd.Flags().String("raw-out", "", "")
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestManifestConfig_RefusesRunFlags(t *testing.T) {
+	path := writeTestManifest(t, engine.Config{URL: "http://example.test/", Connections: 4, Seed: 7})
+
+	_, err := manifestConfig(manifestCmd(t, "--from-manifest", path, "-c", "100"), path)
+	if err == nil || !strings.Contains(err.Error(), "--connections cannot be used with --from-manifest") {
+		t.Fatalf("err = %v", err)
+	}
+
+	cfg, err := manifestConfig(manifestCmd(t, "--from-manifest", path, "--raw-out", "raw.csv"), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Connections != 4 || cfg.Seed != 7 {
+		t.Errorf("config = %+v, want the recorded connections and seed", cfg)
+	}
+}
+
+func TestManifestConfig_ReloadsSigV4Credentials(t *testing.T) {
+	path := writeTestManifest(t, engine.Config{
+		URL:      "https://api.example.test/",
+		AWSSigV4: &engine.SigV4{Region: "eu-west-1", Service: "execute-api", AccessKeyID: "AKIDOLD", SecretAccessKey: "old"},
+	})
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDNEW")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "new")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	cfg, err := manifestConfig(manifestCmd(t, "--from-manifest", path), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cfg.AWSSigV4
+	if got == nil || got.Region != "eu-west-1" || got.Service != "execute-api" || got.AccessKeyID != "AKIDNEW" || got.SecretAccessKey != "new" {
+		t.Errorf("SigV4 = %+v, want the recorded region and service with the credentials from the environment", got)
+	}
+}
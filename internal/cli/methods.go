@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/ui"
+)
+
+// bodylessMethods are sent without the configured body by --methods.
+var bodylessMethods = []string{"GET", "HEAD", "OPTIONS", "TRACE"}
+
+// parseMethods parses the --methods list, e.g. "GET,POST,PUT", into upper
+// case method names. At least two are needed for a comparison.
+func parseMethods(s string) ([]string, error) {
+	var methods []string
+	for _, m := range strings.Split(s, ",") {
+		m = strings.ToUpper(strings.TrimSpace(m))
+		if m == "" || strings.ContainsAny(m, " \t\"(),/:;<=>?@[\\]{}") {
+			return nil, fmt.Errorf("--methods %q: invalid method %q", s, m)
+		}
+		if slices.Contains(methods, m) {
+			return nil, fmt.Errorf("--methods %q: %s is listed twice", s, m)
+		}
+		methods = append(methods, m)
+	}
+	if len(methods) < 2 {
+		return nil, fmt.Errorf("--methods %q: list at least two methods to compare", s)
+	}
+	return methods, nil
+}
+
+// runMethodComparison runs cfg once per method, back to back, each phase
+// with its own report, then prints their throughput and latency in one
+// table.
+func runMethodComparison(cfg engine.Config, methods []string) error {
+	runs, err := runPhases(methods, methodPhases(cfg, methods))
+	if runs == nil {
+		return err
+	}
+	unit, _ := ui.ParseLatencyUnit(flagLatUnit)
+	ui.PrintComparisonTable(unit, runs)
+	return err
+}
+
+// methodPhases returns cfg once per method. GET, HEAD, OPTIONS and TRACE
+// phases leave the body out; the others send the configured one.
+func methodPhases(cfg engine.Config, methods []string) []engine.Config {
+	cfgs := make([]engine.Config, len(methods))
+	for i, m := range methods {
+		cfgs[i] = cfg
+		cfgs[i].Method = m
+		if slices.Contains(bodylessMethods, m) {
+			cfgs[i].Body, cfgs[i].BodyCorpus, cfgs[i].BodySize, cfgs[i].ContentType = nil, nil, 0, ""
+		}
+	}
+	return cfgs
+}
+
+// methodsGuardConfig returns a config whose requests are those of every
+// --methods phase, so the write guard asks about all of them at once.
+func methodsGuardConfig(cfg engine.Config, methods []string) engine.Config {
+	guard := cfg
+	guard.Replay = nil
+	for _, m := range methods {
+		mcfg := cfg
+		mcfg.Method = m
+		guard.Replay = append(guard.Replay, guardedRequests(mcfg)...)
+	}
+	return guard
+}
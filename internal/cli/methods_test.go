@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+func TestParseMethods(t *testing.T) {
+	got, err := parseMethods("get, POST,put")
+	if err != nil || strings.Join(got, ",") != "GET,POST,PUT" {
+		t.Errorf(`parseMethods("get, POST,put") = %q, %v`, got, err)
+	}
+	for in, want := range map[string]string{
+		"GET":          "at least two",
+		"GET,,POST":    "invalid method",
+		"GET,PO ST":    "invalid method",
+		"GET,get":      "listed twice",
+		"GET,POST/PUT": "invalid method",
+	} {
+		if _, err := parseMethods(in); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parseMethods(%q) error = %v, want %q", in, err, want)
+		}
+	}
+}
+
+// TestMethodPhases_SeparateSnapshots runs a GET and a POST phase against a
+// server that is slower for POST and checks each phase has its own
+// snapshot, and only the POST phase sends the body.
+func TestMethodPhases_SeparateSnapshots(t *testing.T) {
+	var mu sync.Mutex
+	counts := map[string]uint64{}
+	bodies := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		counts[r.Method]++
+		bodies[r.Method] = string(body)
+		mu.Unlock()
+		if r.Method == http.MethodPost {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		URL:         srv.URL,
+		Body:        []byte(`{"n":1}`),
+		ContentType: "application/json",
+		Connections: 1,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	methods := []string{"GET", "POST"}
+	runs, err := runPhases(methods, methodPhases(cfg, methods))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(runs) != 2 || runs[0].Label != "GET" || runs[1].Label != "POST" {
+		t.Fatalf("runs = %+v, want a GET and a POST phase", runs)
+	}
+	for _, r := range runs {
+		if r.Snap.TotalRequests == 0 || r.Snap.TotalRequests != counts[r.Label] {
+			t.Errorf("%s phase recorded %d requests, server saw %d", r.Label, r.Snap.TotalRequests, counts[r.Label])
+		}
+	}
+	if get, post := runs[0].Snap, runs[1].Snap; post.LatencyP50 < 5*time.Millisecond || get.LatencyP50 >= post.LatencyP50 {
+		t.Errorf("p50 GET %s, POST %s; want the slow POSTs only in the POST phase", get.LatencyP50, post.LatencyP50)
+	}
+	if bodies["GET"] != "" || bodies["POST"] != `{"n":1}` {
+		t.Errorf("bodies by method = %q, want the body on POST only", bodies)
+	}
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/ui"
+)
+
+// runOnce sends the single request of `httpcl once` and prints its status
+// line to w. A request that failed, by the same rules as a run, is returned
+// as an error so the process exits non-zero.
+func runOnce(ctx context.Context, cfg engine.Config, w io.Writer) error {
+	res, err := engine.NewOrchestrator(cfg, ui.NewRenderer()).Once(ctx)
+	if err != nil {
+		return err
+	}
+	latency := res.Latency.Round(10 * time.Microsecond)
+	if res.Status != "" {
+		fmt.Fprintf(w, "%s %s in %s (%s)\n", res.Proto, res.Status, latency, ui.HumanizeBytes(res.Bytes))
+	}
+	if !res.Success {
+		return fmt.Errorf("request failed after %s: %s: %s", latency, res.ErrorCategory, res.ErrorMessage)
+	}
+	return nil
+}
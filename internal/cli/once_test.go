@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+func TestRunOnce_ExitStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	cfg := engine.Config{URL: srv.URL + "/up", Headers: http.Header{"Authorization": {"Bearer t"}}}
+	if err := runOnce(t.Context(), cfg, &out); err != nil {
+		t.Fatalf("healthy endpoint: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "HTTP/1.1 200 OK in ") {
+		t.Errorf("status line = %q", out.String())
+	}
+
+	out.Reset()
+	cfg.URL = srv.URL + "/down"
+	err := runOnce(t.Context(), cfg, &out)
+	if err == nil || !strings.Contains(err.Error(), "http_5xx") {
+		t.Errorf("503 should fail with an http_5xx error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "503 Service Unavailable") {
+		t.Errorf("status line = %q", out.String())
+	}
+
+	srv.Close()
+	cfg.URL = srv.URL + "/up"
+	if err := runOnce(t.Context(), cfg, &out); err == nil || !strings.Contains(err.Error(), "connect") {
+		t.Errorf("closed server should fail with a connect error, got %v", err)
+	}
+}
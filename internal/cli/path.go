@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// overridePath replaces the path and query of rawURL with path, which must
+// start with "/" and may carry its own "?query". Scheme, credentials and
+// host are kept. path is spliced in as text rather than set on a url.URL,
+// which would escape the braces of {{...}} placeholders.
+func overridePath(rawURL, path string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("--url: %w", err)
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return "", fmt.Errorf("--path needs an absolute --url, got %q", rawURL)
+	}
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("--path must start with /, got %q", path)
+	}
+	if strings.Contains(path, "#") {
+		return "", fmt.Errorf("--path must not contain a fragment, got %q", path)
+	}
+	base := u.Scheme + "://"
+	if u.User != nil {
+		base += u.User.String() + "@"
+	}
+	out := base + u.Host + path
+	if _, err := url.Parse(out); err != nil {
+		return "", fmt.Errorf("--path: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+func TestOverridePath(t *testing.T) {
+	cases := []struct{ url, path, want string }{
+		{"https://api.example.com/v1/users?page=2", "/v2/orders", "https://api.example.com/v2/orders"},
+		{"http://u:p@127.0.0.1:8080/", "/items?id=7", "http://u:p@127.0.0.1:8080/items?id=7"},
+		{"http://[::1]:9000/old", "/items/{{seq}}", "http://[::1]:9000/items/{{seq}}"},
+	}
+	for _, c := range cases {
+		got, err := overridePath(c.url, c.path)
+		if err != nil || got != c.want {
+			t.Errorf("overridePath(%q, %q) = %q, %v; want %q", c.url, c.path, got, err, c.want)
+		}
+	}
+	for _, bad := range [][2]string{
+		{"https://api.example.com/", "orders"},
+		{"https://api.example.com/", "/orders#top"},
+		{"/relative", "/orders"},
+		{"https://api.example.com/", "/bad%zz"},
+	} {
+		if _, err := overridePath(bad[0], bad[1]); err == nil {
+			t.Errorf("overridePath(%q, %q) should fail", bad[0], bad[1])
+		}
+	}
+}
+
+func TestOverridePath_HitsServer(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.RequestURI()
+	}))
+	defer srv.Close()
+
+	u, err := overridePath(srv.URL+"/health?verbose=1", "/api/items?limit=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := runOnce(t.Context(), engine.Config{URL: u}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if got != "/api/items?limit=5" {
+		t.Errorf("server saw %q", got)
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// profileSetting is one "flag = value" line of a --config file.
+type profileSetting struct {
+	flag, value string
+	line        int
+}
+
+// loadConfigFile reads a --config file: "flag = value" lines giving run flag
+// defaults, grouped into named profiles by "[name]" headers. Lines before
+// the first header apply to every profile. Blank lines and lines starting
+// with # are skipped; a repeatable flag may be given on several lines.
+func loadConfigFile(path string) (common []profileSetting, profiles map[string][]profileSetting, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("--config: %w", err)
+	}
+	defer f.Close()
+
+	profiles = make(map[string][]profileSetting)
+	current := ""
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			name, ok := strings.CutSuffix(line[1:], "]")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" {
+				return nil, nil, fmt.Errorf("%s:%d: invalid profile header %q: expected \"[name]\"", path, n, line)
+			}
+			if _, dup := profiles[name]; dup {
+				return nil, nil, fmt.Errorf("%s:%d: profile %q defined twice", path, n, name)
+			}
+			profiles[name] = nil
+			current = name
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimPrefix(strings.TrimSpace(key), "--")
+		if !ok || key == "" {
+			return nil, nil, fmt.Errorf("%s:%d: invalid line %q: expected \"flag = value\"", path, n, line)
+		}
+		s := profileSetting{flag: key, value: strings.TrimSpace(value), line: n}
+		if current == "" {
+			common = append(common, s)
+		} else {
+			profiles[current] = append(profiles[current], s)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, fmt.Errorf("--config: %w", err)
+	}
+	return common, profiles, nil
+}
+
+// applyProfileFlags applies --config and --profile to the commands taking the
+// run flags, before the banner is printed.
+func applyProfileFlags(cmd *cobra.Command, _ []string) error {
+	return applyConfigFile(cmd, flagConfig, flagProfile)
+}
+
+// applyConfigFile sets the flags of cmd that were not given on the command
+// line from the config file at path: its common settings, then those of
+// profile if named. The flags then behave as if given explicitly, so they
+// are validated the same way. A missing profile is an error.
+func applyConfigFile(cmd *cobra.Command, path, profile string) error {
+	if path == "" {
+		if profile != "" {
+			return fmt.Errorf("--profile requires --config")
+		}
+		return nil
+	}
+	common, profiles, err := loadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	flags := cmd.Flags()
+	// Flag names are checked in every profile, so a typo shows up before
+	// the profile holding it is needed.
+	for _, ps := range append([][]profileSetting{common}, slices.Collect(maps.Values(profiles))...) {
+		for _, s := range ps {
+			if flags.Lookup(s.flag) == nil || s.flag == "config" || s.flag == "profile" {
+				return fmt.Errorf("%s:%d: unknown flag %q", path, s.line, s.flag)
+			}
+		}
+	}
+	settings := common
+	if profile != "" {
+		ps, ok := profiles[profile]
+		if !ok {
+			names := make([]string, 0, len(profiles))
+			for name := range profiles {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return fmt.Errorf("--profile %q is not in %s (profiles: %s)", profile, path, strings.Join(names, ", "))
+		}
+		settings = append(slices.Clip(common), ps...)
+	}
+
+	explicit := make(map[string]bool)
+	for _, s := range settings {
+		explicit[s.flag] = flags.Changed(s.flag)
+	}
+	for _, s := range settings {
+		if explicit[s.flag] {
+			continue
+		}
+		// A profile line overrides a common one; repeatable flags collect
+		// both.
+		if err := flags.Set(s.flag, s.value); err != nil {
+			return fmt.Errorf("%s:%d: --%s: %w", path, s.line, s.flag, err)
+		}
+	}
+	return nil
+}
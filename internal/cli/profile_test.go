@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const testProfiles = `# team benchmark settings
+duration = 30s
+header = X-Team: perf
+
+[staging]
+connections = 50
+--max-error-rate = 0.05
+
+[prod-canary]
+connections = 5
+duration = 2m
+header = X-Canary: 1
+`
+
+// profileCmd returns a command with a few run-like flags, parsed from args.
+func profileCmd(t *testing.T, args ...string) (cmd *cobra.Command, conns *int, dur *time.Duration, rate *float64, headers *[]string) {
+	t.Helper()
+	cmd = &cobra.Command{Use: "run"}
+	conns = cmd.Flags().IntP("connections", "c", 10, "")
+	dur = cmd.Flags().Duration("duration", 10*time.Second, "")
+	rate = cmd.Flags().Float64("max-error-rate", 0, "")
+	headers = cmd.Flags().StringArrayP("header", "H", nil, "")
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatal(err)
+	}
+	return cmd, conns, dur, rate, headers
+}
+
+func TestApplyConfigFile_Profiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "httpcl.conf")
+	if err := os.WriteFile(path, []byte(testProfiles), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, conns, dur, rate, headers := profileCmd(t)
+	if err := applyConfigFile(cmd, path, "staging"); err != nil {
+		t.Fatal(err)
+	}
+	if *conns != 50 || *dur != 30*time.Second || *rate != 0.05 || !slices.Equal(*headers, []string{"X-Team: perf"}) {
+		t.Errorf("staging: connections=%d duration=%s max-error-rate=%v headers=%q", *conns, *dur, *rate, *headers)
+	}
+
+	// Explicit flags win over the profile; repeatable flags collect lines.
+	cmd, conns, dur, rate, headers = profileCmd(t, "-c", "8")
+	if err := applyConfigFile(cmd, path, "prod-canary"); err != nil {
+		t.Fatal(err)
+	}
+	if *conns != 8 || *dur != 2*time.Minute || *rate != 0 || !slices.Equal(*headers, []string{"X-Team: perf", "X-Canary: 1"}) {
+		t.Errorf("prod-canary: connections=%d duration=%s max-error-rate=%v headers=%q", *conns, *dur, *rate, *headers)
+	}
+
+	// Without a profile only the common settings apply.
+	cmd, conns, dur, _, _ = profileCmd(t)
+	if err := applyConfigFile(cmd, path, ""); err != nil {
+		t.Fatal(err)
+	}
+	if *conns != 10 || *dur != 30*time.Second {
+		t.Errorf("common: connections=%d duration=%s", *conns, *dur)
+	}
+
+	cmd, _, _, _, _ = profileCmd(t)
+	err := applyConfigFile(cmd, path, "prod")
+	if err == nil || !strings.Contains(err.Error(), "prod-canary, staging") {
+		t.Errorf("missing profile: %v", err)
+	}
+	if err := applyConfigFile(cmd, "", "staging"); err == nil {
+		t.Error("--profile without --config should fail")
+	}
+}
+
+func TestApplyConfigFile_Errors(t *testing.T) {
+	cases := map[string]string{
+		"unknown flag":  "[a]\nconections = 5\n",
+		"bad value":     "connections = many\n",
+		"no equals":     "connections 5\n",
+		"bad header":    "[a\n",
+		"duplicate":     "[a]\n[a]\n",
+		"config itself": "config = other.conf\n",
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "httpcl.conf")
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cmd, _, _, _, _ := profileCmd(t)
+			cmd.Flags().String("config", "", "")
+			err := applyConfigFile(cmd, path, "")
+			if err == nil || !strings.Contains(err.Error(), path+":") {
+				t.Errorf("want a path:line error, got %v", err)
+			}
+		})
+	}
+}
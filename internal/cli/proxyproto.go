@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// parseProxyProtocol validates --proxy-protocol ("v1", "v2" or "" for off)
+// and --proxy-protocol-source ("ip:port", optional).
+func parseProxyProtocol(version, source string) (int, netip.AddrPort, error) {
+	var v int
+	switch strings.ToLower(version) {
+	case "":
+		if source != "" {
+			return 0, netip.AddrPort{}, fmt.Errorf("--proxy-protocol-source requires --proxy-protocol")
+		}
+		return 0, netip.AddrPort{}, nil
+	case "v1", "1":
+		v = 1
+	case "v2", "2":
+		v = 2
+	default:
+		return 0, netip.AddrPort{}, fmt.Errorf("--proxy-protocol must be v1 or v2, got %q", version)
+	}
+	if source == "" {
+		return v, netip.AddrPort{}, nil
+	}
+	src, err := netip.ParseAddrPort(source)
+	if err != nil {
+		return 0, netip.AddrPort{}, fmt.Errorf("--proxy-protocol-source: expected ip:port: %w", err)
+	}
+	return v, src, nil
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestParseProxyProtocol(t *testing.T) {
+	v, src, err := parseProxyProtocol("v2", "203.0.113.7:4242")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 2 || src != netip.MustParseAddrPort("203.0.113.7:4242") {
+		t.Errorf("got v%d %s", v, src)
+	}
+	if v, src, err := parseProxyProtocol("V1", ""); err != nil || v != 1 || src.IsValid() {
+		t.Errorf("v1 without source: got v%d %s, %v", v, src, err)
+	}
+	for _, tc := range [][2]string{{"v3", ""}, {"v1", "203.0.113.7"}, {"", "203.0.113.7:1"}} {
+		if _, _, err := parseProxyProtocol(tc[0], tc[1]); err == nil {
+			t.Errorf("parseProxyProtocol(%q, %q): expected an error", tc[0], tc[1])
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// parseRate parses --rate: a fixed rate in requests per second, or
+// "auto:F" to pace at fraction F of the throughput --calibrate measures.
+// Exactly one of the results is non-zero for a non-empty s.
+func parseRate(s string) (rate, fraction float64, err error) {
+	if s == "" {
+		return 0, 0, nil
+	}
+	if f, ok := strings.CutPrefix(s, "auto:"); ok {
+		fraction, err = strconv.ParseFloat(f, 64)
+		if err != nil || !(fraction > 0 && fraction <= 1) {
+			return 0, 0, fmt.Errorf("--rate auto:F needs a fraction in (0, 1], got %q", f)
+		}
+		return 0, fraction, nil
+	}
+	rate, err = strconv.ParseFloat(s, 64)
+	if err != nil || !(rate > 0) || math.IsInf(rate, 0) {
+		return 0, 0, fmt.Errorf("--rate must be a positive number of requests per second or auto:F, got %q", s)
+	}
+	return rate, 0, nil
+}
@@ -0,0 +1,27 @@
+package cli
+
+import "testing"
+
+func TestParseRate(t *testing.T) {
+	cases := []struct {
+		in             string
+		rate, fraction float64
+	}{
+		{"", 0, 0},
+		{"250", 250, 0},
+		{"0.5", 0.5, 0},
+		{"auto:0.8", 0, 0.8},
+		{"auto:1", 0, 1},
+	}
+	for _, c := range cases {
+		rate, fraction, err := parseRate(c.in)
+		if err != nil || rate != c.rate || fraction != c.fraction {
+			t.Errorf("parseRate(%q) = %v, %v, %v; want %v, %v", c.in, rate, fraction, err, c.rate, c.fraction)
+		}
+	}
+	for _, bad := range []string{"0", "-5", "fast", "auto", "auto:0", "auto:1.5", "auto:x", "+Inf"} {
+		if _, _, err := parseRate(bad); err == nil {
+			t.Errorf("parseRate(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// replayRecord is one line of a replay-jsonl file.
+type replayRecord struct {
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	BodyBase64 string            `json:"body_base64"`
+	Time       time.Time         `json:"time"`
+}
+
+// loadReplayFile reads a replay-jsonl file; see parseReplay.
+func loadReplayFile(path, base string) ([]engine.ReplayRequest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseReplay(f, path, base)
+}
+
+// parseReplay reads one JSON request per line. Blank lines are skipped;
+// method defaults to GET; relative URLs are resolved against base, which may
+// be empty if every URL is absolute. Capture times become offsets from the
+// first timed line; a line without one, or with one earlier than the line
+// before it, keeps the previous offset. Errors are prefixed with name:line.
+func parseReplay(r io.Reader, name, base string) ([]engine.ReplayRequest, error) {
+	var baseURL *url.URL
+	if base != "" {
+		var err error
+		if baseURL, err = url.Parse(base); err != nil {
+			return nil, fmt.Errorf("--url: %w", err)
+		}
+	}
+	var out []engine.ReplayRequest
+	var first time.Time
+	var at time.Duration
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		// ReadBytes rather than a Scanner: captured bodies can exceed any
+		// fixed line limit.
+		raw, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 {
+			req, captured, perr := parseReplayLine(trimmed, baseURL)
+			if perr != nil {
+				return nil, fmt.Errorf("%s:%d: %w", name, line, perr)
+			}
+			if !captured.IsZero() {
+				if first.IsZero() {
+					first = captured
+				}
+				at = max(at, captured.Sub(first))
+			}
+			req.At = at
+			out = append(out, req)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%s: no requests", name)
+	}
+	return out, nil
+}
+
+func parseReplayLine(raw []byte, base *url.URL) (engine.ReplayRequest, time.Time, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var rec replayRecord
+	if err := dec.Decode(&rec); err != nil {
+		return engine.ReplayRequest{}, time.Time{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return engine.ReplayRequest{}, time.Time{}, fmt.Errorf("invalid JSON: more than one object on the line")
+	}
+
+	method := strings.ToUpper(rec.Method)
+	if method == "" {
+		method = "GET"
+	}
+	if strings.ContainsAny(method, " \t") {
+		return engine.ReplayRequest{}, time.Time{}, fmt.Errorf("invalid method %q", rec.Method)
+	}
+
+	if rec.URL == "" {
+		return engine.ReplayRequest{}, time.Time{}, fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(rec.URL)
+	if err != nil {
+		return engine.ReplayRequest{}, time.Time{}, fmt.Errorf("url: %w", err)
+	}
+	if !u.IsAbs() {
+		if base == nil {
+			return engine.ReplayRequest{}, time.Time{}, fmt.Errorf("relative url %q needs a base --url", rec.URL)
+		}
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return engine.ReplayRequest{}, time.Time{}, fmt.Errorf("url %q must be http or https", u)
+	}
+
+	body := []byte(rec.Body)
+	if rec.BodyBase64 != "" {
+		if rec.Body != "" {
+			return engine.ReplayRequest{}, time.Time{}, fmt.Errorf("body and body_base64 cannot be combined")
+		}
+		if body, err = base64.StdEncoding.DecodeString(rec.BodyBase64); err != nil {
+			return engine.ReplayRequest{}, time.Time{}, fmt.Errorf("body_base64: %w", err)
+		}
+	}
+
+	var header http.Header
+	if len(rec.Headers) > 0 {
+		header = make(http.Header, len(rec.Headers))
+		for k, v := range rec.Headers {
+			header.Set(k, v)
+		}
+	}
+	return engine.ReplayRequest{Method: method, URL: u.String(), Header: header, Body: body}, rec.Time, nil
+}
+
+// requestFlags are run flags that shape the request, which replay-jsonl and
+// --transaction take from a file instead.
+var requestFlags = []string{"method", "methods", "body", "body-file", "json", "data", "body-dir", "body-cycle", "body-size", "body-random", "grpc"}
+
+// rejectFlags fails if any of the named flags was set alongside mode.
+func rejectFlags(cmd *cobra.Command, mode string, names ...string) error {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with %s", name, mode)
+		}
+	}
+	return nil
+}
+
+// replayConfigFromFlags builds the run config for `replay-jsonl path`. The
+// replay file supplies each request; --url is optional and serves as the
+// base for relative URLs. Without it the first request names the target.
+func replayConfigFromFlags(cmd *cobra.Command, path string) (engine.Config, error) {
+	if err := rejectFlags(cmd, "replay-jsonl", append(requestFlags, "transaction", "url-weight", "path")...); err != nil {
+		return engine.Config{}, err
+	}
+	reqs, err := loadReplayFile(path, flagURL)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	if flagURL == "" {
+		flagURL = reqs[0].URL
+	}
+	cfg, err := runConfigFromFlags()
+	if err != nil {
+		return engine.Config{}, err
+	}
+	if flagReplaySpeed < 0 {
+		return engine.Config{}, fmt.Errorf("--replay-speed must not be negative")
+	}
+	if flagReplaySpeed > 0 && (flagRate != "" || flagAdaptive || flagBurst > 0) {
+		return engine.Config{}, fmt.Errorf("--replay-speed cannot be combined with --rate, --adaptive-rate or --burst")
+	}
+	cfg.Replay = reqs
+	cfg.ReplaySpeed = flagReplaySpeed
+	return cfg, nil
+}
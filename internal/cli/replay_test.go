@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReplay(t *testing.T) {
+	in := `{"url": "/a"}
+
+{"method": "post", "url": "/b?x=1", "headers": {"X-Id": "7"}, "body": "{}"}
+{"method": "PUT", "url": "http://other.example/c", "body_base64": "AAEC"}
+`
+	got, err := parseReplay(strings.NewReader(in), "reqs.jsonl", "http://example.com/api/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d requests, want 3", len(got))
+	}
+	if got[0].Method != "GET" || got[0].URL != "http://example.com/a" || len(got[0].Body) != 0 {
+		t.Errorf("request 1 = %+v", got[0])
+	}
+	if got[1].Method != "POST" || got[1].URL != "http://example.com/b?x=1" || got[1].Header.Get("X-Id") != "7" || string(got[1].Body) != "{}" {
+		t.Errorf("request 2 = %+v", got[1])
+	}
+	if got[2].URL != "http://other.example/c" || string(got[2].Body) != "\x00\x01\x02" {
+		t.Errorf("request 3 = %+v", got[2])
+	}
+}
+
+func TestParseReplay_Errors(t *testing.T) {
+	tests := []struct {
+		in, base, want string
+	}{
+		{"{\"url\": \"/a\"}\n{\"url\": ", "http://example.com", "reqs.jsonl:2: invalid JSON"},
+		{"{\"url\": \"/a\"}", "", "reqs.jsonl:1: relative url"},
+		{"\n\n{\"method\": \"GET\"}", "", "reqs.jsonl:3: url is required"},
+		{"{\"url\": \"http://x/\", \"bodyy\": \"\"}", "", "reqs.jsonl:1: invalid JSON"},
+		{"{\"url\": \"http://x/\", \"body\": \"a\", \"body_base64\": \"YQ==\"}", "", "reqs.jsonl:1: body and body_base64"},
+		{"{\"url\": \"http://x/\", \"body_base64\": \"!\"}", "", "reqs.jsonl:1: body_base64"},
+		{"{\"url\": \"ftp://x/\"}", "", "must be http or https"},
+		{"\n", "", "no requests"},
+	}
+	for _, tt := range tests {
+		_, err := parseReplay(strings.NewReader(tt.in), "reqs.jsonl", tt.base)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseReplay(%q) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestParseReplay_Times(t *testing.T) {
+	in := `{"url": "/a", "time": "2026-03-01T10:00:00.5Z"}
+{"url": "/b"}
+{"url": "/c", "time": "2026-03-01T10:00:02Z"}
+{"url": "/d", "time": "2026-03-01T10:00:01Z"}
+`
+	got, err := parseReplay(strings.NewReader(in), "reqs.jsonl", "http://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Duration{0, 0, 1500 * time.Millisecond, 1500 * time.Millisecond}
+	for i, r := range got {
+		if r.At != want[i] {
+			t.Errorf("request %d At = %s, want %s", i+1, r.At, want[i])
+		}
+	}
+	if _, err := parseReplay(strings.NewReader(`{"url": "/a", "time": "yesterday"}`), "reqs.jsonl", "http://example.com/"); err == nil || !strings.Contains(err.Error(), "reqs.jsonl:1: invalid JSON") {
+		t.Errorf("bad time: error = %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// parseResolve parses curl-style --resolve entries "host:port:addr" into the
+// engine's "host:port" -> "addr:port" map. addr may be a bracketed IPv6
+// address.
+func parseResolve(entries []string) (map[string]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(entries))
+	for _, e := range entries {
+		parts := strings.SplitN(strings.TrimSpace(e), ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid --resolve %q (want host:port:addr)", e)
+		}
+		host, port, addr := parts[0], parts[1], strings.Trim(parts[2], "[]")
+		if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+			return nil, fmt.Errorf("invalid port in --resolve %q", e)
+		}
+		if net.ParseIP(addr) == nil {
+			return nil, fmt.Errorf("invalid address in --resolve %q: %q is not an IP", e, addr)
+		}
+		out[net.JoinHostPort(strings.ToLower(host), port)] = net.JoinHostPort(addr, port)
+	}
+	return out, nil
+}
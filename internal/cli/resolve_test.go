@@ -0,0 +1,27 @@
+package cli
+
+import "testing"
+
+func TestParseResolve(t *testing.T) {
+	got, err := parseResolve([]string{"API.example.com:443:10.0.0.5", "example.com:8080:[::1]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"api.example.com:443": "10.0.0.5:443",
+		"example.com:8080":    "[::1]:8080",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: got %q, want %q", k, got[k], v)
+		}
+	}
+	for _, bad := range []string{"example.com", "example.com:443", "example.com:http:1.2.3.4", "example.com:443:not-an-ip", ":443:1.2.3.4"} {
+		if _, err := parseResolve([]string{bad}); err == nil {
+			t.Errorf("parseResolve(%q) should fail", bad)
+		}
+	}
+}
@@ -1,13 +1,18 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/export"
 	"github.com/thetangentline/httpcl/internal/ui"
 )
 
@@ -20,80 +25,804 @@ var rootCmd = &cobra.Command{
 var (
 	flagMethod      string
 	flagURL         string
+	flagPath        string
 	flagBody        string
+	flagBodyFile    string
 	flagConnections int
 	flagDuration    time.Duration
 	flagWorkers     int
 	flagPipeline    int
+	flagOutDir      string
+	flagArtifacts   []string
+	flagHeaders     []string
+	flagMaxBytes    string
+	flagJSON        string
+	flagContentType string
+	flagVerbose     bool
+	flagDrain       time.Duration
+	flagData        []string
+	flagStopSigs    []string
+	flagStatusSigs  []string
+	flagBurst       int
+	flagBurstEvery  time.Duration
+	flagExpectHash  string
+	flagDecode      bool
+	flagAssertJSON  []string
+	flagSkipDNS     bool
+	flagDNSCache    string
+	flagResolve     []string
+	flagBodyDir     string
+	flagBodyCycle   bool
+	flagBodySize    string
+	flagBodyRandom  bool
+	flagSeed        uint64
+	flagMaxErrRate  float64
+	flagMinRPS      float64
+	flagMinRPSWarm  time.Duration
+	flagAdaptive    bool
+	flagTargetP99   time.Duration
+	flagAdaptEvery  time.Duration
+	flagBackoffErrs bool
+	flagSteps       string
+	flagHeaderFiles []string
+	flagMaxPerConn  int
+	flagGRPC        bool
+	flagExpectHdrs  []string
+	flagRejectHdrs  []string
+	flagTrailers    []string
+	flagExpectTrls  []string
+	flagRejectTrls  []string
+	flagProxyProto  string
+	flagProxySource string
+	flagCapConns    bool
+	flagPoolWarn    float64
+	flagStrict      bool
+	flagConnBench   bool
+	flagOutput      string
+	flagTemplate    string
+	flagTmplFile    string
+	flagRawOut      string
+	flagReadBuf     string
+	flagWriteBuf    string
+	flagSimulate    string
+	flagLatUnit     string
+	flagPrecision   int
+	flagStatsMem    string
+	flagRetries     int
+	flagRetryDelay  time.Duration
+	flagBackoff     string
+	flagRetryJitter bool
+	flagPerConn     bool
+	flagShowAddrs   bool
+	flagSigV4       sigV4Flags
+	flagIfNoneMatch string
+	flagIfModSince  string
+	flagETagChain   bool
+	flagTransaction string
+	flagStagger     time.Duration
+	flagSoakReport  time.Duration
+	flagInsecure    bool
+	flagCertWarn    time.Duration
+	flagRate        string
+	flagCalibrate   time.Duration
+	flagDiscardConn int
+	flagCompare     bool
+	flagFullWidth   bool
+	flagRetryAnyMth bool
+	flagStatsD      string
+	flagStatsDPfx   string
+	flagStatsDTags  []string
+	flagURLWeights  []string
+	flagYes         bool
+	flagConfig      string
+	flagProfile     string
+	flagManifest    string
+	flagRerun       string
+	flagRunID       string
+	flagMethods     string
+	flagShardKey    string
+	flagLowRPSWarn  float64
+	flagDB          string
+	flagDBTags      []string
+	flagDBName      string
+	flagTrendDB     string
+	flagTrendName   string
+	flagTrendTags   []string
+	flagTrendSince  string
+	flagTrendUntil  string
+	flagTrendLast   int
+	flagTrendThresh float64
+	flagCount429    string
+	flagReplaySpeed float64
 )
 
 func init() {
+	// The intro banner is shown once per command, after its flags and any
+	// --config profile are applied, so that --output json can keep it off
+	// stdout.
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Lookup("config") != nil {
+			if err := applyProfileFlags(cmd, args); err != nil {
+				return err
+			}
+		}
+		ui.PrintIntroBanner(runLog())
+		return nil
+	}
+
 	// start (interactive) command
 	startCmd := &cobra.Command{
 		Use:   "start",
 		Short: "Start interactive benchmark wizard",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			wcfg, err := ui.RunInteractiveWizard()
+			wcfg, err := ui.RunInteractiveWizard(flagFullWidth, wizardURLCheck(flagSkipDNS), wizardHeaderCheck)
+			if err != nil {
+				return err
+			}
+			headers, err := parseHeaders(wcfg.Headers)
 			if err != nil {
 				return err
 			}
 			cfg := engine.Config{
-				Method:      wcfg.Method,
-				URL:         wcfg.URL,
-				Body:        wcfg.Body,
-				Connections: wcfg.Connections,
-				Duration:    wcfg.Duration,
-				Workers:     wcfg.Workers,
-				Pipeline:    wcfg.Pipeline,
+				Method:       wcfg.Method,
+				URL:          wcfg.URL,
+				Body:         wcfg.Body,
+				Headers:      headers,
+				Connections:  wcfg.Connections,
+				Duration:     wcfg.Duration,
+				Workers:      wcfg.Workers,
+				Pipeline:     wcfg.Pipeline,
+				SkipDNSCheck: flagSkipDNS,
+				RunID:        engine.NewRunID(),
+				LowRPSWarn:   engine.DefaultLowRPSWarn,
 			}
 			return runBenchmark(cfg)
 		},
 	}
+	startCmd.Flags().BoolVar(&flagSkipDNS, "skip-dns-check", false, "Accept a target URL whose host does not resolve yet, e.g. when setting up offline")
 
 	// run (direct) command
 	runCmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run benchmark with flags",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if flagURL == "" {
-				return fmt.Errorf("url is required (use -u or --url)")
+			if flagRerun != "" {
+				cfg, err := manifestConfig(cmd, flagRerun)
+				if err != nil {
+					return err
+				}
+				if err := confirmWrites(cfg); err != nil {
+					return err
+				}
+				return runBenchmark(cfg)
 			}
-
-			var body []byte
-			if flagBody != "" {
-				body = []byte(flagBody)
+			if err := rejectFlags(cmd, "run", "replay-speed"); err != nil {
+				return err
 			}
-			cfg := engine.Config{
-				Method:      flagMethod,
-				URL:         flagURL,
-				Body:        body,
-				Connections: flagConnections,
-				Duration:    flagDuration,
-				Workers:     flagWorkers,
-				Pipeline:    flagPipeline,
+			if flagTransaction != "" {
+				if err := rejectFlags(cmd, "--transaction", append(requestFlags, "retries", "expect-sha256", "assert-json", "discard-first-per-conn", "url-weight", "path", "conn-bench")...); err != nil {
+					return err
+				}
+			}
+			if flagCompare {
+				if err := rejectFlags(cmd, "--compare-protocol", "grpc", "out-dir", "output", "template", "template-file", "raw-out", "db", "db-tag", "db-name"); err != nil {
+					return err
+				}
+			}
+			var methods []string
+			if flagMethods != "" {
+				if err := rejectFlags(cmd, "--methods", "method", "grpc", "compare-protocol", "out-dir", "output", "template", "template-file", "raw-out", "manifest", "db", "db-tag", "db-name"); err != nil {
+					return err
+				}
+				var err error
+				if methods, err = parseMethods(flagMethods); err != nil {
+					return err
+				}
+			}
+			cfg, err := runConfigFromFlags()
+			if err != nil {
+				return err
+			}
+			if methods != nil {
+				if err := confirmWrites(methodsGuardConfig(cfg, methods)); err != nil {
+					return err
+				}
+				return runMethodComparison(cfg, methods)
+			}
+			if err := confirmWrites(cfg); err != nil {
+				return err
+			}
+			if flagCompare {
+				return runProtocolComparison(cfg)
 			}
-
 			return runBenchmark(cfg)
 		},
 	}
 
+	runCmd.Flags().StringVar(&flagConfig, "config", "", "File of flag defaults (\"flag = value\" lines, with [name] profile sections); flags given on the command line win")
+	runCmd.Flags().StringVar(&flagProfile, "profile", "", "Apply this [name] section of the --config file on top of its common settings")
 	runCmd.Flags().StringVarP(&flagMethod, "method", "m", "GET", "HTTP method")
+	runCmd.Flags().StringVar(&flagMethods, "methods", "", "Run once per method in this list (e.g. GET,POST,PUT), each as its own phase, and compare req/sec and latency per method")
 	runCmd.Flags().StringVarP(&flagURL, "url", "u", "", "Target URL")
+	runCmd.Flags().StringVar(&flagPath, "path", "", "Replace the path and query of --url, e.g. /v2/items?limit=5")
 	runCmd.Flags().StringVarP(&flagBody, "body", "b", "", "Request body for POST/PUT/PATCH")
+	runCmd.Flags().StringVar(&flagBodyFile, "body-file", "", "Read the request body for POST/PUT/PATCH from this file")
 	runCmd.Flags().IntVarP(&flagConnections, "connections", "c", 10, "Number of concurrent persistent connections")
 	runCmd.Flags().DurationVarP(&flagDuration, "duration", "d", 10*time.Second, "Total test duration (e.g. 10s, 2m, 1h)")
+	runCmd.Flags().BoolVar(&flagPerConn, "per-conn", false, "Count requests and errors per connection and list the worst connections in the summary")
+	runCmd.Flags().BoolVar(&flagCapConns, "cap-connections", false, "Never open more than --connections connections per host; extra slots wait and the wait is reported as conn wait")
+	runCmd.Flags().Float64Var(&flagPoolWarn, "pool-wait-warn", 0.25, "With --cap-connections, warn when slots spend more than this share of the run waiting for a connection")
+	runCmd.Flags().BoolVar(&flagStrict, "strict", false, "Fail the run when the --cap-connections pool is exhausted (see --pool-wait-warn) instead of only warning")
+	runCmd.Flags().BoolVar(&flagConnBench, "conn-bench", false, "Open a new connection for every request and report connections and TLS handshakes per second instead of requests")
 	runCmd.Flags().IntVarP(&flagWorkers, "workers", "w", 1, "Number of CPU workers/goroutines to spawn")
 	runCmd.Flags().IntVarP(&flagPipeline, "pipeline", "p", 1, "Number of pipelined requests per connection")
+	runCmd.Flags().StringVar(&flagBodyDir, "body-dir", "", "Send a random file from this directory as each request's body")
+	runCmd.Flags().BoolVar(&flagBodyCycle, "body-cycle", false, "Send the --body-dir files in name order, round-robin, instead of at random")
+	runCmd.Flags().StringVar(&flagBodySize, "body-size", "", "Stream a generated body of this size with each request instead of buffering one (e.g. 1GB)")
+	runCmd.Flags().BoolVar(&flagBodyRandom, "body-random", false, "Fill --body-size bodies with incompressible random bytes instead of zeros")
+	runCmd.Flags().StringVar(&flagJSON, "json", "", "JSON request body; also sets Content-Type: application/json")
+	runCmd.Flags().StringArrayVar(&flagData, "data", nil, "Form field=value for an application/x-www-form-urlencoded body (repeatable)")
+	runCmd.Flags().StringVar(&flagContentType, "content-type", "", "Content-Type for the request body (an explicit -H Content-Type wins)")
+	runCmd.Flags().StringVar(&flagRunID, "run-id", "", "Tag every request of the run with this X-Benchmark-Run header value (default: a generated UUID)")
+	runCmd.Flags().StringArrayVarP(&flagHeaders, "header", "H", nil, "Request header \"Key: Value\" (repeatable; values may use {{uuid}}, {{seq}}, {{rand}}, {{now}})")
+	runCmd.Flags().Float64Var(&flagReplaySpeed, "replay-speed", 0, "replay-jsonl: send requests at their captured \"time\" gaps sped up by this factor (2 = twice as fast; 0 = as fast as possible)")
+	runCmd.Flags().StringVar(&flagTransaction, "transaction", "", "Send the requests in this JSON Lines file (replay-jsonl format) in order as one transaction per iteration and measure whole sequences")
+	runCmd.Flags().BoolVar(&flagShowAddrs, "show-addrs", false, "Print the addresses the target resolved to and how many requests went to each address connected to")
+	runCmd.Flags().BoolVar(&flagSkipDNS, "skip-dns-check", false, "Continue with a warning if the DNS preflight fails (implied by --resolve for the target or a proxy)")
+	runCmd.Flags().StringVar(&flagDNSCache, "dns-cache", "", "Resolve hosts in httpcl, reusing each result this long (e.g. 30s; 0 = look up on every dial), and report the lookup count")
+	runCmd.Flags().StringArrayVar(&flagURLWeights, "url-weight", nil, "Spread requests over several URLs by weight, as URL=WEIGHT (repeatable; replaces --url), e.g. https://us.example.com=70")
+	runCmd.Flags().StringVar(&flagShardKey, "shard-key", "", "Route each request to a --url-weight target by consistent hashing of this key, e.g. user-{{seq}}, so the same key always hits the same URL")
+	runCmd.Flags().StringArrayVar(&flagResolve, "resolve", nil, "Connect to addr instead of resolving host, as \"host:port:addr\" (repeatable)")
+	runCmd.Flags().StringVar(&flagProxyProto, "proxy-protocol", "", "Send a PROXY protocol header (v1 or v2) at the start of each connection")
+	runCmd.Flags().StringVar(&flagProxySource, "proxy-protocol-source", "", "Client ip:port announced in the PROXY header (default: the real local address)")
+	runCmd.Flags().StringVar(&flagSigV4.spec, "aws-sigv4", "", "Sign every request with AWS Signature Version 4 for region/service (e.g. us-east-1/execute-api)")
+	runCmd.Flags().StringVar(&flagSigV4.accessKeyID, "aws-access-key-id", "", "Access key for --aws-sigv4 (default $AWS_ACCESS_KEY_ID)")
+	runCmd.Flags().StringVar(&flagSigV4.secretKey, "aws-secret-access-key", "", "Secret key for --aws-sigv4 (default $AWS_SECRET_ACCESS_KEY; prefer the variable, flags are visible in ps)")
+	runCmd.Flags().StringVar(&flagSigV4.sessionToken, "aws-session-token", "", "Session token for --aws-sigv4 temporary credentials (default $AWS_SESSION_TOKEN)")
+	runCmd.Flags().StringVar(&flagIfNoneMatch, "if-none-match", "", "Send If-None-Match with this entity tag (quoted if bare) to benchmark cache revalidation; 304s are counted separately")
+	runCmd.Flags().StringVar(&flagIfModSince, "if-modified-since", "", "Send If-Modified-Since with this time (HTTP date or RFC 3339)")
+	runCmd.Flags().BoolVar(&flagETagChain, "etag-chain", false, "Revalidate the ETag of the latest 2xx or 304 response with If-None-Match (seeded by --if-none-match)")
+	runCmd.Flags().BoolVar(&flagDecode, "decode", false, "Ask for compressed responses and decode gzip, deflate, br and zstd bodies before checking and counting them")
+	runCmd.Flags().StringVar(&flagExpectHash, "expect-sha256", "", "Count responses whose body does not match this SHA-256 (hex) as validation errors")
+	runCmd.Flags().StringArrayVar(&flagAssertJSON, "assert-json", nil, "Count responses whose JSON body fails this check (\"$.path\" or \"$.path==value\") as validation errors (repeatable)")
+	runCmd.Flags().StringArrayVar(&flagExpectHdrs, "expect-header", nil, "Count responses without this header (\"Name\" or \"Name: value\") as header errors (repeatable)")
+	runCmd.Flags().StringArrayVar(&flagRejectHdrs, "reject-header", nil, "Count responses with this header (\"Name\" or \"Name: value\") as header errors (repeatable)")
+	runCmd.Flags().StringArrayVar(&flagTrailers, "trailer", nil, "Send a request trailer \"Key: Value\" after a chunked body (repeatable)")
+	runCmd.Flags().StringArrayVar(&flagExpectTrls, "expect-trailer", nil, "Count responses without this trailer (\"Name\" or \"Name: value\") as header errors (repeatable)")
+	runCmd.Flags().StringArrayVar(&flagRejectTrls, "reject-trailer", nil, "Count responses with this trailer (\"Name\" or \"Name: value\") as header errors (repeatable)")
+	runCmd.Flags().StringVarP(&flagRate, "rate", "r", "", "Pace request starts at this many per second across all connections, or auto:F for fraction F of the --calibrate max (e.g. auto:0.8)")
+	runCmd.Flags().DurationVar(&flagCalibrate, "calibrate", 0, "Measure the server's max throughput with closed-loop load for this long before the run (e.g. 5s)")
+	runCmd.Flags().BoolVar(&flagAdaptive, "adaptive-rate", false, "Experimental: search for the highest rate that keeps p99 under --target-p99")
+	runCmd.Flags().DurationVar(&flagTargetP99, "target-p99", 0, "p99 latency bound for --adaptive-rate (e.g. 50ms)")
+	runCmd.Flags().DurationVar(&flagAdaptEvery, "adaptive-interval", time.Second, "How often --adaptive-rate re-evaluates p99 and adjusts the rate")
+	runCmd.Flags().StringVar(&flagSteps, "step-concurrency", "", "Step load test: hold each CONCURRENCY:DURATION level in turn and report each (e.g. 10:30s,50:30s,100:30s); replaces --duration")
+	runCmd.Flags().BoolVar(&flagBackoffErrs, "backoff-on-errors", false, "Halve the active connections when connection errors spike, and add them back as the errors subside")
+	runCmd.Flags().BoolVar(&flagGRPC, "grpc", false, "Send the body as a unary gRPC message to the method path in --url over HTTP/2 (h2c for http://)")
+	runCmd.Flags().IntVar(&flagRetries, "retries", 0, "Re-send a request that failed with a transport error or 5xx up to this many times; only the last attempt is recorded")
+	runCmd.Flags().DurationVar(&flagRetryDelay, "retry-delay", 100*time.Millisecond, "Wait before the first retry (the base for --retry-backoff)")
+	runCmd.Flags().StringVar(&flagBackoff, "retry-backoff", engine.BackoffConstant, "Backoff between retries: constant, or exponential (doubling, capped at 10s)")
+	runCmd.Flags().BoolVar(&flagRetryJitter, "retry-jitter", false, "Randomize each retry wait between half and all of its backoff (seeded by --seed)")
+	runCmd.Flags().BoolVar(&flagRetryAnyMth, "retry-non-idempotent", false, "Also retry POST, PATCH and other non-idempotent requests after the server may have received them")
+	runCmd.Flags().IntVar(&flagDiscardConn, "discard-first-per-conn", 0, "Leave the first K requests on each new connection out of the stats (warm-up)")
+	runCmd.Flags().IntVar(&flagMaxPerConn, "max-requests-per-conn", 0, "Close each connection after this many requests per pipeline slot to force churn (0 = keep alive)")
+	runCmd.Flags().IntVar(&flagBurst, "burst", 0, "Spike test: release this many requests at once every --burst-interval, idle in between")
+	runCmd.Flags().DurationVar(&flagBurstEvery, "burst-interval", time.Second, "Time between bursts with --burst")
+	runCmd.Flags().StringArrayVar(&flagHeaderFiles, "headers-file", nil, "File of \"Key: Value\" lines (# comments, ${ENV} expansion; repeatable, -H overrides)")
+	runCmd.Flags().DurationVar(&flagStagger, "stagger-start", 0, "Delay each pipeline slot's first request by a random offset within this window (seeded by --seed) to avoid a synchronized start")
+	runCmd.Flags().BoolVarP(&flagInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	runCmd.Flags().DurationVar(&flagCertWarn, "cert-expiry-warn", 30*24*time.Hour, "Warn before the run if the server's TLS certificate expires within this window (0 skips the check)")
+	runCmd.Flags().DurationVar(&flagSoakReport, "soak-report", 0, "Print a full interim report with memory and goroutine counts at this interval while the run continues (e.g. 5m)")
+	runCmd.Flags().BoolVar(&flagCompare, "compare-protocol", false, "Run the benchmark twice, over HTTP/1.1 and then HTTP/2, and print a side-by-side comparison")
+	runCmd.Flags().StringVar(&flagStatsD, "statsd", "", "Send live metrics (requests, errors, rps, p50, p99, in-flight) every second to this StatsD host:port over UDP")
+	runCmd.Flags().StringVar(&flagStatsDPfx, "statsd-prefix", "httpcl", "Prefix for StatsD metric names")
+	runCmd.Flags().StringArrayVar(&flagStatsDTags, "statsd-tag", nil, "DogStatsD tag for every metric, e.g. env:staging (repeatable)")
+	runCmd.Flags().DurationVar(&flagDrain, "drain-timeout", 5*time.Second, "How long in-flight requests may run after the duration before being abandoned (negative = wait forever)")
+	runCmd.Flags().Float64Var(&flagMaxErrRate, "max-error-rate", 0, "Exit non-zero if more than this fraction of requests fail (e.g. 0.05; 0 = off)")
+	runCmd.Flags().Float64Var(&flagMinRPS, "min-rps", 0, "Exit non-zero if the sustained request rate after --min-rps-warmup falls below this (0 = off)")
+	runCmd.Flags().DurationVar(&flagMinRPSWarm, "min-rps-warmup", time.Second, "Start of the load phase ignored by --min-rps while the run ramps up")
+	runCmd.Flags().StringVar(&flagCount429, "count-429", engine.Count429Separate, "How 429 Too Many Requests responses count: separate (own counter, neither success nor error), success or error")
+	runCmd.Flags().Float64Var(&flagLowRPSWarn, "low-rps-warn", engine.DefaultLowRPSWarn, "Warn that results may be invalid when each slot completes fewer requests per second than this and most fail (0 = off)")
+	runCmd.Flags().StringVar(&flagStatsMem, "stats-memory", "", "Memory budget for retained latency samples and time-series buckets (e.g. 1MB; default 50k samples, 600 buckets)")
+	runCmd.Flags().StringVar(&flagReadBuf, "read-buffer", "", "Per-connection read buffer size for HTTP/1.1 (e.g. 64KB; default 4KiB)")
+	runCmd.Flags().StringVar(&flagWriteBuf, "write-buffer", "", "Per-connection write buffer size for HTTP/1.1 (e.g. 64KB; default 4KiB)")
+	runCmd.Flags().StringVar(&flagMaxBytes, "max-bytes", "", "Stop once this much data has been sent+received (e.g. 500MB, 1GB)")
+	runCmd.Flags().StringSliceVar(&flagStopSigs, "stop-signals", nil, "Signals that stop the run (e.g. INT,TERM,HUP; \"none\" to ignore all; default INT,TERM)")
+	runCmd.Flags().StringSliceVar(&flagStatusSigs, "status-signals", nil, "Signals that print a live snapshot without stopping (default QUIT, i.e. Ctrl+\\; \"none\" to disable)")
+	runCmd.Flags().Uint64Var(&flagSeed, "seed", 0, "Seed for random choices such as --body-dir picks (0 = random; shown with --verbose)")
+	runCmd.Flags().StringVar(&flagSimulate, "simulate-latency", "", "Testing aid: send nothing and record synthetic latencies (const:5ms, uniform:1ms,10ms, normal:20ms,5ms, exp:10ms); --url is optional")
+	runCmd.Flags().BoolVar(&flagYes, "yes", false, "Skip the confirmation for POST/PUT/PATCH/DELETE benchmarks against public addresses")
+	runCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Print the effective configuration (after defaults) before the run")
+	runCmd.Flags().StringVar(&flagLatUnit, "latency-unit", "auto", "Unit for latencies in the HUD and report: auto (from p50), ns, us, ms or s")
+	runCmd.Flags().IntVar(&flagPrecision, "precision", -1, "Decimal places (0-6) for latencies and req/s in the HUD and report; -1 keeps the defaults")
+	runCmd.Flags().StringVar(&flagOutput, "output", "text", "Extra output after the report: text (none), tsv (one header row and one data row for spreadsheets) or template (--template or --template-file); json replaces the report with the final snapshot as JSON")
+	runCmd.Flags().StringVar(&flagTemplate, "template", "", "Go text/template for --output template, executed against the run's .Meta and .Snapshot")
+	runCmd.Flags().StringVar(&flagTmplFile, "template-file", "", "File holding the Go text/template for --output template")
+	runCmd.Flags().StringVar(&flagRawOut, "raw-out", "", "Write one CSV row per request, with its wall-clock start time, to this file")
+	runCmd.Flags().StringVar(&flagManifest, "manifest", "", "Write the effective config (defaults and seed resolved), build and results as JSON to this file, to rerun with --from-manifest")
+	runCmd.Flags().StringVar(&flagRerun, "from-manifest", "", "Repeat the run recorded in this --manifest file; only output flags may be added")
+	runCmd.Flags().StringVar(&flagOutDir, "out-dir", "", "Write all report artifacts into <path>/run-<timestamp>/")
+	runCmd.Flags().StringSliceVar(&flagArtifacts, "out-artifacts", nil, "Artifacts to write with --out-dir (json, csv, cdf, html; default all)")
+	runCmd.Flags().StringVar(&flagDB, "db", "", "Append the run's summary (time, tags, config, req/s, p50, p99, errors) to this SQLite history database, creating it on first use")
+	runCmd.Flags().StringArrayVar(&flagDBTags, "db-tag", nil, "Tag stored with the run in --db, e.g. env:staging (repeatable)")
+	runCmd.Flags().StringVar(&flagDBName, "db-name", "", "Benchmark name the run is stored under in --db, for the trend command (default: the URL)")
+
+	// once command: the run flags, for a single request
+	onceCmd := &cobra.Command{
+		Use:   "once",
+		Short: "Send one request and exit non-zero unless it succeeds",
+		Long: `Send a single request built from the run flags (URL, method, body,
+headers, signing, checks) and print its status and latency. The exit status
+is 0 if the request succeeds by the same rules as a run (a 2xx-4xx status
+passing any --expect-header, --reject-header, --expect-sha256 or
+--assert-json check) and 1 otherwise. Load flags such as --duration have no
+effect.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := rejectFlags(cmd, "once", "transaction", "simulate-latency", "compare-protocol", "raw-out", "soak-report", "calibrate", "replay-speed", "methods", "db", "db-tag", "db-name", "output", "template", "template-file"); err != nil {
+				return err
+			}
+			cfg, err := runConfigFromFlags()
+			if err != nil {
+				return err
+			}
+			return runOnce(cmd.Context(), cfg, os.Stdout)
+		},
+	}
+	onceCmd.Flags().AddFlagSet(runCmd.Flags())
+
+	// replay-jsonl command: the run flags, with requests from a file
+	replayCmd := &cobra.Command{
+		Use:   "replay-jsonl <file>",
+		Short: "Replay captured requests from a JSON Lines file",
+		Long: `Replay the requests in a JSON Lines file, one object per line:
+
+  {"method": "POST", "url": "/api/items", "headers": {"X-Id": "1"}, "body": "{}"}
+
+Binary bodies go in "body_base64" instead of "body". Relative URLs are
+resolved against --url. Requests are sent in file order, shared by all
+connections, and the file repeats until the run ends. All run flags apply
+except those that shape the request (--method, --body and the like).
+
+A line may carry its capture time as "time" (RFC 3339). With
+--replay-speed F the requests are then sent at the captured gaps divided by
+F, e.g. --replay-speed 1 for the original timing or 2 for twice as fast.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := replayConfigFromFlags(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			if err := confirmWrites(cfg); err != nil {
+				return err
+			}
+			return runBenchmark(cfg)
+		},
+	}
+	replayCmd.Flags().AddFlagSet(runCmd.Flags())
+
+	// trend command: stored --db runs of one benchmark over time
+	trendCmd := &cobra.Command{
+		Use:   "trend",
+		Short: "Show how a benchmark's req/sec and p99 evolved across runs stored with --db",
+		Long: `Read the runs stored in a --db history database under --name and print
+them oldest first: req/sec and p99 latency with the change from the run
+before, and sparklines of both. A run whose req/sec fell or p99 rose by
+more than --threshold is flagged as a regression. No requests are sent.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTrend()
+		},
+	}
+	trendCmd.Flags().StringVar(&flagTrendDB, "db", "", "History database written by run --db (required)")
+	trendCmd.Flags().StringVar(&flagTrendName, "name", "", "Benchmark to show, as stored by --db-name (the URL by default) (required)")
+	trendCmd.Flags().StringArrayVar(&flagTrendTags, "tag", nil, "Only runs stored with this --db-tag (repeatable; all must match)")
+	trendCmd.Flags().StringVar(&flagTrendSince, "since", "", "Only runs started at or after this time: RFC 3339, a date (2006-01-02) or a duration ago (168h)")
+	trendCmd.Flags().StringVar(&flagTrendUntil, "until", "", "Only runs started before this time, in the same forms as --since")
+	trendCmd.Flags().IntVar(&flagTrendLast, "last", 20, "Show at most this many of the latest runs (0 = all)")
+	trendCmd.Flags().Float64Var(&flagTrendThresh, "threshold", 0.1, "Flag a run as a regression when req/sec falls or p99 rises by more than this share of the run before")
 
+	rootCmd.PersistentFlags().BoolVar(&flagFullWidth, "full-width", false, "Let tables span the whole terminal instead of stopping at 72 columns")
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(onceCmd)
+	rootCmd.AddCommand(trendCmd)
+}
+
+// validateOutputFlags checks the flags that shape the report and artifacts.
+func validateOutputFlags() error {
+	if err := export.ValidateKinds(flagArtifacts); err != nil {
+		return err
+	}
+	if _, err := ui.ParseLatencyUnit(flagLatUnit); err != nil {
+		return err
+	}
+	if flagPrecision < -1 || flagPrecision > 6 {
+		return fmt.Errorf("--precision must be between 0 and 6, got %d", flagPrecision)
+	}
+	switch flagOutput {
+	case "text", "tsv", "template", "json":
+	default:
+		return fmt.Errorf("--output must be text, tsv, template or json, got %q", flagOutput)
+	}
+	t, err := loadOutputTemplate(flagOutput, flagTemplate, flagTmplFile)
+	if err != nil {
+		return err
+	}
+	outputTemplate = t
+	return nil
+}
+
+// runConfigFromFlags validates the `run` flags and maps them into engine.Config.
+func runConfigFromFlags() (engine.Config, error) {
+	var simulate *engine.LatencyDist
+	if flagSimulate != "" {
+		var err error
+		if simulate, err = parseLatencyDist(flagSimulate); err != nil {
+			return engine.Config{}, err
+		}
+	} else if flagURL == "" && flagTransaction == "" && len(flagURLWeights) == 0 {
+		return engine.Config{}, fmt.Errorf("url is required (use -u or --url)")
+	}
+	if err := validateOutputFlags(); err != nil {
+		return engine.Config{}, err
+	}
+	headers, err := resolveHeaders(flagHeaderFiles, flagHeaders)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	expectHeaders, err := parseHeaderMatches("--expect-header", flagExpectHdrs)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	rejectHeaders, err := parseHeaderMatches("--reject-header", flagRejectHdrs)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	trailers, err := parseTrailers(flagTrailers)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	expectTrailers, err := parseHeaderMatches("--expect-trailer", flagExpectTrls)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	rejectTrailers, err := parseHeaderMatches("--reject-trailer", flagRejectTrls)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	var dnsCache time.Duration
+	if flagDNSCache != "" {
+		if dnsCache, err = time.ParseDuration(flagDNSCache); err != nil || dnsCache < 0 {
+			return engine.Config{}, fmt.Errorf("--dns-cache must be a non-negative duration, got %q", flagDNSCache)
+		}
+	}
+	var maxBytes uint64
+	if flagMaxBytes != "" {
+		if maxBytes, err = parseSize(flagMaxBytes); err != nil {
+			return engine.Config{}, fmt.Errorf("--max-bytes: %w", err)
+		}
+	}
+	var statsMemory uint64
+	if flagStatsMem != "" {
+		if statsMemory, err = parseSize(flagStatsMem); err != nil {
+			return engine.Config{}, fmt.Errorf("--stats-memory: %w", err)
+		}
+	}
+	readBuffer, err := parseBufferSize("--read-buffer", flagReadBuf)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	writeBuffer, err := parseBufferSize("--write-buffer", flagWriteBuf)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	body, contentType, err := resolveBody(bodyFlags{
+		body:        flagBody,
+		bodyFile:    flagBodyFile,
+		json:        flagJSON,
+		data:        flagData,
+		bodyDir:     flagBodyDir,
+		bodySize:    flagBodySize,
+		contentType: flagContentType,
+	})
+	if err != nil {
+		return engine.Config{}, err
+	}
+	var bodySize uint64
+	if flagBodySize != "" {
+		if bodySize, err = parseSize(flagBodySize); err != nil {
+			return engine.Config{}, fmt.Errorf("--body-size: %w", err)
+		}
+		if bodySize == 0 || bodySize > math.MaxInt64 {
+			return engine.Config{}, fmt.Errorf("--body-size must be between 1 byte and 8EiB")
+		}
+	} else if flagBodyRandom {
+		return engine.Config{}, fmt.Errorf("--body-random requires --body-size")
+	}
+	var corpus [][]byte
+	if flagBodyDir != "" {
+		var warning string
+		if corpus, warning, err = loadBodyDir(flagBodyDir); err != nil {
+			return engine.Config{}, err
+		}
+		if warning != "" {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+		}
+	} else if flagBodyCycle {
+		return engine.Config{}, fmt.Errorf("--body-cycle requires --body-dir")
+	}
+	// Transaction steps resolve relative URLs against --url; without it the
+	// first step names the target.
+	url := flagURL
+	if flagPath != "" {
+		if flagURL == "" {
+			return engine.Config{}, fmt.Errorf("--path requires --url")
+		}
+		if url, err = overridePath(flagURL, flagPath); err != nil {
+			return engine.Config{}, err
+		}
+	}
+	var transaction []engine.ReplayRequest
+	if flagTransaction != "" {
+		if transaction, err = loadReplayFile(flagTransaction, flagURL); err != nil {
+			return engine.Config{}, err
+		}
+		if url == "" {
+			url = transaction[0].URL
+		}
+	}
+	resolve, err := parseResolve(flagResolve)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	proxyProto, proxySource, err := parseProxyProtocol(flagProxyProto, flagProxySource)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	sigv4, err := parseSigV4(flagSigV4, os.Getenv)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	statsd, err := parseStatsD(flagStatsD, flagStatsDPfx, flagStatsDTags)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	if err := checkHistoryFlags(flagDB, flagDBName, flagDBTags); err != nil {
+		return engine.Config{}, err
+	}
+	targets, err := parseURLWeights(flagURLWeights)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	if len(targets) > 0 && flagURL != "" {
+		return engine.Config{}, fmt.Errorf("--url cannot be combined with --url-weight; give every URL a weight")
+	}
+	if flagShardKey != "" && len(targets) < 2 {
+		return engine.Config{}, fmt.Errorf("--shard-key needs at least two --url-weight targets to pick from")
+	}
+	ifNoneMatch, err := parseETag(flagIfNoneMatch)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	ifModifiedSince, err := parseHTTPTime(flagIfModSince)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	var expectSHA256 []byte
+	if flagExpectHash != "" {
+		if expectSHA256, err = parseSHA256(flagExpectHash); err != nil {
+			return engine.Config{}, fmt.Errorf("--expect-sha256: %w", err)
+		}
+	}
+	var assertJSON []engine.JSONAssert
+	for _, s := range flagAssertJSON {
+		a, err := engine.ParseJSONAssert(s)
+		if err != nil {
+			return engine.Config{}, err
+		}
+		assertJSON = append(assertJSON, a)
+	}
+	if flagMaxErrRate < 0 || flagMaxErrRate >= 1 {
+		return engine.Config{}, fmt.Errorf("--max-error-rate must be in [0, 1)")
+	}
+	if flagMinRPS < 0 {
+		return engine.Config{}, fmt.Errorf("--min-rps must not be negative")
+	}
+	if flagStagger < 0 {
+		return engine.Config{}, fmt.Errorf("--stagger-start must not be negative")
+	}
+	if flagCertWarn < 0 {
+		return engine.Config{}, fmt.Errorf("--cert-expiry-warn must not be negative")
+	}
+	if flagSoakReport != 0 && flagSoakReport < time.Second {
+		return engine.Config{}, fmt.Errorf("--soak-report must be at least 1s")
+	}
+	if flagLowRPSWarn < 0 {
+		return engine.Config{}, fmt.Errorf("--low-rps-warn must not be negative")
+	}
+	if flagMinRPSWarm < 0 {
+		return engine.Config{}, fmt.Errorf("--min-rps-warmup must not be negative")
+	}
+	rate, rateFraction, err := parseRate(flagRate)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	if flagCalibrate < 0 {
+		return engine.Config{}, fmt.Errorf("--calibrate must not be negative")
+	}
+	if rateFraction > 0 && flagCalibrate == 0 {
+		return engine.Config{}, fmt.Errorf("--rate auto:F requires --calibrate")
+	}
+	if flagRate != "" && (flagAdaptive || flagBurst > 0) {
+		return engine.Config{}, fmt.Errorf("--rate cannot be combined with --adaptive-rate or --burst")
+	}
+	steps, err := parseSteps(flagSteps)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	if steps != nil && (flagBackoffErrs || flagBurst > 0) {
+		return engine.Config{}, fmt.Errorf("--step-concurrency cannot be combined with --backoff-on-errors or --burst")
+	}
+	if flagAdaptive {
+		if flagTargetP99 <= 0 {
+			return engine.Config{}, fmt.Errorf("--adaptive-rate requires a positive --target-p99")
+		}
+		if flagAdaptEvery <= 0 {
+			return engine.Config{}, fmt.Errorf("--adaptive-interval must be positive")
+		}
+		if flagBurst > 0 {
+			return engine.Config{}, fmt.Errorf("--adaptive-rate and --burst cannot be combined")
+		}
+	} else if flagTargetP99 > 0 {
+		return engine.Config{}, fmt.Errorf("--target-p99 requires --adaptive-rate")
+	}
+	method := flagMethod
+	if flagGRPC {
+		// gRPC calls are always POSTs; only the untouched GET default is replaced.
+		if method != "GET" && !strings.EqualFold(method, "POST") {
+			return engine.Config{}, fmt.Errorf("--grpc always uses POST, not %s", method)
+		}
+		method = "POST"
+		if flagJSON != "" || len(flagData) > 0 || flagContentType != "" || flagBodySize != "" {
+			return engine.Config{}, fmt.Errorf("--grpc cannot be combined with --json, --data, --content-type or --body-size; pass the serialized message with --body or --body-dir")
+		}
+	}
+	if flagPoolWarn <= 0 || flagPoolWarn >= 1 {
+		return engine.Config{}, fmt.Errorf("--pool-wait-warn must be between 0 and 1, not %g", flagPoolWarn)
+	}
+	if flagStrict && !flagCapConns {
+		return engine.Config{}, fmt.Errorf("--strict requires --cap-connections")
+	}
+	if flagMaxPerConn < 0 {
+		return engine.Config{}, fmt.Errorf("--max-requests-per-conn must not be negative")
+	}
+	if flagConnBench && (flagMaxPerConn > 0 || flagDiscardConn > 0) {
+		return engine.Config{}, fmt.Errorf("--conn-bench opens a connection per request; it cannot be combined with --max-requests-per-conn or --discard-first-per-conn")
+	}
+	if flagDiscardConn < 0 {
+		return engine.Config{}, fmt.Errorf("--discard-first-per-conn must not be negative")
+	}
+	if flagRetries < 0 {
+		return engine.Config{}, fmt.Errorf("--retries must not be negative")
+	}
+	if flagBackoff != engine.BackoffConstant && flagBackoff != engine.BackoffExponential {
+		return engine.Config{}, fmt.Errorf("--retry-backoff must be %s or %s, not %q", engine.BackoffConstant, engine.BackoffExponential, flagBackoff)
+	}
+	if flagRetryDelay <= 0 {
+		return engine.Config{}, fmt.Errorf("--retry-delay must be positive")
+	}
+	switch flagCount429 {
+	case engine.Count429Separate, engine.Count429Success, engine.Count429Error:
+	default:
+		return engine.Config{}, fmt.Errorf("--count-429 must be %s, %s or %s, not %q", engine.Count429Separate, engine.Count429Success, engine.Count429Error, flagCount429)
+	}
+	if flagRetries == 0 && (flagRetryJitter || flagBackoff != engine.BackoffConstant) {
+		return engine.Config{}, fmt.Errorf("--retry-backoff and --retry-jitter require --retries")
+	}
+	if flagRetries == 0 && flagRetryAnyMth {
+		return engine.Config{}, fmt.Errorf("--retry-non-idempotent requires --retries")
+	}
+	if flagBurst < 0 {
+		return engine.Config{}, fmt.Errorf("--burst must not be negative")
+	}
+	if flagBurst > 0 && flagBurstEvery <= 0 {
+		return engine.Config{}, fmt.Errorf("--burst-interval must be positive")
+	}
+	stopSigs, statusSigs, err := signalConfig(flagStopSigs, flagStatusSigs)
+	if err != nil {
+		return engine.Config{}, err
+	}
+	runID, err := parseRunID(flagRunID)
+	if err != nil {
+		return engine.Config{}, err
+	}
+
+	return engine.Config{
+		Method:              method,
+		URL:                 url,
+		Body:                body,
+		BodyCorpus:          corpus,
+		BodyCycle:           flagBodyCycle,
+		BodySize:            int64(bodySize),
+		BodyRandom:          flagBodyRandom,
+		Headers:             headers,
+		RunID:               runID,
+		ContentType:         contentType,
+		Connections:         flagConnections,
+		Duration:            flagDuration,
+		Workers:             flagWorkers,
+		Pipeline:            flagPipeline,
+		MaxBytes:            maxBytes,
+		StatsMemory:         statsMemory,
+		Verbose:             flagVerbose,
+		DrainTimeout:        flagDrain,
+		StaggerStart:        flagStagger,
+		SoakReport:          flagSoakReport,
+		Insecure:            flagInsecure,
+		CertExpiryWarn:      flagCertWarn,
+		SkipDNSCheck:        flagSkipDNS,
+		ResolveDNS:          flagDNSCache != "",
+		DNSCache:            dnsCache,
+		Resolve:             resolve,
+		CapConnections:      flagCapConns,
+		PoolWaitWarn:        flagPoolWarn,
+		Strict:              flagStrict,
+		ConnBench:           flagConnBench,
+		PerConn:             flagPerConn,
+		ShowAddrs:           flagShowAddrs,
+		SimulateLatency:     simulate,
+		ProxyProtocol:       proxyProto,
+		ProxySource:         proxySource,
+		Seed:                flagSeed,
+		MaxErrorRate:        flagMaxErrRate,
+		MinRPS:              flagMinRPS,
+		MinRPSWarmup:        flagMinRPSWarm,
+		LowRPSWarn:          flagLowRPSWarn,
+		Transaction:         transaction,
+		AWSSigV4:            sigv4,
+		IfNoneMatch:         ifNoneMatch,
+		IfModifiedSince:     ifModifiedSince,
+		ChainETag:           flagETagChain,
+		Decode:              flagDecode,
+		ExpectSHA256:        expectSHA256,
+		AssertJSON:          assertJSON,
+		ExpectHeaders:       expectHeaders,
+		RejectHeaders:       rejectHeaders,
+		Trailers:            trailers,
+		ExpectTrailers:      expectTrailers,
+		RejectTrailers:      rejectTrailers,
+		MaxRequestsPerConn:  flagMaxPerConn,
+		DiscardFirstPerConn: flagDiscardConn,
+		Retries:             flagRetries,
+		RetryDelay:          flagRetryDelay,
+		RetryBackoff:        flagBackoff,
+		Count429:            flagCount429,
+		RetryNonIdempotent:  flagRetryAnyMth,
+		StatsD:              statsd,
+		Targets:             targets,
+		ShardKey:            flagShardKey,
+		ReadBufferSize:      readBuffer,
+		WriteBufferSize:     writeBuffer,
+		RetryJitter:         flagRetryJitter,
+		GRPC:                flagGRPC,
+		Rate:                rate,
+		Calibrate:           flagCalibrate,
+		RateFraction:        rateFraction,
+		AdaptiveRate:        flagAdaptive,
+		TargetP99:           flagTargetP99,
+		AdaptiveInterval:    flagAdaptEvery,
+		BackoffOnErrors:     flagBackoffErrs,
+		ConcurrencySteps:    steps,
+		Burst:               flagBurst,
+		BurstInterval:       flagBurstEvery,
+		StopSignals:         stopSigs,
+		StatusSignals:       statusSigs,
+	}, nil
 }
 
 // Execute runs the root cobra command.
 func Execute() {
-	// Intro banner shown once at startup.
-	ui.PrintIntroBanner()
-
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -102,7 +831,137 @@ func Execute() {
 
 // runBenchmark is a thin wrapper to wire engine and UI.
 func runBenchmark(cfg engine.Config) error {
-	renderer := ui.NewRenderer()
+	var raw *export.RawWriter
+	if flagRawOut != "" {
+		f, err := os.Create(flagRawOut)
+		if err != nil {
+			return fmt.Errorf("--raw-out: %w", err)
+		}
+		defer f.Close()
+		raw = export.NewRawWriter(f)
+		cfg.OnResult = raw.Record
+	}
+	opts := rendererOptions()
+	if restore, ok := enableKeyControls(&cfg); ok {
+		defer restore()
+		opts = append(opts, ui.WithKeyControls())
+	}
+	renderer := ui.NewRenderer(opts...)
+	if flagOutput == "json" {
+		renderer = ui.NewJSONRenderer(os.Stdout, opts...)
+	}
+	cfg.Log = runLog()
 	orch := engine.NewOrchestrator(cfg, renderer)
-	return orch.Run()
+	startedAt := time.Now()
+	err := orch.Run()
+	if raw != nil {
+		if werr := raw.Flush(); werr != nil {
+			fmt.Fprintf(os.Stderr, "warning: raw output: %v\n", werr)
+		}
+	}
+	// An unhealthy, slow or pool-bound run still produced results worth
+	// keeping.
+	var runErr *engine.RunError
+	var slowErr *engine.ThroughputError
+	var poolErr *engine.PoolExhaustedError
+	if err != nil && !errors.As(err, &runErr) && !errors.As(err, &slowErr) && !errors.As(err, &poolErr) {
+		return err
+	}
+	if flagOutDir != "" {
+		writeOutDir(orch, startedAt)
+	}
+	if flagManifest != "" {
+		writeManifest(orch, startedAt)
+	}
+	if flagDB != "" {
+		writeHistory(orch, startedAt)
+	}
+	switch flagOutput {
+	case "tsv":
+		report := export.NewReport(reportMeta(orch.Config(), orch, startedAt), orch.FinalSnapshot(), nil)
+		if werr := export.WriteTSV(os.Stdout, report); werr != nil {
+			fmt.Fprintf(os.Stderr, "warning: tsv output: %v\n", werr)
+		}
+	case "template":
+		report := export.NewReport(reportMeta(orch.Config(), orch, startedAt), orch.FinalSnapshot(), nil)
+		if werr := export.WriteTemplate(os.Stdout, outputTemplate, report); werr != nil {
+			fmt.Fprintf(os.Stderr, "warning: template output: %v\n", werr)
+		}
+	}
+	return err
+}
+
+// runLog is where a run's preflight steps, header and notes go: stdout, or
+// stderr with --output json, which keeps stdout for the report.
+func runLog() io.Writer {
+	if flagOutput == "json" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// rendererOptions maps the display flags to renderer options.
+func rendererOptions() []ui.RendererOption {
+	// Validated by runConfigFromFlags; the wizard leaves the default.
+	unit, _ := ui.ParseLatencyUnit(flagLatUnit)
+	opts := []ui.RendererOption{ui.WithLatencyUnit(unit), ui.WithPrecision(flagPrecision)}
+	if flagFullWidth {
+		opts = append(opts, ui.WithFullWidth())
+	}
+	return opts
+}
+
+// reportMeta describes the run for the exporters.
+func reportMeta(cfg engine.Config, orch *engine.Orchestrator, startedAt time.Time) export.Meta {
+	return export.Meta{
+		Method:      cfg.Method,
+		URL:         cfg.URL,
+		Connections: cfg.Connections,
+		Workers:     cfg.Workers,
+		Pipeline:    cfg.Pipeline,
+		Duration:    cfg.Duration,
+		StartedAt:   startedAt,
+		SLOs:        runSLOs(orch),
+	}
+}
+
+// runSLOs reports the thresholds set for the run and whether they held, or
+// nil if none were set.
+func runSLOs(orch *engine.Orchestrator) map[string]export.SLO {
+	cfg := orch.Config()
+	slos := make(map[string]export.SLO)
+	if cfg.MaxErrorRate > 0 {
+		snap := orch.FinalSnapshot()
+		slo := export.SLO{Target: cfg.MaxErrorRate, Evaluated: snap.TotalRequests > 0, Passed: true}
+		if slo.Evaluated {
+			slo.Measured = float64(snap.Errors) / float64(snap.TotalRequests)
+			slo.Passed = slo.Measured <= cfg.MaxErrorRate
+		}
+		slos["max_error_rate"] = slo
+	}
+	if cfg.MinRPS > 0 {
+		rps, ok := orch.SustainedRPS()
+		slos["min_rps"] = export.SLO{Target: cfg.MinRPS, Measured: rps, Evaluated: ok, Passed: !ok || rps >= cfg.MinRPS}
+	}
+	if len(slos) == 0 {
+		return nil
+	}
+	return slos
+}
+
+// writeOutDir bundles every selected artifact into a fresh run directory.
+// Exporter failures are reported as warnings; the run itself already succeeded.
+func writeOutDir(orch *engine.Orchestrator, startedAt time.Time) {
+	dir, err := export.NewRunDir(flagOutDir, startedAt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return
+	}
+	report := export.NewReport(reportMeta(orch.Config(), orch, startedAt), orch.FinalSnapshot(), orch.Collector())
+	if err := export.WriteBundle(dir, report, flagArtifacts); err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", line)
+		}
+	}
+	fmt.Fprintf(runLog(), "Artifacts written to %s\n", dir)
 }
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// noopRenderer keeps CLI-level runs quiet in tests.
+type noopRenderer struct{}
+
+func (noopRenderer) Render(stats.Snapshot)      {}
+func (noopRenderer) RenderFinal(stats.Snapshot) {}
+
+// captureOutput returns what fn printed to os.Stdout and os.Stderr.
+func captureOutput(t *testing.T, fn func()) (stdout, stderr string) {
+	t.Helper()
+	capture := func(f **os.File) func() string {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		orig := *f
+		*f = w
+		done := make(chan string)
+		go func() {
+			b, _ := io.ReadAll(r)
+			done <- string(b)
+		}()
+		return func() string {
+			*f = orig
+			w.Close()
+			return <-done
+		}
+	}
+	endOut, endErr := capture(&os.Stdout), capture(&os.Stderr)
+	fn()
+	return endOut(), endErr()
+}
+
+func TestDataFlag_ServerParsesForm(t *testing.T) {
+	var mu sync.Mutex
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		got = append(got, r.PostForm.Get("comment"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(r.PostForm.Get("comment")))
+	}))
+	defer srv.Close()
+
+	body, ct, err := resolveBody(bodyFlags{data: []string{"comment=50% off & free/shipping?", "id=7"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := engine.Config{
+		Method:      "POST",
+		URL:         srv.URL + "/",
+		Body:        body,
+		ContentType: ct,
+		Connections: 1,
+		Duration:    80 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	if err := engine.NewOrchestrator(cfg, noopRenderer{}).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) == 0 {
+		t.Fatal("server received no requests")
+	}
+	for _, v := range got {
+		if v != "50% off & free/shipping?" {
+			t.Fatalf("server parsed comment=%q", v)
+		}
+	}
+}
+
+// TestRunBenchmark_OutputJSON checks that --output json leaves stdout to the
+// final snapshot, with the run header and preflight steps on stderr.
+func TestRunBenchmark_OutputJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	defer func(old string) { flagOutput = old }(flagOutput)
+	flagOutput = "json"
+	if err := validateOutputFlags(); err != nil {
+		t.Fatalf("--output json: %v", err)
+	}
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 1,
+		Duration:    80 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	var err error
+	stdout, stderr := captureOutput(t, func() { err = runBenchmark(cfg) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var snap struct{ TotalRequests uint64 }
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	if err := dec.Decode(&snap); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, stdout)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		t.Errorf("stdout holds more than one JSON document:\n%s", stdout)
+	}
+	if snap.TotalRequests == 0 {
+		t.Error("snapshot reports no requests")
+	}
+	for _, line := range []string{"Starting HTTPCL benchmark", "DNS"} {
+		if !strings.Contains(stderr, line) {
+			t.Errorf("stderr lacks %q:\n%s", line, stderr)
+		}
+	}
+}
+
+// TestRunCmd_OutputJSONFromConfig checks that --output json set by a --config
+// file keeps the banner off stdout too: it is printed once the file applies.
+func TestRunCmd_OutputJSONFromConfig(t *testing.T) {
+	conf := filepath.Join(t.TempDir(), "p.conf")
+	if err := os.WriteFile(conf, []byte("output = json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer func(output, config, simulate string, duration time.Duration) {
+		flagOutput, flagConfig, flagSimulate, flagDuration = output, config, simulate, duration
+	}(flagOutput, flagConfig, flagSimulate, flagDuration)
+	rootCmd.SetArgs([]string{"run", "--config", conf, "--simulate-latency", "const:1ms", "-d", "100ms"})
+	defer rootCmd.SetArgs(nil)
+
+	var err error
+	stdout, stderr := captureOutput(t, func() { err = rootCmd.Execute() })
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	var snap struct{ TotalRequests uint64 }
+	if err := dec.Decode(&snap); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, stdout)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		t.Errorf("stdout holds more than one JSON document:\n%s", stdout)
+	}
+	if !strings.Contains(stderr, "██╗") {
+		t.Errorf("banner not on stderr:\n%s", stderr)
+	}
+}
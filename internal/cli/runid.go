@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// parseRunID returns the --run-id value, or a fresh ID if it is empty. The
+// ID is sent as a header, so it must be printable ASCII.
+func parseRunID(s string) (string, error) {
+	if s == "" {
+		return engine.NewRunID(), nil
+	}
+	for _, c := range []byte(s) {
+		if c < ' ' || c > '~' {
+			return "", fmt.Errorf("--run-id %q: must be printable ASCII", s)
+		}
+	}
+	return s, nil
+}
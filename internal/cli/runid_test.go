@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseRunID(t *testing.T) {
+	if got, err := parseRunID("nightly-42"); err != nil || got != "nightly-42" {
+		t.Errorf(`parseRunID("nightly-42") = %q, %v`, got, err)
+	}
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	a, err := parseRunID("")
+	if err != nil || !uuid.MatchString(a) {
+		t.Errorf(`parseRunID("") = %q, %v; want a UUID`, a, err)
+	}
+	if b, _ := parseRunID(""); b == a {
+		t.Errorf("two generated run IDs are both %q", a)
+	}
+	for _, bad := range []string{"a\r\nX-Injected: 1", "tab\there", "café"} {
+		if _, err := parseRunID(bad); err == nil || !strings.Contains(err.Error(), "printable ASCII") {
+			t.Errorf("parseRunID(%q) error = %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// signalNames maps the signal names accepted by --stop-signals and
+// --status-signals to their values.
+var signalNames = map[string]syscall.Signal{
+	"INT":  syscall.SIGINT,
+	"TERM": syscall.SIGTERM,
+	"QUIT": syscall.SIGQUIT,
+	"HUP":  syscall.SIGHUP,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+}
+
+// parseSignals parses signal names such as "INT", "SIGTERM" or "quit". The
+// single name "none" yields an empty, non-nil set.
+func parseSignals(names []string) ([]os.Signal, error) {
+	sigs := []os.Signal{}
+	for _, name := range names {
+		n := strings.ToUpper(strings.TrimSpace(name))
+		if n == "NONE" {
+			if len(names) != 1 {
+				return nil, fmt.Errorf("signal \"none\" cannot be combined with other signals")
+			}
+			return sigs, nil
+		}
+		sig, ok := signalNames[strings.TrimPrefix(n, "SIG")]
+		if !ok {
+			return nil, fmt.Errorf("unknown signal %q (use INT, TERM, QUIT, HUP, USR1, USR2 or none)", name)
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, nil
+}
+
+// signalConfig resolves the stop and status signal flags. Unset flags return
+// nil so the engine defaults apply.
+func signalConfig(stop, status []string) (stopSigs, statusSigs []os.Signal, err error) {
+	if stop != nil {
+		if stopSigs, err = parseSignals(stop); err != nil {
+			return nil, nil, fmt.Errorf("--stop-signals: %w", err)
+		}
+	}
+	if status != nil {
+		if statusSigs, err = parseSignals(status); err != nil {
+			return nil, nil, fmt.Errorf("--status-signals: %w", err)
+		}
+	}
+	for _, sig := range statusSigs {
+		for _, s := range stopSigs {
+			if s == sig {
+				return nil, nil, fmt.Errorf("signal %v cannot be both a stop and a status signal", sig)
+			}
+		}
+	}
+	return stopSigs, statusSigs, nil
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestParseSignals(t *testing.T) {
+	sigs, err := parseSignals([]string{"INT", "sigterm", " Hup "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []syscall.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}
+	if len(sigs) != len(want) {
+		t.Fatalf("got %v, want %v", sigs, want)
+	}
+	for i := range want {
+		if sigs[i] != want[i] {
+			t.Errorf("sigs[%d] = %v, want %v", i, sigs[i], want[i])
+		}
+	}
+
+	none, err := parseSignals([]string{"none"})
+	if err != nil || none == nil || len(none) != 0 {
+		t.Errorf("none: got %v (nil=%v), %v; want empty non-nil set", none, none == nil, err)
+	}
+	for _, bad := range [][]string{{"KILL"}, {"none", "INT"}, {""}} {
+		if _, err := parseSignals(bad); err == nil {
+			t.Errorf("parseSignals(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSignalConfig(t *testing.T) {
+	stop, status, err := signalConfig(nil, nil)
+	if err != nil || stop != nil || status != nil {
+		t.Errorf("unset flags should leave engine defaults: %v %v %v", stop, status, err)
+	}
+	if _, _, err := signalConfig([]string{"INT", "QUIT"}, []string{"QUIT"}); err == nil {
+		t.Error("a signal in both sets should be rejected")
+	}
+}
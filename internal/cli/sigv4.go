@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// sigV4Flags are the --aws-* flags; empty credentials fall back to the
+// standard AWS environment variables.
+type sigV4Flags struct {
+	spec         string // "region/service"
+	accessKeyID  string
+	secretKey    string
+	sessionToken string
+}
+
+// parseSigV4 validates --aws-sigv4 and resolves its credentials, or returns
+// nil if signing is off. getenv is os.Getenv outside tests.
+func parseSigV4(f sigV4Flags, getenv func(string) string) (*engine.SigV4, error) {
+	if f.spec == "" {
+		if f.accessKeyID != "" || f.secretKey != "" || f.sessionToken != "" {
+			return nil, fmt.Errorf("--aws-access-key-id, --aws-secret-access-key and --aws-session-token require --aws-sigv4")
+		}
+		return nil, nil
+	}
+	region, service, ok := strings.Cut(f.spec, "/")
+	if !ok || region == "" || service == "" || strings.Contains(service, "/") {
+		return nil, fmt.Errorf("--aws-sigv4: expected region/service (e.g. us-east-1/execute-api), got %q", f.spec)
+	}
+	s := &engine.SigV4{
+		Region:          region,
+		Service:         service,
+		AccessKeyID:     f.accessKeyID,
+		SecretAccessKey: f.secretKey,
+		SessionToken:    f.sessionToken,
+	}
+	if s.AccessKeyID == "" {
+		s.AccessKeyID = getenv("AWS_ACCESS_KEY_ID")
+	}
+	if s.SecretAccessKey == "" {
+		s.SecretAccessKey = getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if s.SessionToken == "" {
+		s.SessionToken = getenv("AWS_SESSION_TOKEN")
+	}
+	if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return nil, fmt.Errorf("--aws-sigv4: no credentials; set --aws-access-key-id and --aws-secret-access-key or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	return s, nil
+}
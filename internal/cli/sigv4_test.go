@@ -0,0 +1,35 @@
+package cli
+
+import "testing"
+
+func TestParseSigV4(t *testing.T) {
+	env := map[string]string{"AWS_ACCESS_KEY_ID": "AKIDENV", "AWS_SECRET_ACCESS_KEY": "envsecret", "AWS_SESSION_TOKEN": "tok"}
+	getenv := func(k string) string { return env[k] }
+
+	s, err := parseSigV4(sigV4Flags{spec: "eu-west-1/execute-api", accessKeyID: "AKIDFLAG", secretKey: "flagsecret"}, getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Region != "eu-west-1" || s.Service != "execute-api" || s.AccessKeyID != "AKIDFLAG" || s.SecretAccessKey != "flagsecret" || s.SessionToken != "tok" {
+		t.Errorf("flags should win over the environment: %+v", s)
+	}
+	if s, err := parseSigV4(sigV4Flags{spec: "us-east-1/s3"}, getenv); err != nil || s.AccessKeyID != "AKIDENV" {
+		t.Errorf("credentials from the environment: %+v, %v", s, err)
+	}
+	if s, err := parseSigV4(sigV4Flags{}, getenv); s != nil || err != nil {
+		t.Errorf("off: got %+v, %v", s, err)
+	}
+
+	noEnv := func(string) string { return "" }
+	for _, f := range []sigV4Flags{
+		{spec: "us-east-1"},
+		{spec: "us-east-1/s3/x"},
+		{spec: "/s3"},
+		{spec: "us-east-1/s3"},    // no credentials
+		{accessKeyID: "AKIDFLAG"}, // credentials without --aws-sigv4
+	} {
+		if _, err := parseSigV4(f, noEnv); err == nil {
+			t.Errorf("parseSigV4(%+v): expected an error", f)
+		}
+	}
+}
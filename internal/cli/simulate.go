@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// parseLatencyDist parses --simulate-latency: "const:5ms", "uniform:1ms,10ms",
+// "normal:20ms,5ms" (mean, stdev) or "exp:10ms" (mean).
+func parseLatencyDist(s string) (*engine.LatencyDist, error) {
+	kind, params, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return nil, fmt.Errorf("--simulate-latency %q: expected kind:params, e.g. uniform:1ms,10ms", s)
+	}
+	kind = strings.ToLower(kind)
+	var want int
+	switch kind {
+	case "const", "exp":
+		want = 1
+	case "uniform", "normal":
+		want = 2
+	default:
+		return nil, fmt.Errorf("--simulate-latency: unknown distribution %q (const, uniform, normal, exp)", kind)
+	}
+	fields := strings.Split(params, ",")
+	if len(fields) != want {
+		return nil, fmt.Errorf("--simulate-latency: %s takes %d duration(s), got %q", kind, want, params)
+	}
+	var ds []time.Duration
+	for _, f := range fields {
+		d, err := time.ParseDuration(strings.TrimSpace(f))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("--simulate-latency: invalid duration %q", f)
+		}
+		ds = append(ds, d)
+	}
+	dist := &engine.LatencyDist{Kind: kind, A: ds[0]}
+	if want == 2 {
+		dist.B = ds[1]
+	}
+	if kind == "uniform" && dist.B < dist.A {
+		return nil, fmt.Errorf("--simulate-latency: uniform range %s,%s is reversed", dist.A, dist.B)
+	}
+	return dist, nil
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+func TestParseLatencyDist(t *testing.T) {
+	cases := map[string]engine.LatencyDist{
+		"const:5ms":         {Kind: "const", A: 5 * time.Millisecond},
+		"uniform:1ms, 10ms": {Kind: "uniform", A: time.Millisecond, B: 10 * time.Millisecond},
+		"Normal:20ms,5ms":   {Kind: "normal", A: 20 * time.Millisecond, B: 5 * time.Millisecond},
+		"exp:300us":         {Kind: "exp", A: 300 * time.Microsecond},
+	}
+	for in, want := range cases {
+		got, err := parseLatencyDist(in)
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		if *got != want {
+			t.Errorf("%q = %+v, want %+v", in, *got, want)
+		}
+	}
+	for _, bad := range []string{"5ms", "gamma:1ms", "uniform:1ms", "const:1ms,2ms", "uniform:10ms,1ms", "exp:-1ms"} {
+		if _, err := parseLatencyDist(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
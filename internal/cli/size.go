@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits maps suffixes to multipliers. Decimal units match humanizeBytes in
+// the renderer (1 KB = 1000 B); binary units are accepted for convenience.
+var sizeUnits = []struct {
+	suffix string
+	mult   float64
+}{
+	// Longest suffixes first so "KiB" is not matched as "B".
+	{"kib", 1 << 10}, {"mib", 1 << 20}, {"gib", 1 << 30}, {"tib", 1 << 40},
+	{"kb", 1e3}, {"mb", 1e6}, {"gb", 1e9}, {"tb", 1e12},
+	{"k", 1e3}, {"m", 1e6}, {"g", 1e9}, {"t", 1e12},
+	{"b", 1},
+}
+
+// parseSize parses a human-readable byte size such as "512", "64KB", "1.5GB"
+// or "1GiB".
+func parseSize(s string) (uint64, error) {
+	str := strings.ToLower(strings.TrimSpace(s))
+	if str == "" {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	mult := 1.0
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, u.suffix))
+			mult = u.mult
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	// float64(math.MaxUint64) rounds up to 2^64, which no longer fits.
+	if v*mult >= math.MaxUint64 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return uint64(v * mult), nil
+}
+
+// Bounds for --read-buffer and --write-buffer: below 1KiB every response
+// header takes several reads, and beyond 16MiB the buffer is only memory.
+const (
+	minConnBuffer = 1 << 10
+	maxConnBuffer = 16 << 20
+)
+
+// parseBufferSize parses the size given to a connection buffer flag such as
+// --read-buffer; "" leaves the transport's default (4KiB).
+func parseBufferSize(flag, s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := parseSize(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", flag, err)
+	}
+	if n < minConnBuffer || n > maxConnBuffer {
+		return 0, fmt.Errorf("%s must be between 1KiB and 16MiB, got %s", flag, s)
+	}
+	return int(n), nil
+}
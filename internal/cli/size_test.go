@@ -0,0 +1,45 @@
+package cli
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]uint64{
+		"0":      0,
+		"512":    512,
+		"64KB":   64_000,
+		"1.5 GB": 1_500_000_000,
+		"1GiB":   1 << 30,
+		"10mb":   10_000_000,
+		"2k":     2000,
+		"100B":   100,
+	}
+	for in, want := range cases {
+		got, err := parseSize(in)
+		if err != nil {
+			t.Errorf("parseSize(%q): %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("parseSize(%q) = %d, want %d", in, got, want)
+		}
+	}
+	for _, bad := range []string{"", "GB", "-1MB", "ten", "1XB", "1e20"} {
+		if _, err := parseSize(bad); err == nil {
+			t.Errorf("parseSize(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseBufferSize(t *testing.T) {
+	if n, err := parseBufferSize("--read-buffer", ""); n != 0 || err != nil {
+		t.Errorf("empty: got %d, %v", n, err)
+	}
+	if n, err := parseBufferSize("--read-buffer", "64KiB"); n != 64<<10 || err != nil {
+		t.Errorf("64KiB: got %d, %v", n, err)
+	}
+	for _, bad := range []string{"0", "512", "32MiB", "big"} {
+		if _, err := parseBufferSize("--write-buffer", bad); err == nil {
+			t.Errorf("%q should fail", bad)
+		}
+	}
+}
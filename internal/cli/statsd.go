@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// parseStatsD builds the StatsD target from --statsd, --statsd-prefix and
+// --statsd-tag, or returns nil when --statsd is not set. Names and tags must
+// not contain the characters that delimit a StatsD line.
+func parseStatsD(addr, prefix string, tags []string) (*engine.StatsD, error) {
+	if addr == "" {
+		if len(tags) > 0 {
+			return nil, fmt.Errorf("--statsd-tag requires --statsd")
+		}
+		return nil, nil
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return nil, fmt.Errorf("--statsd must be host:port: %w", err)
+	}
+	if strings.ContainsAny(prefix, ":|@#, \t\n") {
+		return nil, fmt.Errorf("--statsd-prefix %q must not contain ':', '|', '@', '#', ',' or spaces", prefix)
+	}
+	for _, tag := range tags {
+		if tag == "" || strings.ContainsAny(tag, "|#, \t\n") {
+			return nil, fmt.Errorf("--statsd-tag %q must be non-empty without '|', '#', ',' or spaces", tag)
+		}
+	}
+	return &engine.StatsD{Addr: addr, Prefix: prefix, Tags: tags}, nil
+}
@@ -0,0 +1,31 @@
+package cli
+
+import "testing"
+
+func TestParseStatsD(t *testing.T) {
+	got, err := parseStatsD("127.0.0.1:8125", "bench.api", []string{"env:staging", "canary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Addr != "127.0.0.1:8125" || got.Prefix != "bench.api" || len(got.Tags) != 2 {
+		t.Errorf("parseStatsD = %+v", got)
+	}
+	if got, err := parseStatsD("", "httpcl", nil); got != nil || err != nil {
+		t.Errorf("without --statsd: got %+v, %v; want nil, nil", got, err)
+	}
+
+	for _, tt := range []struct {
+		addr, prefix string
+		tags         []string
+	}{
+		{"", "httpcl", []string{"env:ci"}},
+		{"localhost", "httpcl", nil},
+		{"localhost:8125", "http|cl", nil},
+		{"localhost:8125", "httpcl", []string{"a,b"}},
+		{"localhost:8125", "httpcl", []string{""}},
+	} {
+		if _, err := parseStatsD(tt.addr, tt.prefix, tt.tags); err == nil {
+			t.Errorf("parseStatsD(%q, %q, %q) accepted invalid input", tt.addr, tt.prefix, tt.tags)
+		}
+	}
+}
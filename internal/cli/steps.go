@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// parseSteps parses --step-concurrency: comma-separated CONCURRENCY:DURATION
+// levels, e.g. "10:30s,50:30s,100:30s".
+func parseSteps(s string) ([]engine.ConcurrencyStep, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var steps []engine.ConcurrencyStep
+	for _, part := range strings.Split(s, ",") {
+		conc, dur, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("--step-concurrency %q: want CONCURRENCY:DURATION, e.g. 50:30s", part)
+		}
+		n, err := strconv.Atoi(conc)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("--step-concurrency %q: concurrency must be a positive integer", part)
+		}
+		d, err := time.ParseDuration(dur)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("--step-concurrency %q: duration must be positive, e.g. 30s", part)
+		}
+		steps = append(steps, engine.ConcurrencyStep{Concurrency: n, Duration: d})
+	}
+	return steps, nil
+}
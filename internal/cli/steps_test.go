@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+func TestParseSteps(t *testing.T) {
+	got, err := parseSteps("10:30s, 50:1m,100:500ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []engine.ConcurrencyStep{
+		{Concurrency: 10, Duration: 30 * time.Second},
+		{Concurrency: 50, Duration: time.Minute},
+		{Concurrency: 100, Duration: 500 * time.Millisecond},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseSteps = %v, want %v", got, want)
+	}
+	if steps, err := parseSteps(""); steps != nil || err != nil {
+		t.Errorf("parseSteps(\"\") = %v, %v; want no steps", steps, err)
+	}
+	for _, bad := range []string{"10", "10:", ":30s", "0:30s", "-1:30s", "x:30s", "10:0s", "10:soon", "10:30s,", "10:30s,,20:30s"} {
+		if _, err := parseSteps(bad); err == nil {
+			t.Errorf("parseSteps(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// parseURLWeights parses --url-weight values of the form URL=WEIGHT. The
+// weight follows the last '=', so URLs may carry query strings. Weights are
+// relative and must be positive.
+func parseURLWeights(specs []string) ([]engine.Target, error) {
+	targets := make([]engine.Target, 0, len(specs))
+	for _, spec := range specs {
+		i := strings.LastIndexByte(spec, '=')
+		if i < 0 {
+			return nil, fmt.Errorf("--url-weight %q must be URL=WEIGHT", spec)
+		}
+		raw, weightStr := spec[:i], spec[i+1:]
+		weight, err := strconv.ParseFloat(weightStr, 64)
+		if err != nil || !(weight > 0) || weight > 1e9 {
+			return nil, fmt.Errorf("--url-weight %q: weight must be a positive number", spec)
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("--url-weight %q: %w", spec, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("--url-weight %q: url must be absolute http or https", spec)
+		}
+		targets = append(targets, engine.Target{URL: raw, Weight: weight})
+	}
+	return targets, nil
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+func TestParseURLWeights(t *testing.T) {
+	got, err := parseURLWeights([]string{"https://us.example.com/search?q=a=70", "https://eu.example.com=30.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []engine.Target{
+		{URL: "https://us.example.com/search?q=a", Weight: 70},
+		{URL: "https://eu.example.com", Weight: 30.5},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	for _, spec := range []string{
+		"https://example.com",
+		"https://example.com=0",
+		"https://example.com=-1",
+		"https://example.com=NaN",
+		"https://example.com=heavy",
+		"/relative=10",
+		"ftp://example.com=10",
+	} {
+		if _, err := parseURLWeights([]string{spec}); err == nil {
+			t.Errorf("parseURLWeights(%q) accepted an invalid spec", spec)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/template"
+
+	"github.com/thetangentline/httpcl/internal/export"
+)
+
+// outputTemplate is the parsed --output template, set by
+// validateOutputFlags before the run.
+var outputTemplate *template.Template
+
+// loadOutputTemplate parses the template for --output template from text
+// (--template) or the file at path (--template-file); exactly one must be
+// given, and neither without --output template.
+func loadOutputTemplate(output, text, path string) (*template.Template, error) {
+	if output != "template" {
+		if text != "" || path != "" {
+			return nil, fmt.Errorf("--template and --template-file require --output template")
+		}
+		return nil, nil
+	}
+	name := "--template"
+	switch {
+	case text != "" && path != "":
+		return nil, fmt.Errorf("--template and --template-file cannot be used together")
+	case path != "":
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("--template-file: %w", err)
+		}
+		name, text = path, string(b)
+	case text == "":
+		return nil, fmt.Errorf("--output template requires --template or --template-file")
+	}
+	// The library's errors start "template: <name>:<line>:", which is
+	// clear enough as is.
+	return export.ParseTemplate(name, text)
+}
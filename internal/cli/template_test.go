@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOutputTemplate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "report.tmpl")
+	if err := os.WriteFile(file, []byte("{{.Meta.URL}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		output, text, path string
+		ok                 bool
+	}{
+		{"text", "", "", true},
+		{"template", "{{.Snapshot.TotalRequests}}", "", true},
+		{"template", "", file, true},
+		{"template", "", "", false},
+		{"template", "{{.Meta.URL}}", file, false},
+		{"template", "{{.Meta.URL", "", false},
+		{"template", "{{nosuchfunc .Meta.URL}}", "", false},
+		{"template", "{{.Meta.NoSuchField}}", "", false},
+		{"template", "", filepath.Join(t.TempDir(), "missing.tmpl"), false},
+		{"tsv", "{{.Meta.URL}}", "", false},
+		{"text", "", file, false},
+	} {
+		tmpl, err := loadOutputTemplate(tt.output, tt.text, tt.path)
+		if (err == nil) != tt.ok {
+			t.Errorf("loadOutputTemplate(%q, %q, %q) = %v, want ok=%v", tt.output, tt.text, tt.path, err, tt.ok)
+		}
+		if err == nil && (tmpl != nil) != (tt.output == "template") {
+			t.Errorf("loadOutputTemplate(%q, %q, %q) template = %v", tt.output, tt.text, tt.path, tmpl)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/export"
+	"github.com/thetangentline/httpcl/internal/ui"
+)
+
+// parseTrendTime reads --since or --until: an RFC 3339 time, a local date
+// (2006-01-02), or a duration before now (e.g. 168h). Empty is the zero
+// time, no bound.
+func parseTrendTime(flag, s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("--%s %q: want an RFC 3339 time, a date (2006-01-02) or a duration before now (e.g. 168h)", flag, s)
+}
+
+// runTrend prints how the stored runs of --name evolved.
+func runTrend() error {
+	if flagTrendDB == "" || flagTrendName == "" {
+		return fmt.Errorf("--db and --name are required")
+	}
+	if flagTrendThresh <= 0 {
+		return fmt.Errorf("--threshold must be positive")
+	}
+	if flagTrendLast < 0 {
+		return fmt.Errorf("--last must not be negative")
+	}
+	now := time.Now()
+	since, err := parseTrendTime("since", flagTrendSince, now)
+	if err != nil {
+		return err
+	}
+	until, err := parseTrendTime("until", flagTrendUntil, now)
+	if err != nil {
+		return err
+	}
+	runs, err := export.ReadHistory(flagTrendDB, export.HistoryFilter{Name: flagTrendName, Tags: flagTrendTags, Since: since, Until: until})
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Printf("No runs of %q recorded in %s\n", flagTrendName, flagTrendDB)
+		return nil
+	}
+	points := export.Trend(runs, flagTrendThresh)
+	if flagTrendLast > 0 && len(points) > flagTrendLast {
+		points = points[len(points)-flagTrendLast:]
+	}
+	rows := make([]ui.TrendRow, len(points))
+	for i, p := range points {
+		rows[i] = ui.TrendRow{
+			StartedAt: p.Run.StartedAt,
+			RPS:       p.Run.RPS,
+			P99:       p.Run.P99,
+			RPSChange: p.RPSChange,
+			P99Change: p.P99Change,
+			Regressed: p.Regressed,
+		}
+	}
+	ui.PrintTrend(ui.LatencyAuto, flagTrendName, flagTrendThresh, rows)
+	return nil
+}
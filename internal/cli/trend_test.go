@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTrendTime(t *testing.T) {
+	now := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		in   string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"2026-03-01T09:30:00Z", time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)},
+		{"168h", now.Add(-7 * 24 * time.Hour)},
+	} {
+		got, err := parseTrendTime("since", tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseTrendTime(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"yesterday", "-24h", "0s", "2026-13-01"} {
+		if _, err := parseTrendTime("since", in, now); err == nil {
+			t.Errorf("parseTrendTime(%q) accepted an invalid time", in)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/thetangentline/httpcl/pkg/netutil"
+)
+
+// wizardURLCheck returns the check the start wizard runs on the target URL
+// as soon as it is entered. The URL must be absolute http or https; unless
+// skipDNS is set (--skip-dns-check, for setting up a run while offline) its
+// host must also resolve.
+func wizardURLCheck(skipDNS bool) func(string) error {
+	return func(raw string) error {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("invalid url: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Hostname() == "" {
+			return fmt.Errorf("url %q must be absolute http or https, e.g. https://example.com/", raw)
+		}
+		if skipDNS {
+			return nil
+		}
+		_, err = netutil.PreflightDNS(raw)
+		return err
+	}
+}
+
+// wizardHeaderCheck vets each header the start wizard is given, with the
+// same rules as -H.
+func wizardHeaderCheck(line string) error {
+	_, _, err := parseHeaderLine(line)
+	return err
+}
@@ -0,0 +1,32 @@
+package cli
+
+import "testing"
+
+func TestWizardURLCheck(t *testing.T) {
+	for _, bad := range []string{"example.com", "ftp://example.com/", "http://", "http://[::1"} {
+		if err := wizardURLCheck(true)(bad); err == nil {
+			t.Errorf("wizardURLCheck(%q) = nil, want error", bad)
+		}
+	}
+	// .invalid never resolves, so only the skip mode accepts it.
+	if err := wizardURLCheck(true)("https://api.invalid/v1"); err != nil {
+		t.Errorf("skip mode: %v", err)
+	}
+	if err := wizardURLCheck(false)("https://api.invalid/v1"); err == nil {
+		t.Error("want a DNS error for an .invalid host")
+	}
+	if err := wizardURLCheck(false)("http://127.0.0.1:8080/"); err != nil {
+		t.Errorf("literal IP: %v", err)
+	}
+}
+
+func TestWizardHeaderCheck(t *testing.T) {
+	if err := wizardHeaderCheck("Authorization: Bearer abc"); err != nil {
+		t.Errorf("valid header: %v", err)
+	}
+	for _, bad := range []string{"Authorization", ": value"} {
+		if err := wizardHeaderCheck(bad); err == nil {
+			t.Errorf("wizardHeaderCheck(%q) = nil, want error", bad)
+		}
+	}
+}
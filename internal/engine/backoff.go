@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// Connection error back-off parameters (Config.BackoffOnErrors).
+const (
+	backoffErrorRate = 0.05 // connection errors per completed request that count as a spike
+	backoffDecrease  = 0.5  // multiplicative cut of the active slots on a spike
+	backoffIncrease  = 0.1  // additive step per quiet interval, as a fraction of all slots
+)
+
+// backoffCategories are the errors that mean the server is turning
+// connections away rather than answering badly.
+var backoffCategories = []stats.ErrorCategory{stats.ErrConnect, stats.ErrRead, stats.ErrTimeout}
+
+// backoffLimiter adjusts a slotLimiter every interval: it compares the
+// connection errors of the requests completed in it with backoffErrorRate
+// and, AIMD-style, halves the limit on a spike and raises it by a tenth of
+// the slots while errors stay under it.
+type backoffLimiter struct {
+	*slotLimiter
+}
+
+func newBackoffLimiter(slots int, inner scheduler) *backoffLimiter {
+	return &backoffLimiter{newSlotLimiter(slots, slots, inner)}
+}
+
+// run adjusts the limit every interval from the collector's counts.
+func (b *backoffLimiter) run(ctx context.Context, durationDone <-chan struct{}, interval time.Duration, collector *stats.Collector) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var lastRequests, lastErrors uint64
+	for {
+		select {
+		case <-ticker.C:
+			snap := collector.Snapshot()
+			var connErrors uint64
+			for _, c := range backoffCategories {
+				connErrors += snap.ErrorsByCategory[c]
+			}
+			b.adjust(snap.TotalRequests-lastRequests, connErrors-lastErrors)
+			lastRequests, lastErrors = snap.TotalRequests, connErrors
+		case <-durationDone:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// adjust runs one step of the control loop over an interval in which
+// requests completed, connErrors of them with a connection error.
+func (b *backoffLimiter) adjust(requests, connErrors uint64) {
+	if requests == 0 {
+		return
+	}
+	limit, _, _ := b.state()
+	if float64(connErrors) > backoffErrorRate*float64(requests) {
+		b.setLimit(int(float64(limit) * backoffDecrease))
+	} else if limit < b.slots {
+		b.setLimit(limit + max(int(float64(b.slots)*backoffIncrease), 1))
+	}
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// calibrate sends closed-loop load with all of cfg's slots for d and
+// returns the rate of successful requests, i.e. the most the server
+// sustained. It has its own client and collector, so nothing it sends shows
+// up in the run's stats. In-flight requests get cfg.DrainTimeout to finish
+// once d is over.
+func calibrate(parent context.Context, cfg Config, d time.Duration) (float64, error) {
+	cfg.OnResult = nil
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	collector := stats.NewCollector()
+	client := newHTTPClient(cfg, nil)
+	defer client.CloseIdleConnections()
+	reqs := newRequestBuilder(cfg)
+
+	done := make(chan struct{})
+	stop := time.AfterFunc(d, func() {
+		close(done)
+		if cfg.DrainTimeout > 0 {
+			time.AfterFunc(cfg.DrainTimeout, cancel)
+		}
+	})
+	defer stop.Stop()
+
+	var wg sync.WaitGroup
+	reqsPerWorker := max(cfg.Connections/cfg.Workers, 1)
+	for i := 0; i < cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker(ctx, i, done, client, cfg, reqs, nil, reqsPerWorker, collector)
+		}()
+	}
+	wg.Wait()
+	if err := parent.Err(); err != nil {
+		return 0, err
+	}
+	snap := collector.Snapshot()
+	if snap.Successes == 0 {
+		return 0, fmt.Errorf("calibration: no request succeeded in %s (%d errors)", d, snap.Errors)
+	}
+	return float64(snap.Successes) / d.Seconds(), nil
+}
+
+// Calibration returns the throughput measured by Config.Calibrate and the
+// rate the run was then paced at (0 if it was not), or zeros if the run did
+// not calibrate.
+func (o *Orchestrator) Calibration() (maxRPS, rate float64) {
+	return o.calibratedMax, o.calibratedRate
+}
@@ -1,31 +1,110 @@
 package engine
 
 import (
+	"context"
+	"crypto/tls"
 	"net"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// Transport timeouts. Request lifetime itself is governed by the run context
+// and duration, not by http.Client.Timeout.
+const (
+	dialTimeout           = 5 * time.Second
+	dialKeepAlive         = 30 * time.Second
+	tlsHandshakeTimeout   = 10 * time.Second
+	idleConnTimeout       = 90 * time.Second
+	expectContinueTimeout = 1 * time.Second
+)
+
 // newHTTPClient returns an *http.Client tuned for benchmarking:
-// - keep-alives enabled
+// - keep-alives enabled, unless cfg.ConnBench wants a new connection per request
 // - larger MaxIdleConns and MaxIdleConnsPerHost
-func newHTTPClient(maxConns int) *http.Client {
+// - dials to a "host:port" listed in cfg.Resolve go to the pinned address instead
+// - new connections start with a PROXY protocol header if cfg.ProxyProtocol is set
+// - connections count their requests if cfg.DiscardFirstPerConn is set
+// - at most cfg.Connections connections per host with cfg.CapConnections
+// - HTTP/2 only (including h2c) in gRPC mode, or as set by cfg.HTTPVersion
+// - host names are resolved (and cached) by httpcl with cfg.ResolveDNS
+// - dials and DNS lookups are counted in counts, if not nil
+// - certificates are not verified with cfg.Insecure
+func newHTTPClient(cfg Config, counts *connCounts) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: dialKeepAlive,
+	}
+	var dials, lookups *atomic.Uint64
+	if counts != nil {
+		dials, lookups = &counts.dials, &counts.lookups
+	}
+	dial := dialer.DialContext
+	if cfg.ResolveDNS {
+		dial = newDNSCache(cfg.DNSCache, lookups).dial(dial)
+	}
 	transport := &http.Transport{
 		Proxy:                 http.ProxyFromEnvironment,
-		MaxIdleConns:          maxConns,
-		MaxIdleConnsPerHost:   maxConns,
+		MaxIdleConns:          cfg.Connections,
+		MaxIdleConnsPerHost:   cfg.Connections,
 		ForceAttemptHTTP2:     true,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-		DialContext: (&net.Dialer{
-			Timeout:   5 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
+		IdleConnTimeout:       idleConnTimeout,
+		TLSHandshakeTimeout:   tlsHandshakeTimeout,
+		ExpectContinueTimeout: expectContinueTimeout,
+		ReadBufferSize:        cfg.ReadBufferSize,
+		WriteBufferSize:       cfg.WriteBufferSize,
+		DialContext:           countedDial(proxyProtoDial(pinnedDial(dial, cfg.Resolve), cfg.ProxyProtocol, cfg.ProxySource), cfg.DiscardFirstPerConn, dials),
+	}
+	if cfg.CapConnections {
+		transport.MaxConnsPerHost = cfg.Connections
+	}
+	if cfg.ConnBench {
+		transport.DisableKeepAlives = true
+	}
+	if cfg.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	switch {
+	case cfg.GRPC || cfg.HTTPVersion == "2":
+		transport.Protocols = http2Protocols()
+	case cfg.HTTPVersion == "1.1":
+		transport.Protocols = new(http.Protocols)
+		transport.Protocols.SetHTTP1(true)
 	}
 
 	return &http.Client{
-		Timeout:   0, // we control timeouts via context / duration
-		Transport: transport,
+		Timeout:       0, // we control timeouts via context / duration
+		Transport:     transport,
+		CheckRedirect: checkRedirect,
 	}
 }
+
+// connCounts counts what a client's dials did over a run.
+type connCounts struct {
+	dials   atomic.Uint64 // connections made
+	lookups atomic.Uint64 // DNS lookups, with Config.ResolveDNS
+}
+
+// pinnedDial wraps dial so that addresses found in resolve (keyed by
+// lower-case "host:port") connect to their pinned "addr:port" instead.
+func pinnedDial(dial func(ctx context.Context, network, addr string) (net.Conn, error), resolve map[string]string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if len(resolve) == 0 {
+		return dial
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if pinned, ok := resolve[strings.ToLower(addr)]; ok {
+			addr = pinned
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// http2Protocols limits the transport to HTTP/2: TLS with h2 for https
+// targets and prior-knowledge h2c for plain http ones, as gRPC servers expect.
+func http2Protocols() *http.Protocols {
+	p := new(http.Protocols)
+	p.SetHTTP2(true)
+	p.SetUnencryptedHTTP2(true)
+	return p
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// etagChain remembers the latest ETag the server sent so every following
+// request revalidates it with If-None-Match (see Config.ChainETag).
+type etagChain struct {
+	tag atomic.Pointer[string]
+}
+
+// observe records the ETag of a successful or 304 response.
+func (c *etagChain) observe(resp *http.Response) {
+	if resp == nil || resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotModified {
+		return
+	}
+	tag := resp.Header.Get("ETag")
+	if tag == "" {
+		return
+	}
+	if cur := c.tag.Load(); cur != nil && *cur == tag {
+		return
+	}
+	c.tag.Store(&tag)
+}
+
+// current returns the ETag to send, or "" before one has been seen.
+func (c *etagChain) current() string {
+	if tag := c.tag.Load(); tag != nil {
+		return *tag
+	}
+	return ""
+}
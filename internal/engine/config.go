@@ -1,15 +1,297 @@
 package engine
 
-import "time"
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"time"
 
-// Config holds the runtime configuration for a benchmark run.
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// Config holds the runtime configuration for a benchmark run. It is saved
+// in run manifests as JSON; fields tied to the process (signals, controls,
+// callbacks) are left out.
+//
+// URL, Body and header values may contain placeholders such as {{uuid}} or
+// {{seq}} that are expanded for every request (see template.go).
 type Config struct {
-	Method      string
-	URL         string
-	Body        []byte // optional; used for POST, PUT, PATCH
+	Method string
+	URL    string
+	Body   []byte // optional; used for POST, PUT, PATCH
+	// BodyCorpus, if set, replaces Body: each request sends one entry chosen
+	// at random with the slot's seeded RNG, or with BodyCycle the next one
+	// in order, wrapping around, shared by all slots.
+	BodyCorpus [][]byte
+	BodyCycle  bool
+	// BodySize, if positive, replaces Body with a generated body of this
+	// many bytes, streamed with a known Content-Length and never held in
+	// memory: zeros, or with BodyRandom a repeating block of random bytes.
+	BodySize   int64
+	BodyRandom bool
+	// Replay, if set, replaces Method, URL and Body: requests are sent from
+	// it in order, wrapping around, shared by all slots. URL still names the
+	// target for the DNS preflight; Headers apply unless a request sets the
+	// same header.
+	Replay []ReplayRequest
+	// ReplaySpeed, if positive, sends the Replay requests at their capture
+	// times (ReplayRequest.At) sped up by this factor: 2 halves every gap,
+	// 0.5 doubles it. 0 sends them as fast as the slots allow.
+	ReplaySpeed float64
+	// Transaction, if set, makes every iteration of a slot send these
+	// requests in order as one transaction, recorded as a single result: its
+	// latency is the sum of the steps' latencies, and it fails at the first
+	// failing step, which ends the sequence. Snapshot.Steps has per-step
+	// stats. It replaces Method, URL and Body like Replay, and is not
+	// retried (Retries) or hashed (ExpectSHA256).
+	Transaction []ReplayRequest
+	Headers     http.Header
+	// RunID, if set, tags every request of the run with an X-Benchmark-Run
+	// header (unless Headers sets one) and is echoed in the run header and
+	// the summary, to find the run in downstream logs.
+	RunID string
+	// ContentType is sent as Content-Type unless Headers already sets one.
+	ContentType string
 	Connections int
 	Duration    time.Duration
 	Workers     int
 	Pipeline    int
+	MaxBytes    uint64 // stop after this many bytes sent+received; 0 = no budget
+	// ReadBufferSize and WriteBufferSize size each HTTP/1.1 connection's
+	// buffers; larger ones mean fewer syscalls per response. 0 keeps the
+	// transport's default (4KiB).
+	ReadBufferSize  int
+	WriteBufferSize int
+	// DrainTimeout is how long in-flight requests may run after the duration
+	// ends before they are cancelled and counted as abandoned. 0 means the
+	// default (5s); negative waits indefinitely.
+	DrainTimeout time.Duration
+	// StaggerStart, if positive, delays each pipeline slot's first request
+	// by a random offset within this window (from the slot's seeded RNG),
+	// so slots do not all fire at once when the run starts.
+	StaggerStart time.Duration
+	Verbose      bool // print the effective configuration before the run
+	// Seed drives every random choice made by the engine (e.g. BodyCorpus
+	// picks). 0 picks a random seed, reported by Orchestrator.Config.
+	Seed uint64
+	// MaxErrorRate, if positive, makes Run return a *RunError when more than
+	// this fraction of requests failed. 0 = always return nil after a run.
+	MaxErrorRate float64
+	// MinRPS, if positive, makes Run return a *ThroughputError when the
+	// sustained request rate fell below it. The rate is measured over the
+	// load phase after MinRPSWarmup (default 1s), so ramp-up does not count;
+	// a run too short to measure past the warm-up passes.
+	MinRPS       float64
+	MinRPSWarmup time.Duration
+	// LowRPSWarn, if positive, flags a closed-loop run whose slots each
+	// completed fewer than this many requests a second while most requests
+	// failed: its numbers describe the failure, not the server, and the
+	// summary says so (Snapshot.Suspect). 0 disables the check.
+	LowRPSWarn float64
+	// SkipDNSCheck lets the run continue past a failed DNS preflight with a
+	// warning. A Resolve entry for the target or a proxy implies it.
+	SkipDNSCheck bool
+	// ResolveDNS makes httpcl resolve host names for its dials instead of
+	// the dialer, counting the lookups and reusing each result for DNSCache
+	// (zero: a fresh lookup for every dial).
+	ResolveDNS bool
+	DNSCache   time.Duration
+	// Resolve pins lower-case "host:port" keys to an "addr:port" to connect to
+	// instead, like curl --resolve. Host headers and TLS SNI are unchanged.
+	Resolve map[string]string
+	// ProxyProtocol, if 1 or 2, sends a PROXY protocol header of that version
+	// at the start of every connection, for targets behind a load balancer
+	// that expects one. ProxySource is the client address it announces; the
+	// zero value uses the connection's real local address.
+	ProxyProtocol int
+	ProxySource   netip.AddrPort
+	// AWSSigV4, if set, signs every request with AWS Signature Version 4 just
+	// before it is sent. The signature covers a timestamp, so it is computed
+	// per request. Generated (BodySize) bodies are signed as UNSIGNED-PAYLOAD.
+	AWSSigV4 *SigV4
+	// IfNoneMatch and IfModifiedSince, if set, make every request
+	// conditional, so a server whose copy has not changed answers 304 Not
+	// Modified (counted in Snapshot.NotModified). ChainETag instead sends
+	// the ETag of the latest 2xx or 304 response as If-None-Match, starting
+	// from IfNoneMatch if set.
+	IfNoneMatch     string
+	IfModifiedSince time.Time
+	ChainETag       bool
+	// Decode asks for compressed responses (gzip, deflate, br, zstd, unless
+	// an Accept-Encoding header is set) and decodes their bodies before
+	// ExpectSHA256 and AssertJSON check them and before they are counted as
+	// received. Encodings it cannot decode are counted in
+	// Snapshot.Undecoded and the body is taken as is.
+	Decode bool
+	// Count429 says how 429 Too Many Requests responses are counted:
+	// Count429Separate (the default) in Snapshot.RateLimited, as neither
+	// successes nor errors; Count429Success as successes, like other 4xx;
+	// Count429Error as errors in the http_429 category.
+	Count429 string
+	// ExpectSHA256, if set, is the SHA-256 digest every response body must
+	// match; mismatches count as validation errors.
+	ExpectSHA256 []byte
+	// AssertJSON checks must all pass on a response's JSON body (its first
+	// MiB); failures count as validation errors.
+	AssertJSON []JSONAssert
+	// ExpectHeaders must all be present in a response, and RejectHeaders
+	// must all be absent, for it to count as a success regardless of its
+	// status code. Failures are counted as header errors.
+	ExpectHeaders []HeaderMatch
+	RejectHeaders []HeaderMatch
+	// Trailers are sent after every request body, which then goes out
+	// chunked over HTTP/1.1 (an empty one if there is no body).
+	// ExpectTrailers and RejectTrailers check response trailers as
+	// ExpectHeaders and RejectHeaders check headers, after the body is
+	// drained; failures are header errors too.
+	Trailers       http.Header
+	ExpectTrailers []HeaderMatch
+	RejectTrailers []HeaderMatch
+	// StatsMemory, if positive, bounds in bytes the memory the collector
+	// uses for retained latency samples and time-series buckets instead of
+	// the fixed defaults (see stats.RetentionFor).
+	StatsMemory uint64
+	// SimulateLatency, if set, replaces real requests with synthetic ones
+	// whose latencies follow this distribution. No connection is made. It is
+	// a testing aid for the metrics pipeline and renderers.
+	SimulateLatency *LatencyDist
+	// CapConnections makes Connections a hard limit on connections per host
+	// instead of just the idle pool size, so slots beyond it queue for a free
+	// connection. The queueing time is reported as conn wait.
+	CapConnections bool
+	// PoolWaitWarn is the share of the load phase (default 0.25) that the
+	// slots of a capped pool may spend waiting for a connection before the
+	// pool is reported as too small for the load. Strict makes that fail
+	// the run with a *PoolExhaustedError.
+	PoolWaitWarn float64
+	Strict       bool
+	// ConnBench disables keep-alive so every request sets up a new
+	// connection, and reports connections and TLS handshakes per second and
+	// the setup time, headlined over requests per second.
+	ConnBench bool
+	// PerConn counts requests and errors per connection, identified by its
+	// local address, and reports the worst connections in the summary. It
+	// is off by default because it traces every request.
+	PerConn bool
+	// ShowAddrs reports the addresses the DNS preflight resolved the target
+	// to and how many requests went to each remote address actually
+	// connected to, e.g. to see which backends round-robin DNS picked.
+	ShowAddrs bool
+	// DiscardFirstPerConn leaves the first K requests on every new
+	// connection out of the stats, so connection setup, TCP slow start and
+	// server warm-up do not skew steady-state numbers. They are counted as
+	// discarded instead.
+	DiscardFirstPerConn int
+	// GRPC sends each body as a unary gRPC call: framed as a length-prefixed
+	// message with Content-Type application/grpc over HTTP/2 (h2c for http
+	// URLs). A call only succeeds if its grpc-status is 0.
+	GRPC bool
+	// HTTPVersion pins the protocol: "1.1" for HTTP/1.1 only, "2" for HTTP/2
+	// only (h2c with prior knowledge for http URLs). Empty negotiates as usual:
+	// HTTP/2 over TLS when the server offers it, HTTP/1.1 otherwise.
+	HTTPVersion string
+	// Targets, if set, spreads requests over several URLs by weight, e.g.
+	// 70% to one region and 30% to another: each request picks one with its
+	// slot's seeded RNG, and the summary breaks the run down by target.
+	// Weights are relative; they need not add up to anything.
+	Targets []Target
+	// ShardKey, if set, picks each request's target by consistent hashing
+	// of this key instead of at random, simulating sticky routing to the
+	// shards of a backend: the same key always goes to the same target,
+	// and the weights set each target's share of the keys. Placeholders
+	// such as {{seq}} are expanded per request, as for a header value.
+	ShardKey string
+	// StatsD, if set, sends requests, errors, rate, latency and in-flight
+	// metrics to a StatsD server every second during the run and once more
+	// at the end.
+	StatsD *StatsD
+	// Retries re-sends a request that failed with a transport error or a 5xx
+	// up to this many times before recording it; only the final attempt's
+	// outcome and latency are recorded. RetryBackoff (BackoffConstant, the
+	// default, or BackoffExponential) spaces the attempts starting from
+	// RetryDelay (default 100ms), and RetryJitter randomizes each wait with
+	// the slot's seeded RNG. Requests that are not idempotent (POST, PATCH)
+	// are only retried when they failed while dialing, so a benchmark does not
+	// submit them twice, unless RetryNonIdempotent is set.
+	Retries            int
+	RetryDelay         time.Duration
+	RetryBackoff       string
+	RetryJitter        bool
+	RetryNonIdempotent bool
+	// MaxRequestsPerConn, if positive, makes each pipeline slot send every
+	// Nth request with Connection: close so the connection is replaced,
+	// exercising connection churn. 0 keeps connections alive indefinitely.
+	MaxRequestsPerConn int
+	// Rate, if positive, paces request starts at this many per second
+	// across all slots (open loop) instead of sending each slot's next
+	// request as soon as the previous one completes.
+	Rate float64
+	// Calibrate, if positive, first sends closed-loop load for this long to
+	// measure the server's maximum throughput. With RateFraction, the run
+	// is then paced at that fraction of it, replacing Rate.
+	Calibrate    time.Duration
+	RateFraction float64
+	// AdaptiveRate paces requests at a rate that is adjusted every
+	// AdaptiveInterval (default 1s) to find the highest rate keeping p99
+	// latency at or under TargetP99. Experimental.
+	AdaptiveRate     bool
+	TargetP99        time.Duration
+	AdaptiveInterval time.Duration
+	// BackoffOnErrors protects a struggling server: when more than 5% of the
+	// requests completed in a BackoffInterval (default 1s) failed to connect,
+	// were reset or timed out, only half of the slots keep sending, and a
+	// tenth of them is added back each interval the errors stay under that.
+	BackoffOnErrors bool
+	BackoffInterval time.Duration
+	// ConcurrencySteps turns the run into a step load test: each step's
+	// concurrency is held for its duration, in order, and reported on its
+	// own in Snapshot.LoadSteps. Duration becomes the steps' total, and
+	// Pipeline is raised if needed to have a slot for the largest step.
+	ConcurrencySteps []ConcurrencyStep
+	// Burst switches to spike testing: every BurstInterval (default 1s) Burst
+	// requests are released at once and nothing is sent in between. 0 = off.
+	Burst         int
+	BurstInterval time.Duration
+	// StopSignals cancel the run; StatusSignals print a live snapshot and let
+	// it continue. nil selects the defaults (SIGINT and SIGTERM to stop,
+	// SIGQUIT for status); an empty non-nil slice handles no signals.
+	StopSignals   []os.Signal `json:"-"`
+	StatusSignals []os.Signal `json:"-"`
+	// Controls, if set, pauses, resumes or stops the run while it is under
+	// way, e.g. from keypresses. While paused no request starts; in-flight
+	// ones finish and idle connections stay open. The duration keeps
+	// running. ControlStop ends the load phase as the duration would.
+	Controls <-chan Control `json:"-"`
+	// Insecure skips TLS certificate verification, e.g. for a staging
+	// server with a self-signed or expired certificate.
+	Insecure bool
+	// CertExpiryWarn is how close to expiry the server's certificate may be
+	// before the TLS preflight warns; 0 skips the preflight.
+	CertExpiryWarn time.Duration
+	// SoakReport, if positive, prints a full interim report with the
+	// client's memory and goroutine counts at this interval while the run
+	// goes on, for multi-hour stability tests.
+	SoakReport time.Duration
+	// OnResult, if set, receives every result right after the collector
+	// records it, e.g. to feed an external metrics sink. It is called on the
+	// sending slot's goroutine, concurrently from all slots, so it must be
+	// safe for concurrent use; while it runs the slot sends nothing, so a slow
+	// hook lowers the measured throughput.
+	OnResult func(stats.Result) `json:"-"`
+	// Log receives the preflight steps, the run header and the notes
+	// printed after the run; nil means stdout. A caller writing its own
+	// report to stdout points it elsewhere, e.g. at stderr.
+	Log io.Writer `json:"-"`
+	// RequestContext, if set, derives the context of each request from
+	// base, e.g. to attach a trace span or baggage that an instrumented
+	// transport reads. base is the run's context, cancelled when the run is
+	// aborted or the drain timeout expires, so the result must be derived
+	// from it. It is called once per request, before the connection-tracing
+	// hooks are added, and retries of the request reuse its context. It runs
+	// on the sending slot's goroutine, concurrently from all slots. The end
+	// of Duration does not cancel base: requests in flight then are waited
+	// for. Transaction steps do not go through it.
+	RequestContext func(base context.Context) context.Context `json:"-"`
 }
-
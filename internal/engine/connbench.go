@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"time"
+)
+
+// connSetup times how a request set up its connection, for --conn-bench.
+type connSetup struct {
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+}
+
+// trace adds hooks to t that fill s. Only the first connect attempt's start
+// counts, so a dial that tries several addresses is timed as a whole.
+func (s *connSetup) trace(t *httptrace.ClientTrace) {
+	t.ConnectStart = func(string, string) {
+		if s.connectStart.IsZero() {
+			s.connectStart = time.Now()
+		}
+	}
+	t.ConnectDone = func(_, _ string, err error) {
+		if err == nil {
+			s.connectDone = time.Now()
+		}
+	}
+	t.TLSHandshakeStart = func() { s.tlsStart = time.Now() }
+	t.TLSHandshakeDone = func(_ tls.ConnectionState, err error) {
+		if err == nil {
+			s.tlsDone = time.Now()
+		}
+	}
+}
+
+// durations returns the TCP connect and TLS handshake times, each zero if
+// the request did not complete that step.
+func (s *connSetup) durations() (connect, handshake time.Duration) {
+	if !s.connectStart.IsZero() && !s.connectDone.IsZero() {
+		connect = s.connectDone.Sub(s.connectStart)
+	}
+	if !s.tlsStart.IsZero() && !s.tlsDone.IsZero() {
+		handshake = s.tlsDone.Sub(s.tlsStart)
+	}
+	return connect, handshake
+}
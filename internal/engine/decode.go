@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// contentDecoder decodes one Content-Encoding. A slot keeps one per
+// encoding and resets it for every response, so decoder state is reused.
+type contentDecoder interface {
+	reset(r io.Reader) (io.Reader, error)
+}
+
+// contentDecoders holds the decoders --decode can use, by encoding, in the
+// order they are offered in Accept-Encoding. br and zstd are registered by
+// decode_brotli.go and decode_zstd.go unless built with the nobrotli or
+// nozstd tag.
+var (
+	contentDecoders = map[string]func() contentDecoder{
+		"gzip":    func() contentDecoder { return &gzipDecoder{} },
+		"deflate": func() contentDecoder { return &deflateDecoder{} },
+	}
+	decoderPreference = []string{"zstd", "br", "gzip", "deflate"}
+)
+
+// acceptEncoding lists the encodings this build decodes, e.g.
+// "zstd, br, gzip, deflate".
+func acceptEncoding() string {
+	var names []string
+	for _, name := range decoderPreference {
+		if contentDecoders[name] != nil {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+type gzipDecoder struct{ zr *gzip.Reader }
+
+func (d *gzipDecoder) reset(r io.Reader) (io.Reader, error) {
+	if d.zr == nil {
+		zr, err := gzip.NewReader(r)
+		d.zr = zr
+		return zr, err
+	}
+	return d.zr, d.zr.Reset(r)
+}
+
+// deflateDecoder reads HTTP's "deflate", which is zlib-wrapped.
+type deflateDecoder struct{ zr io.ReadCloser }
+
+func (d *deflateDecoder) reset(r io.Reader) (io.Reader, error) {
+	if d.zr == nil {
+		zr, err := zlib.NewReader(r)
+		d.zr = zr
+		return zr, err
+	}
+	return d.zr, d.zr.(zlib.Resetter).Reset(r, nil)
+}
+
+// bodyDecoder is a slot's set of content decoders for --decode.
+type bodyDecoder struct {
+	decoders map[string]contentDecoder
+}
+
+func newBodyDecoder() *bodyDecoder {
+	return &bodyDecoder{decoders: make(map[string]contentDecoder)}
+}
+
+// decode returns a reader over the decoded body of resp, undoing its
+// Content-Encoding codings last to first. If one of them is not supported
+// the body is returned as is, with that encoding.
+func (d *bodyDecoder) decode(resp *http.Response) (body io.Reader, unsupported string, err error) {
+	body = resp.Body
+	codings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
+	for _, c := range codings {
+		if c = strings.ToLower(strings.TrimSpace(c)); c != "" && c != "identity" && contentDecoders[c] == nil {
+			return resp.Body, c, nil
+		}
+	}
+	for i := len(codings) - 1; i >= 0; i-- {
+		c := strings.ToLower(strings.TrimSpace(codings[i]))
+		if c == "" || c == "identity" {
+			continue
+		}
+		dec := d.decoders[c]
+		if dec == nil {
+			dec = contentDecoders[c]()
+			d.decoders[c] = dec
+		}
+		if body, err = dec.reset(body); err != nil {
+			return nil, "", fmt.Errorf("--decode %s: %w", c, err)
+		}
+	}
+	return body, "", nil
+}
@@ -0,0 +1,23 @@
+//go:build !nobrotli
+
+package engine
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+)
+
+func init() {
+	contentDecoders["br"] = func() contentDecoder { return &brotliDecoder{} }
+}
+
+type brotliDecoder struct{ br *brotli.Reader }
+
+func (d *brotliDecoder) reset(r io.Reader) (io.Reader, error) {
+	if d.br == nil {
+		d.br = brotli.NewReader(r)
+		return d.br, nil
+	}
+	return d.br, d.br.Reset(r)
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// encoders produce each encoding --decode knows, for the round trips.
+var encoders = map[string]func(io.Writer) io.WriteCloser{
+	"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+	"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+	"br":      func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+	"zstd": func(w io.Writer) io.WriteCloser {
+		zw, _ := zstd.NewWriter(w)
+		return zw
+	},
+}
+
+// encodedResponse applies codings to body in order, as a server listing
+// them in Content-Encoding would.
+func encodedResponse(t *testing.T, body string, codings ...string) *http.Response {
+	t.Helper()
+	data := []byte(body)
+	for _, c := range codings {
+		if c == "identity" {
+			continue
+		}
+		var buf bytes.Buffer
+		w := encoders[c](&buf)
+		w.Write(data)
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		data = buf.Bytes()
+	}
+	return &http.Response{
+		Header: http.Header{"Content-Encoding": {strings.Join(codings, ", ")}},
+		Body:   io.NopCloser(bytes.NewReader(data)),
+	}
+}
+
+func TestBodyDecoder_RoundTrips(t *testing.T) {
+	const body = `{"status":"ok","items":[1,2,3]}`
+	d := newBodyDecoder()
+	cases := [][]string{{"gzip"}, {"deflate"}, {"br"}, {"zstd"}, {"zstd"}, {"gzip", "br"}, {"identity"}}
+	for _, codings := range cases {
+		supported := true
+		for _, c := range codings {
+			supported = supported && (c == "identity" || contentDecoders[c] != nil)
+		}
+		if !supported {
+			continue // built with nobrotli or nozstd
+		}
+		r, unsupported, err := d.decode(encodedResponse(t, body, codings...))
+		if err != nil || unsupported != "" {
+			t.Fatalf("%v: unsupported %q, err %v", codings, unsupported, err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil || string(got) != body {
+			t.Errorf("%v: decoded %q, %v; want %q", codings, got, err, body)
+		}
+	}
+}
+
+func TestBodyDecoder_Unsupported(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": {"gzip, compress"}},
+		Body:   io.NopCloser(strings.NewReader("raw")),
+	}
+	r, unsupported, err := newBodyDecoder().decode(resp)
+	if err != nil || unsupported != "compress" || r != resp.Body {
+		t.Errorf("got unsupported %q, err %v, body replaced %v; want compress and the body as is", unsupported, err, r != resp.Body)
+	}
+	if _, _, err := newBodyDecoder().decode(&http.Response{
+		Header: http.Header{"Content-Encoding": {"gzip"}},
+		Body:   io.NopCloser(strings.NewReader("not gzip")),
+	}); err == nil || !strings.Contains(err.Error(), "--decode gzip") {
+		t.Errorf("a corrupt gzip body should fail to decode, got %v", err)
+	}
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	got := acceptEncoding()
+	if !strings.HasSuffix(got, "gzip, deflate") {
+		t.Errorf("acceptEncoding() = %q, want the built-in encodings last", got)
+	}
+}
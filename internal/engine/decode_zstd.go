@@ -0,0 +1,26 @@
+//go:build !nozstd
+
+package engine
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func init() {
+	contentDecoders["zstd"] = func() contentDecoder { return &zstdDecoder{} }
+}
+
+// zstdDecoder decodes on the slot's goroutine; the decoder's own
+// goroutines would only add overhead for a response at a time.
+type zstdDecoder struct{ zr *zstd.Decoder }
+
+func (d *zstdDecoder) reset(r io.Reader) (io.Reader, error) {
+	if d.zr == nil {
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		d.zr = zr
+		return zr, err
+	}
+	return d.zr, d.zr.Reset(r)
+}
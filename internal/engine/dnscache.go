@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/thetangentline/httpcl/pkg/netutil"
+)
+
+// dnsCache resolves host names for dials itself (--dns-cache) instead of
+// leaving it to the dialer, so lookups can be counted and their results
+// reused for ttl. A zero ttl looks up the host on every dial.
+type dnsCache struct {
+	resolver netutil.HostResolver
+	ttl      time.Duration
+	lookups  *atomic.Uint64 // may be nil
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+func newDNSCache(ttl time.Duration, lookups *atomic.Uint64) *dnsCache {
+	return &dnsCache{resolver: net.DefaultResolver, ttl: ttl, lookups: lookups, entries: make(map[string]dnsEntry)}
+}
+
+// lookup returns the addresses of host, from the cache if still fresh.
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	if c.ttl > 0 {
+		c.mu.Lock()
+		e, ok := c.entries[host]
+		c.mu.Unlock()
+		if ok && time.Now().Before(e.expires) {
+			return e.addrs, nil
+		}
+	}
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if c.lookups != nil {
+		c.lookups.Add(1)
+	}
+	if err != nil {
+		// Keep failures classified as DNS errors whatever the resolver.
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) {
+			err = &net.DNSError{Err: err.Error(), Name: host}
+		}
+		return nil, err
+	}
+	if c.ttl > 0 {
+		c.mu.Lock()
+		c.entries[host] = dnsEntry{addrs: addrs, expires: time.Now().Add(c.ttl)}
+		c.mu.Unlock()
+	}
+	return addrs, nil
+}
+
+// dial wraps dial so that host names are resolved by c and the addresses
+// tried in order until one connects. IP addresses are dialled as given.
+func (c *dnsCache) dial(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+		addrs, err := c.lookup(ctx, host)
+		if err != nil {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+		}
+		for _, ip := range addrs {
+			var conn net.Conn
+			if conn, err = dial(ctx, network, net.JoinHostPort(ip, port)); err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
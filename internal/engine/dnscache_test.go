@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// countingResolver resolves every host to 127.0.0.1 and counts lookups.
+type countingResolver struct{ n atomic.Int64 }
+
+func (r *countingResolver) LookupHost(context.Context, string) ([]string, error) {
+	r.n.Add(1)
+	return []string{"127.0.0.1"}, nil
+}
+
+func TestDNSCache_LookupsFollowPolicy(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			c.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	addr := net.JoinHostPort("api.example.test", port)
+
+	const dials = 4
+	for _, tc := range []struct {
+		ttl  time.Duration
+		want int64
+	}{
+		{0, dials},
+		{time.Hour, 1},
+	} {
+		res := &countingResolver{}
+		var lookups atomic.Uint64
+		c := newDNSCache(tc.ttl, &lookups)
+		c.resolver = res
+		dial := c.dial((&net.Dialer{}).DialContext)
+		for range dials {
+			conn, err := dial(context.Background(), "tcp", addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+		}
+		if got := res.n.Load(); got != tc.want {
+			t.Errorf("ttl %s: %d lookups for %d dials, want %d", tc.ttl, got, dials, tc.want)
+		}
+		if lookups.Load() != uint64(tc.want) {
+			t.Errorf("ttl %s: counted %d lookups, want %d", tc.ttl, lookups.Load(), tc.want)
+		}
+		// IP addresses bypass the resolver.
+		if conn, err := dial(context.Background(), "tcp", ln.Addr().String()); err == nil {
+			conn.Close()
+		}
+		if got := res.n.Load(); got != tc.want {
+			t.Errorf("ttl %s: dialling an IP looked it up", tc.ttl)
+		}
+	}
+}
+
+type failingResolver struct{}
+
+func (failingResolver) LookupHost(context.Context, string) ([]string, error) {
+	return nil, errors.New("server misbehaving")
+}
+
+func TestDNSCache_FailureIsDNSError(t *testing.T) {
+	c := newDNSCache(0, nil)
+	c.resolver = failingResolver{}
+	_, err := c.dial((&net.Dialer{}).DialContext)(context.Background(), "tcp", "api.example.test:80")
+	if got := classifyError(err); got != stats.ErrDNS {
+		t.Errorf("classifyError(%v) = %q, want dns", err, got)
+	}
+}
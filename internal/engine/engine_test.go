@@ -1,8 +1,12 @@
 package engine
 
 import (
+	"net/http"
 	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/thetangentline/httpcl/internal/stats"
 )
@@ -10,7 +14,7 @@ import (
 // noopRender implements ui.Renderer for tests.
 type noopRender struct{}
 
-func (noopRender) Render(snap stats.Snapshot)   {}
+func (noopRender) Render(snap stats.Snapshot)      {}
 func (noopRender) RenderFinal(snap stats.Snapshot) {}
 
 func TestNewOrchestrator_AppliesDefaults(t *testing.T) {
@@ -21,8 +25,10 @@ func TestNewOrchestrator_AppliesDefaults(t *testing.T) {
 	if orch == nil {
 		t.Fatal("NewOrchestrator returned nil")
 	}
-	// Defaults are applied to a copy; we can't read private cfg.
-	// Just ensure no panic. Optionally run with very short duration and expect connection error or success.
+	got := orch.Config()
+	if got.Workers <= 0 || got.Connections != got.Workers*10 || got.Pipeline != 1 {
+		t.Errorf("defaults not applied: workers=%d connections=%d pipeline=%d", got.Workers, got.Connections, got.Pipeline)
+	}
 }
 
 func TestNewOrchestrator_ZeroWorkersUsesNumCPU(t *testing.T) {
@@ -38,8 +44,9 @@ func TestNewOrchestrator_ZeroWorkersUsesNumCPU(t *testing.T) {
 	if expect <= 0 {
 		expect = 1
 	}
-	// We cannot read orch.cfg from here; we only verify NewOrchestrator doesn't panic.
-	_ = expect
+	if got := orch.Config().Workers; got != expect {
+		t.Errorf("Workers: got %d, want NumCPU=%d", got, expect)
+	}
 }
 
 func TestNewOrchestrator_ZeroDurationUsesDefault(t *testing.T) {
@@ -51,7 +58,9 @@ func TestNewOrchestrator_ZeroDurationUsesDefault(t *testing.T) {
 	if orch == nil {
 		t.Fatal("NewOrchestrator returned nil")
 	}
-	_ = orch
+	if got := orch.Config().Duration; got != 10*time.Second {
+		t.Errorf("Duration: got %v, want 10s", got)
+	}
 }
 
 func TestConfig_DefaultMethod(t *testing.T) {
@@ -60,10 +69,40 @@ func TestConfig_DefaultMethod(t *testing.T) {
 	if orch == nil {
 		t.Fatal("NewOrchestrator returned nil")
 	}
+	if got := orch.Config().Method; got != "GET" {
+		t.Errorf("Method: got %q, want GET", got)
+	}
+}
+
+func TestOrchestrator_ConfigKeepsExplicitValues(t *testing.T) {
+	cfg := Config{
+		URL:         "http://a/",
+		Method:      "POST",
+		Workers:     3,
+		Connections: 7,
+		Pipeline:    2,
+		Duration:    time.Second,
+	}
+	got := NewOrchestrator(cfg, noopRender{}).Config()
+	if got.Method != "POST" || got.Workers != 3 || got.Connections != 7 || got.Pipeline != 2 || got.Duration != time.Second {
+		t.Errorf("explicit values were overridden: %+v", got)
+	}
+}
+
+func TestOrchestrator_ConfigItemsRedactSecrets(t *testing.T) {
+	cfg := Config{
+		URL:     "http://a/",
+		Headers: map[string][]string{"Authorization": {"Bearer secret"}, "X-Trace": {"1"}},
+	}
+	for _, it := range NewOrchestrator(cfg, noopRender{}).configItems() {
+		if strings.Contains(it.Value, "secret") {
+			t.Errorf("verbose config leaks a secret: %s = %s", it.Label, it.Value)
+		}
+	}
 }
 
 func TestNewHTTPClient_NoPanic(t *testing.T) {
-	client := newHTTPClient(10)
+	client := newHTTPClient(Config{Connections: 10}, nil)
 	if client == nil {
 		t.Fatal("newHTTPClient returned nil")
 	}
@@ -73,8 +112,27 @@ func TestNewHTTPClient_NoPanic(t *testing.T) {
 }
 
 func TestNewHTTPClient_ZeroTimeout(t *testing.T) {
-	client := newHTTPClient(5)
+	client := newHTTPClient(Config{Connections: 5}, nil)
 	if client.Timeout != 0 {
 		t.Errorf("expected Timeout 0 for benchmark client, got %v", client.Timeout)
 	}
 }
+
+func TestNewHTTPClient_BufferSizes(t *testing.T) {
+	tr := newHTTPClient(Config{Connections: 1}, nil).Transport.(*http.Transport)
+	if tr.ReadBufferSize != 0 || tr.WriteBufferSize != 0 {
+		t.Errorf("default buffers: read %d, write %d", tr.ReadBufferSize, tr.WriteBufferSize)
+	}
+	cfg := Config{Connections: 1, ReadBufferSize: 64 << 10, WriteBufferSize: 32 << 10}
+	tr = newHTTPClient(cfg, nil).Transport.(*http.Transport)
+	if tr.ReadBufferSize != 64<<10 || tr.WriteBufferSize != 32<<10 {
+		t.Errorf("buffers: read %d, write %d", tr.ReadBufferSize, tr.WriteBufferSize)
+	}
+	var labels []string
+	for _, it := range NewOrchestrator(cfg, noopRender{}).configItems() {
+		labels = append(labels, it.Label)
+	}
+	if !slices.Contains(labels, "read buffer") || !slices.Contains(labels, "write buffer") {
+		t.Errorf("verbose config lacks the buffer sizes: %v", labels)
+	}
+}
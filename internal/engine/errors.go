@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// classifyError maps a transport error to an error category.
+func classifyError(err error) stats.ErrorCategory {
+	if errors.Is(err, errRedirectLoop) || errors.Is(err, errTooManyRedirects) {
+		return stats.ErrRedirect
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return stats.ErrDNS
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, syscall.ETIMEDOUT) {
+		return stats.ErrTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return stats.ErrTimeout
+	}
+
+	var recordErr tls.RecordHeaderError
+	var certErr *tls.CertificateVerificationError
+	var alertErr tls.AlertError
+	var unknownAuth x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	if errors.As(err, &recordErr) || errors.As(err, &certErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &unknownAuth) || errors.As(err, &hostErr) || errors.As(err, &invalidCert) ||
+		strings.Contains(err.Error(), "tls: ") {
+		return stats.ErrTLS
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return stats.ErrConnect
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ENETUNREACH) {
+		return stats.ErrConnect
+	}
+
+	if h2ServerReset(err.Error()) {
+		return stats.ErrH2Reset
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return stats.ErrRead
+	}
+	if errors.As(err, &opErr) && (opErr.Op == "read" || opErr.Op == "write") {
+		return stats.ErrRead
+	}
+
+	msg := err.Error()
+	if strings.Contains(msg, "malformed HTTP") || strings.Contains(msg, "http2:") ||
+		strings.Contains(msg, "stream error") || strings.Contains(msg, "unsupported protocol") {
+		return stats.ErrProtocol
+	}
+	return stats.ErrOther
+}
+
+// h2ServerReset reports whether msg is how the HTTP/2 transport reports the
+// server turning a request away: a GOAWAY, or a RST_STREAM it received, e.g.
+// REFUSED_STREAM past its stream limit or ENHANCE_YOUR_CALM when overloaded.
+// The bundled HTTP/2 error types are unexported, so only the text is left.
+func h2ServerReset(msg string) bool {
+	if strings.Contains(msg, "GOAWAY") {
+		return true
+	}
+	return strings.Contains(msg, "stream error:") &&
+		(strings.Contains(msg, "received from peer") ||
+			strings.Contains(msg, "REFUSED_STREAM") || strings.Contains(msg, "ENHANCE_YOUR_CALM"))
+}
+
+// failure describes why a request is counted as an error: the transport error
+// if there was one, otherwise a 5xx or (with Count429Error) 429 status.
+func failure(err error, resp *http.Response) (stats.ErrorCategory, string) {
+	if err != nil {
+		return classifyError(err), err.Error()
+	}
+	if resp != nil && resp.StatusCode >= 500 {
+		return stats.ErrHTTP5xx, fmt.Sprintf("HTTP %s", resp.Status)
+	}
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		return stats.ErrHTTP429, fmt.Sprintf("HTTP %s", resp.Status)
+	}
+	return stats.ErrOther, ""
+}
+
+// RunError is returned by Run when the run completed but was unhealthy: its
+// error rate exceeded Config.MaxErrorRate. It carries the breakdown so callers
+// can branch on the dominant failure mode.
+type RunError struct {
+	Requests   uint64
+	Errors     uint64
+	ByCategory map[stats.ErrorCategory]uint64
+	MaxRate    float64 // the threshold that was exceeded
+}
+
+// ErrorRate is the fraction of requests that failed.
+func (e *RunError) ErrorRate() float64 {
+	if e.Requests == 0 {
+		return 0
+	}
+	return float64(e.Errors) / float64(e.Requests)
+}
+
+// Dominant returns the most frequent error category and its share of all
+// requests. Ties go to the category listed first by stats.ErrorCategories.
+func (e *RunError) Dominant() (stats.ErrorCategory, float64) {
+	var top stats.ErrorCategory
+	var n uint64
+	for _, cat := range stats.ErrorCategories() {
+		if c := e.ByCategory[cat]; c > n {
+			top, n = cat, c
+		}
+	}
+	if e.Requests == 0 {
+		return top, 0
+	}
+	return top, float64(n) / float64(e.Requests)
+}
+
+func (e *RunError) Error() string {
+	cat, share := e.Dominant()
+	var parts []string
+	for _, c := range stats.ErrorCategories() {
+		if n := e.ByCategory[c]; n > 0 && c != cat {
+			parts = append(parts, fmt.Sprintf("%s=%d", c, n))
+		}
+	}
+	msg := fmt.Sprintf("run unhealthy: %.1f%% of %d requests failed (max %.1f%%); mostly %s (%.1f%%)",
+		e.ErrorRate()*100, e.Requests, e.MaxRate*100, cat, share*100)
+	if len(parts) > 0 {
+		msg += ", also " + strings.Join(parts, " ")
+	}
+	return msg
+}
+
+// checkHealth returns a *RunError if snap's error rate exceeds maxRate. A
+// non-positive maxRate disables the check.
+func checkHealth(snap stats.Snapshot, maxRate float64) error {
+	if maxRate <= 0 || snap.TotalRequests == 0 {
+		return nil
+	}
+	if float64(snap.Errors)/float64(snap.TotalRequests) <= maxRate {
+		return nil
+	}
+	return &RunError{
+		Requests:   snap.TotalRequests,
+		Errors:     snap.Errors,
+		ByCategory: maps.Clone(snap.ErrorsByCategory),
+		MaxRate:    maxRate,
+	}
+}
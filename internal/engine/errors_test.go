@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+func TestClassifyError(t *testing.T) {
+	wrap := func(err error) error { return &url.Error{Op: "Get", URL: "http://x/", Err: err} }
+	cases := []struct {
+		name string
+		err  error
+		want stats.ErrorCategory
+	}{
+		{"dns", wrap(&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "x"}}), stats.ErrDNS},
+		{"refused", wrap(&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}), stats.ErrConnect},
+		{"deadline", wrap(context.DeadlineExceeded), stats.ErrTimeout},
+		{"dial timeout", wrap(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ETIMEDOUT)}), stats.ErrTimeout},
+		{"tls record", wrap(tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}), stats.ErrTLS},
+		{"eof", wrap(io.EOF), stats.ErrRead},
+		{"reset", wrap(&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}), stats.ErrRead},
+		{"goaway", wrap(errors.New(`http2: server sent GOAWAY and closed the connection; LastStreamID=5, ErrCode=ENHANCE_YOUR_CALM, debug=""`)), stats.ErrH2Reset},
+		{"rst refused", wrap(errors.New("stream error: stream ID 7; REFUSED_STREAM; received from peer")), stats.ErrH2Reset},
+		{"h2 local protocol", wrap(errors.New("stream error: stream ID 3; PROTOCOL_ERROR; invalid header field value")), stats.ErrProtocol},
+		{"malformed", wrap(errors.New(`net/http: HTTP/1.x transport connection broken: malformed HTTP response "junk"`)), stats.ErrProtocol},
+		{"redirect loop", wrap(fmt.Errorf("%w: http://x/a redirects back to http://x/", errRedirectLoop)), stats.ErrRedirect},
+		{"too many redirects", wrap(fmt.Errorf("%w: stopped after 10", errTooManyRedirects)), stats.ErrRedirect},
+		{"other", wrap(fmt.Errorf("something odd")), stats.ErrOther},
+	}
+	for _, tc := range cases {
+		if got := classifyError(tc.err); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFailure_5xx(t *testing.T) {
+	cat, msg := failure(nil, &http.Response{StatusCode: 503, Status: "503 Service Unavailable"})
+	if cat != stats.ErrHTTP5xx || msg != "HTTP 503 Service Unavailable" {
+		t.Errorf("got %q %q", cat, msg)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	snap := stats.Snapshot{
+		TotalRequests:    100,
+		Errors:           92,
+		ErrorsByCategory: map[stats.ErrorCategory]uint64{stats.ErrConnect: 90, stats.ErrTimeout: 2},
+	}
+	if err := checkHealth(snap, 0); err != nil {
+		t.Errorf("a zero threshold disables the check: %v", err)
+	}
+	if err := checkHealth(snap, 0.95); err != nil {
+		t.Errorf("92%% is within a 95%% threshold: %v", err)
+	}
+
+	err := checkHealth(snap, 0.05)
+	var runErr *RunError
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected *RunError, got %T %v", err, err)
+	}
+	if cat, share := runErr.Dominant(); cat != stats.ErrConnect || share != 0.9 {
+		t.Errorf("Dominant: got %s %.2f", cat, share)
+	}
+	if runErr.ErrorRate() != 0.92 {
+		t.Errorf("ErrorRate: got %v", runErr.ErrorRate())
+	}
+	want := "run unhealthy: 92.0% of 100 requests failed (max 5.0%); mostly connect (90.0%), also timeout=2"
+	if runErr.Error() != want {
+		t.Errorf("Error():\n got %q\nwant %q", runErr.Error(), want)
+	}
+}
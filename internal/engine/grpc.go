@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"encoding/binary"
+	"net/http"
+	"net/url"
+)
+
+// grpcContentType is sent on every gRPC request unless a header overrides it
+// (e.g. application/grpc+proto).
+const grpcContentType = "application/grpc"
+
+// grpcFrame wraps msg in the gRPC length-prefixed message framing: a zero
+// compressed flag, a big-endian uint32 length, then the message itself.
+func grpcFrame(msg []byte) []byte {
+	framed := make([]byte, 5+len(msg))
+	binary.BigEndian.PutUint32(framed[1:5], uint32(len(msg)))
+	copy(framed[5:], msg)
+	return framed
+}
+
+// grpcFailure inspects a fully read gRPC response and returns a description
+// of the failure, or "" if the call succeeded. The status normally arrives in
+// the trailers; a trailers-only response carries it in the headers.
+func grpcFailure(resp *http.Response) string {
+	status := resp.Trailer.Get("Grpc-Status")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+	}
+	switch status {
+	case "0":
+		return ""
+	case "":
+		return "response has no grpc-status"
+	}
+	msg := resp.Trailer.Get("Grpc-Message")
+	if msg == "" {
+		msg = resp.Header.Get("Grpc-Message")
+	}
+	// grpc-message is percent-encoded on the wire.
+	if decoded, err := url.PathUnescape(msg); err == nil {
+		msg = decoded
+	}
+	if msg == "" {
+		return "grpc-status " + status
+	}
+	return "grpc-status " + status + ": " + msg
+}
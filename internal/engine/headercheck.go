@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// HeaderMatch matches a response header by name and, if Value is set, by an
+// exact value among the header's values.
+type HeaderMatch struct {
+	Name  string
+	Value string
+}
+
+func (m HeaderMatch) String() string {
+	if m.Value == "" {
+		return m.Name
+	}
+	return m.Name + ": " + m.Value
+}
+
+// matches reports whether h has the header, with the value if one is set.
+func (m HeaderMatch) matches(h http.Header) bool {
+	values := h.Values(m.Name)
+	if m.Value == "" {
+		return len(values) > 0
+	}
+	for _, v := range values {
+		if v == m.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// headerFailure checks response headers against --expect-header and
+// --reject-header and describes the first violation, or returns "".
+func headerFailure(h http.Header, expect, reject []HeaderMatch) string {
+	return matchFailure("header", h, expect, reject)
+}
+
+// trailerFailure is headerFailure for response trailers (--expect-trailer,
+// --reject-trailer). They are only complete once the body was read to EOF.
+func trailerFailure(h http.Header, expect, reject []HeaderMatch) string {
+	return matchFailure("trailer", h, expect, reject)
+}
+
+func matchFailure(kind string, h http.Header, expect, reject []HeaderMatch) string {
+	for _, m := range expect {
+		if !m.matches(h) {
+			return fmt.Sprintf("missing expected %s %q", kind, m.String())
+		}
+	}
+	for _, m := range reject {
+		if m.matches(h) {
+			return fmt.Sprintf("rejected %s %q present", kind, m.String())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,247 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxAssertBody is how much of each response body is kept for AssertJSON. A
+// path that lies beyond it counts as missing.
+const maxAssertBody = 1 << 20
+
+// JSONAssert checks one value in a JSON response body (--assert-json). The
+// path is a small JSONPath subset: $ followed by .name, ["name"] and [index]
+// steps. Without a Want value the check only needs the path to exist.
+type JSONAssert struct {
+	Text    string // as given, for messages
+	path    []jsonStep
+	Want    any
+	HasWant bool
+}
+
+// jsonStep is one path step: an object key, or an array index when key is
+// empty.
+type jsonStep struct {
+	key   string
+	index int
+}
+
+// ParseJSONAssert parses "$.path" (exists) or "$.path==value". The value is
+// read as JSON when it parses (1, true, null, "ok", {...}) and as a bare
+// string otherwise, so $.status==ok and $.status=="ok" are the same check.
+func ParseJSONAssert(s string) (JSONAssert, error) {
+	a := JSONAssert{Text: s}
+	path, want, hasWant := strings.Cut(s, "==")
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return a, fmt.Errorf("--assert-json %q: path must start with $", s)
+	}
+	steps, err := parseJSONPath(path[1:])
+	if err != nil {
+		return a, fmt.Errorf("--assert-json %q: %w", s, err)
+	}
+	a.path = steps
+	if hasWant {
+		want = strings.TrimSpace(want)
+		a.HasWant = true
+		if err := json.Unmarshal([]byte(want), &a.Want); err != nil {
+			a.Want = want
+		}
+	}
+	return a, nil
+}
+
+// MarshalJSON saves the assertion as its text, e.g. in a manifest.
+func (a JSONAssert) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Text)
+}
+
+// UnmarshalJSON parses an assertion saved by MarshalJSON.
+func (a *JSONAssert) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseJSONAssert(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+func parseJSONPath(p string) ([]jsonStep, error) {
+	var steps []jsonStep
+	for p != "" {
+		switch p[0] {
+		case '.':
+			end := strings.IndexAny(p[1:], ".[")
+			if end < 0 {
+				end = len(p) - 1
+			}
+			key := p[1 : 1+end]
+			if key == "" {
+				return nil, errors.New("empty key after .")
+			}
+			steps = append(steps, jsonStep{key: key})
+			p = p[1+end:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, errors.New("unclosed [")
+			}
+			inner := p[1:end]
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, jsonStep{key: inner[1 : len(inner)-1]})
+			} else if i, err := strconv.Atoi(inner); err == nil && i >= 0 {
+				steps = append(steps, jsonStep{index: i})
+			} else {
+				return nil, fmt.Errorf("bad index [%s]", inner)
+			}
+			p = p[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q", p)
+		}
+	}
+	return steps, nil
+}
+
+// check runs the assertion against body and describes the failure, or
+// returns "". Only as much of the body as the path needs is parsed.
+func (a JSONAssert) check(body []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for _, step := range a.path {
+		found, err := enterStep(dec, step)
+		if err != nil {
+			return a.failure("body is not valid JSON", body)
+		}
+		if !found {
+			return a.failure("path not found", body)
+		}
+	}
+	var got any
+	if err := dec.Decode(&got); err != nil {
+		return a.failure("body is not valid JSON", body)
+	}
+	if !a.HasWant || reflect.DeepEqual(got, a.Want) {
+		return ""
+	}
+	b, _ := json.Marshal(got)
+	return a.failure("got "+string(b), body)
+}
+
+func (a JSONAssert) failure(why string, body []byte) string {
+	return fmt.Sprintf("--assert-json %s: %s in body %s", a.Text, why, snippet(body))
+}
+
+// snippet shortens a body for an error message.
+func snippet(body []byte) string {
+	const max = 80
+	if len(body) > max {
+		return strconv.Quote(string(body[:max])) + "..."
+	}
+	return strconv.Quote(string(body))
+}
+
+// enterStep moves dec to the value selected by step within the next value,
+// skipping whatever comes before it.
+func enterStep(dec *json.Decoder, step jsonStep) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+	want := json.Delim('[')
+	if step.key != "" {
+		want = '{'
+	}
+	if tok != want {
+		if d, ok := tok.(json.Delim); ok {
+			// Not the container the step needs; still check the rest parses.
+			return false, skipRest(dec, d)
+		}
+		return false, nil
+	}
+	for i := 0; dec.More(); i++ {
+		if step.key != "" {
+			key, err := dec.Token()
+			if err != nil {
+				return false, err
+			}
+			if key == step.key {
+				return true, nil
+			}
+		} else if i == step.index {
+			return true, nil
+		}
+		if err := skipValue(dec); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// skipValue reads past the next value.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// skipRest reads past the rest of a container whose opening delimiter was
+// already read.
+func skipRest(dec *json.Decoder, open json.Delim) error {
+	for dec.More() {
+		if open == '{' {
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+		}
+		if err := skipValue(dec); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// jsonFailure runs every assertion against body and describes the first
+// that fails, or returns "".
+func jsonFailure(body []byte, asserts []JSONAssert) string {
+	for _, a := range asserts {
+		if msg := a.check(body); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// cappedBuffer keeps the first maxAssertBody bytes written to it and drops
+// the rest, so huge bodies still drain without being held in memory.
+type cappedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := maxAssertBody - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
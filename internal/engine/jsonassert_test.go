@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONAssert(t *testing.T) {
+	body := []byte(`{"meta":{"v":2},"status":"ok","items":[{"id":1},{"id":7,"tags":["a"]}],"odd key":null}`)
+	pass := []string{
+		"$.status==ok",
+		`$.status=="ok"`,
+		"$.meta.v==2",
+		"$.items[1].id==7",
+		`$.items[1]["tags"][0]==a`,
+		`$['odd key']==null`,
+		"$.items[0]",
+		`$.meta=={"v":2}`,
+	}
+	for _, s := range pass {
+		a, err := ParseJSONAssert(s)
+		if err != nil {
+			t.Fatalf("ParseJSONAssert(%q): %v", s, err)
+		}
+		if msg := a.check(body); msg != "" {
+			t.Errorf("%s: %s", s, msg)
+		}
+	}
+
+	fail := map[string]string{
+		"$.status==error":  `got "ok"`,
+		"$.meta.v==3":      "got 2",
+		"$.items[2]":       "path not found",
+		"$.missing":        "path not found",
+		"$.status.x":       "path not found",
+		"$.items.id":       "path not found",
+		"$.meta[0]":        "path not found",
+		"$.items[0].id==2": "got 1",
+	}
+	for s, want := range fail {
+		a, err := ParseJSONAssert(s)
+		if err != nil {
+			t.Fatalf("ParseJSONAssert(%q): %v", s, err)
+		}
+		if msg := a.check(body); !strings.Contains(msg, want) {
+			t.Errorf("%s: message %q, want %q", s, msg, want)
+		}
+	}
+
+	a, _ := ParseJSONAssert("$.status")
+	if msg := a.check([]byte(`{"status": `)); !strings.Contains(msg, "not valid JSON") {
+		t.Errorf("truncated body: %q", msg)
+	}
+
+	for _, bad := range []string{"status==ok", "$.", "$[x]", "$[1", "$..a", "$ foo"} {
+		if _, err := ParseJSONAssert(bad); err == nil {
+			t.Errorf("ParseJSONAssert(%q) accepted", bad)
+		}
+	}
+}
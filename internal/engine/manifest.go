@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// ManifestVersion is bumped when a manifest written by an older httpcl can
+// no longer be read back into the same Config.
+const ManifestVersion = 1
+
+// Manifest records a run so it can be repeated: the effective Config,
+// with defaults resolved and the seed that drove every random choice, the
+// build that ran it and its final snapshot. Bodies and headers are kept as
+// sent; signing credentials, signals and callbacks are not (see Config).
+type Manifest struct {
+	ManifestVersion int            `json:"manifest_version"`
+	Build           string         `json:"build"`
+	StartedAt       time.Time      `json:"started_at"`
+	Seed            uint64         `json:"seed"`
+	Config          Config         `json:"config"`
+	Result          stats.Snapshot `json:"result"`
+}
+
+// NewManifest describes the run o has finished.
+func NewManifest(o *Orchestrator, build string, startedAt time.Time) Manifest {
+	return Manifest{
+		ManifestVersion: ManifestVersion,
+		Build:           build,
+		StartedAt:       startedAt,
+		Seed:            o.cfg.Seed,
+		Config:          o.cfg,
+		Result:          o.final,
+	}
+}
+
+// WriteManifest writes m as indented JSON.
+func WriteManifest(w io.Writer, m Manifest) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// ReadManifest reads a manifest written by WriteManifest and returns the
+// config to repeat its run with, seed included.
+func ReadManifest(r io.Reader) (Manifest, error) {
+	var m Manifest
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return Manifest{}, fmt.Errorf("manifest: %w", err)
+	}
+	if m.ManifestVersion != ManifestVersion {
+		return Manifest{}, fmt.Errorf("manifest: version %d is not supported (want %d)", m.ManifestVersion, ManifestVersion)
+	}
+	m.Config.Seed = m.Seed
+	return m, nil
+}
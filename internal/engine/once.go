@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// OnceResult is the outcome of Orchestrator.Once.
+type OnceResult struct {
+	Proto   string // e.g. "HTTP/1.1"; empty if no response arrived
+	Status  string // e.g. "200 OK"
+	Latency time.Duration
+	Bytes   uint64 // response body bytes
+	// Success uses the load loop's rules: a 2xx-4xx status that passes the
+	// configured header, trailer, gRPC and body hash checks.
+	Success       bool
+	ErrorCategory stats.ErrorCategory
+	ErrorMessage  string
+}
+
+// Once sends a single request built from the run's config, with no load
+// phase, renderer or stats, for a quick "is it up?" check. Only a request
+// that cannot be built is returned as an error; a failed request is
+// reported in the result.
+func (o *Orchestrator) Once(ctx context.Context) (OnceResult, error) {
+	if o.cfg.URL == "" {
+		return OnceResult{}, fmt.Errorf("url is required")
+	}
+	if len(o.cfg.Transaction) > 0 {
+		return OnceResult{}, fmt.Errorf("a single request cannot run a transaction")
+	}
+	client := newHTTPClient(o.cfg, nil)
+	defer client.CloseIdleConnections()
+	req, _, err := newRequestBuilder(o.cfg).build(ctx, newSlotRand(o.cfg.Seed, 0))
+	if err != nil {
+		return OnceResult{}, err
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	var res OnceResult
+	var sum []byte
+	var body cappedBuffer
+	var decodeErr error
+	if resp != nil {
+		var src io.Reader = resp.Body
+		if o.cfg.Decode {
+			if dec, _, err := newBodyDecoder().decode(resp); err != nil {
+				decodeErr = err
+			} else {
+				src = dec
+			}
+		}
+		h := sha256.New()
+		n, readErr, corrupt := drainBody(io.MultiWriter(h, &body), src, resp.Body)
+		_ = resp.Body.Close()
+		if corrupt != nil {
+			decodeErr = corrupt
+		}
+		if readErr != nil && err == nil {
+			err = readErr
+		}
+		res.Proto, res.Status, res.Bytes, sum = resp.Proto, resp.Status, uint64(n), h.Sum(nil)
+	}
+	res.Latency = time.Since(start)
+
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode >= 500 {
+		res.ErrorCategory, res.ErrorMessage = failure(err, resp)
+	} else if msg := grpcFailure(resp); o.cfg.GRPC && msg != "" {
+		res.ErrorCategory, res.ErrorMessage = stats.ErrGRPC, msg
+	} else if msg := headerFailure(resp.Header, o.cfg.ExpectHeaders, o.cfg.RejectHeaders); msg != "" {
+		res.ErrorCategory, res.ErrorMessage = stats.ErrHeader, msg
+	} else if msg := trailerFailure(resp.Trailer, o.cfg.ExpectTrailers, o.cfg.RejectTrailers); msg != "" {
+		res.ErrorCategory, res.ErrorMessage = stats.ErrHeader, msg
+	} else if decodeErr != nil {
+		res.ErrorCategory, res.ErrorMessage = stats.ErrValidation, decodeErr.Error()
+	} else if len(o.cfg.ExpectSHA256) > 0 && !bytes.Equal(sum, o.cfg.ExpectSHA256) {
+		res.ErrorCategory, res.ErrorMessage = stats.ErrValidation, "body SHA-256 mismatch: got "+hex.EncodeToString(sum)
+	} else if msg := jsonFailure(body.Bytes(), o.cfg.AssertJSON); msg != "" {
+		res.ErrorCategory, res.ErrorMessage = stats.ErrValidation, msg
+	}
+	res.Success = res.ErrorMessage == "" && res.ErrorCategory == ""
+	return res, nil
+}
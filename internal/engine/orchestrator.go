@@ -2,11 +2,21 @@ package engine
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -15,10 +25,32 @@ import (
 	"github.com/thetangentline/httpcl/pkg/netutil"
 )
 
+// defaultDrainTimeout bounds how long in-flight requests may run past the
+// duration before they are abandoned.
+const defaultDrainTimeout = 5 * time.Second
+
+// errDrainTimeout is the cancellation cause for requests abandoned after the
+// drain timeout; workers use it to tell them apart from a user interrupt.
+var errDrainTimeout = errors.New("drain timeout exceeded")
+
 // Orchestrator coordinates workers, stats collection and UI rendering.
 type Orchestrator struct {
 	cfg      Config
 	renderer ui.Renderer
+
+	// Populated by Run so callers can export results after it returns.
+	collector     *stats.Collector
+	final         stats.Snapshot
+	stopReason    string
+	sustainedRate float64
+	// calibratedMax is the throughput Config.Calibrate measured and
+	// calibratedRate the rate derived from it.
+	calibratedMax  float64
+	calibratedRate float64
+	resolved       []string               // target addresses found by the DNS preflight
+	preflight      []stats.PreflightCheck // checks made before the run, for the final snapshot
+	sustainedRPS   float64                // request rate after the --min-rps warm-up
+	rpsMeasured    bool
 }
 
 // NewOrchestrator constructs a new Orchestrator.
@@ -33,12 +65,76 @@ func NewOrchestrator(cfg Config, renderer ui.Renderer) *Orchestrator {
 	if cfg.Pipeline <= 0 {
 		cfg.Pipeline = 1
 	}
+	if cfg.URL == "" && len(cfg.Targets) > 0 {
+		cfg.URL = cfg.Targets[0].URL
+	}
 	if cfg.Method == "" {
 		cfg.Method = "GET"
+		if cfg.GRPC {
+			cfg.Method = "POST"
+		}
 	}
 	if cfg.Duration <= 0 {
 		cfg.Duration = 10 * time.Second
 	}
+	if cfg.Log == nil {
+		cfg.Log = os.Stdout
+	}
+	if cfg.AdaptiveRate && cfg.AdaptiveInterval <= 0 {
+		cfg.AdaptiveInterval = time.Second
+	}
+	if len(cfg.ConcurrencySteps) > 0 {
+		cfg.Duration = 0
+		most := 0
+		for _, s := range cfg.ConcurrencySteps {
+			cfg.Duration += s.Duration
+			most = max(most, s.Concurrency)
+		}
+		if slots := cfg.Workers * cfg.Pipeline; slots < most {
+			cfg.Pipeline = (most + cfg.Workers - 1) / cfg.Workers
+		}
+	}
+	if cfg.BackoffOnErrors && cfg.BackoffInterval <= 0 {
+		cfg.BackoffInterval = time.Second
+	}
+	if cfg.Burst > 0 {
+		if cfg.BurstInterval <= 0 {
+			cfg.BurstInterval = time.Second
+		}
+		// A burst needs one slot per request to be released at once.
+		if slots := cfg.Workers * cfg.Pipeline; slots < cfg.Burst {
+			cfg.Pipeline = (cfg.Burst + cfg.Workers - 1) / cfg.Workers
+		}
+	}
+	if cfg.Retries > 0 {
+		if cfg.RetryDelay <= 0 {
+			cfg.RetryDelay = defaultRetryDelay
+		}
+		if cfg.RetryBackoff == "" {
+			cfg.RetryBackoff = BackoffConstant
+		}
+	}
+	if cfg.Count429 == "" {
+		cfg.Count429 = Count429Separate
+	}
+	if cfg.MinRPS > 0 && cfg.MinRPSWarmup <= 0 {
+		cfg.MinRPSWarmup = defaultMinRPSWarmup
+	}
+	if cfg.CapConnections && cfg.PoolWaitWarn <= 0 {
+		cfg.PoolWaitWarn = defaultPoolWaitWarn
+	}
+	if cfg.DrainTimeout == 0 {
+		cfg.DrainTimeout = defaultDrainTimeout
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = newSeed()
+	}
+	if cfg.StopSignals == nil {
+		cfg.StopSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	if cfg.StatusSignals == nil {
+		cfg.StatusSignals = []os.Signal{syscall.SIGQUIT}
+	}
 
 	return &Orchestrator{
 		cfg:      cfg,
@@ -46,68 +142,318 @@ func NewOrchestrator(cfg Config, renderer ui.Renderer) *Orchestrator {
 	}
 }
 
-// Run executes a full benchmark session.
+// Run executes a full benchmark session. It returns an error if the run could
+// not start. A run that completed but missed a threshold returns a *RunError
+// (more than cfg.MaxErrorRate of requests failed) and/or a *ThroughputError
+// (sustained rate under cfg.MinRPS), and with cfg.Strict a
+// *PoolExhaustedError (see cfg.PoolWaitWarn), joined if several apply.
 func (o *Orchestrator) Run() error {
-	if o.cfg.URL == "" {
+	return o.RunContext(context.Background())
+}
+
+// RunContext is Run under ctx. Cancelling ctx (or reaching its deadline)
+// stops the run like a stop signal: in-flight requests are cancelled and
+// the results so far are reported.
+func (o *Orchestrator) RunContext(parent context.Context) error {
+	if o.cfg.URL == "" && o.cfg.SimulateLatency == nil {
 		return fmt.Errorf("url is required")
 	}
-
-	// Basic DNS preflight.
-	if err := netutil.PreflightDNS(o.cfg.URL); err != nil {
+	if len(o.cfg.Replay) > 0 && len(o.cfg.Transaction) > 0 {
+		return fmt.Errorf("replay and transaction cannot be combined")
+	}
+	if o.cfg.ReplaySpeed < 0 {
+		return fmt.Errorf("replay speed must not be negative")
+	}
+	if o.cfg.ReplaySpeed > 0 && len(o.cfg.Replay) == 0 {
+		return fmt.Errorf("replay speed requires replay requests")
+	}
+	if o.cfg.ReplaySpeed > 0 && (o.cfg.Rate > 0 || o.cfg.RateFraction > 0 || o.cfg.AdaptiveRate || o.cfg.Burst > 0) {
+		return fmt.Errorf("replay speed cannot be combined with rate, adaptive rate or burst")
+	}
+	if len(o.cfg.Targets) > 0 && (len(o.cfg.Replay) > 0 || len(o.cfg.Transaction) > 0) {
+		return fmt.Errorf("targets cannot be combined with replay or transaction")
+	}
+	if o.cfg.ShardKey != "" && len(o.cfg.Targets) == 0 {
+		return fmt.Errorf("shard key requires targets")
+	}
+	if err := validateTargets(o.cfg.Targets); err != nil {
+		return err
+	}
+	if err := validateSteps(o.cfg.ConcurrencySteps); err != nil {
+		return err
+	}
+	if len(o.cfg.ConcurrencySteps) > 0 && o.cfg.BackoffOnErrors {
+		return fmt.Errorf("concurrency steps cannot be combined with backoff on errors")
+	}
+	switch o.cfg.HTTPVersion {
+	case "", "1.1", "2":
+	default:
+		return fmt.Errorf("http version must be 1.1 or 2, got %q", o.cfg.HTTPVersion)
+	}
+	switch o.cfg.Count429 {
+	case Count429Separate, Count429Success, Count429Error:
+	default:
+		return fmt.Errorf("count 429 must be %s, %s or %s, got %q", Count429Separate, Count429Success, Count429Error, o.cfg.Count429)
+	}
+	if o.cfg.RateFraction != 0 && (o.cfg.RateFraction < 0 || o.cfg.RateFraction > 1 || o.cfg.Calibrate <= 0) {
+		return fmt.Errorf("rate fraction must be in (0, 1] and requires calibration")
+	}
+	if err := parent.Err(); err != nil {
 		return err
 	}
-	fmt.Println()
-	ui.PrintStepResult("DNS", "OK", true)
+	var statsd *statsdSender
+	if o.cfg.StatsD != nil {
+		var err error
+		if statsd, err = newStatsDSender(o.cfg.StatsD); err != nil {
+			return err
+		}
+		defer statsd.close()
+	}
+	runStart := time.Now()
+
+	// Basic DNS preflight. A failed lookup is only a warning when the address
+	// does not come from DNS anyway (pinned target, proxy) or the user opted out.
+	// A simulated run never touches the network.
+	if o.cfg.SimulateLatency != nil {
+		fmt.Fprintf(os.Stderr, "warning: simulating %s latencies; no requests are sent\n", o.cfg.SimulateLatency)
+		fmt.Fprintln(o.cfg.Log)
+		o.preflightStep("DNS", stats.PreflightSkipped, "skipped (simulated)")
+	} else if addrs, err := netutil.PreflightDNS(o.cfg.URL); err != nil {
+		reason := o.dnsSkipReason()
+		if reason == "" || !errors.Is(err, netutil.ErrDNSResolution) {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		fmt.Fprintln(o.cfg.Log)
+		o.preflightStep("DNS", stats.PreflightSkipped, "skipped ("+reason+")")
+	} else {
+		o.resolved = addrs
+		status := "OK"
+		if o.cfg.ShowAddrs {
+			status += " (" + strings.Join(addrs, ", ") + ")"
+		}
+		fmt.Fprintln(o.cfg.Log)
+		o.preflightStep("DNS", stats.PreflightOK, status)
+	}
+
+	// TLS preflight: flag a certificate that expired or is about to. A
+	// connection failure is left for the run to report.
+	if o.cfg.SimulateLatency == nil && o.cfg.CertExpiryWarn > 0 {
+		if leaf, err := peerCertificate(parent, o.cfg, o.cfg.URL); err != nil {
+			o.preflightStep("TLS", stats.PreflightWarning, "not checked ("+err.Error()+")")
+		} else if leaf != nil {
+			status, ok, failed := certStatus(leaf, time.Now(), o.cfg.CertExpiryWarn, o.cfg.Insecure)
+			switch {
+			case failed:
+				o.preflightStep("TLS", stats.PreflightFailed, status)
+			case ok:
+				o.preflightStep("TLS", stats.PreflightOK, status)
+			default:
+				o.preflightStep("TLS", stats.PreflightWarning, status)
+			}
+		}
+	}
 
 	// Basic ulimit warning (best-effort, *nix only).
 	if err := netutil.CheckUlimitWarning(o.cfg.Connections); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
-		ui.PrintStepResult("Ulimit", "warning", false)
+		ui.PrintStepResult(o.cfg.Log, "Ulimit", "warning", false)
+		o.preflight = append(o.preflight, stats.PreflightCheck{Name: "Ulimit", Status: stats.PreflightWarning, Detail: err.Error()})
 	}
 
+	target := o.cfg.URL
+	if target == "" {
+		target = "(simulated)"
+	}
 	ui.PrintRunHeader(
-		o.cfg.URL,
+		o.cfg.Log,
+		target,
+		o.cfg.RunID,
 		o.cfg.Workers,
 		o.cfg.Connections,
 		o.cfg.Pipeline,
 		o.cfg.Duration.String(),
 	)
+	if o.cfg.Verbose {
+		ui.PrintResolvedConfig(o.cfg.Log, o.configItems())
+	}
 
-	// Context cancelled only on SIGINT so in-flight requests can complete when duration ends.
-	ctx, cancel := context.WithCancel(context.Background())
+	// Calibration runs before the load phase and is not part of its stats.
+	var calibration time.Duration
+	if o.cfg.Calibrate > 0 {
+		calibStart := time.Now()
+		maxRPS, err := calibrate(parent, o.cfg, o.cfg.Calibrate)
+		if err != nil {
+			return err
+		}
+		calibration = time.Since(calibStart)
+		o.calibratedMax = maxRPS
+		status := fmt.Sprintf("max %.1f req/s", maxRPS)
+		if o.cfg.RateFraction > 0 {
+			o.calibratedRate = maxRPS * o.cfg.RateFraction
+			o.cfg.Rate = o.calibratedRate
+			status += fmt.Sprintf(", pacing at %.1f req/s (%.0f%%)", o.calibratedRate, o.cfg.RateFraction*100)
+		}
+		ui.PrintStepResult(o.cfg.Log, "Calibrate", status, true)
+	}
+
+	// Context cancelled only on a stop signal (or by the parent) so in-flight requests can complete
+	// when duration ends, or with errDrainTimeout when they take longer than the drain timeout to do so.
+	ctx, cancelCause := context.WithCancelCause(parent)
+	cancel := func() { cancelCause(nil) }
 	defer cancel()
 
 	// After duration, close this so workers stop starting new requests but finish in-flight ones.
+	// stopEarly closes it ahead of time when a run budget is exhausted.
+	// The load phase runs from here until durationDone closes; the drain
+	// phase from then until the last worker returns.
 	durationDone := make(chan struct{})
-	time.AfterFunc(o.cfg.Duration, func() { close(durationDone) })
+	var stopOnce sync.Once
+	var loadEnd time.Time
+	stopEarly := func(reason string) {
+		stopOnce.Do(func() {
+			loadEnd = time.Now()
+			if reason != "" {
+				o.stopReason = reason
+			}
+			close(durationDone)
+		})
+	}
+	loadStart := time.Now()
+	durationTimer := time.AfterFunc(o.cfg.Duration, func() { stopEarly("") })
+	defer durationTimer.Stop()
 
-	// Trap SIGINT for graceful shutdown.
+	// Trap stop signals for graceful shutdown and status signals for a live
+	// snapshot. Notify with no signals would relay all of them, so skip it.
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	if handled := slices.Concat(o.cfg.StopSignals, o.cfg.StatusSignals); len(handled) > 0 {
+		signal.Notify(sigCh, handled...)
+	}
 	defer signal.Stop(sigCh)
+	// statusReq asks the renderer goroutine for a status snapshot, so status
+	// output never races with the live HUD.
+	statusReq := make(chan struct{}, 1)
 
-	collector := stats.NewCollector()
-	client := newHTTPClient(o.cfg.Connections)
+	collectorOpts := []stats.Option{stats.WithMemoryBudget(o.cfg.StatsMemory)}
+	if o.cfg.CapConnections {
+		collectorOpts = append(collectorOpts, stats.WithConnWait())
+	}
+	if o.cfg.ConnBench {
+		collectorOpts = append(collectorOpts, stats.WithHandshakes())
+	}
+	if o.cfg.PerConn {
+		collectorOpts = append(collectorOpts, stats.WithPerConn())
+	}
+	if o.cfg.ShowAddrs {
+		collectorOpts = append(collectorOpts, stats.WithRemoteAddrs())
+	}
+	if len(o.cfg.Transaction) > 0 {
+		collectorOpts = append(collectorOpts, stats.WithSteps(stepNames(o.cfg.Transaction)))
+	}
+	if len(o.cfg.Targets) > 0 {
+		collectorOpts = append(collectorOpts, stats.WithTargets(targetURLs(o.cfg.Targets)))
+	}
+	collector := stats.NewCollector(collectorOpts...)
+	o.collector = collector
+	var counts connCounts
+	client := newHTTPClient(o.cfg, &counts)
+	reqs := newRequestBuilder(o.cfg)
+	sched := newScheduler(ctx, durationDone, o.cfg)
+	// With controls, slots go through the pause gate; sched stays the
+	// scheduler itself for the adaptive summary.
+	slotSched := sched
+	var gate *pauseGate
+	if o.cfg.Controls != nil {
+		gate = &pauseGate{}
+		slotSched = pausableScheduler{gate: gate, inner: sched}
+		go followControls(ctx, durationDone, o.cfg.Controls, gate, stopEarly)
+	}
+	var backoff *backoffLimiter
+	if o.cfg.BackoffOnErrors {
+		backoff = newBackoffLimiter(o.cfg.Workers*o.cfg.Pipeline, slotSched)
+		slotSched = backoff
+		go backoff.run(ctx, durationDone, o.cfg.BackoffInterval, collector)
+	}
+	var steps *stepRecorder
+	if len(o.cfg.ConcurrencySteps) > 0 {
+		limiter := newSlotLimiter(o.cfg.Workers*o.cfg.Pipeline, o.cfg.ConcurrencySteps[0].Concurrency, slotSched)
+		slotSched = limiter
+		steps = newStepRecorder(o.cfg.ConcurrencySteps, limiter, o.cfg.StatsMemory)
+		go steps.run(ctx, durationDone)
+	}
 
-	// Start renderer loop.
+	// workersDone is closed once every worker has returned and o.final has
+	// been taken, so nothing can be recorded after the final snapshot.
+	workersDone := make(chan struct{})
+
+	// Start renderer loop. The final report waits for workersDone rather than
+	// ctx: a stop signal or drain-timeout cancel still has in-flight and
+	// abandoned requests to record.
 	doneRendering := make(chan struct{})
 	go func() {
 		ticker := time.NewTicker(200 * time.Millisecond)
 		defer ticker.Stop()
+		// Soak reports come from this goroutine too, so they never race
+		// with the HUD; soakTick stays nil, and never fires, without them.
+		var soakTick <-chan time.Time
+		var soak *soakSampler
+		soakRenderer, ok := o.renderer.(ui.SoakRenderer)
+		if ok && o.cfg.SoakReport > 0 {
+			soakTicker := time.NewTicker(o.cfg.SoakReport)
+			defer soakTicker.Stop()
+			soakTick, soak = soakTicker.C, newSoakSampler()
+		}
 		for {
 			select {
 			case <-ticker.C:
 				snap := collector.Snapshot()
+				snap.Paused = gate != nil && gate.paused()
+				if backoff != nil {
+					snap.Slots = backoff.slots
+					snap.ActiveSlots, _, _ = backoff.state()
+				}
 				o.renderer.Render(snap)
-			case <-ctx.Done():
-				snap := collector.Snapshot()
-				o.renderer.RenderFinal(snap)
+				if statsd != nil && statsd.due(time.Now()) {
+					statsd.send(snap)
+				}
+				if o.cfg.MaxBytes > 0 && snap.TotalBytesSent+snap.TotalBytesRecv >= o.cfg.MaxBytes {
+					stopEarly(fmt.Sprintf("byte budget of %s reached", ui.HumanizeBytes(o.cfg.MaxBytes)))
+				}
+			case <-soakTick:
+				soakRenderer.RenderSoak(collector.Snapshot(), soak.sample())
+			case <-statusReq:
+				if sr, ok := o.renderer.(ui.StatusRenderer); ok {
+					sr.RenderStatus(collector.Snapshot())
+				}
+			case <-workersDone:
+				o.renderer.RenderFinal(o.final)
 				close(doneRendering)
 				return
 			}
 		}
 	}()
 
+	// A first request that ends in a redirect loop means every request
+	// will: stop the run rather than spend its duration on the loop. The
+	// hook goes on the workers' copy of the config so Config().OnResult
+	// stays the caller's and a second Run does not wrap it again.
+	var firstResult atomic.Bool
+	var redirectAbort string
+	onResult := o.cfg.OnResult
+	workerCfg := o.cfg
+	workerCfg.OnResult = func(r stats.Result) {
+		if !firstResult.Load() && !r.Discarded && !firstResult.Swap(true) && r.ErrorCategory == stats.ErrRedirect {
+			redirectAbort = r.ErrorMessage
+			stopEarly("first request failed following redirects")
+		}
+		if steps != nil {
+			steps.record(r)
+		}
+		if onResult != nil {
+			onResult(r)
+		}
+	}
+
 	var wg sync.WaitGroup
 	reqsPerWorker := o.cfg.Connections / o.cfg.Workers
 	if reqsPerWorker == 0 {
@@ -118,22 +464,472 @@ func (o *Orchestrator) Run() error {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			worker(ctx, durationDone, client, o.cfg, reqsPerWorker, collector)
+			worker(ctx, i, durationDone, client, workerCfg, reqs, slotSched, reqsPerWorker, collector)
+		}()
+	}
+
+	// Once the duration is over, give in-flight requests DrainTimeout to finish,
+	// then abandon whatever is still pending.
+	if o.cfg.DrainTimeout > 0 {
+		go func() {
+			select {
+			case <-durationDone:
+			case <-ctx.Done():
+				return
+			}
+			timer := time.NewTimer(o.cfg.DrainTimeout)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+				cancelCause(errDrainTimeout)
+			case <-workersDone:
+			}
 		}()
 	}
 
-	// Watch for interrupt (cancel context so workers and renderer exit).
+	// Watch for signals: a stop signal cancels the context so workers exit,
+	// a status signal only pokes the renderer.
 	go func() {
-		select {
-		case <-sigCh:
-			cancel()
-		case <-ctx.Done():
+		for {
+			select {
+			case sig := <-sigCh:
+				if slices.Contains(o.cfg.StopSignals, sig) {
+					cancel()
+					return
+				}
+				select {
+				case statusReq <- struct{}{}:
+				default: // a status snapshot is already pending
+				}
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 
 	wg.Wait()
+	// Workers stopped by a signal never closed durationDone; their load
+	// phase ends here with no drain.
+	stopEarly("")
+	o.final = collector.Snapshot()
+	o.final.OpenLoop = o.cfg.AdaptiveRate || o.cfg.Rate > 0
+	o.final.ConnsOpened = counts.dials.Load()
+	o.final.DNSLookups = counts.lookups.Load()
+	o.final.BodySends = reqs.bodySends()
+	o.final.Preflight = o.preflight
+	o.final.RunID = o.cfg.RunID
+	o.final.Phases = []stats.Phase{
+		{Name: "preflight", Duration: loadStart.Sub(runStart) - calibration},
+		{Name: "load", Duration: loadEnd.Sub(loadStart)},
+		{Name: "drain", Duration: time.Since(loadEnd)},
+	}
+	if calibration > 0 {
+		o.final.Phases = slices.Insert(o.final.Phases, 1, stats.Phase{Name: "calibrate", Duration: calibration})
+	}
+	if o.cfg.ShowAddrs {
+		o.final.ResolvedAddrs = o.resolved
+	}
+	if steps != nil {
+		o.final.LoadSteps = steps.finish()
+	}
+	if backoff != nil {
+		o.final.Slots = backoff.slots
+		o.final.ActiveSlots, o.final.LowestSlots, _ = backoff.state()
+	}
+	if !o.final.OpenLoop && o.cfg.Burst == 0 && o.cfg.ReplaySpeed == 0 {
+		// Paced runs are slow on purpose.
+		o.final.Suspect = suspectResults(o.final, o.cfg.Workers*o.cfg.Pipeline, o.cfg.LowRPSWarn)
+	}
+	if o.cfg.CapConnections {
+		o.final.ConnWaitShare = poolWaitShare(o.final.ConnWaitTotal, o.cfg.Workers*o.cfg.Pipeline, loadEnd.Sub(loadStart))
+	}
+	close(workersDone)
 	cancel()
 	<-doneRendering
+	// The last send covers whatever the poll loop had not reported yet.
+	if statsd != nil {
+		statsd.send(o.final)
+	}
+
+	if o.stopReason != "" {
+		ui.PrintStepResult(o.cfg.Log, "Stopped", o.stopReason, false)
+	}
+	if o.final.Suspect != "" {
+		ui.PrintStepFailure(o.cfg.Log, "Results", "may be invalid: "+o.final.Suspect)
+	}
+	if a, ok := sched.(*adaptiveScheduler); ok {
+		o.sustainedRate = a.sustainedRate()
+		if o.sustainedRate > 0 {
+			ui.PrintStepResult(o.cfg.Log, "Adaptive", fmt.Sprintf("sustained %.1f req/s with p99 <= %s", o.sustainedRate, o.cfg.TargetP99), true)
+		} else {
+			ui.PrintStepResult(o.cfg.Log, "Adaptive", fmt.Sprintf("p99 stayed above %s at every rate tried", o.cfg.TargetP99), false)
+		}
+	}
+	if backoff != nil {
+		if o.final.LowestSlots < o.final.Slots {
+			ui.PrintStepResult(o.cfg.Log, "Backoff", fmt.Sprintf("connection errors cut active slots to as few as %d of %d", o.final.LowestSlots, o.final.Slots), false)
+		} else {
+			ui.PrintStepResult(o.cfg.Log, "Backoff", fmt.Sprintf("all %d slots stayed active", o.final.Slots), true)
+		}
+	}
+	if o.cfg.CapConnections && o.final.ConnWaitShare > o.cfg.PoolWaitWarn {
+		ui.PrintStepResult(o.cfg.Log, "Conn pool", fmt.Sprintf("exhausted: slots waited %.0f%% of the run for one of %d connections; more connections would raise the rate", o.final.ConnWaitShare*100, o.cfg.Connections), false)
+	}
+	if o.cfg.MinRPS > 0 {
+		o.sustainedRPS, o.rpsMeasured = sustainedRPS(collector.TimeSeries(), o.cfg.MinRPSWarmup, loadEnd.Sub(loadStart))
+		if !o.rpsMeasured {
+			ui.PrintStepResult(o.cfg.Log, "Min RPS", fmt.Sprintf("not evaluated (the run ended within the %s warm-up)", o.cfg.MinRPSWarmup), false)
+		}
+	}
+	var abortErr error
+	if redirectAbort != "" {
+		abortErr = fmt.Errorf("run aborted: %s", redirectAbort)
+	}
+	return errors.Join(
+		abortErr,
+		checkHealth(o.final, o.cfg.MaxErrorRate),
+		checkThroughput(o.sustainedRPS, o.rpsMeasured, o.cfg.MinRPS, o.cfg.MinRPSWarmup),
+		checkPool(o.final.ConnWaitShare, o.cfg.PoolWaitWarn, o.cfg.Strict, o.cfg.Connections, o.cfg.Workers*o.cfg.Pipeline),
+	)
+}
+
+// preflightStep prints a preflight check the way it always has and records
+// it for the final snapshot.
+func (o *Orchestrator) preflightStep(name string, status stats.PreflightStatus, detail string) {
+	switch status {
+	case stats.PreflightOK:
+		ui.PrintStepResult(o.cfg.Log, name, detail, true)
+	case stats.PreflightFailed:
+		ui.PrintStepFailure(o.cfg.Log, name, detail)
+	default:
+		ui.PrintStepResult(o.cfg.Log, name, detail, false)
+	}
+	o.preflight = append(o.preflight, stats.PreflightCheck{Name: name, Status: status, Detail: detail})
+}
+
+// dnsSkipReason explains why a DNS preflight failure should not stop the run,
+// or returns "" if it should.
+func (o *Orchestrator) dnsSkipReason() string {
+	if o.cfg.SkipDNSCheck {
+		return "check disabled"
+	}
+	u, err := url.Parse(o.cfg.URL)
+	if err != nil {
+		return ""
+	}
+	if _, ok := o.cfg.Resolve[strings.ToLower(hostPort(u))]; ok {
+		return "pinned address"
+	}
+	if proxy, err := http.ProxyFromEnvironment(&http.Request{URL: u}); err == nil && proxy != nil {
+		return "via proxy"
+	}
+	return ""
+}
+
+// hostPort returns u's "host:port", filling in the scheme's default port.
+func hostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// Config returns the effective configuration, i.e. the caller's Config with
+// defaults applied by NewOrchestrator.
+func (o *Orchestrator) Config() Config {
+	return o.cfg
+}
+
+// configItems lists the effective settings shown by --verbose.
+func (o *Orchestrator) configItems() []ui.ConfigItem {
+	cfg := o.cfg
+	items := []ui.ConfigItem{
+		{Label: "method", Value: cfg.Method},
+		{Label: "url", Value: cfg.URL},
+		{Label: "workers", Value: strconv.Itoa(cfg.Workers)},
+		{Label: "connections", Value: strconv.Itoa(cfg.Connections)},
+		{Label: "pipeline", Value: strconv.Itoa(cfg.Pipeline)},
+		{Label: "slots", Value: strconv.Itoa(cfg.Workers * cfg.Pipeline)},
+		{Label: "duration", Value: cfg.Duration.String()},
+	}
+	if cfg.StaggerStart > 0 {
+		items = append(items, ui.ConfigItem{Label: "stagger start", Value: "slots start within " + cfg.StaggerStart.String()})
+	}
+	if len(cfg.Replay) > 0 {
+		value := fmt.Sprintf("%d requests, in order", len(cfg.Replay))
+		if cfg.ReplaySpeed > 0 {
+			value += fmt.Sprintf(", at %gx capture timing", cfg.ReplaySpeed)
+		}
+		items = append(items, ui.ConfigItem{Label: "replay", Value: value})
+	}
+	if len(cfg.Transaction) > 0 {
+		items = append(items, ui.ConfigItem{Label: "transaction", Value: strings.Join(stepNames(cfg.Transaction), " -> ")})
+	}
+	if cfg.AdaptiveRate {
+		items = append(items, ui.ConfigItem{Label: "adaptive rate", Value: fmt.Sprintf("target p99 %s, adjusted every %s", cfg.TargetP99, cfg.AdaptiveInterval)})
+	}
+	if len(cfg.ConcurrencySteps) > 0 {
+		parts := make([]string, len(cfg.ConcurrencySteps))
+		for i, s := range cfg.ConcurrencySteps {
+			parts[i] = fmt.Sprintf("%d for %s", s.Concurrency, s.Duration)
+		}
+		items = append(items, ui.ConfigItem{Label: "concurrency steps", Value: strings.Join(parts, ", ")})
+	}
+	if cfg.BackoffOnErrors {
+		items = append(items, ui.ConfigItem{Label: "backoff on errors", Value: "checked every " + cfg.BackoffInterval.String()})
+	}
+	if cfg.Rate > 0 {
+		items = append(items, ui.ConfigItem{Label: "rate", Value: fmt.Sprintf("%.1f req/s", cfg.Rate)})
+	}
+	if cfg.Calibrate > 0 {
+		value := cfg.Calibrate.String() + " closed loop"
+		if cfg.RateFraction > 0 {
+			value += fmt.Sprintf(", then pace at %.0f%% of the max", cfg.RateFraction*100)
+		}
+		items = append(items, ui.ConfigItem{Label: "calibrate", Value: value})
+	}
+	if cfg.Burst > 0 {
+		items = append(items, ui.ConfigItem{Label: "burst", Value: fmt.Sprintf("%d every %s", cfg.Burst, cfg.BurstInterval)})
+	}
+	if s := cfg.AWSSigV4; s != nil {
+		items = append(items, ui.ConfigItem{Label: "aws sigv4", Value: fmt.Sprintf("%s/%s as %s", s.Region, s.Service, s.AccessKeyID)})
+	}
+	if s := conditionalString(cfg); s != "" {
+		items = append(items, ui.ConfigItem{Label: "conditional", Value: s})
+	}
+	if cfg.MinRPS > 0 {
+		items = append(items, ui.ConfigItem{Label: "min rps", Value: fmt.Sprintf("%.1f after a %s warm-up", cfg.MinRPS, cfg.MinRPSWarmup)})
+	}
+	if cfg.DiscardFirstPerConn > 0 {
+		items = append(items, ui.ConfigItem{Label: "discarded", Value: fmt.Sprintf("first %d requests per connection", cfg.DiscardFirstPerConn)})
+	}
+	if len(cfg.Targets) > 0 {
+		shares := targetShares(cfg.Targets)
+		for i, t := range cfg.Targets {
+			share := shares[i]
+			if i > 0 {
+				share -= shares[i-1]
+			}
+			items = append(items, ui.ConfigItem{Label: fmt.Sprintf("target %d", i+1), Value: fmt.Sprintf("%s (%.1f%%)", t.URL, share*100)})
+		}
+		if cfg.ShardKey != "" {
+			items = append(items, ui.ConfigItem{Label: "shard key", Value: cfg.ShardKey + " (consistent hashing)"})
+		}
+	}
+	if cfg.StatsD != nil {
+		items = append(items, ui.ConfigItem{Label: "statsd", Value: fmt.Sprintf("%s every %s", cfg.StatsD.Addr, statsdInterval)})
+	}
+	if cfg.Retries > 0 {
+		retries := fmt.Sprintf("up to %d, %s backoff from %s", cfg.Retries, cfg.RetryBackoff, cfg.RetryDelay)
+		if cfg.RetryJitter {
+			retries += " with jitter"
+		}
+		if cfg.RetryNonIdempotent {
+			retries += ", any method"
+		}
+		items = append(items, ui.ConfigItem{Label: "retries", Value: retries})
+	}
+	items = append(items, []ui.ConfigItem{
+		{Label: "drain timeout", Value: drainTimeoutString(cfg.DrainTimeout)},
+		{Label: "body", Value: bodyString(cfg)},
+		{Label: "seed", Value: strconv.FormatUint(cfg.Seed, 10)},
+	}...)
+	if cfg.ContentType != "" {
+		items = append(items, ui.ConfigItem{Label: "content-type", Value: cfg.ContentType})
+	}
+	if cfg.ProxyProtocol > 0 {
+		source := "local address"
+		if cfg.ProxySource.IsValid() {
+			source = cfg.ProxySource.String()
+		}
+		items = append(items, ui.ConfigItem{Label: "proxy protocol", Value: fmt.Sprintf("v%d, source %s", cfg.ProxyProtocol, source)})
+	}
+	if cfg.GRPC {
+		items = append(items, ui.ConfigItem{Label: "grpc", Value: "unary calls over HTTP/2"})
+	}
+	if cfg.HTTPVersion != "" {
+		items = append(items, ui.ConfigItem{Label: "http version", Value: cfg.HTTPVersion + " only"})
+	}
+	if cfg.Count429 != Count429Separate {
+		items = append(items, ui.ConfigItem{Label: "429 counted as", Value: cfg.Count429})
+	}
+	retention := stats.RetentionFor(cfg.StatsMemory, cfg.CapConnections)
+	retained := fmt.Sprintf("%d latency samples, %d buckets", retention.LatencySamples, retention.Buckets)
+	if cfg.StatsMemory > 0 {
+		retained += " (budget " + ui.HumanizeBytes(cfg.StatsMemory) + ")"
+	}
+	items = append(items, ui.ConfigItem{Label: "stats retention", Value: retained})
+	if cfg.SimulateLatency != nil {
+		items = append(items, ui.ConfigItem{Label: "simulated latency", Value: cfg.SimulateLatency.String()})
+	}
+	if cfg.ConnBench {
+		items = append(items, ui.ConfigItem{Label: "conn bench", Value: "new connection per request"})
+	}
+	if cfg.CapConnections {
+		items = append(items, ui.ConfigItem{Label: "connection cap", Value: fmt.Sprintf("%d per host", cfg.Connections)})
+	}
+	if cfg.MaxRequestsPerConn > 0 {
+		items = append(items, ui.ConfigItem{Label: "max requests/conn", Value: strconv.Itoa(cfg.MaxRequestsPerConn)})
+	}
+	for _, m := range cfg.ExpectHeaders {
+		items = append(items, ui.ConfigItem{Label: "expect header", Value: m.String()})
+	}
+	for _, m := range cfg.RejectHeaders {
+		items = append(items, ui.ConfigItem{Label: "reject header", Value: m.String()})
+	}
+	for _, key := range slices.Sorted(maps.Keys(cfg.Trailers)) {
+		items = append(items, ui.ConfigItem{Label: "trailer", Value: key + ": " + strings.Join(cfg.Trailers[key], ", ")})
+	}
+	for _, m := range cfg.ExpectTrailers {
+		items = append(items, ui.ConfigItem{Label: "expect trailer", Value: m.String()})
+	}
+	for _, m := range cfg.RejectTrailers {
+		items = append(items, ui.ConfigItem{Label: "reject trailer", Value: m.String()})
+	}
+	if len(cfg.ExpectSHA256) > 0 {
+		items = append(items, ui.ConfigItem{Label: "expect sha256", Value: hex.EncodeToString(cfg.ExpectSHA256)})
+	}
+	for _, a := range cfg.AssertJSON {
+		items = append(items, ui.ConfigItem{Label: "assert json", Value: a.Text})
+	}
+	for _, key := range slices.Sorted(maps.Keys(cfg.Headers)) {
+		value := strings.Join(cfg.Headers[key], ", ")
+		switch http.CanonicalHeaderKey(key) {
+		case "Authorization", "Proxy-Authorization", "Cookie":
+			value = "<redacted>"
+		}
+		items = append(items, ui.ConfigItem{Label: "header", Value: key + ": " + value})
+	}
+	for _, key := range slices.Sorted(maps.Keys(cfg.Resolve)) {
+		items = append(items, ui.ConfigItem{Label: "resolve", Value: key + " -> " + cfg.Resolve[key]})
+	}
+	if cfg.SkipDNSCheck {
+		items = append(items, ui.ConfigItem{Label: "dns check", Value: "skipped"})
+	}
+	if cfg.ResolveDNS {
+		policy := "lookup per dial"
+		if cfg.DNSCache > 0 {
+			policy = "cached for " + cfg.DNSCache.String()
+		}
+		items = append(items, ui.ConfigItem{Label: "dns cache", Value: policy})
+	}
+	if cfg.Insecure {
+		items = append(items, ui.ConfigItem{Label: "tls verify", Value: "off (insecure)"})
+	}
+	if cfg.MaxErrorRate > 0 {
+		items = append(items, ui.ConfigItem{Label: "max error rate", Value: fmt.Sprintf("%.1f%%", cfg.MaxErrorRate*100)})
+	}
+	if cfg.SoakReport > 0 {
+		items = append(items, ui.ConfigItem{Label: "soak report", Value: "every " + cfg.SoakReport.String()})
+	}
+	if cfg.MaxBytes > 0 {
+		items = append(items, ui.ConfigItem{Label: "max-bytes", Value: ui.HumanizeBytes(cfg.MaxBytes)})
+	}
+	items = append(items,
+		ui.ConfigItem{Label: "stop signals", Value: signalsString(cfg.StopSignals)},
+		ui.ConfigItem{Label: "status signals", Value: signalsString(cfg.StatusSignals)},
+	)
+	items = append(items,
+		ui.ConfigItem{Label: "dial timeout", Value: dialTimeout.String()},
+		ui.ConfigItem{Label: "tls handshake timeout", Value: tlsHandshakeTimeout.String()},
+		ui.ConfigItem{Label: "idle conn timeout", Value: idleConnTimeout.String()},
+	)
+	if cfg.ReadBufferSize > 0 {
+		items = append(items, ui.ConfigItem{Label: "read buffer", Value: ui.HumanizeBytes(uint64(cfg.ReadBufferSize))})
+	}
+	if cfg.WriteBufferSize > 0 {
+		items = append(items, ui.ConfigItem{Label: "write buffer", Value: ui.HumanizeBytes(uint64(cfg.WriteBufferSize))})
+	}
+	return items
+}
+
+func bodyString(cfg Config) string {
+	if cfg.BodySize > 0 {
+		fill := "zeros"
+		if cfg.BodyRandom {
+			fill = "random bytes"
+		}
+		return fmt.Sprintf("%s of %s, streamed", ui.HumanizeBytes(uint64(cfg.BodySize)), fill)
+	}
+	if len(cfg.BodyCorpus) > 0 {
+		var total int
+		for _, b := range cfg.BodyCorpus {
+			total += len(b)
+		}
+		order := "random"
+		if cfg.BodyCycle {
+			order = "in order"
+		}
+		return fmt.Sprintf("%d files from corpus (%s), %s", len(cfg.BodyCorpus), ui.HumanizeBytes(uint64(total)), order)
+	}
+	return fmt.Sprintf("%d bytes", len(cfg.Body))
+}
+
+// conditionalString describes the conditional request headers, or "" if
+// requests are unconditional.
+func conditionalString(cfg Config) string {
+	var parts []string
+	if cfg.IfNoneMatch != "" {
+		parts = append(parts, "If-None-Match "+cfg.IfNoneMatch)
+	}
+	if !cfg.IfModifiedSince.IsZero() {
+		parts = append(parts, "If-Modified-Since "+cfg.IfModifiedSince.UTC().Format(http.TimeFormat))
+	}
+	if cfg.ChainETag {
+		parts = append(parts, "ETag chained from responses")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func drainTimeoutString(d time.Duration) string {
+	if d < 0 {
+		return "unbounded"
+	}
+	return d.String()
+}
+
+func signalsString(sigs []os.Signal) string {
+	if len(sigs) == 0 {
+		return "none"
+	}
+	names := make([]string, len(sigs))
+	for i, sig := range sigs {
+		names[i] = sig.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// Collector returns the stats collector used by the last Run, or nil if Run
+// has not started a benchmark.
+func (o *Orchestrator) Collector() *stats.Collector {
+	return o.collector
+}
+
+// StopReason explains why the last Run ended before its duration, or returns
+// "" if it ran for the full duration (or was interrupted).
+func (o *Orchestrator) StopReason() string {
+	return o.stopReason
+}
+
+// SustainedRate returns the rate (req/s) the adaptive scheduler sustained with
+// p99 at or under Config.TargetP99, or 0 if the run was not adaptive or never
+// met the target.
+func (o *Orchestrator) SustainedRate() float64 {
+	return o.sustainedRate
+}
+
+// SustainedRPS returns the request rate measured after Config.MinRPSWarmup
+// for the --min-rps check. ok is false if MinRPS was not set or the run was
+// too short to measure.
+func (o *Orchestrator) SustainedRPS() (rps float64, ok bool) {
+	return o.sustainedRPS, o.rpsMeasured
+}
 
-	return nil
+// FinalSnapshot returns the snapshot passed to RenderFinal by the last Run.
+func (o *Orchestrator) FinalSnapshot() stats.Snapshot {
+	return o.final
 }
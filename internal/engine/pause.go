@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Control is a command for a run in progress (see Config.Controls).
+type Control int
+
+const (
+	ControlPause Control = iota + 1
+	ControlResume
+	ControlStop
+)
+
+// pauseGate holds back request starts while a run is paused. resumed is
+// nil while running and, while paused, a channel closed on resume, so the
+// common case costs slots one atomic load.
+type pauseGate struct {
+	resumed atomic.Pointer[chan struct{}]
+}
+
+func (g *pauseGate) pause() {
+	ch := make(chan struct{})
+	g.resumed.CompareAndSwap(nil, &ch)
+}
+
+func (g *pauseGate) resume() {
+	if ch := g.resumed.Swap(nil); ch != nil {
+		close(*ch)
+	}
+}
+
+func (g *pauseGate) paused() bool {
+	return g.resumed.Load() != nil
+}
+
+// pausableScheduler makes slots wait at the gate before asking the
+// scheduler it wraps, if any, for their turn.
+type pausableScheduler struct {
+	gate  *pauseGate
+	inner scheduler // nil for closed loop
+}
+
+func (s pausableScheduler) wait(ctx context.Context, durationDone <-chan struct{}) bool {
+	if ch := s.gate.resumed.Load(); ch != nil {
+		select {
+		case <-*ch:
+		case <-durationDone:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return s.inner == nil || s.inner.wait(ctx, durationDone)
+}
+
+func (s pausableScheduler) observe(latency time.Duration) {
+	if o, ok := s.inner.(latencyObserver); ok {
+		o.observe(latency)
+	}
+}
+
+// followControls applies controls until the load phase ends.
+func followControls(ctx context.Context, durationDone <-chan struct{}, controls <-chan Control, gate *pauseGate, stop func(reason string)) {
+	for {
+		select {
+		case c, ok := <-controls:
+			if !ok {
+				return
+			}
+			switch c {
+			case ControlPause:
+				gate.pause()
+			case ControlResume:
+				gate.resume()
+			case ControlStop:
+				gate.resume()
+				stop("stop requested")
+				return
+			}
+		case <-durationDone:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultPoolWaitWarn is the share of slot time spent waiting for a
+// connection above which a capped pool counts as exhausted.
+const defaultPoolWaitWarn = 0.25
+
+// PoolExhaustedError is returned by Run with Config.Strict when the slots
+// of a capped connection pool spent more than Config.PoolWaitWarn of the
+// load phase waiting for a connection: the pool, not the server, limited
+// the request rate.
+type PoolExhaustedError struct {
+	WaitShare   float64 // share of slot time spent waiting for a connection
+	Max         float64 // the threshold that was exceeded
+	Connections int
+	Slots       int
+}
+
+func (e *PoolExhaustedError) Error() string {
+	return fmt.Sprintf("connection pool exhausted: %d slots spent %.1f%% of the run waiting for %d connections (max %.1f%%)",
+		e.Slots, e.WaitShare*100, e.Connections, e.Max*100)
+}
+
+// poolWaitShare returns the share of the slots' time in the load phase that
+// went to waiting for a connection.
+func poolWaitShare(wait time.Duration, slots int, load time.Duration) float64 {
+	if slots <= 0 || load <= 0 {
+		return 0
+	}
+	return wait.Seconds() / (float64(slots) * load.Seconds())
+}
+
+// checkPool returns a *PoolExhaustedError if share exceeds max and strict
+// is set.
+func checkPool(share, max float64, strict bool, connections, slots int) error {
+	if !strict || max <= 0 || share <= max {
+		return nil
+	}
+	return &PoolExhaustedError{WaitShare: share, Max: max, Connections: connections, Slots: slots}
+}
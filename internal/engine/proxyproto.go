@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// proxyV2Signature starts every PROXY protocol v2 header.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxyProtoDial wraps dial so every new connection starts with a PROXY
+// protocol header of the given version (1 or 2), sent before any TLS or HTTP
+// bytes. The header names src as the client, or the connection's real local
+// address if src is the zero value, and the dialled peer as the destination.
+func proxyProtoDial(dial func(ctx context.Context, network, addr string) (net.Conn, error), version int, src netip.AddrPort) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if version == 0 {
+		return dial
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		from := src
+		if !from.IsValid() {
+			from = addrPortOf(conn.LocalAddr())
+		}
+		header, err := proxyHeader(version, from, addrPortOf(conn.RemoteAddr()))
+		if err == nil {
+			_, err = conn.Write(header)
+		}
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("proxy protocol: %w", err)
+		}
+		return conn, nil
+	}
+}
+
+// addrPortOf returns a's address, or the zero value if a is not TCP.
+func addrPortOf(a net.Addr) netip.AddrPort {
+	if tcp, ok := a.(*net.TCPAddr); ok {
+		return tcp.AddrPort()
+	}
+	return netip.AddrPort{}
+}
+
+// proxyHeader encodes a PROXY protocol header for a TCP connection from src
+// to dst. Both addresses must be of the same family.
+func proxyHeader(version int, src, dst netip.AddrPort) ([]byte, error) {
+	src = netip.AddrPortFrom(src.Addr().Unmap(), src.Port())
+	dst = netip.AddrPortFrom(dst.Addr().Unmap(), dst.Port())
+	if !src.IsValid() || !dst.IsValid() {
+		return nil, fmt.Errorf("connection is not TCP")
+	}
+	if src.Addr().Is4() != dst.Addr().Is4() {
+		return nil, fmt.Errorf("source %s and destination %s are different address families", src, dst)
+	}
+
+	switch version {
+	case 1:
+		family := "TCP4"
+		if src.Addr().Is6() {
+			family = "TCP6"
+		}
+		return fmt.Appendf(nil, "PROXY %s %s %s %d %d\r\n",
+			family, src.Addr(), dst.Addr(), src.Port(), dst.Port()), nil
+	case 2:
+		var b bytes.Buffer
+		b.Write(proxyV2Signature)
+		b.WriteByte(0x21) // version 2, PROXY command
+		if src.Addr().Is4() {
+			b.WriteByte(0x11) // TCP over IPv4
+			b.Write(binary.BigEndian.AppendUint16(nil, 12))
+		} else {
+			b.WriteByte(0x21) // TCP over IPv6
+			b.Write(binary.BigEndian.AppendUint16(nil, 36))
+		}
+		b.Write(src.Addr().AsSlice())
+		b.Write(dst.Addr().AsSlice())
+		b.Write(binary.BigEndian.AppendUint16(nil, src.Port()))
+		b.Write(binary.BigEndian.AppendUint16(nil, dst.Port()))
+		return b.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported version %d", version)
+}
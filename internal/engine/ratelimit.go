@@ -0,0 +1,20 @@
+package engine
+
+import "net/http"
+
+// How a 429 Too Many Requests response is counted, for Config.Count429.
+const (
+	Count429Separate = "separate" // on its own, in Snapshot.RateLimited
+	Count429Success  = "success"  // as a success, like any other 4xx
+	Count429Error    = "error"    // as an error in the http_429 category
+)
+
+// count429 applies mode to a response that passed the status check: a 429
+// is taken out of the successes, and rateLimited says it is counted on its
+// own rather than as an error.
+func count429(resp *http.Response, mode string) (success, rateLimited bool) {
+	if resp.StatusCode != http.StatusTooManyRequests || mode == Count429Success {
+		return true, false
+	}
+	return false, mode == Count429Separate
+}
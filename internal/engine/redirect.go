@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// maxRedirects is how many redirects a request may follow, as in net/http.
+const maxRedirects = 10
+
+var (
+	errRedirectLoop     = errors.New("redirect loop")
+	errTooManyRedirects = errors.New("too many redirects")
+)
+
+// checkRedirect is the client's CheckRedirect. It stops a redirect back to a
+// request already made in the chain, so loops fail at once with a clear
+// message rather than after maxRedirects round trips.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	for _, prev := range via {
+		if prev.Method == req.Method && prev.URL.String() == req.URL.String() {
+			return fmt.Errorf("%w: %s redirects back to %s", errRedirectLoop, via[len(via)-1].URL, req.URL)
+		}
+	}
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("%w: stopped after %d", errTooManyRedirects, maxRedirects)
+	}
+	return nil
+}
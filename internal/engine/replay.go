@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ReplayRequest is one captured request for Config.Replay.
+type ReplayRequest struct {
+	Method string
+	URL    string // absolute
+	Header http.Header
+	Body   []byte
+	// At is when the request was captured, relative to the first request
+	// of the list. It only matters with Config.ReplaySpeed.
+	At time.Duration
+}
+
+// nextReplay returns the next request of the replay list. All pipeline slots
+// share one cursor, so the list is sent in order and wraps around at the end.
+func (b *requestBuilder) nextReplay() *ReplayRequest {
+	i := (b.replayNext.Add(1) - 1) % uint64(len(b.replay))
+	return &b.replay[i]
+}
+
+// replayScheduler starts the Nth request of the run at the capture time of
+// the Nth replay request, divided by the replay speed. Each pass over the
+// list starts one average gap after the previous pass's last request. A
+// request whose time has passed (every slot was busy) starts at once, so a
+// slow server is caught up with rather than skipped.
+type replayScheduler struct {
+	start  time.Time
+	at     []time.Duration // scaled capture offsets
+	period time.Duration   // scaled length of one pass
+	next   atomic.Uint64
+}
+
+// newReplayScheduler returns nil when the requests carry no timing, so they
+// are sent as fast as the slots allow.
+func newReplayScheduler(reqs []ReplayRequest, speed float64) *replayScheduler {
+	last := reqs[len(reqs)-1].At
+	if last <= 0 {
+		return nil
+	}
+	s := &replayScheduler{start: time.Now(), at: make([]time.Duration, len(reqs))}
+	for i, r := range reqs {
+		s.at[i] = time.Duration(float64(r.At) / speed)
+	}
+	s.period = s.at[len(s.at)-1]
+	if n := len(reqs); n > 1 {
+		s.period += s.period / time.Duration(n-1)
+	}
+	return s
+}
+
+func (s *replayScheduler) wait(ctx context.Context, durationDone <-chan struct{}) bool {
+	i := s.next.Add(1) - 1
+	n := uint64(len(s.at))
+	at := s.start.Add(time.Duration(i/n)*s.period + s.at[i%n])
+	d := time.Until(at)
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-durationDone:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,385 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// requestBuilder produces the request for each iteration of a pipeline slot.
+// Values without placeholders are resolved once; only templated URL, body and
+// header values are expanded per request.
+type requestBuilder struct {
+	method string
+	url    string
+	body   []byte
+	corpus [][]byte
+	grpc   bool // frame bodies as gRPC messages
+
+	// cycleSends, if set, makes corpus entries go out in order rather than
+	// at random, and counts the requests built with each.
+	cycleNext  atomic.Uint64
+	cycleSends []atomic.Uint64
+
+	// replay, if set, replaces method, url and body: requests are taken
+	// from it in order through replayNext.
+	replay     []ReplayRequest
+	replayNext atomic.Uint64
+	// steps are the requests of Config.Transaction, built by buildStep.
+	steps []ReplayRequest
+	// targets replace url when set, picked by weight with targetCum, their
+	// cumulative share of the total weight.
+	targets   []Target
+	targetCum []float64
+	// With a shard key, shards picks the target instead, by consistent
+	// hashing of the key expanded per request. The key has its own
+	// templateVars so its {{seq}} does not skip numbers in the URL or body.
+	shards    *shardRing
+	shardKey  string
+	shardTmpl *template
+	shardVars *templateVars
+
+	// A positive streamSize replaces body with that many bytes repeating
+	// streamBlock, generated while the request is written.
+	streamSize  int64
+	streamBlock []byte
+
+	// etags, if set, supplies If-None-Match from the latest response.
+	etags *etagChain
+
+	// sigv4 signs every request; bodyHash is the payload hash of a static body.
+	sigv4    *SigV4
+	bodyHash string
+
+	urlTmpl  *template
+	bodyTmpl *template
+
+	static   http.Header
+	host     string // Host header override; net/http ignores Header["Host"]
+	dynamic  []dynamicHeader
+	trailers http.Header
+
+	vars *templateVars
+}
+
+type dynamicHeader struct {
+	key  string
+	tmpl *template
+}
+
+func newRequestBuilder(cfg Config) *requestBuilder {
+	b := &requestBuilder{
+		method:   cfg.Method,
+		url:      cfg.URL,
+		body:     cfg.Body,
+		corpus:   cfg.BodyCorpus,
+		grpc:     cfg.GRPC,
+		replay:   cfg.Replay,
+		steps:    cfg.Transaction,
+		targets:  cfg.Targets,
+		urlTmpl:  parseTemplate(cfg.URL),
+		bodyTmpl: parseTemplate(string(cfg.Body)),
+		static:   make(http.Header),
+		vars:     &templateVars{},
+	}
+	if len(cfg.Targets) > 0 {
+		b.targetCum = targetShares(cfg.Targets)
+	}
+	if cfg.ShardKey != "" {
+		b.shards = newShardRing(cfg.Targets)
+		b.shardKey = cfg.ShardKey
+		b.shardTmpl = parseTemplate(cfg.ShardKey)
+		b.shardVars = &templateVars{}
+	}
+	if cfg.BodyCycle && len(cfg.BodyCorpus) > 0 {
+		b.cycleSends = make([]atomic.Uint64, len(cfg.BodyCorpus))
+	}
+	if cfg.BodySize > 0 {
+		b.streamSize = cfg.BodySize
+		b.streamBlock = streamBlock(cfg.BodyRandom, cfg.Seed)
+	}
+	if cfg.AWSSigV4 != nil {
+		b.sigv4 = cfg.AWSSigV4
+		b.bodyHash = payloadHash(b.body)
+	}
+	if cfg.ContentType != "" && !hasHeader(cfg.Headers, "Content-Type") {
+		b.static.Set("Content-Type", cfg.ContentType)
+	}
+	if cfg.RunID != "" && !hasHeader(cfg.Headers, RunIDHeader) {
+		b.static.Set(RunIDHeader, cfg.RunID)
+	}
+	if cfg.Decode && !hasHeader(cfg.Headers, "Accept-Encoding") {
+		b.static.Set("Accept-Encoding", acceptEncoding())
+	}
+	if cfg.GRPC {
+		// An empty message still needs its 5-byte frame.
+		b.body = grpcFrame(cfg.Body)
+		if !hasHeader(cfg.Headers, "Content-Type") {
+			b.static.Set("Content-Type", grpcContentType)
+		}
+		b.static.Set("TE", "trailers")
+	}
+	for key, values := range cfg.Headers {
+		for _, v := range values {
+			if t := parseTemplate(v); t != nil {
+				b.dynamic = append(b.dynamic, dynamicHeader{key: key, tmpl: t})
+				continue
+			}
+			if http.CanonicalHeaderKey(key) == "Host" {
+				b.host = v
+				continue
+			}
+			b.static.Add(key, v)
+		}
+	}
+	if cfg.IfNoneMatch != "" {
+		b.static.Set("If-None-Match", cfg.IfNoneMatch)
+	}
+	if !cfg.IfModifiedSince.IsZero() {
+		b.static.Set("If-Modified-Since", cfg.IfModifiedSince.UTC().Format(http.TimeFormat))
+	}
+	if cfg.ChainETag {
+		b.etags = &etagChain{}
+	}
+	if len(cfg.Trailers) > 0 {
+		b.trailers = cfg.Trailers.Clone()
+	}
+	return b
+}
+
+// bodySends returns how many requests were built with each corpus entry
+// under BodyCycle, in corpus order, or nil without it.
+func (b *requestBuilder) bodySends() []uint64 {
+	if b.cycleSends == nil {
+		return nil
+	}
+	out := make([]uint64, len(b.cycleSends))
+	for i := range b.cycleSends {
+		out[i] = b.cycleSends[i].Load()
+	}
+	return out
+}
+
+// hasHeader reports whether h sets key, tolerating non-canonical map keys
+// from callers that build Config.Headers by hand.
+func hasHeader(h http.Header, key string) bool {
+	for k := range h {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// reusable reports whether a single request can be sent on every iteration:
+// no body to re-read, nothing to expand, no chained ETag and no per-request
+// signature.
+func (b *requestBuilder) reusable() bool {
+	return len(b.body) == 0 && len(b.corpus) == 0 && len(b.replay) == 0 && len(b.targets) == 0 && b.streamSize == 0 && b.urlTmpl == nil && len(b.dynamic) == 0 && b.etags == nil && b.sigv4 == nil && b.trailers == nil
+}
+
+// staticBody reports whether every request is the same apart from a body
+// that never changes, so it can be built once and only its body rewound.
+func (b *requestBuilder) staticBody() bool {
+	return len(b.body) > 0 && b.bodyTmpl == nil && len(b.corpus) == 0 && len(b.replay) == 0 && len(b.targets) == 0 && b.streamSize == 0 && b.urlTmpl == nil && len(b.dynamic) == 0 && b.etags == nil && b.sigv4 == nil && b.trailers == nil
+}
+
+// sharedBody is a static request body a pipeline slot sends over and over
+// from one reader, rewound instead of reallocated. The transport may still
+// be writing a body after Do returns and closes it once done, so the reader
+// is only rewound after that close; until then a send gets a fresh reader.
+type sharedBody struct {
+	data []byte
+
+	mu     sync.Mutex
+	r      bytes.Reader
+	closed bool
+}
+
+func newSharedBody(data []byte) *sharedBody {
+	b := &sharedBody{data: data, closed: true}
+	b.r.Reset(data)
+	return b
+}
+
+func (b *sharedBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.r.Read(p)
+}
+
+func (b *sharedBody) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	return nil
+}
+
+// open returns the body ready to be sent from the start: the shared reader
+// when its last send is over, otherwise a fresh one.
+func (b *sharedBody) open() (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		return io.NopCloser(bytes.NewReader(b.data)), nil
+	}
+	b.closed = false
+	_, err := b.r.Seek(0, io.SeekStart)
+	return b, err
+}
+
+// attach returns a shallow copy of req that sends the body. GetBody rewinds
+// it too, so retries and redirects that re-send the body still work.
+func (b *sharedBody) attach(req *http.Request) *http.Request {
+	r := *req
+	r.Body, _ = b.open()
+	r.GetBody = b.open
+	return &r
+}
+
+// frame returns body as sent on the wire: gRPC-framed in gRPC mode.
+func (b *requestBuilder) frame(body []byte) []byte {
+	if b.grpc {
+		return grpcFrame(body)
+	}
+	return body
+}
+
+// build creates a fresh request and returns it with its body length. rng
+// picks the corpus entry and target; it may be nil when there are neither.
+func (b *requestBuilder) build(ctx context.Context, rng *mathrand.Rand) (*http.Request, int64, error) {
+	req, n, _, err := b.buildTarget(ctx, rng)
+	return req, n, err
+}
+
+// buildTarget is build that also returns the index of the Config.Targets
+// entry the request goes to, or -1 without targets.
+func (b *requestBuilder) buildTarget(ctx context.Context, rng *mathrand.Rand) (*http.Request, int64, int, error) {
+	switch {
+	case len(b.replay) > 0:
+		req, n, err := b.buildFrom(ctx, rng, b.nextReplay(), "")
+		return req, n, -1, err
+	case b.shards != nil:
+		key := b.shardKey
+		if b.shardTmpl != nil {
+			key = b.shardTmpl.expand(b.shardVars)
+		}
+		i := b.shards.pick(key)
+		req, n, err := b.buildFrom(ctx, rng, nil, b.targets[i].URL)
+		return req, n, i, err
+	case len(b.targets) > 0:
+		i := pickTarget(b.targetCum, rng.Float64())
+		req, n, err := b.buildFrom(ctx, rng, nil, b.targets[i].URL)
+		return req, n, i, err
+	}
+	req, n, err := b.buildFrom(ctx, rng, nil, "")
+	return req, n, -1, err
+}
+
+// buildStep creates the request for step i of Config.Transaction.
+func (b *requestBuilder) buildStep(ctx context.Context, i int) (*http.Request, int64, error) {
+	return b.buildFrom(ctx, nil, &b.steps[i], "")
+}
+
+// buildFrom is build for a given recorded request, or for the configured
+// one if replay is nil, sent to target instead of the configured URL if set.
+func (b *requestBuilder) buildFrom(ctx context.Context, rng *mathrand.Rand, replay *ReplayRequest, target string) (*http.Request, int64, error) {
+	method, url, body := b.method, b.url, b.body
+	if replay != nil {
+		method, url, body = replay.Method, replay.URL, replay.Body
+	} else if target != "" {
+		url = target
+	} else if b.urlTmpl != nil {
+		url = b.urlTmpl.expand(b.vars)
+	}
+	switch {
+	case replay != nil:
+	case b.cycleSends != nil:
+		i := (b.cycleNext.Add(1) - 1) % uint64(len(b.corpus))
+		b.cycleSends[i].Add(1)
+		body = b.frame(b.corpus[i])
+	case len(b.corpus) > 0:
+		body = b.frame(b.corpus[rng.IntN(len(b.corpus))])
+	case b.bodyTmpl != nil:
+		body = b.frame([]byte(b.bodyTmpl.expand(b.vars)))
+	}
+
+	var bodyReader io.Reader
+	if len(body) > 0 {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(body) > 0 {
+		req.ContentLength = int64(len(body))
+	}
+	if b.streamSize > 0 {
+		req.Body = io.NopCloser(newPatternReader(b.streamBlock, b.streamSize))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(newPatternReader(b.streamBlock, b.streamSize)), nil
+		}
+		req.ContentLength = b.streamSize
+	}
+
+	req.Header = b.static.Clone()
+	if b.host != "" {
+		req.Host = b.host
+	}
+	for _, h := range b.dynamic {
+		v := h.tmpl.expand(b.vars)
+		if http.CanonicalHeaderKey(h.key) == "Host" {
+			req.Host = v
+			continue
+		}
+		req.Header.Add(h.key, v)
+	}
+	if replay != nil {
+		// A recorded header replaces the same header from Config.Headers.
+		for k, vs := range replay.Header {
+			if http.CanonicalHeaderKey(k) == "Host" {
+				req.Host = vs[len(vs)-1]
+				continue
+			}
+			req.Header[http.CanonicalHeaderKey(k)] = vs
+		}
+	}
+	if b.etags != nil {
+		if tag := b.etags.current(); tag != "" {
+			req.Header.Set("If-None-Match", tag)
+		}
+	}
+	if b.sigv4 != nil {
+		hash := b.bodyHash
+		switch {
+		case b.streamSize > 0:
+			hash = sigV4UnsignedBody
+		case replay != nil || len(b.corpus) > 0 || b.bodyTmpl != nil:
+			hash = payloadHash(body)
+		}
+		b.sigv4.sign(req, hash, time.Now())
+	}
+	if b.trailers != nil {
+		// Trailers need a chunked body; the returned length stays the real
+		// one for the byte counts.
+		n := req.ContentLength
+		if req.Body == nil {
+			// Not http.NoBody, which the transport sends without a body
+			// and so without trailers.
+			empty := func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(nil)), nil }
+			req.Body, _ = empty()
+			req.GetBody = empty
+		}
+		req.Trailer = b.trailers.Clone()
+		req.ContentLength = -1
+		return req, n, nil
+	}
+	return req, req.ContentLength, nil
+}
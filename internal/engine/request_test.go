@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestRequestBuilder_StaticAndDynamicHeaders(t *testing.T) {
+	cfg := Config{
+		Method: "GET",
+		URL:    "http://example.com/",
+		Headers: map[string][]string{
+			"Authorization": {"Bearer x"},
+			"X-Cache-Bust":  {"{{seq}}"},
+			"Host":          {"override.example"},
+		},
+	}
+	b := newRequestBuilder(cfg)
+	if b.reusable() {
+		t.Fatal("builder with a templated header must not be reusable")
+	}
+	r1, _, err := b.build(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, _, _ := b.build(context.Background(), nil)
+	if r1.Header.Get("Authorization") != "Bearer x" {
+		t.Errorf("static header missing: %v", r1.Header)
+	}
+	if r1.Host != "override.example" {
+		t.Errorf("Host override not applied: %q", r1.Host)
+	}
+	if r1.Header.Get("X-Cache-Bust") == r2.Header.Get("X-Cache-Bust") {
+		t.Errorf("templated header should differ per request: %q", r1.Header.Get("X-Cache-Bust"))
+	}
+}
+
+func TestRequestBuilder_ContentTypePrecedence(t *testing.T) {
+	cases := []struct {
+		name    string
+		headers http.Header
+		want    string
+	}{
+		{"default applies", nil, "application/json"},
+		{"explicit header wins", http.Header{"Content-Type": {"text/plain"}}, "text/plain"},
+		{"non-canonical explicit header wins", map[string][]string{"content-type": {"text/csv"}}, "text/csv"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newRequestBuilder(Config{
+				Method:      "POST",
+				URL:         "http://example.com/",
+				Body:        []byte("{}"),
+				Headers:     tc.headers,
+				ContentType: "application/json",
+			})
+			req, _, err := b.build(context.Background(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := req.Header.Values("Content-Type"); len(got) != 1 || got[0] != tc.want {
+				t.Errorf("Content-Type: got %v, want [%s]", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRequestBuilder_CorpusPicksAreSeeded(t *testing.T) {
+	cfg := Config{
+		Method:     "POST",
+		URL:        "http://example.com/",
+		BodyCorpus: [][]byte{[]byte("a"), []byte("bb"), []byte("ccc"), []byte("dddd")},
+	}
+	b := newRequestBuilder(cfg)
+	if b.reusable() {
+		t.Fatal("builder with a corpus must not be reusable")
+	}
+	picks := func(seed uint64) string {
+		rng := newSlotRand(seed, 0)
+		var out []byte
+		for i := 0; i < 20; i++ {
+			req, n, err := b.build(context.Background(), rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.ContentLength != n {
+				t.Fatalf("ContentLength %d != body length %d", req.ContentLength, n)
+			}
+			out = append(out, byte('0'+n))
+		}
+		return string(out)
+	}
+	if picks(42) != picks(42) {
+		t.Error("the same seed must give the same sequence of bodies")
+	}
+	if picks(42) == picks(43) {
+		t.Error("different seeds should give different sequences")
+	}
+}
+
+func TestRequestBuilder_GeneratedBody(t *testing.T) {
+	const size = 3*streamBlockSize + 123
+	for _, random := range []bool{false, true} {
+		b := newRequestBuilder(Config{Method: "PUT", URL: "http://example.com/", BodySize: size, BodyRandom: random, Seed: 7})
+		if b.reusable() {
+			t.Fatal("a generated body must be fresh for every request")
+		}
+		r, n, err := b.build(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != size || r.ContentLength != size {
+			t.Errorf("random=%v: length %d, Content-Length %d, want %d", random, n, r.ContentLength, size)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if len(body) != size {
+			t.Errorf("random=%v: read %d bytes, want %d", random, len(body), size)
+		}
+		zero := bytes.Count(body, []byte{0}) == len(body)
+		if zero == random {
+			t.Errorf("random=%v: body all zeros = %v", random, zero)
+		}
+		again, _ := r.GetBody()
+		if replay, _ := io.ReadAll(again); !bytes.Equal(replay, body) {
+			t.Errorf("random=%v: GetBody does not replay the body", random)
+		}
+	}
+}
+
+// benchmarkSlotBody sends a static body the way a pipeline slot does, with
+// the transport's side played by draining and closing the body.
+func benchmarkSlotBody(b *testing.B, shared bool) {
+	reqs := newRequestBuilder(Config{Method: "POST", URL: "http://example.com/", Body: bytes.Repeat([]byte("x"), 4096)})
+	ctx := context.Background()
+	req, _, _ := reqs.build(ctx, nil)
+	body := newSharedBody(reqs.body)
+	b.ReportAllocs()
+	for b.Loop() {
+		r := req
+		if shared {
+			r = body.attach(req)
+		} else {
+			r, _, _ = reqs.build(ctx, nil)
+		}
+		_, _ = io.Copy(io.Discard, r.Body)
+		_ = r.Body.Close()
+	}
+}
+
+func BenchmarkSlotBody_Rebuild(b *testing.B) { benchmarkSlotBody(b, false) }
+func BenchmarkSlotBody_Shared(b *testing.B)  { benchmarkSlotBody(b, true) }
+
+func TestSharedBody_RewindsOnlyOnceClosed(t *testing.T) {
+	body := newSharedBody([]byte("payload"))
+	first, _ := body.open()
+	if first != body {
+		t.Fatal("a fresh body should hand out the shared reader")
+	}
+	// Still being sent: the next send must not touch the shared reader.
+	second, _ := body.open()
+	if second == body {
+		t.Fatal("the shared reader was handed out while in use")
+	}
+	if got, _ := io.ReadAll(second); string(got) != "payload" {
+		t.Errorf("fresh reader read %q", got)
+	}
+	_, _ = io.ReadAll(first)
+	_ = first.Close()
+	third, _ := body.open()
+	if third != body {
+		t.Fatal("a closed body should be rewound and reused")
+	}
+	if got, _ := io.ReadAll(third); string(got) != "payload" {
+		t.Errorf("rewound reader read %q", got)
+	}
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	mathrand "math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Retry backoff strategies for Config.RetryBackoff.
+const (
+	BackoffConstant    = "constant"    // wait RetryDelay before every retry
+	BackoffExponential = "exponential" // double the wait on each retry, up to maxRetryDelay
+)
+
+const (
+	defaultRetryDelay = 100 * time.Millisecond
+	maxRetryDelay     = 10 * time.Second
+)
+
+// retryable reports whether a failed attempt of r is worth sending again:
+// transport errors and 5xx responses. Responses that arrived but failed a
+// user check (headers, body hash, grpc-status) are final. Unless anyMethod,
+// a request that is not idempotent is only retried if it never left the
+// client, since the server may already have acted on it.
+func retryable(r *http.Request, err error, resp *http.Response, anyMethod bool) bool {
+	if err == nil && (resp == nil || resp.StatusCode < 500) {
+		return false
+	}
+	return anyMethod || idempotent(r) || err != nil && notSent(err)
+}
+
+// idempotent reports whether sending r twice has the same effect as once:
+// GET, HEAD, OPTIONS, TRACE, PUT and DELETE, plus requests carrying an
+// Idempotency-Key header, as net/http's own retries treat them.
+func idempotent(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return r.Header.Get("Idempotency-Key") != "" || r.Header.Get("X-Idempotency-Key") != ""
+}
+
+// notSent reports whether err happened before any of the request was
+// written, i.e. while dialing.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryDelay returns how long to wait before retry n (1 for the first retry)
+// under cfg's backoff. With RetryJitter the delay is drawn from [d/2, d] with
+// the slot's seeded RNG, so retries from many slots do not line up but the
+// backoff still grows.
+func retryDelay(cfg Config, n int, rng *mathrand.Rand) time.Duration {
+	d := cfg.RetryDelay
+	if cfg.RetryBackoff == BackoffExponential {
+		for i := 1; i < n && d < maxRetryDelay; i++ {
+			d *= 2
+		}
+		d = min(d, maxRetryDelay)
+	}
+	if cfg.RetryJitter && d > 1 {
+		d = d/2 + time.Duration(rng.Int64N(int64(d-d/2)+1))
+	}
+	return d
+}
+
+// sleepUnlessStopped waits d, e.g. before a retry. It returns false without
+// waiting out d if the run is stopping, in which case a retry's last attempt
+// is final.
+func sleepUnlessStopped(ctx context.Context, durationDone <-chan struct{}, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-durationDone:
+		return false
+	}
+}
+
+// rewind returns r ready to be sent again. Requests with a body get a fresh
+// reader; the consumed one cannot be replayed.
+func rewind(r *http.Request) (*http.Request, error) {
+	if r.GetBody == nil {
+		return r, nil
+	}
+	body, err := r.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r = r.Clone(r.Context())
+	r.Body = body
+	return r, nil
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay_Strategies(t *testing.T) {
+	const base = 100 * time.Millisecond
+	delays := func(cfg Config, n int) []time.Duration {
+		rng := newSlotRand(1, 0)
+		var out []time.Duration
+		for i := 1; i <= n; i++ {
+			out = append(out, retryDelay(cfg, i, rng))
+		}
+		return out
+	}
+
+	constant := delays(Config{RetryDelay: base, RetryBackoff: BackoffConstant}, 4)
+	if want := []time.Duration{base, base, base, base}; !slices.Equal(constant, want) {
+		t.Errorf("constant: got %v, want %v", constant, want)
+	}
+
+	exp := delays(Config{RetryDelay: base, RetryBackoff: BackoffExponential}, 9)
+	want := []time.Duration{base, 2 * base, 4 * base, 8 * base, 16 * base, 32 * base, 64 * base, maxRetryDelay, maxRetryDelay}
+	if !slices.Equal(exp, want) {
+		t.Errorf("exponential: got %v, want %v", exp, want)
+	}
+
+	cfg := Config{RetryDelay: base, RetryBackoff: BackoffExponential, RetryJitter: true}
+	jittered := delays(cfg, 9)
+	for i, d := range jittered {
+		if d < want[i]/2 || d > want[i] {
+			t.Errorf("jittered retry %d: %v outside [%v, %v]", i+1, d, want[i]/2, want[i])
+		}
+	}
+	if slices.Equal(jittered, want) {
+		t.Error("jitter did not change any delay")
+	}
+	if again := delays(cfg, 9); !slices.Equal(again, jittered) {
+		t.Errorf("jitter is not reproducible from the seed: %v vs %v", again, jittered)
+	}
+}
+
+func TestSleepRetry_StopsWithRun(t *testing.T) {
+	done := make(chan struct{})
+	close(done)
+	start := time.Now()
+	if sleepUnlessStopped(t.Context(), done, time.Minute) {
+		t.Error("sleepUnlessStopped should give up once the duration is over")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("sleepUnlessStopped waited out the backoff after the run ended")
+	}
+}
+
+func TestRetryable_IdempotencyGuard(t *testing.T) {
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable}
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	keyed := httptest.NewRequest("POST", "/", nil)
+	keyed.Header.Set("Idempotency-Key", "k1")
+
+	tests := []struct {
+		name      string
+		r         *http.Request
+		err       error
+		resp      *http.Response
+		anyMethod bool
+		want      bool
+	}{
+		{"GET 503", httptest.NewRequest("GET", "/", nil), nil, unavailable, false, true},
+		{"PUT read error", httptest.NewRequest("PUT", "/", nil), readErr, nil, false, true},
+		{"GET 404", httptest.NewRequest("GET", "/", nil), nil, &http.Response{StatusCode: 404}, false, false},
+		{"POST 503", httptest.NewRequest("POST", "/", nil), nil, unavailable, false, false},
+		{"PATCH read error", httptest.NewRequest("PATCH", "/", nil), readErr, nil, false, false},
+		{"POST dial error", httptest.NewRequest("POST", "/", nil), dialErr, nil, false, true},
+		{"POST with Idempotency-Key", keyed, nil, unavailable, false, true},
+		{"POST 503 any method", httptest.NewRequest("POST", "/", nil), nil, unavailable, true, true},
+	}
+	for _, tt := range tests {
+		if got := retryable(tt.r, tt.err, tt.resp, tt.anyMethod); got != tt.want {
+			t.Errorf("%s: retryable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
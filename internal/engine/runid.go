@@ -0,0 +1,10 @@
+package engine
+
+// RunIDHeader carries Config.RunID on every request, so all the requests of
+// one run can be found in the server's logs.
+const RunIDHeader = "X-Benchmark-Run"
+
+// NewRunID returns a fresh run ID, a random UUID.
+func NewRunID() string {
+	return newUUID()
+}
@@ -0,0 +1,379 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// scheduler paces request starts. Slots call wait before every request; a nil
+// scheduler means each slot starts its next request as soon as the previous
+// one completes (closed loop).
+type scheduler interface {
+	// wait blocks until the next request may start. It returns false when the
+	// run is stopping and the slot should exit.
+	wait(ctx context.Context, durationDone <-chan struct{}) bool
+}
+
+// newScheduler returns the scheduler selected by cfg, or nil for closed loop.
+// Schedulers that release work on their own clock are started here and stop
+// with ctx or durationDone.
+func newScheduler(ctx context.Context, durationDone <-chan struct{}, cfg Config) scheduler {
+	if cfg.AdaptiveRate {
+		s := newAdaptiveScheduler(cfg.TargetP99)
+		go s.run(ctx, durationDone, cfg.AdaptiveInterval)
+		return s
+	}
+	if cfg.Burst > 0 {
+		s := newBurstScheduler(cfg.Burst)
+		go s.run(ctx, durationDone, cfg.BurstInterval)
+		return s
+	}
+	if cfg.Rate > 0 {
+		return newRateScheduler(cfg.Rate)
+	}
+	if cfg.ReplaySpeed > 0 {
+		if s := newReplayScheduler(cfg.Replay, cfg.ReplaySpeed); s != nil {
+			return s
+		}
+	}
+	return nil
+}
+
+// burstScheduler releases size request starts at once every interval and
+// nothing in between, producing a square wave of concurrency.
+type burstScheduler struct {
+	size   int
+	tokens chan struct{}
+}
+
+func newBurstScheduler(size int) *burstScheduler {
+	return &burstScheduler{size: size, tokens: make(chan struct{}, size)}
+}
+
+// run releases the first burst immediately and then one per interval.
+func (s *burstScheduler) run(ctx context.Context, durationDone <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.release()
+		select {
+		case <-ticker.C:
+		case <-durationDone:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// release discards tokens left over from the previous burst (slots that were
+// still busy) so bursts never merge, then issues a fresh burst.
+func (s *burstScheduler) release() {
+drain:
+	for {
+		select {
+		case <-s.tokens:
+		default:
+			break drain
+		}
+	}
+	for i := 0; i < s.size; i++ {
+		s.tokens <- struct{}{}
+	}
+}
+
+func (s *burstScheduler) wait(ctx context.Context, durationDone <-chan struct{}) bool {
+	select {
+	case <-s.tokens:
+		return true
+	case <-durationDone:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// latencyObserver is implemented by schedulers that adapt to observed request
+// latency. Slots report every completed request to it.
+type latencyObserver interface {
+	observe(latency time.Duration)
+}
+
+// rateScheduler spaces request starts evenly at a run-wide rate shared by all
+// slots. Starts are reserved one interval apart, so idle time is not saved up
+// into a burst. The rate can be changed while the run is in progress.
+type rateScheduler struct {
+	mu      sync.Mutex
+	rate    float64 // requests per second
+	next    time.Time
+	paced   int           // reservations that had to wait, i.e. the rate was binding
+	changed chan struct{} // closed by setRate to wake slots holding old reservations
+}
+
+func newRateScheduler(rate float64) *rateScheduler {
+	return &rateScheduler{rate: rate, changed: make(chan struct{})}
+}
+
+// setRate changes the rate and drops outstanding reservations: waiting slots
+// wake up and reserve again at the new spacing.
+func (s *rateScheduler) setRate(rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rate == s.rate {
+		return
+	}
+	s.rate = rate
+	if now := time.Now(); s.next.After(now) {
+		s.next = now
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// takeStats returns the current rate and how many reservations were paced
+// since the last call.
+func (s *rateScheduler) takeStats() (rate float64, paced int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rate, paced = s.rate, s.paced
+	s.paced = 0
+	return rate, paced
+}
+
+// reserve returns the start time of the next request, and a channel closed if
+// the reservation is invalidated by a rate change.
+func (s *rateScheduler) reserve() (time.Time, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	at := s.next
+	if at.Before(now) {
+		at = now
+	} else {
+		s.paced++
+	}
+	s.next = at.Add(time.Duration(float64(time.Second) / s.rate))
+	return at, s.changed
+}
+
+func (s *rateScheduler) wait(ctx context.Context, durationDone <-chan struct{}) bool {
+	for {
+		at, changed := s.reserve()
+		d := time.Until(at)
+		if d <= 0 {
+			return true
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+			return true
+		case <-changed:
+			timer.Stop()
+		case <-durationDone:
+			timer.Stop()
+			return false
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+	}
+}
+
+// Adaptive rate control parameters.
+const (
+	adaptiveStartRate = 10   // req/s before the first adjustment
+	adaptiveMinRate   = 1    // never pace below this
+	adaptiveIncrease  = 0.05 // additive step, as a fraction of the current rate
+	adaptiveDecrease  = 0.75 // multiplicative back-off factor
+	adaptiveGoodKeep  = 5    // intervals averaged into the sustained rate
+)
+
+// adaptiveScheduler searches for the highest rate that keeps p99 latency at
+// or under a target. Each interval it computes p99 over the requests that
+// completed in that interval and adjusts the pacing rate AIMD-style: it
+// doubles while probing (slow start) until the first breach, then adds a
+// small step while under target and backs off multiplicatively above it.
+type adaptiveScheduler struct {
+	*rateScheduler
+	target time.Duration
+
+	mu        sync.Mutex
+	window    []time.Duration // latencies completed in the current interval
+	probing   bool
+	good      []float64 // achieved rates of recent intervals under target
+	sustained float64
+}
+
+func newAdaptiveScheduler(target time.Duration) *adaptiveScheduler {
+	return &adaptiveScheduler{
+		rateScheduler: newRateScheduler(adaptiveStartRate),
+		target:        target,
+		probing:       true,
+	}
+}
+
+func (s *adaptiveScheduler) observe(latency time.Duration) {
+	s.mu.Lock()
+	s.window = append(s.window, latency)
+	s.mu.Unlock()
+}
+
+func (s *adaptiveScheduler) run(ctx context.Context, durationDone <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.adjust(interval)
+		case <-durationDone:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// adjust runs one step of the control loop over the last interval.
+func (s *adaptiveScheduler) adjust(interval time.Duration) {
+	s.mu.Lock()
+	window := s.window
+	s.window = nil
+	s.mu.Unlock()
+	if len(window) == 0 {
+		return
+	}
+	slices.Sort(window)
+	p99 := window[(len(window)*99+99)/100-1] // nearest-rank p99
+	achieved := float64(len(window)) / interval.Seconds()
+	rate, paced := s.takeStats()
+
+	if p99 > s.target {
+		s.mu.Lock()
+		s.probing = false
+		s.mu.Unlock()
+		s.setRate(max(rate*adaptiveDecrease, adaptiveMinRate))
+		return
+	}
+
+	s.mu.Lock()
+	s.good = append(s.good, achieved)
+	if len(s.good) > adaptiveGoodKeep {
+		s.good = s.good[1:]
+	}
+	var sum float64
+	for _, r := range s.good {
+		sum += r
+	}
+	s.sustained = sum / float64(len(s.good))
+	probing := s.probing
+	s.mu.Unlock()
+
+	// If no slot had to wait, the slots (not the rate) are the limit, and
+	// raising the rate further would change nothing.
+	if paced == 0 {
+		return
+	}
+	if probing {
+		s.setRate(rate * 2)
+	} else {
+		s.setRate(rate + max(rate*adaptiveIncrease, 1))
+	}
+}
+
+// sustainedRate is the mean achieved rate over the most recent intervals that
+// met the target, or 0 if none did.
+func (s *adaptiveScheduler) sustainedRate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sustained
+}
+
+// slotScheduler is implemented by schedulers that treat slots differently;
+// worker hands each slot its own view of the scheduler.
+type slotScheduler interface {
+	forSlot(slot int) scheduler
+}
+
+// slotLimiter lets only the first limit of a run's slots send; the others
+// wait for the limit to rise. Slots it lets through then ask the scheduler
+// it wraps, if any, for their turn.
+type slotLimiter struct {
+	slots int
+	inner scheduler // nil for closed loop
+
+	mu     sync.Mutex
+	limit  int
+	lowest int
+	raised chan struct{} // closed, and replaced, when limit rises
+}
+
+func newSlotLimiter(slots, limit int, inner scheduler) *slotLimiter {
+	return &slotLimiter{slots: slots, inner: inner, limit: limit, lowest: limit, raised: make(chan struct{})}
+}
+
+// state returns the current limit, the lowest it went and a channel closed
+// when it rises.
+func (l *slotLimiter) state() (limit, lowest int, raised <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit, l.lowest, l.raised
+}
+
+// setLimit changes the limit, kept between 1 and the number of slots, and
+// wakes the waiting slots if it rose.
+func (l *slotLimiter) setLimit(limit int) {
+	limit = min(max(limit, 1), l.slots)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rose := limit > l.limit
+	l.limit = limit
+	l.lowest = min(l.lowest, limit)
+	if rose {
+		close(l.raised)
+		l.raised = make(chan struct{})
+	}
+}
+
+func (l *slotLimiter) forSlot(slot int) scheduler {
+	return limitedSlot{limiter: l, slot: slot}
+}
+
+// wait is slot 0's, which the limit never holds back.
+func (l *slotLimiter) wait(ctx context.Context, durationDone <-chan struct{}) bool {
+	return l.forSlot(0).wait(ctx, durationDone)
+}
+
+func (l *slotLimiter) observe(latency time.Duration) {
+	if o, ok := l.inner.(latencyObserver); ok {
+		o.observe(latency)
+	}
+}
+
+// limitedSlot is one slot's view of a slotLimiter.
+type limitedSlot struct {
+	limiter *slotLimiter
+	slot    int
+}
+
+func (s limitedSlot) wait(ctx context.Context, durationDone <-chan struct{}) bool {
+	for {
+		limit, _, raised := s.limiter.state()
+		if s.slot < limit {
+			break
+		}
+		select {
+		case <-raised:
+		case <-durationDone:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return s.limiter.inner == nil || s.limiter.inner.wait(ctx, durationDone)
+}
+
+func (s limitedSlot) observe(latency time.Duration) {
+	s.limiter.observe(latency)
+}
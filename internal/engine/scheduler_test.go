@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBurstScheduler_ReleaseDoesNotAccumulate(t *testing.T) {
+	s := newBurstScheduler(3)
+	s.release()
+	<-s.tokens // one slot took its token; two are left over
+	s.release()
+	if got := len(s.tokens); got != 3 {
+		t.Errorf("tokens after second burst: got %d, want 3", got)
+	}
+}
+
+func TestBurstScheduler_WaitStops(t *testing.T) {
+	s := newBurstScheduler(1)
+	done := make(chan struct{})
+	close(done)
+	if s.wait(context.Background(), done) {
+		t.Error("wait should return false once the duration is over")
+	}
+	s.release()
+	if !s.wait(context.Background(), make(chan struct{})) {
+		t.Error("wait should return true when a token is available")
+	}
+}
+
+func TestRateScheduler_WaitStopsOnCancel(t *testing.T) {
+	s := newRateScheduler(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	if !s.wait(ctx, make(chan struct{})) {
+		t.Fatal("the first start should not wait")
+	}
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	if s.wait(ctx, make(chan struct{})) {
+		t.Error("wait should return false once ctx is cancelled")
+	}
+	if took := time.Since(start); took > 500*time.Millisecond {
+		t.Errorf("wait took %s after a cancel at 20ms; the next start was 1s away", took)
+	}
+}
+
+func TestNewOrchestrator_BurstDefaults(t *testing.T) {
+	cfg := NewOrchestrator(Config{URL: "http://x", Workers: 2, Pipeline: 1, Burst: 5}, nil).Config()
+	if cfg.BurstInterval <= 0 {
+		t.Errorf("BurstInterval default not applied: %v", cfg.BurstInterval)
+	}
+	if cfg.Workers*cfg.Pipeline < 5 {
+		t.Errorf("slots (%d) must fit the burst", cfg.Workers*cfg.Pipeline)
+	}
+}
+
+func TestAdaptiveScheduler_AIMD(t *testing.T) {
+	s := newAdaptiveScheduler(10 * time.Millisecond)
+	step := func(latency time.Duration, paced bool) float64 {
+		for i := 0; i < 10; i++ {
+			s.observe(latency)
+		}
+		if paced {
+			s.reserve()
+			s.reserve() // the second reservation lies in the future
+		}
+		s.adjust(time.Second)
+		rate, _ := s.takeStats()
+		return rate
+	}
+
+	if got := step(time.Millisecond, true); got != 2*adaptiveStartRate {
+		t.Errorf("probing under target should double: got %v", got)
+	}
+	if got := step(time.Millisecond, false); got != 2*adaptiveStartRate {
+		t.Errorf("rate must not grow when it is not the limit: got %v", got)
+	}
+	if got := step(50*time.Millisecond, true); got != 2*adaptiveStartRate*adaptiveDecrease {
+		t.Errorf("a breach should back off multiplicatively: got %v", got)
+	}
+	if got := step(time.Millisecond, true); got != 2*adaptiveStartRate*adaptiveDecrease+1 {
+		t.Errorf("after the first breach growth should be additive: got %v", got)
+	}
+	if s.sustainedRate() != 10 {
+		t.Errorf("sustained rate averages achieved rates under target: got %v", s.sustainedRate())
+	}
+}
+
+func TestBackoffLimiter_AIMD(t *testing.T) {
+	b := newBackoffLimiter(40, nil)
+	step := func(requests, connErrors uint64) int {
+		b.adjust(requests, connErrors)
+		limit, _, _ := b.state()
+		return limit
+	}
+
+	if got := step(100, 5); got != 40 {
+		t.Errorf("errors at the threshold should not back off: got %d", got)
+	}
+	if got := step(100, 50); got != 20 {
+		t.Errorf("a spike should halve the slots: got %d", got)
+	}
+	if got := step(0, 0); got != 20 {
+		t.Errorf("an interval without completions should change nothing: got %d", got)
+	}
+	if got := step(100, 0); got != 24 {
+		t.Errorf("a quiet interval should add a tenth of the slots: got %d", got)
+	}
+	for range 10 {
+		step(100, 0)
+	}
+	if limit, lowest, _ := b.state(); limit != 40 || lowest != 20 {
+		t.Errorf("limit %d, lowest %d: want all 40 slots back, lowest 20", limit, lowest)
+	}
+}
+
+func TestBackoffSlot_WaitsForTheLimit(t *testing.T) {
+	b := newBackoffLimiter(4, nil)
+	b.adjust(10, 10) // down to 2 slots
+	if !b.forSlot(1).wait(context.Background(), nil) {
+		t.Fatal("slot 1 is under the limit and should not wait")
+	}
+	done := make(chan bool)
+	go func() { done <- b.forSlot(3).wait(context.Background(), nil) }()
+	select {
+	case <-done:
+		t.Fatal("slot 3 is over the limit and should wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+	b.adjust(10, 0) // back up to 3 slots
+	b.adjust(10, 0) // and 4
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Error("wait returned false although the run goes on")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slot 3 still waits after the limit rose above it")
+	}
+}
@@ -0,0 +1,21 @@
+package engine
+
+import (
+	mathrand "math/rand/v2"
+)
+
+// newSeed picks a random non-zero run seed for configs that leave Seed unset.
+func newSeed() uint64 {
+	for {
+		if s := mathrand.Uint64(); s != 0 {
+			return s
+		}
+	}
+}
+
+// newSlotRand returns the RNG for one pipeline slot. Each slot has its own
+// stream derived from the run seed and its index, so choices are reproducible
+// for a given seed and slots never contend on a shared source.
+func newSlotRand(seed uint64, slot int) *mathrand.Rand {
+	return mathrand.New(mathrand.NewPCG(seed, uint64(slot)))
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"cmp"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// shardPointsPerTarget is how many points an average-weight target gets on
+// the hash ring. More points even out the shares at the cost of a larger
+// ring to search.
+const shardPointsPerTarget = 160
+
+// shardRing maps keys to targets by consistent hashing (Config.ShardKey):
+// every target owns points on a ring of 64-bit hashes in proportion to its
+// weight, and a key goes to the owner of the first point at or after its
+// hash. The same key always lands on the same target, and the mapping
+// depends only on the targets, not on the run.
+type shardRing struct {
+	points []uint64 // sorted
+	owners []int    // index into Config.Targets of each point
+}
+
+func newShardRing(targets []Target) *shardRing {
+	var total float64
+	for _, t := range targets {
+		total += t.Weight
+	}
+	type point struct {
+		hash  uint64
+		owner int
+	}
+	var points []point
+	for i, t := range targets {
+		n := max(1, int(math.Round(t.Weight/total*float64(shardPointsPerTarget*len(targets)))))
+		for j := range n {
+			points = append(points, point{shardHash(t.URL + "#" + strconv.Itoa(j)), i})
+		}
+	}
+	slices.SortFunc(points, func(a, b point) int {
+		return cmp.Or(cmp.Compare(a.hash, b.hash), cmp.Compare(a.owner, b.owner))
+	})
+	r := &shardRing{points: make([]uint64, len(points)), owners: make([]int, len(points))}
+	for i, p := range points {
+		r.points[i], r.owners[i] = p.hash, p.owner
+	}
+	return r
+}
+
+// pick returns the index of the target key maps to.
+func (r *shardRing) pick(key string) int {
+	i, _ := slices.BinarySearch(r.points, shardHash(key))
+	if i == len(r.points) {
+		i = 0 // past the last point: wrap around the ring
+	}
+	return r.owners[i]
+}
+
+// shardHash is FNV-1a with a final mix, so keys that differ only in their
+// last characters ("1", "2", ...) still spread over the whole ring.
+func shardHash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
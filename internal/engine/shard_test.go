@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestShardRing_StickyAndEven(t *testing.T) {
+	targets := []Target{
+		{URL: "http://shard-a/", Weight: 1},
+		{URL: "http://shard-b/", Weight: 1},
+		{URL: "http://shard-c/", Weight: 1},
+		{URL: "http://shard-d/", Weight: 1},
+	}
+	ring := newShardRing(targets)
+	again := newShardRing(targets)
+
+	const keys = 20000
+	counts := make([]int, len(targets))
+	for k := range keys {
+		key := strconv.Itoa(k + 1)
+		i := ring.pick(key)
+		if j := ring.pick(key); j != i {
+			t.Fatalf("key %s went to target %d, then %d", key, i, j)
+		}
+		if j := again.pick(key); j != i {
+			t.Fatalf("key %s went to target %d on one ring and %d on an identical one", key, i, j)
+		}
+		counts[i]++
+	}
+	for i, n := range counts {
+		if share := float64(n) / keys; math.Abs(share-0.25) > 0.05 {
+			t.Errorf("target %d got %.1f%% of the keys, want about 25%% (all: %v)", i, share*100, counts)
+		}
+	}
+}
+
+func TestShardRing_Weights(t *testing.T) {
+	ring := newShardRing([]Target{{URL: "http://big/", Weight: 3}, {URL: "http://small/", Weight: 1}})
+	big := 0
+	const keys = 20000
+	for k := range keys {
+		if ring.pick("user-"+strconv.Itoa(k)) == 0 {
+			big++
+		}
+	}
+	if share := float64(big) / keys; share < 0.68 || share > 0.82 {
+		t.Errorf("weight 3 of 4 got %.1f%% of the keys, want about 75%%", share*100)
+	}
+}
+
+// TestShardRing_AddingTargetMovesFewKeys checks the point of consistent
+// hashing: a new shard takes its share of keys, and only from the others.
+func TestShardRing_AddingTargetMovesFewKeys(t *testing.T) {
+	three := []Target{{URL: "http://a/", Weight: 1}, {URL: "http://b/", Weight: 1}, {URL: "http://c/", Weight: 1}}
+	before := newShardRing(three)
+	after := newShardRing(append(three, Target{URL: "http://d/", Weight: 1}))
+
+	moved := 0
+	const keys = 10000
+	for k := range keys {
+		key := strconv.Itoa(k)
+		i, j := before.pick(key), after.pick(key)
+		if i != j {
+			if j != 3 {
+				t.Fatalf("key %s moved from target %d to %d, not to the new one", key, i, j)
+			}
+			moved++
+		}
+	}
+	if share := float64(moved) / keys; share < 0.15 || share > 0.35 {
+		t.Errorf("%.1f%% of keys moved, want about 25%%", share*100)
+	}
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	mathrand "math/rand/v2"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// LatencyDist is a synthetic latency distribution for Config.SimulateLatency.
+//
+//	const    always A
+//	uniform  uniformly between A and B
+//	normal   mean A, standard deviation B, clamped at 0
+//	exp      exponential with mean A
+type LatencyDist struct {
+	Kind string
+	A, B time.Duration
+}
+
+func (d LatencyDist) String() string {
+	switch d.Kind {
+	case "uniform", "normal":
+		return fmt.Sprintf("%s:%s,%s", d.Kind, d.A, d.B)
+	}
+	return fmt.Sprintf("%s:%s", d.Kind, d.A)
+}
+
+// sample draws one latency from the distribution.
+func (d LatencyDist) sample(rng *mathrand.Rand) time.Duration {
+	switch d.Kind {
+	case "uniform":
+		return d.A + time.Duration(rng.Int64N(int64(d.B-d.A)+1))
+	case "normal":
+		return max(0, d.A+time.Duration(rng.NormFloat64()*float64(d.B)))
+	case "exp":
+		return time.Duration(rng.ExpFloat64() * float64(d.A))
+	}
+	return d.A
+}
+
+// runSimulatedSlot is runPipelineSlot without the network: each iteration
+// draws a latency from cfg.SimulateLatency, waits that long as if a server
+// had answered, and records it as a successful request. It exercises the
+// scheduler, collector and renderers exactly like a real run.
+func runSimulatedSlot(
+	ctx context.Context,
+	durationDone <-chan struct{},
+	cfg Config,
+	sched scheduler,
+	rng *mathrand.Rand,
+	collector *stats.Collector,
+) {
+	observer, _ := sched.(latencyObserver)
+	timer := time.NewTimer(0)
+	<-timer.C
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-durationDone:
+			return
+		default:
+		}
+		if sched != nil && !sched.wait(ctx, durationDone) {
+			return
+		}
+
+		latency := cfg.SimulateLatency.sample(rng)
+		start := time.Now()
+		collector.RequestStarted()
+		timer.Reset(latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			collector.RequestFinished()
+			if context.Cause(ctx) == errDrainTimeout {
+				record(collector, cfg.OnResult, stats.Result{Start: start, Abandoned: true})
+			}
+			return
+		}
+		collector.RequestFinished()
+		record(collector, cfg.OnResult, stats.Result{Start: start, Latency: latency, Success: true})
+		if observer != nil {
+			observer.observe(latency)
+		}
+	}
+}
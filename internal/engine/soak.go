@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"runtime"
+
+	"github.com/thetangentline/httpcl/internal/ui"
+)
+
+// soakSampler reads the process's runtime stats for soak reports and
+// remembers the previous reading for the deltas.
+type soakSampler struct {
+	reports    int
+	heapAlloc  uint64
+	goroutines int
+	numGC      uint32
+}
+
+// newSoakSampler takes the baseline the first report is compared with.
+func newSoakSampler() *soakSampler {
+	s := &soakSampler{}
+	s.sample()
+	s.reports = 0
+	return s
+}
+
+// sample reads the runtime stats. ReadMemStats stops the world briefly,
+// which is fine at soak-report intervals but not on every tick.
+func (s *soakSampler) sample() ui.RuntimeStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	g := runtime.NumGoroutine()
+	s.reports++
+	rt := ui.RuntimeStats{
+		Report:         s.reports,
+		HeapAlloc:      m.HeapAlloc,
+		HeapDelta:      int64(m.HeapAlloc) - int64(s.heapAlloc),
+		Goroutines:     g,
+		GoroutineDelta: g - s.goroutines,
+		NumGC:          m.NumGC - s.numGC,
+	}
+	s.heapAlloc, s.goroutines, s.numGC = m.HeapAlloc, g, m.NumGC
+	return rt
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// statsdInterval is how often live metrics go out to StatsD.
+const statsdInterval = time.Second
+
+// StatsD sends live metrics to a StatsD (or DogStatsD) server over UDP.
+type StatsD struct {
+	Addr   string   // host:port
+	Prefix string   // prepended to every metric name, e.g. "httpcl"
+	Tags   []string // DogStatsD tags such as "env:staging", appended as |#tags
+}
+
+// statsdSender turns snapshots into StatsD lines: requests and errors as
+// counters of what happened since the last send, rate, latency and
+// in-flight requests as gauges. Only the orchestrator's poll loop and,
+// after it stopped, the final report use it.
+type statsdSender struct {
+	conn   net.Conn
+	prefix string
+	suffix string // "|#tags", or ""
+
+	lastRequests uint64
+	lastErrors   uint64
+	lastDuration time.Duration
+	lastSent     time.Time
+}
+
+func newStatsDSender(cfg *StatsD) (*statsdSender, error) {
+	conn, err := net.Dial("udp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+	s := &statsdSender{conn: conn, prefix: cfg.Prefix}
+	if s.prefix != "" && !strings.HasSuffix(s.prefix, ".") {
+		s.prefix += "."
+	}
+	if len(cfg.Tags) > 0 {
+		s.suffix = "|#" + strings.Join(cfg.Tags, ",")
+	}
+	return s, nil
+}
+
+// due reports whether statsdInterval has passed since the last send.
+func (s *statsdSender) due(now time.Time) bool {
+	return now.Sub(s.lastSent) >= statsdInterval
+}
+
+// send emits snap as one datagram. Delivery is best effort, as usual for
+// StatsD: write errors, e.g. nobody listening, are ignored.
+func (s *statsdSender) send(snap stats.Snapshot) {
+	var rps float64
+	if elapsed := snap.Duration - s.lastDuration; elapsed > 0 {
+		rps = float64(snap.TotalRequests-s.lastRequests) / elapsed.Seconds()
+	}
+	ms := func(d time.Duration) string {
+		return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+	}
+
+	var b strings.Builder
+	line := func(name, value, kind string) {
+		b.WriteString(s.prefix + name + ":" + value + "|" + kind + s.suffix + "\n")
+	}
+	line("requests", strconv.FormatUint(snap.TotalRequests-s.lastRequests, 10), "c")
+	line("errors", strconv.FormatUint(snap.Errors-s.lastErrors, 10), "c")
+	line("rps", strconv.FormatFloat(rps, 'f', 1, 64), "g")
+	line("latency.p50_ms", ms(snap.LatencyP50), "g")
+	line("latency.p99_ms", ms(snap.LatencyP99), "g")
+	line("in_flight", strconv.FormatInt(snap.InFlight, 10), "g")
+	_, _ = s.conn.Write([]byte(strings.TrimSuffix(b.String(), "\n")))
+
+	s.lastRequests, s.lastErrors, s.lastDuration = snap.TotalRequests, snap.Errors, snap.Duration
+	s.lastSent = time.Now()
+}
+
+func (s *statsdSender) close() {
+	_ = s.conn.Close()
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// ConcurrencyStep is one level of a step load test (Config.ConcurrencySteps):
+// Concurrency slots send for Duration.
+type ConcurrencyStep struct {
+	Concurrency int
+	Duration    time.Duration
+}
+
+// validateSteps checks that every step has slots and a duration.
+func validateSteps(steps []ConcurrencyStep) error {
+	for i, s := range steps {
+		if s.Concurrency <= 0 || s.Duration <= 0 {
+			return fmt.Errorf("step %d: concurrency and duration must be positive, got %d for %s", i+1, s.Concurrency, s.Duration)
+		}
+	}
+	return nil
+}
+
+// stepRecorder drives a step load test: it sets the slot limit to each
+// step's concurrency in turn and gives every step a collector of its own,
+// so each is reported as if it were a run by itself. A result counts
+// towards the step during which it completed.
+type stepRecorder struct {
+	steps       []ConcurrencyStep
+	limiter     *slotLimiter
+	statsMemory uint64
+
+	current atomic.Pointer[stats.Collector]
+	mu      sync.Mutex
+	started time.Time // of the current step
+	done    []stats.LoadStep
+}
+
+func newStepRecorder(steps []ConcurrencyStep, limiter *slotLimiter, statsMemory uint64) *stepRecorder {
+	s := &stepRecorder{steps: steps, limiter: limiter, statsMemory: statsMemory}
+	s.start()
+	return s
+}
+
+// start begins the step after the last finished one.
+func (s *stepRecorder) start() {
+	s.limiter.setLimit(s.steps[len(s.done)].Concurrency)
+	s.started = time.Now()
+	s.current.Store(stats.NewCollector(stats.WithMemoryBudget(s.statsMemory)))
+}
+
+func (s *stepRecorder) record(r stats.Result) {
+	s.current.Load().RecordResult(r)
+}
+
+// run moves to the next step after each step's duration until the last
+// one, which finish ends once the load is over.
+func (s *stepRecorder) run(ctx context.Context, durationDone <-chan struct{}) {
+	for range len(s.steps) - 1 {
+		timer := time.NewTimer(s.steps[len(s.done)].Duration)
+		select {
+		case <-timer.C:
+		case <-durationDone:
+			timer.Stop()
+			return
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		s.mu.Lock()
+		s.finishLocked()
+		s.start()
+		s.mu.Unlock()
+	}
+}
+
+// finish ends the step in progress and returns every step reported so far.
+// Steps the run stopped before reaching are left out.
+func (s *stepRecorder) finish() []stats.LoadStep {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finishLocked()
+	return s.done
+}
+
+func (s *stepRecorder) finishLocked() {
+	snap := s.current.Load().Snapshot()
+	s.done = append(s.done, stats.LoadStep{
+		Concurrency:  s.steps[len(s.done)].Concurrency,
+		Duration:     time.Since(s.started),
+		Requests:     snap.TotalRequests,
+		Errors:       snap.Errors,
+		RequestsPerS: snap.RequestsPerSAvg,
+		LatencyP50:   snap.LatencyP50,
+		LatencyP99:   snap.LatencyP99,
+	})
+}
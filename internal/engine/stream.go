@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"io"
+	mathrand "math/rand/v2"
+)
+
+// streamBlockSize is the block a generated body repeats. Random blocks are
+// larger than a deflate window, so repeated content does not compress.
+const streamBlockSize = 64 << 10
+
+// streamBlock returns the block generated bodies repeat: zeros, or bytes
+// drawn from seed when random is set.
+func streamBlock(random bool, seed uint64) []byte {
+	block := make([]byte, streamBlockSize)
+	if random {
+		rng := mathrand.New(mathrand.NewPCG(seed, 0))
+		for i := 0; i < len(block); i += 8 {
+			v := rng.Uint64()
+			for j := 0; j < 8; j++ {
+				block[i+j] = byte(v >> (8 * j))
+			}
+		}
+	}
+	return block
+}
+
+// patternReader yields n bytes by repeating block, so a body of any size is
+// streamed without ever being held in memory.
+type patternReader struct {
+	block []byte
+	off   int
+	n     int64
+}
+
+func newPatternReader(block []byte, n int64) *patternReader {
+	return &patternReader{block: block, n: n}
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.n {
+		p = p[:r.n]
+	}
+	total := 0
+	for len(p) > 0 {
+		c := copy(p, r.block[r.off:])
+		r.off = (r.off + c) % len(r.block)
+		p = p[c:]
+		total += c
+	}
+	r.n -= int64(total)
+	return total, nil
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// DefaultLowRPSWarn is the Config.LowRPSWarn the CLI uses: a slot that
+// completes less than one request a second while most fail is not
+// measuring the server.
+const DefaultLowRPSWarn = 1.0
+
+// suspectErrorRate is the share of failed requests above which a slow run
+// is flagged by suspectResults.
+const suspectErrorRate = 0.5
+
+// suspectCauses are the likely causes named for the dominant error category.
+var suspectCauses = map[stats.ErrorCategory]string{
+	stats.ErrDNS:     "the host does not resolve",
+	stats.ErrConnect: "connections are refused or unreachable; is the server up?",
+	stats.ErrTLS:     "TLS handshakes fail; check the certificate or try --insecure",
+	stats.ErrTimeout: "requests time out; the server may be stalled or overloaded",
+	stats.ErrRead:    "the server closes or resets connections mid-response",
+	stats.ErrHTTP5xx: "the server answers with 5xx errors",
+}
+
+// suspectResults explains why a finished closed-loop run is unlikely to be
+// a valid measurement, or returns "": each slot completed fewer than
+// minRPSPerSlot requests a second and most of them failed (or none
+// completed at all). A non-positive minRPSPerSlot disables the check.
+func suspectResults(snap stats.Snapshot, slots int, minRPSPerSlot float64) string {
+	if minRPSPerSlot <= 0 || slots <= 0 || snap.Duration <= 0 {
+		return ""
+	}
+	perSlot := float64(snap.TotalRequests) / snap.Duration.Seconds() / float64(slots)
+	if perSlot >= minRPSPerSlot {
+		return ""
+	}
+	if snap.TotalRequests == 0 {
+		return fmt.Sprintf("no request completed in %s; the server may be stalled or unreachable", snap.Duration.Round(time.Millisecond))
+	}
+	errRate := float64(snap.Errors) / float64(snap.TotalRequests)
+	if errRate < suspectErrorRate {
+		return ""
+	}
+	msg := fmt.Sprintf("only %.2f req/s per slot and %.0f%% of %d requests failed", perSlot, errRate*100, snap.TotalRequests)
+	top, n := stats.ErrorCategory(""), uint64(0)
+	for _, cat := range stats.ErrorCategories() {
+		if c := snap.ErrorsByCategory[cat]; c > n {
+			top, n = cat, c
+		}
+	}
+	if cause, ok := suspectCauses[top]; ok {
+		msg += fmt.Sprintf(", mostly %s: %s", top, cause)
+	} else if top != "" {
+		msg += fmt.Sprintf(", mostly %s", top)
+	}
+	return msg
+}
@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+func TestSuspectResults(t *testing.T) {
+	failing := stats.Snapshot{
+		TotalRequests:    20,
+		Errors:           18,
+		Duration:         10 * time.Second,
+		ErrorsByCategory: map[stats.ErrorCategory]uint64{stats.ErrTimeout: 15, stats.ErrHTTP5xx: 3},
+	}
+	tests := []struct {
+		name  string
+		snap  stats.Snapshot
+		slots int
+		min   float64
+		want  string
+	}{
+		{"slow and failing", failing, 10, 1, "only 0.20 req/s per slot and 90% of 20 requests failed, mostly timeout: requests time out"},
+		{"nothing completed", stats.Snapshot{Duration: 5 * time.Second}, 4, 1, "no request completed in 5s"},
+		{"disabled", failing, 10, 0, ""},
+		{"fast enough per slot", failing, 1, 1, ""},
+		{"slow but healthy", stats.Snapshot{TotalRequests: 20, Errors: 2, Duration: 10 * time.Second}, 10, 1, ""},
+		{"uncategorised", stats.Snapshot{TotalRequests: 4, Errors: 4, Duration: 10 * time.Second, ErrorsByCategory: map[stats.ErrorCategory]uint64{stats.ErrValidation: 4}}, 2, 1, "mostly validation"},
+	}
+	for _, tt := range tests {
+		got := suspectResults(tt.snap, tt.slots, tt.min)
+		if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+			t.Errorf("%s: suspectResults = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Target is one URL of Config.Targets and its relative weight.
+type Target struct {
+	URL    string
+	Weight float64
+}
+
+// validateTargets checks that every target has a URL and a positive weight.
+func validateTargets(targets []Target) error {
+	for _, t := range targets {
+		if t.URL == "" {
+			return fmt.Errorf("target without a url")
+		}
+		if !(t.Weight > 0) {
+			return fmt.Errorf("target %s: weight must be positive, got %v", t.URL, t.Weight)
+		}
+	}
+	return nil
+}
+
+// targetShares returns the cumulative share of the total weight up to and
+// including each target; the last one is 1.
+func targetShares(targets []Target) []float64 {
+	var total float64
+	for _, t := range targets {
+		total += t.Weight
+	}
+	cum := make([]float64, len(targets))
+	var sum float64
+	for i, t := range targets {
+		sum += t.Weight
+		cum[i] = sum / total
+	}
+	cum[len(cum)-1] = 1
+	return cum
+}
+
+// pickTarget returns the index of the target that u, uniform in [0, 1),
+// falls on.
+func pickTarget(cum []float64, u float64) int {
+	i, _ := slices.BinarySearch(cum, u)
+	if i < len(cum) && cum[i] == u {
+		i++ // u == cum[i] belongs to the next target
+	}
+	return min(i, len(cum)-1)
+}
+
+// targetURLs returns the URLs of targets, in order.
+func targetURLs(targets []Target) []string {
+	urls := make([]string, len(targets))
+	for i, t := range targets {
+		urls[i] = t.URL
+	}
+	return urls
+}
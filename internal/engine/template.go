@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	mathrand "math/rand/v2"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Placeholders recognised in URL, body and header values. Each one is expanded
+// fresh for every request. Unknown {{...}} sequences are left untouched so
+// bodies that merely contain braces keep working.
+//
+//	{{uuid}}  random RFC 4122 version 4 UUID
+//	{{seq}}   run-wide request sequence number starting at 1
+//	{{rand}}  random non-negative int64
+//	{{now}}   current Unix time in milliseconds
+var placeholders = map[string]func(*templateVars) string{
+	"uuid": func(*templateVars) string { return newUUID() },
+	"seq":  func(v *templateVars) string { return strconv.FormatUint(v.seq.Add(1), 10) },
+	"rand": func(*templateVars) string { return strconv.FormatInt(mathrand.Int64(), 10) },
+	"now":  func(*templateVars) string { return strconv.FormatInt(time.Now().UnixMilli(), 10) },
+}
+
+// templateVars is the per-run state shared by all expansions.
+type templateVars struct {
+	seq atomic.Uint64
+}
+
+// template is a parsed string made of literal text and placeholders.
+type template struct {
+	parts []templatePart
+}
+
+type templatePart struct {
+	literal string
+	expand  func(*templateVars) string
+}
+
+// parseTemplate splits s into literal and placeholder parts. It returns nil if
+// s contains no known placeholder, so callers can keep static values on the
+// fast path.
+func parseTemplate(s string) *template {
+	var parts []templatePart
+	dynamic := false
+	rest := s
+	for {
+		open := strings.Index(rest, "{{")
+		if open < 0 {
+			break
+		}
+		end := strings.Index(rest[open:], "}}")
+		if end < 0 {
+			break
+		}
+		name := strings.TrimSpace(rest[open+2 : open+end])
+		fn, ok := placeholders[name]
+		if !ok {
+			parts = append(parts, templatePart{literal: rest[:open+end+2]})
+			rest = rest[open+end+2:]
+			continue
+		}
+		if open > 0 {
+			parts = append(parts, templatePart{literal: rest[:open]})
+		}
+		parts = append(parts, templatePart{expand: fn})
+		dynamic = true
+		rest = rest[open+end+2:]
+	}
+	if !dynamic {
+		return nil
+	}
+	if rest != "" {
+		parts = append(parts, templatePart{literal: rest})
+	}
+	return &template{parts: parts}
+}
+
+// expand renders the template for one request.
+func (t *template) expand(v *templateVars) string {
+	var sb strings.Builder
+	for _, p := range t.parts {
+		if p.expand != nil {
+			sb.WriteString(p.expand(v))
+		} else {
+			sb.WriteString(p.literal)
+		}
+	}
+	return sb.String()
+}
+
+// newUUID returns a random version 4 UUID string.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+	return string(out[:])
+}
@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestParseTemplate_StaticReturnsNil(t *testing.T) {
+	for _, s := range []string{"", "plain", `{"a":{"b":1}}`, "{{unknown}}"} {
+		if tmpl := parseTemplate(s); tmpl != nil {
+			t.Errorf("parseTemplate(%q) should be nil for a static value", s)
+		}
+	}
+}
+
+func TestTemplate_SeqIncrements(t *testing.T) {
+	vars := &templateVars{}
+	tmpl := parseTemplate("id-{{seq}}-{{unknown}}")
+	if tmpl == nil {
+		t.Fatal("expected a template")
+	}
+	if got := tmpl.expand(vars); got != "id-1-{{unknown}}" {
+		t.Errorf("first expansion: got %q", got)
+	}
+	if got := tmpl.expand(vars); got != "id-2-{{unknown}}" {
+		t.Errorf("second expansion: got %q", got)
+	}
+}
+
+func TestTemplate_UUIDFormat(t *testing.T) {
+	re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	got := parseTemplate("{{ uuid }}").expand(&templateVars{})
+	if !re.MatchString(got) {
+		t.Errorf("not a v4 UUID: %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// defaultMinRPSWarmup is how much of the load phase --min-rps ignores by
+// default while connections open and the server warms up.
+const defaultMinRPSWarmup = time.Second
+
+// ThroughputError is returned by Run when the sustained request rate after
+// Config.MinRPSWarmup fell below Config.MinRPS.
+type ThroughputError struct {
+	RPS    float64 // sustained rate after the warm-up
+	MinRPS float64 // the floor that was missed
+	Warmup time.Duration
+}
+
+func (e *ThroughputError) Error() string {
+	return fmt.Sprintf("run too slow: sustained %.1f req/s after a %s warm-up, below the %.1f req/s floor",
+		e.RPS, e.Warmup, e.MinRPS)
+}
+
+// sustainedRPS returns the request rate over the 1s buckets that start
+// after warmup and end within the load phase. ok is false when there are
+// none, i.e. the run ended before the warm-up did, so the rate says nothing
+// about steady state.
+func sustainedRPS(buckets []stats.Bucket, warmup, load time.Duration) (rps float64, ok bool) {
+	var reqs uint64
+	var window time.Duration
+	for _, b := range buckets {
+		if b.Start < warmup || b.Start+b.Duration > load {
+			continue
+		}
+		reqs += b.Requests
+		window += b.Duration
+	}
+	if window <= 0 {
+		return 0, false
+	}
+	return float64(reqs) / window.Seconds(), true
+}
+
+// checkThroughput returns a *ThroughputError if a measured rps is under
+// minRPS. A non-positive minRPS or an unmeasured rate passes.
+func checkThroughput(rps float64, measured bool, minRPS float64, warmup time.Duration) error {
+	if minRPS <= 0 || !measured || rps >= minRPS {
+		return nil
+	}
+	return &ThroughputError{RPS: rps, MinRPS: minRPS, Warmup: warmup}
+}
@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// certStatus describes the server certificate's expiry for the TLS
+// preflight step, and whether it is fine (ok) or fatal (failed); neither
+// means a warning.
+func certStatus(leaf *x509.Certificate, now time.Time, window time.Duration, insecure bool) (status string, ok, failed bool) {
+	date := leaf.NotAfter.UTC().Format(time.DateOnly)
+	left := leaf.NotAfter.Sub(now)
+	switch {
+	case left <= 0:
+		// Verification would fail every request; with --insecure it is
+		// only worth knowing.
+		return fmt.Sprintf("certificate expired on %s", date), false, !insecure
+	case left <= window:
+		return fmt.Sprintf("certificate expires on %s (in %s)", date, humanDays(left)), false, false
+	}
+	return fmt.Sprintf("OK (certificate valid until %s)", date), true, false
+}
+
+// humanDays formats d in days, or hours below two days.
+func humanDays(d time.Duration) string {
+	if d < 48*time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d days", int(d.Hours()/24))
+}
+
+// peerCertificate connects to an https rawURL the way the client would,
+// honoring --resolve pins, and returns the server's leaf certificate. It
+// does not verify the chain: an expired certificate must still be readable.
+// Targets behind a proxy are not checked (nil, nil).
+func peerCertificate(ctx context.Context, cfg Config, rawURL string) (*x509.Certificate, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" {
+		return nil, err
+	}
+	if proxy, err := http.ProxyFromEnvironment(&http.Request{URL: u}); err == nil && proxy != nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout+tlsHandshakeTimeout)
+	defer cancel()
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	raw, err := pinnedDial(dialer.DialContext, cfg.Resolve)(ctx, "tcp", hostPort(u))
+	if err != nil {
+		return nil, err
+	}
+	conn := tls.Client(raw, &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: true})
+	defer conn.Close()
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("server sent no certificate")
+	}
+	return certs[0], nil
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// shortLivedServer starts a TLS server whose self-signed certificate for
+// cert.test expires after lifetime.
+func shortLivedServer(t *testing.T, lifetime time.Duration) *httptest.Server {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "cert.test"},
+		DNSNames:     []string{"cert.test"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(lifetime),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // rejected handshakes are expected
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPeerCertificate_ShortLived(t *testing.T) {
+	srv := shortLivedServer(t, 3*24*time.Hour)
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "https://"))
+	target := "https://cert.test:" + port + "/"
+	cfg := Config{Resolve: map[string]string{"cert.test:" + port: srv.Listener.Addr().String()}}
+
+	leaf, err := peerCertificate(t.Context(), cfg, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf.Subject.CommonName != "cert.test" {
+		t.Fatalf("got certificate for %q", leaf.Subject.CommonName)
+	}
+
+	status, ok, failed := certStatus(leaf, time.Now(), 30*24*time.Hour, false)
+	if ok || failed || !strings.Contains(status, "expires on") || !strings.Contains(status, "in 2 days") {
+		t.Errorf("within the window: %q ok=%v failed=%v", status, ok, failed)
+	}
+	if status, ok, _ := certStatus(leaf, time.Now(), 24*time.Hour, false); !ok {
+		t.Errorf("outside the window: %q", status)
+	}
+	later := leaf.NotAfter.Add(time.Minute)
+	if status, _, failed := certStatus(leaf, later, 30*24*time.Hour, false); !failed || !strings.Contains(status, "expired on") {
+		t.Errorf("expired: %q failed=%v", status, failed)
+	}
+	if _, _, failed := certStatus(leaf, later, 30*24*time.Hour, true); failed {
+		t.Error("an expired certificate is only a warning with --insecure")
+	}
+
+	if leaf, err := peerCertificate(t.Context(), cfg, "http://cert.test:"+port+"/"); leaf != nil || err != nil {
+		t.Errorf("plain http should not be checked, got %v, %v", leaf, err)
+	}
+}
+
+func TestNewHTTPClient_Insecure(t *testing.T) {
+	srv := shortLivedServer(t, time.Hour)
+	u, _ := url.Parse(srv.URL)
+
+	if _, err := newHTTPClient(Config{Connections: 1}, nil).Get(u.String()); err == nil {
+		t.Error("a self-signed certificate should fail verification")
+	}
+	resp, err := newHTTPClient(Config{Connections: 1, Insecure: true}, nil).Get(u.String())
+	if err != nil {
+		t.Fatalf("insecure client: %v", err)
+	}
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// stepNames labels transaction steps as "METHOD /path?query".
+func stepNames(steps []ReplayRequest) []string {
+	names := make([]string, len(steps))
+	for i, s := range steps {
+		target := s.URL
+		if u, err := url.Parse(s.URL); err == nil {
+			target = u.RequestURI()
+		}
+		names[i] = s.Method + " " + target
+	}
+	return names
+}
+
+// runTransactionSlot is runPipelineSlot for Config.Transaction: each
+// iteration sends every step in order and records the sequence as one
+// result, with each step also recorded through collector.RecordStep. The
+// first failing step fails the transaction and skips the rest.
+func runTransactionSlot(
+	ctx context.Context,
+	durationDone <-chan struct{},
+	client *http.Client,
+	cfg Config,
+	reqs *requestBuilder,
+	sched scheduler,
+	collector *stats.Collector,
+) {
+	observer, _ := sched.(latencyObserver)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-durationDone:
+			return
+		default:
+		}
+		if sched != nil && !sched.wait(ctx, durationDone) {
+			return
+		}
+
+		collector.RequestStarted()
+		result := stats.Result{Success: true}
+		for i := range reqs.steps {
+			r, bodyLen, err := reqs.buildStep(ctx, i)
+			if err != nil {
+				collector.RequestFinished()
+				return
+			}
+			start := time.Now()
+			if i == 0 {
+				result.Start = start
+			}
+			resp, err := client.Do(r)
+			latency := time.Since(start)
+			result.BytesSent += uint64(bodyLen)
+
+			if resp != nil && resp.Body != nil {
+				n, readErr := io.Copy(io.Discard, resp.Body)
+				result.BytesRecv += uint64(n)
+				_ = resp.Body.Close()
+				if readErr != nil && err == nil {
+					err = readErr // a body cut short fails the step
+				}
+			}
+			if err != nil && context.Cause(ctx) == errDrainTimeout {
+				collector.RequestFinished()
+				record(collector, cfg.OnResult, stats.Result{Start: result.Start, Abandoned: true, BytesSent: result.BytesSent})
+				return
+			}
+			result.Latency += latency
+			if resp != nil {
+				result.Status = resp.StatusCode
+			}
+
+			var category stats.ErrorCategory
+			var msg string
+			ok := err == nil && resp != nil && resp.StatusCode >= 200 && resp.StatusCode < 500
+			if !ok {
+				category, msg = failure(err, resp)
+			} else if len(cfg.ExpectHeaders) > 0 || len(cfg.RejectHeaders) > 0 {
+				if msg = headerFailure(resp.Header, cfg.ExpectHeaders, cfg.RejectHeaders); msg != "" {
+					ok, category = false, stats.ErrHeader
+				}
+			}
+			if ok && (len(cfg.ExpectTrailers) > 0 || len(cfg.RejectTrailers) > 0) {
+				if msg = trailerFailure(resp.Trailer, cfg.ExpectTrailers, cfg.RejectTrailers); msg != "" {
+					ok, category = false, stats.ErrHeader
+				}
+			}
+			collector.RecordStep(i, latency, ok)
+			if !ok {
+				result.Success = false
+				result.ErrorCategory = category
+				result.ErrorMessage = fmt.Sprintf("step %d: %s", i+1, msg)
+				break
+			}
+		}
+		collector.RequestFinished()
+		record(collector, cfg.OnResult, result)
+		if observer != nil {
+			observer.observe(result.Latency)
+		}
+	}
+}
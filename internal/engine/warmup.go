@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+)
+
+// countedConn numbers the requests sent on one connection so the first
+// Config.DiscardFirstPerConn of them can be left out of the stats.
+type countedConn struct {
+	net.Conn
+	requests atomic.Int64
+}
+
+// countedDial wraps dial so every new connection counts its requests, and
+// dials counts the connections, if not nil. It returns dial unchanged when
+// there is nothing to count.
+func countedDial(dial func(ctx context.Context, network, addr string) (net.Conn, error), discard int, dials *atomic.Uint64) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if discard <= 0 && dials == nil {
+		return dial
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		if dials != nil {
+			dials.Add(1)
+		}
+		if discard <= 0 {
+			return conn, nil
+		}
+		return &countedConn{Conn: conn}, nil
+	}
+}
+
+// connRequest counts a request on conn, as reported by httptrace GotConn,
+// and returns its 1-based position on the connection. TLS connections are
+// unwrapped to the dialled one. It returns 0 for connections not made by
+// countedDial.
+func connRequest(conn net.Conn) int64 {
+	if tc, ok := conn.(interface{ NetConn() net.Conn }); ok {
+		conn = tc.NetConn()
+	}
+	if cc, ok := conn.(*countedConn); ok {
+		return cc.requests.Add(1)
+	}
+	return 0
+}
@@ -3,8 +3,14 @@ package engine
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
 	"io"
+	mathrand "math/rand/v2"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"sync"
 	"time"
 
@@ -17,9 +23,12 @@ import (
 // but let in-flight requests complete. ctx is cancelled on SIGINT to abort immediately.
 func worker(
 	ctx context.Context,
+	id int,
 	durationDone <-chan struct{},
 	client *http.Client,
 	cfg Config,
+	reqs *requestBuilder,
+	sched scheduler,
 	connections int,
 	collector *stats.Collector,
 ) {
@@ -35,33 +44,108 @@ func worker(
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			runPipelineSlot(ctx, durationDone, client, cfg, collector)
+			rng := newSlotRand(cfg.Seed, id*pipeline+i)
+			sched := sched
+			if s, ok := sched.(slotScheduler); ok {
+				sched = s.forSlot(id*pipeline + i)
+			}
+			if cfg.StaggerStart > 0 {
+				offset := time.Duration(rng.Int64N(int64(cfg.StaggerStart)))
+				if !sleepUnlessStopped(ctx, durationDone, offset) {
+					return
+				}
+			}
+			if cfg.SimulateLatency != nil {
+				runSimulatedSlot(ctx, durationDone, cfg, sched, rng, collector)
+				return
+			}
+			if len(cfg.Transaction) > 0 {
+				runTransactionSlot(ctx, durationDone, client, cfg, reqs, sched, collector)
+				return
+			}
+			runPipelineSlot(ctx, durationDone, client, cfg, reqs, sched, rng, collector)
 		}()
 	}
 	wg.Wait()
 }
 
 // runPipelineSlot issues HTTP requests in a loop until ctx is cancelled or durationDone
-// is closed. When durationDone closes, we stop after the current request completes
-// so in-flight requests are not aborted by a timeout.
+// is closed. With a scheduler, each request waits for its turn first. When
+// durationDone closes, we stop after the current request completes so
+// in-flight requests are not aborted by a timeout.
 func runPipelineSlot(
 	ctx context.Context,
 	durationDone <-chan struct{},
 	client *http.Client,
 	cfg Config,
+	reqs *requestBuilder,
+	sched scheduler,
+	rng *mathrand.Rand,
 	collector *stats.Collector,
 ) {
-	var bodyReader io.Reader
-	if len(cfg.Body) > 0 {
-		bodyReader = bytes.NewReader(cfg.Body)
+	// With --expect-sha256 the body is hashed as it drains; one hasher per
+	// slot is reset for every response.
+	var bodyHash hash.Hash
+	if len(cfg.ExpectSHA256) > 0 {
+		bodyHash = sha256.New()
+	}
+	// With --assert-json the start of the body is kept for the checks.
+	var jsonBody *cappedBuffer
+	if len(cfg.AssertJSON) > 0 {
+		jsonBody = &cappedBuffer{}
+	}
+	// With --decode, compressed bodies are checked and counted decoded.
+	var decoder *bodyDecoder
+	if cfg.Decode {
+		decoder = newBodyDecoder()
 	}
-	req, err := http.NewRequestWithContext(ctx, cfg.Method, cfg.URL, bodyReader)
-	if err != nil {
-		return
+	observer, _ := sched.(latencyObserver)
+
+	// With a connection cap, time from asking the pool for a connection to
+	// getting one is the request's conn wait. With PerConn, the connection
+	// it got is what the request is attributed to. With DiscardFirstPerConn,
+	// connSeq is the request's position on that connection.
+	var connTrace *httptrace.ClientTrace
+	var getConn, gotConn time.Time
+	var conn net.Conn
+	var connSeq int64
+	var setup connSetup
+	if cfg.CapConnections || cfg.PerConn || cfg.ShowAddrs || cfg.DiscardFirstPerConn > 0 || cfg.ConnBench {
+		connTrace = &httptrace.ClientTrace{
+			GetConn: func(string) { getConn = time.Now() },
+			GotConn: func(info httptrace.GotConnInfo) {
+				gotConn = time.Now()
+				conn = info.Conn
+				if cfg.DiscardFirstPerConn > 0 {
+					connSeq = connRequest(info.Conn)
+				}
+			},
+		}
+		if cfg.ConnBench {
+			setup.trace(connTrace)
+		}
 	}
-	if len(cfg.Body) > 0 {
-		req.ContentLength = int64(len(cfg.Body))
+
+	// Without a body or placeholders the same request is sent every iteration.
+	// closeReq is its Connection: close twin for rotating connections. A
+	// static body does not change that: it is attached to each send from
+	// one rewound reader.
+	var req, closeReq *http.Request
+	var body *sharedBody
+	var reqLen int64
+	if reqs.reusable() || reqs.staticBody() {
+		var err error
+		req, reqLen, err = reqs.build(ctx, rng)
+		if err != nil {
+			return
+		}
+		closeReq = req.Clone(ctx)
+		closeReq.Close = true
+		if reqLen > 0 {
+			body = newSharedBody(reqs.body)
+		}
 	}
+	var sent int // requests issued by this slot, for MaxRequestsPerConn
 
 	for {
 		select {
@@ -70,32 +154,238 @@ func runPipelineSlot(
 		case <-durationDone:
 			return
 		default:
-			// With a body we must create a new request each time (reader is consumed).
+			if sched != nil && !sched.wait(ctx, durationDone) {
+				return
+			}
+			// With a body or templated values we must create a new request each time.
 			r := req
-			if len(cfg.Body) > 0 {
-				r, err = http.NewRequestWithContext(ctx, cfg.Method, cfg.URL, bytes.NewReader(cfg.Body))
+			bodyLen := reqLen
+			target := -1
+			if r == nil {
+				var err error
+				r, bodyLen, target, err = reqs.buildTarget(ctx, rng)
 				if err != nil {
 					return
 				}
-				r.ContentLength = int64(len(cfg.Body))
 			}
 
-			bytesSent := uint64(len(cfg.Body))
+			sent++
+			rotate := cfg.MaxRequestsPerConn > 0 && sent%cfg.MaxRequestsPerConn == 0
+			if rotate {
+				if req != nil {
+					r = closeReq
+				} else {
+					r.Close = true
+				}
+			}
+			if body != nil {
+				r = body.attach(r)
+			}
+			if cfg.RequestContext != nil {
+				r = r.WithContext(cfg.RequestContext(r.Context()))
+			}
+
+			if connTrace != nil {
+				getConn, gotConn, conn, connSeq, setup = time.Time{}, time.Time{}, nil, 0, connSetup{}
+				r = r.WithContext(httptrace.WithClientTrace(r.Context(), connTrace))
+			}
+
+			bytesSent := uint64(bodyLen)
 
-			start := time.Now()
-			resp, err := client.Do(r)
-			latency := time.Since(start)
+			collector.RequestStarted()
+			var (
+				resp      *http.Response
+				err       error
+				latency   time.Duration
+				bytesRecv uint64
+				chunked   bool
+				retries   int
+				start     time.Time
+				undecoded string // a Content-Encoding --decode does not support
+				decodeErr error
+			)
+			for {
+				start = time.Now()
+				resp, err = client.Do(r)
+				latency = time.Since(start)
 
-			var bytesRecv uint64
-			if resp != nil && resp.Body != nil {
-				n, _ := io.Copy(io.Discard, resp.Body)
-				bytesRecv = uint64(n)
-				_ = resp.Body.Close()
+				chunked = false
+				if resp != nil && resp.Body != nil {
+					// Drain to EOF: for chunked responses this also consumes the
+					// trailers, which is what lets the transport reuse the connection.
+					sink := io.Discard
+					if bodyHash != nil {
+						bodyHash.Reset()
+						sink = bodyHash
+					}
+					if jsonBody != nil {
+						jsonBody.Reset()
+						if bodyHash != nil {
+							sink = io.MultiWriter(bodyHash, jsonBody)
+						} else {
+							sink = jsonBody
+						}
+					}
+					var body io.Reader = resp.Body
+					undecoded, decodeErr = "", nil
+					if decoder != nil {
+						if dec, unsupported, err := decoder.decode(resp); err != nil {
+							decodeErr = err
+							sink = io.Discard
+						} else {
+							body, undecoded = dec, unsupported
+						}
+					}
+					n, readErr, corrupt := drainBody(sink, body, resp.Body)
+					bytesRecv += uint64(n)
+					if corrupt != nil {
+						decodeErr = corrupt
+					}
+					_ = resp.Body.Close()
+					chunked = resp.ContentLength < 0 && r.Method != http.MethodHead
+					// A body cut short fails the request whatever its status; the
+					// bytes that did arrive still count.
+					if readErr != nil && err == nil {
+						err = readErr
+					}
+				}
+
+				if err != nil && context.Cause(ctx) == errDrainTimeout {
+					collector.RequestFinished()
+					record(collector, cfg.OnResult, stats.Result{Start: start, Abandoned: true, BytesSent: bytesSent})
+					return
+				}
+
+				if retries == cfg.Retries || !retryable(r, err, resp, cfg.RetryNonIdempotent) ||
+					!sleepUnlessStopped(ctx, durationDone, retryDelay(cfg, retries+1, rng)) {
+					break
+				}
+				next, rerr := rewind(r)
+				if rerr != nil {
+					break
+				}
+				r = next
+				retries++
+				bytesSent += uint64(bodyLen)
+				if connTrace != nil {
+					getConn, gotConn, conn, connSeq, setup = time.Time{}, time.Time{}, nil, 0, connSetup{}
+				}
+			}
+			collector.RequestFinished()
+			if reqs.etags != nil {
+				reqs.etags.observe(resp)
+			}
+
+			if connSeq > 0 && connSeq <= int64(cfg.DiscardFirstPerConn) {
+				record(collector, cfg.OnResult, stats.Result{Start: start, Discarded: true, BytesSent: bytesSent, BytesRecv: bytesRecv})
+				continue
 			}
 
 			success := err == nil && resp != nil && resp.StatusCode >= 200 && resp.StatusCode < 500
-			collector.Record(latency, success, bytesSent, bytesRecv)
+			var rateLimited bool
+			if success {
+				success, rateLimited = count429(resp, cfg.Count429)
+			}
+			result := stats.Result{
+				Start:       start,
+				Latency:     latency,
+				Success:     success,
+				BytesSent:   bytesSent,
+				BytesRecv:   bytesRecv,
+				Chunked:     chunked,
+				Rotated:     rotate && err == nil,
+				Retries:     retries,
+				Target:      target + 1,
+				Undecoded:   undecoded,
+				RateLimited: rateLimited,
+			}
+			if resp != nil {
+				result.Status = resp.StatusCode
+			}
+			if !getConn.IsZero() && !gotConn.IsZero() {
+				result.ConnWait = gotConn.Sub(getConn)
+			}
+			if cfg.ConnBench {
+				result.Connect, result.TLSHandshake = setup.durations()
+			}
+			if conn != nil {
+				if cfg.PerConn {
+					result.ConnLocal = conn.LocalAddr().String()
+				}
+				if cfg.PerConn || cfg.ShowAddrs {
+					result.ConnRemote = conn.RemoteAddr().String()
+				}
+			}
+			if !success && !rateLimited {
+				result.ErrorCategory, result.ErrorMessage = failure(err, resp)
+			} else if cfg.GRPC {
+				if msg := grpcFailure(resp); msg != "" {
+					result.Success = false
+					result.ErrorCategory = stats.ErrGRPC
+					result.ErrorMessage = msg
+				}
+			}
+			if result.Success && (len(cfg.ExpectHeaders) > 0 || len(cfg.RejectHeaders) > 0) {
+				if msg := headerFailure(resp.Header, cfg.ExpectHeaders, cfg.RejectHeaders); msg != "" {
+					result.Success = false
+					result.ErrorCategory = stats.ErrHeader
+					result.ErrorMessage = msg
+				}
+			}
+			if result.Success && (len(cfg.ExpectTrailers) > 0 || len(cfg.RejectTrailers) > 0) {
+				if msg := trailerFailure(resp.Trailer, cfg.ExpectTrailers, cfg.RejectTrailers); msg != "" {
+					result.Success = false
+					result.ErrorCategory = stats.ErrHeader
+					result.ErrorMessage = msg
+				}
+			}
+			if result.Success && decodeErr != nil {
+				result.Success = false
+				result.ErrorCategory = stats.ErrValidation
+				result.ErrorMessage = decodeErr.Error()
+			}
+			if result.Success && bodyHash != nil {
+				if sum := bodyHash.Sum(nil); !bytes.Equal(sum, cfg.ExpectSHA256) {
+					result.Success = false
+					result.ErrorCategory = stats.ErrValidation
+					result.ErrorMessage = "body SHA-256 mismatch: got " + hex.EncodeToString(sum)
+				}
+			}
+			if result.Success && jsonBody != nil {
+				if msg := jsonFailure(jsonBody.Bytes(), cfg.AssertJSON); msg != "" {
+					result.Success = false
+					result.ErrorCategory = stats.ErrValidation
+					result.ErrorMessage = msg
+				}
+			}
+			record(collector, cfg.OnResult, result)
+			if observer != nil {
+				observer.observe(latency)
+			}
 		}
 	}
 }
 
+// drainBody copies body, which reads from raw or is raw, to sink and then
+// drains what is left of raw so the connection can be reused. readErr is a
+// transport error that cut the body short; corrupt is an error only the
+// decoder saw, i.e. a malformed encoding. raw repeats a transport error the
+// decoder hit, which tells the two apart.
+func drainBody(sink io.Writer, body, raw io.Reader) (n int64, readErr, corrupt error) {
+	n, err := io.Copy(sink, body)
+	if body == raw {
+		return n, err, nil
+	}
+	if _, rawErr := io.Copy(io.Discard, raw); rawErr != nil {
+		return n, rawErr, nil
+	}
+	return n, nil, err
+}
+
+// record hands r to the collector and then to onResult, if set.
+func record(collector *stats.Collector, onResult func(stats.Result), r stats.Result) {
+	collector.RecordResult(r)
+	if onResult != nil {
+		onResult(r)
+	}
+}
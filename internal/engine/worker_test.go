@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// roundTripFunc is a custom transport, as an embedder's instrumented one.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+type spanKey struct{}
+
+func TestRunPipelineSlot_RequestContext(t *testing.T) {
+	const requests = 5
+	var mu sync.Mutex
+	var spans []any
+	done := make(chan struct{})
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		spans = append(spans, r.Context().Value(spanKey{}))
+		if len(spans) == requests {
+			close(done)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok")), Request: r}, nil
+	})}
+
+	var n int
+	cfg := Config{
+		Method: "GET",
+		URL:    "http://127.0.0.1:1/",
+		RequestContext: func(base context.Context) context.Context {
+			n++ // one slot, so no lock
+			return context.WithValue(base, spanKey{}, n)
+		},
+	}
+	runPipelineSlot(context.Background(), done, client, cfg, newRequestBuilder(cfg), nil, newSlotRand(1, 0), stats.NewCollector())
+
+	if len(spans) != requests {
+		t.Fatalf("transport saw %d requests, want %d", len(spans), requests)
+	}
+	for i, span := range spans {
+		if span != i+1 {
+			t.Errorf("request %d carried span %v, want %d", i+1, span, i+1)
+		}
+	}
+}
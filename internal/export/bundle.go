@@ -0,0 +1,102 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Artifact is one file written into an output directory.
+type Artifact struct {
+	Kind     string
+	FileName string
+	Write    func(w io.Writer, r Report) error
+}
+
+// Artifacts lists every exporter that --out-dir knows about, in write order.
+var Artifacts = []Artifact{
+	{Kind: "json", FileName: "summary.json", Write: WriteJSON},
+	{Kind: "csv", FileName: "timeseries.csv", Write: WriteTimeSeriesCSV},
+	{Kind: "cdf", FileName: "latency_cdf.csv", Write: WriteLatencyCDF},
+	{Kind: "html", FileName: "report.html", Write: WriteHTML},
+}
+
+// ArtifactKinds returns the kind names accepted by WriteBundle.
+func ArtifactKinds() []string {
+	kinds := make([]string, len(Artifacts))
+	for i, a := range Artifacts {
+		kinds[i] = a.Kind
+	}
+	return kinds
+}
+
+// NewRunDir creates base/run-<timestamp>/ and returns its path.
+func NewRunDir(base string, t time.Time) (string, error) {
+	dir := filepath.Join(base, "run-"+t.Format("20060102-150405"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create output dir: %w", err)
+	}
+	return dir, nil
+}
+
+// WriteBundle writes the selected artifact kinds into dir; an empty kinds
+// list selects all of them. A failing exporter does not stop the others: every
+// failure is collected and returned joined, naming the file that failed.
+func WriteBundle(dir string, r Report, kinds []string) error {
+	selected, err := selectArtifacts(kinds)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, a := range selected {
+		if err := writeArtifact(filepath.Join(dir, a.FileName), a, r); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", a.FileName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateKinds reports an error if any kind is not a known artifact.
+func ValidateKinds(kinds []string) error {
+	_, err := selectArtifacts(kinds)
+	return err
+}
+
+func selectArtifacts(kinds []string) ([]Artifact, error) {
+	if len(kinds) == 0 {
+		return Artifacts, nil
+	}
+	var out []Artifact
+	for _, k := range kinds {
+		k = strings.ToLower(strings.TrimSpace(k))
+		found := false
+		for _, a := range Artifacts {
+			if a.Kind == k {
+				out = append(out, a)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown artifact %q (valid: %s)", k, strings.Join(ArtifactKinds(), ", "))
+		}
+	}
+	return out, nil
+}
+
+func writeArtifact(path string, a Artifact, r Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := a.Write(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+func sampleReport() Report {
+	return Report{
+		Meta: Meta{Method: "GET", URL: "http://127.0.0.1/", Connections: 1, Workers: 1, Pipeline: 1, Duration: time.Second},
+		Snapshot: stats.Snapshot{
+			TotalRequests: 3,
+			Successes:     3,
+			LatencyP50:    2 * time.Millisecond,
+		},
+		TimeSeries: []stats.Bucket{{Start: 0, Duration: time.Second, Requests: 3, RPS: 3}},
+		Latencies:  []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond},
+	}
+}
+
+func TestWriteBundle_AllArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteBundle(dir, sampleReport(), nil); err != nil {
+		t.Fatalf("WriteBundle: %v", err)
+	}
+	for _, a := range Artifacts {
+		if _, err := os.Stat(filepath.Join(dir, a.FileName)); err != nil {
+			t.Errorf("missing %s: %v", a.FileName, err)
+		}
+	}
+}
+
+func TestWriteBundle_PartialFailureContinues(t *testing.T) {
+	dir := t.TempDir()
+	// A directory where summary.json should go makes only the JSON exporter fail.
+	if err := os.Mkdir(filepath.Join(dir, "summary.json"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err := WriteBundle(dir, sampleReport(), nil)
+	if err == nil {
+		t.Fatal("expected error for blocked summary.json")
+	}
+	if !strings.Contains(err.Error(), "summary.json") {
+		t.Errorf("error should name the failed artifact: %v", err)
+	}
+	for _, name := range []string{"timeseries.csv", "latency_cdf.csv", "report.html"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s should still be written: %v", name, err)
+		}
+	}
+}
+
+func TestWriteBundle_UnknownKind(t *testing.T) {
+	if err := WriteBundle(t.TempDir(), sampleReport(), []string{"pdf"}); err == nil {
+		t.Fatal("expected error for unknown artifact kind")
+	}
+}
+
+func TestNewRunDir_Timestamped(t *testing.T) {
+	base := t.TempDir()
+	at := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	dir, err := NewRunDir(base, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(dir) != "run-20240506-070809" {
+		t.Errorf("unexpected run dir name: %s", dir)
+	}
+}
@@ -0,0 +1,41 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+)
+
+// cdfPercentiles are the points written to the latency CDF: every whole
+// percentile plus a few tail points that matter for SLOs.
+var cdfPercentiles = func() []float64 {
+	ps := make([]float64, 0, 104)
+	for p := 0; p <= 99; p++ {
+		ps = append(ps, float64(p))
+	}
+	return append(ps, 99.9, 99.99, 99.999, 100)
+}()
+
+// WriteLatencyCDF writes the latency distribution as percentile,latency_ns rows.
+func WriteLatencyCDF(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"percentile", "latency_ns"}); err != nil {
+		return err
+	}
+	s := r.Latencies
+	if len(s) > 0 {
+		for _, p := range cdfPercentiles {
+			idx := int(math.Round(p / 100 * float64(len(s)-1)))
+			row := []string{
+				strconv.FormatFloat(p, 'f', -1, 64),
+				strconv.FormatInt(s[idx].Nanoseconds(), 10),
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,34 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// WriteTimeSeriesCSV writes one row per flushed 1s bucket.
+func WriteTimeSeriesCSV(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"start_s", "duration_s", "requests", "rps", "bytes_sent", "bytes_recv", "bytes_per_s", "peak_in_flight", "errors", "successes"}); err != nil {
+		return err
+	}
+	for _, b := range r.TimeSeries {
+		row := []string{
+			strconv.FormatFloat(b.Start.Seconds(), 'f', 3, 64),
+			strconv.FormatFloat(b.Duration.Seconds(), 'f', 3, 64),
+			strconv.FormatUint(b.Requests, 10),
+			strconv.FormatFloat(b.RPS, 'f', 2, 64),
+			strconv.FormatUint(b.BytesSent, 10),
+			strconv.FormatUint(b.BytesRecv, 10),
+			strconv.FormatFloat(b.BytesPerS, 'f', 2, 64),
+			strconv.FormatInt(b.PeakInFlight, 10),
+			strconv.FormatUint(b.Errors, 10),
+			strconv.FormatUint(b.Successes, 10),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
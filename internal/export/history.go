@@ -0,0 +1,198 @@
+package export
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// openHistoryDB opens the --db database. It is set by history_sqlite.go
+// unless built with the nosqlite tag, which leaves the driver out.
+var openHistoryDB func(path string) (*sql.DB, error)
+
+// historySchema is created on first use. Columns may be appended, never
+// changed: old databases keep working with new builds, and older files gain
+// the columns of historyColumns when opened.
+const historySchema = `CREATE TABLE IF NOT EXISTS runs (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at TEXT    NOT NULL,
+	run_id     TEXT    NOT NULL,
+	tags       TEXT    NOT NULL,
+	config     TEXT    NOT NULL,
+	requests   INTEGER NOT NULL,
+	errors     INTEGER NOT NULL,
+	rps        REAL    NOT NULL,
+	p50_ns     INTEGER NOT NULL,
+	p99_ns     INTEGER NOT NULL,
+	name       TEXT    NOT NULL DEFAULT ''
+)`
+
+// historyColumns are the columns appended to the runs table after its first
+// release, with their definitions.
+var historyColumns = [][2]string{
+	{"name", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// HistoryRun is one row of a --db history database.
+type HistoryRun struct {
+	ID        int64
+	Name      string
+	StartedAt time.Time
+	RunID     string
+	Tags      []string
+	Config    SummaryTarget
+	Requests  uint64
+	Errors    uint64
+	RPS       float64
+	P50       time.Duration
+	P99       time.Duration
+}
+
+// openHistory opens the database at path and creates the runs table if the
+// file is new.
+func openHistory(path string) (*sql.DB, error) {
+	if openHistoryDB == nil {
+		return nil, errors.New("built without SQLite support (nosqlite)")
+	}
+	db, err := openHistoryDB(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := addHistoryColumns(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// addHistoryColumns adds the historyColumns a database created by an older
+// build lacks.
+func addHistoryColumns(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('runs')`)
+	if err != nil {
+		return err
+	}
+	var have []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		have = append(have, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, col := range historyColumns {
+		if slices.Contains(have, col[0]) {
+			continue
+		}
+		// A concurrent writer may have added it first.
+		if _, err := db.Exec(`ALTER TABLE runs ADD COLUMN ` + col[0] + ` ` + col[1]); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			return err
+		}
+	}
+	return nil
+}
+
+// AppendHistory adds the run in r to the history database at path under the
+// benchmark name, creating the database on first use. Tags must not contain
+// commas.
+func AppendHistory(path, name string, r Report, tags []string) error {
+	db, err := openHistory(path)
+	if err != nil {
+		return fmt.Errorf("--db %s: %w", path, err)
+	}
+	defer db.Close()
+	s := NewSummary(r)
+	config, err := json.Marshal(s.Target)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO runs (name, started_at, run_id, tags, config, requests, errors, rps, p50_ns, p99_ns)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		name, r.Meta.StartedAt.UTC().Format(time.RFC3339Nano), s.Target.RunID, strings.Join(tags, ","), string(config),
+		s.Requests.Total, s.Requests.Errors, s.Throughput.RPSAvg, s.Latency.P50, s.Latency.P99)
+	if err != nil {
+		return fmt.Errorf("--db %s: %w", path, err)
+	}
+	return nil
+}
+
+// HistoryFilter selects runs for ReadHistory. Zero fields match every run.
+type HistoryFilter struct {
+	Name  string
+	Tags  []string  // a run must carry all of them
+	Since time.Time // inclusive
+	Until time.Time // exclusive
+}
+
+func (f HistoryFilter) match(run HistoryRun) bool {
+	for _, tag := range f.Tags {
+		if !slices.Contains(run.Tags, tag) {
+			return false
+		}
+	}
+	return (f.Since.IsZero() || !run.StartedAt.Before(f.Since)) &&
+		(f.Until.IsZero() || run.StartedAt.Before(f.Until))
+}
+
+// ReadHistory returns the runs in the history database at path that match
+// f, oldest first. A database that does not exist holds no runs; it is not
+// created.
+func ReadHistory(path string, f HistoryFilter) ([]HistoryRun, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	db, err := openHistory(path)
+	if err != nil {
+		return nil, fmt.Errorf("--db %s: %w", path, err)
+	}
+	defer db.Close()
+	rows, err := db.Query(`SELECT id, name, started_at, run_id, tags, config, requests, errors, rps, p50_ns, p99_ns
+		FROM runs WHERE ? = '' OR name = ? ORDER BY id`, f.Name, f.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var runs []HistoryRun
+	for rows.Next() {
+		var run HistoryRun
+		var startedAt, tags, config string
+		var p50, p99 int64
+		if err := rows.Scan(&run.ID, &run.Name, &startedAt, &run.RunID, &tags, &config, &run.Requests, &run.Errors, &run.RPS, &p50, &p99); err != nil {
+			return nil, err
+		}
+		if run.StartedAt, err = time.Parse(time.RFC3339Nano, startedAt); err != nil {
+			return nil, fmt.Errorf("run %d: %w", run.ID, err)
+		}
+		if tags != "" {
+			run.Tags = strings.Split(tags, ",")
+		}
+		if err := json.Unmarshal([]byte(config), &run.Config); err != nil {
+			return nil, fmt.Errorf("run %d: %w", run.ID, err)
+		}
+		run.P50, run.P99 = time.Duration(p50), time.Duration(p99)
+		if f.match(run) {
+			runs = append(runs, run)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Start times are compared as times: RFC 3339 text with trimmed
+	// fractions does not sort.
+	slices.SortStableFunc(runs, func(a, b HistoryRun) int { return a.StartedAt.Compare(b.StartedAt) })
+	return runs, nil
+}
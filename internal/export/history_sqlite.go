@@ -0,0 +1,19 @@
+//go:build !nosqlite
+
+package export
+
+import (
+	"database/sql"
+	"net/url"
+
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	openHistoryDB = func(path string) (*sql.DB, error) {
+		// Concurrent runs appending to one file wait for each other's
+		// writes instead of failing with SQLITE_BUSY.
+		q := url.Values{"_pragma": {"busy_timeout(10000)", "journal_mode(WAL)"}}
+		return sql.Open("sqlite", "file:"+path+"?"+q.Encode())
+	}
+}
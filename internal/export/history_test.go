@@ -0,0 +1,210 @@
+//go:build !nosqlite
+
+package export
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+func TestHistory_AppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first := Report{
+		Meta: Meta{Method: "GET", URL: "http://127.0.0.1:8080/", Connections: 10, Workers: 2, Pipeline: 1, Duration: 30 * time.Second, StartedAt: started},
+		Snapshot: stats.Snapshot{
+			RunID:           "run-1",
+			TotalRequests:   4500,
+			Errors:          7,
+			RequestsPerSAvg: 150,
+			LatencyP50:      1500 * time.Microsecond,
+			LatencyP99:      42 * time.Millisecond,
+		},
+	}
+	second := first
+	second.Meta.StartedAt = started.Add(time.Hour)
+	second.Meta.Connections = 20
+	second.Snapshot.RunID = "run-2"
+	second.Snapshot.RequestsPerSAvg = 310.5
+
+	if err := AppendHistory(path, "api", first, []string{"env:staging", "v1.2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendHistory(path, "api", second, nil); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := ReadHistory(path, HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(runs))
+	}
+	a, b := runs[0], runs[1]
+	if !a.StartedAt.Equal(started) || a.Name != "api" || a.RunID != "run-1" || !slices.Equal(a.Tags, []string{"env:staging", "v1.2"}) {
+		t.Errorf("first run: %+v", a)
+	}
+	if a.Requests != 4500 || a.Errors != 7 || a.RPS != 150 || a.P50 != 1500*time.Microsecond || a.P99 != 42*time.Millisecond {
+		t.Errorf("first run results: %+v", a)
+	}
+	if a.Config.URL != first.Meta.URL || a.Config.Connections != 10 || a.Config.DurationNs != int64(30*time.Second) {
+		t.Errorf("first run config: %+v", a.Config)
+	}
+	if b.RunID != "run-2" || b.Tags != nil || b.RPS != 310.5 || b.Config.Connections != 20 || b.ID <= a.ID {
+		t.Errorf("second run: %+v", b)
+	}
+}
+
+func TestHistory_ConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- AppendHistory(path, "api", Report{Meta: Meta{StartedAt: time.Now()}}, nil)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	runs, err := ReadHistory(path, HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != writers {
+		t.Errorf("got %d runs, want %d", len(runs), writers)
+	}
+}
+
+func TestReadHistory_Filter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	add := func(name string, at time.Time, tags ...string) {
+		t.Helper()
+		if err := AppendHistory(path, name, Report{Meta: Meta{StartedAt: at}}, tags); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Out of order, to check runs come back by start time.
+	add("api", day.Add(48*time.Hour), "env:ci")
+	add("api", day, "env:ci")
+	add("web", day.Add(24*time.Hour), "env:ci")
+	add("api", day.Add(24*time.Hour+500*time.Millisecond), "env:ci", "canary")
+	add("api", day.Add(24*time.Hour+250*time.Millisecond))
+
+	for _, tt := range []struct {
+		name string
+		f    HistoryFilter
+		want []time.Time
+	}{
+		{"name", HistoryFilter{Name: "api"}, []time.Time{day, day.Add(24*time.Hour + 250*time.Millisecond), day.Add(24*time.Hour + 500*time.Millisecond), day.Add(48 * time.Hour)}},
+		{"tags", HistoryFilter{Name: "api", Tags: []string{"env:ci", "canary"}}, []time.Time{day.Add(24*time.Hour + 500*time.Millisecond)}},
+		{"range", HistoryFilter{Name: "api", Since: day.Add(time.Hour), Until: day.Add(48 * time.Hour)}, []time.Time{day.Add(24*time.Hour + 250*time.Millisecond), day.Add(24*time.Hour + 500*time.Millisecond)}},
+		{"other name", HistoryFilter{Name: "web"}, []time.Time{day.Add(24 * time.Hour)}},
+	} {
+		runs, err := ReadHistory(path, tt.f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []time.Time
+		for _, r := range runs {
+			got = append(got, r.StartedAt)
+		}
+		if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	missing := filepath.Join(t.TempDir(), "none.db")
+	if runs, err := ReadHistory(missing, HistoryFilter{}); runs != nil || err != nil {
+		t.Errorf("missing database: got %v, %v; want no runs", runs, err)
+	}
+	if _, err := os.Stat(missing); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadHistory created %s", missing)
+	}
+}
+
+func TestHistory_AddsColumnsToOldDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	db, err := openHistoryDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The runs table as first released, without name.
+	old := strings.Replace(historySchema, ",\n\tname       TEXT    NOT NULL DEFAULT ''", "", 1)
+	if old == historySchema {
+		t.Fatal("schema no longer ends with name")
+	}
+	_, err = db.Exec(old)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendHistory(path, "api", Report{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := ReadHistory(path, HistoryFilter{Name: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Errorf("got %d runs, want 1", len(runs))
+	}
+}
+
+func TestTrend_FlagsRegressions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, r := range []struct {
+		rps float64
+		p99 time.Duration
+	}{
+		{1000, 20 * time.Millisecond},
+		{980, 21 * time.Millisecond},  // within 10%
+		{700, 21 * time.Millisecond},  // req/s down 29%
+		{720, 30 * time.Millisecond},  // p99 up 43%
+		{1000, 18 * time.Millisecond}, // improvement
+	} {
+		report := Report{
+			Meta:     Meta{StartedAt: start.Add(time.Duration(i) * time.Hour)},
+			Snapshot: stats.Snapshot{RequestsPerSAvg: r.rps, LatencyP99: r.p99},
+		}
+		if err := AppendHistory(path, "api", report, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runs, err := ReadHistory(path, HistoryFilter{Name: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := Trend(runs, 0.1)
+	var regressed []bool
+	for _, p := range points {
+		regressed = append(regressed, p.Regressed)
+	}
+	if want := []bool{false, false, true, true, false}; !slices.Equal(regressed, want) {
+		t.Errorf("regressed = %v, want %v", regressed, want)
+	}
+	if got := points[2].RPSChange; math.Abs(got+0.2857) > 0.001 {
+		t.Errorf("RPSChange = %.4f, want -0.2857", got)
+	}
+	if got := points[3].P99Change; math.Abs(got-0.4286) > 0.001 {
+		t.Errorf("P99Change = %.4f, want 0.4286", got)
+	}
+}
@@ -0,0 +1,112 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"ms": func(d time.Duration) string {
+		return fmt.Sprintf("%.2f ms", float64(d.Nanoseconds())/1e6)
+	},
+	"f2": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"secs": func(d time.Duration) string {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>httpcl report - {{.Meta.URL}}</title>
+<style>
+body { font-family: monospace; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #999; padding: 4px 10px; text-align: right; }
+th { background: #eef; }
+td.l, th.l { text-align: left; }
+polyline { fill: none; stroke: #06c; stroke-width: 2; }
+</style>
+</head>
+<body>
+<h1>httpcl report</h1>
+<table>
+<tr><th class="l">Target</th><td class="l">{{.Meta.Method}} {{.Meta.URL}}</td></tr>
+<tr><th class="l">Started</th><td class="l">{{.Meta.StartedAt.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+<tr><th class="l">Workers / Connections / Pipeline</th><td class="l">{{.Meta.Workers}} / {{.Meta.Connections}} / {{.Meta.Pipeline}}</td></tr>
+<tr><th class="l">Duration</th><td class="l">{{.Snapshot.Duration}}</td></tr>
+<tr><th class="l">Total requests</th><td class="l">{{.Snapshot.TotalRequests}}</td></tr>
+<tr><th class="l">Successes</th><td class="l">{{.Snapshot.Successes}}</td></tr>
+<tr><th class="l">Errors</th><td class="l">{{.Snapshot.Errors}}</td></tr>
+<tr><th class="l">Req/Sec (avg)</th><td class="l">{{f2 .Snapshot.RequestsPerSAvg}}</td></tr>
+</table>
+
+<h2>Latency</h2>
+<table>
+<tr><th>2.5%</th><th>50%</th><th>97.5%</th><th>99%</th><th>Avg</th><th>Stdev</th><th>Max</th></tr>
+<tr><td>{{ms .Snapshot.LatencyP25}}</td><td>{{ms .Snapshot.LatencyP50}}</td><td>{{ms .Snapshot.LatencyP975}}</td><td>{{ms .Snapshot.LatencyP99}}</td><td>{{ms .Snapshot.LatencyAvg}}</td><td>{{ms .Snapshot.LatencyStdev}}</td><td>{{ms .Snapshot.LatencyMax}}</td></tr>
+</table>
+
+{{if .TimeSeries}}
+<h2>Requests per second</h2>
+<svg width="{{.ChartWidth}}" height="{{.ChartHeight}}"><polyline points="{{.RPSPoints}}"/></svg>
+<table>
+<tr><th>Start</th><th>Requests</th><th>Errors</th><th>Req/Sec</th><th>Bytes sent</th><th>Bytes recv</th><th>Peak in flight</th></tr>
+{{range .TimeSeries}}<tr><td>{{secs .Start}}</td><td>{{.Requests}}</td><td>{{.Errors}}</td><td>{{f2 .RPS}}</td><td>{{.BytesSent}}</td><td>{{.BytesRecv}}</td><td>{{.PeakInFlight}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+const (
+	chartWidth  = 600
+	chartHeight = 150
+)
+
+// htmlData adds chart geometry on top of the Report for the template.
+type htmlData struct {
+	Report
+	ChartWidth  int
+	ChartHeight int
+	RPSPoints   string
+}
+
+// rpsPoints scales the RPS series into SVG polyline coordinates.
+func rpsPoints(r Report) string {
+	n := len(r.TimeSeries)
+	if n == 0 {
+		return ""
+	}
+	var max float64
+	for _, b := range r.TimeSeries {
+		if b.RPS > max {
+			max = b.RPS
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+	var sb strings.Builder
+	for i, b := range r.TimeSeries {
+		x := 0.0
+		if n > 1 {
+			x = float64(i) * chartWidth / float64(n-1)
+		}
+		y := chartHeight - b.RPS/max*chartHeight
+		fmt.Fprintf(&sb, "%.1f,%.1f ", x, y)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// WriteHTML writes a self-contained HTML report.
+func WriteHTML(w io.Writer, r Report) error {
+	return htmlReport.Execute(w, htmlData{
+		Report:      r,
+		ChartWidth:  chartWidth,
+		ChartHeight: chartHeight,
+		RPSPoints:   rpsPoints(r),
+	})
+}
@@ -0,0 +1,305 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// SchemaVersion is bumped whenever a field in the JSON summary changes meaning
+// or is removed. Adding fields does not bump it.
+const SchemaVersion = 1
+
+// Summary is the JSON document written for a run. All durations are integer
+// nanoseconds; their field names end in _ns.
+type Summary struct {
+	SchemaVersion int             `json:"schema_version"`
+	StartedAt     time.Time       `json:"started_at"`
+	Target        SummaryTarget   `json:"target"`
+	Requests      SummaryRequests `json:"requests"`
+	Latency       SummaryLatency  `json:"latency_ns"`
+	Throughput    SummaryRate     `json:"throughput"`
+	ResponseSize  SummarySize     `json:"response_size_bytes"`
+	Errors        SummaryErrors   `json:"errors"`
+	// Suspect is set when the results are likely invalid, e.g. few
+	// requests completed and most failed; it says why.
+	Suspect string `json:"suspect,omitempty"`
+	// Preflight lists the checks made before the run, e.g. to see which
+	// one failed and why.
+	Preflight []SummaryPreflight `json:"preflight,omitempty"`
+	// Steps has one entry per level of a --step-concurrency run.
+	Steps []SummaryStep `json:"steps,omitempty"`
+	// SLO is present when the run had pass/fail thresholds.
+	SLO map[string]SLO `json:"slo,omitempty"`
+	// TimeSeries has one entry per flushed 1s bucket, e.g. to see when
+	// errors started.
+	TimeSeries []SummaryBucket `json:"timeseries,omitempty"`
+}
+
+// SummaryPreflight is one preflight check. Status is ok, warning, failed
+// (the run will likely fail) or skipped; Detail is what the check printed.
+type SummaryPreflight struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// SummaryStep is one concurrency level of a step load test, counting the
+// requests that completed while it was held.
+type SummaryStep struct {
+	Concurrency  int     `json:"concurrency"`
+	DurationNs   int64   `json:"duration_ns"`
+	Requests     uint64  `json:"requests"`
+	Errors       uint64  `json:"errors"`
+	RPS          float64 `json:"rps"`
+	LatencyP50Ns int64   `json:"latency_p50_ns"`
+	LatencyP99Ns int64   `json:"latency_p99_ns"`
+}
+
+// SummaryBucket is one interval of the time series.
+type SummaryBucket struct {
+	StartNs    int64   `json:"start_ns"`
+	DurationNs int64   `json:"duration_ns"`
+	Requests   uint64  `json:"requests"`
+	Successes  uint64  `json:"successes"`
+	Errors     uint64  `json:"errors"`
+	RPS        float64 `json:"rps"`
+}
+
+// SummaryErrors breaks failed requests down by category. Every known
+// category is always present (with a zero count if unused) so consumers can
+// rely on the keys.
+type SummaryErrors struct {
+	Total      uint64                          `json:"total"`
+	Categories map[string]SummaryErrorCategory `json:"categories"`
+}
+
+// SummaryErrorCategory is the count and a few sample messages for one category.
+type SummaryErrorCategory struct {
+	Count   uint64   `json:"count"`
+	Samples []string `json:"samples"`
+}
+
+// SummaryTarget echoes the configuration the run was started with.
+type SummaryTarget struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	Connections int    `json:"connections"`
+	Workers     int    `json:"workers"`
+	Pipeline    int    `json:"pipeline"`
+	DurationNs  int64  `json:"duration_ns"`
+	// RunID is the --run-id every request carried as X-Benchmark-Run.
+	RunID string `json:"run_id,omitempty"`
+}
+
+// SummaryRequests holds the request and byte totals.
+type SummaryRequests struct {
+	Total     uint64 `json:"total"`
+	Successes uint64 `json:"successes"`
+	Errors    uint64 `json:"errors"`
+	BytesSent uint64 `json:"bytes_sent"`
+	BytesRecv uint64 `json:"bytes_recv"`
+	ElapsedNs int64  `json:"elapsed_ns"`
+	// LatencySamples is how many requests the latency percentiles are based on.
+	LatencySamples uint64 `json:"latency_samples"`
+	// ConnRotations is how many connections were closed by
+	// --max-requests-per-conn.
+	ConnRotations uint64 `json:"conn_rotations"`
+	// Retries is how many failed attempts were re-sent under --retries.
+	Retries uint64 `json:"retries"`
+	// Discarded is how many warm-up requests --discard-first-per-conn left
+	// out of the stats.
+	Discarded uint64 `json:"discarded"`
+	// NotModified is how many responses were 304s; they are included in
+	// Successes.
+	NotModified uint64 `json:"not_modified"`
+	// RateLimited is how many responses were 429s counted on their own
+	// (--count-429 separate); they are in neither Successes nor Errors.
+	RateLimited uint64 `json:"rate_limited"`
+	// StatusCodes counts responses by HTTP status code, keyed by the code
+	// as a string; "0" is failed requests that got no response.
+	StatusCodes map[int]uint64 `json:"status_codes,omitempty"`
+	// Undecoded counts, by Content-Encoding, responses --decode could not
+	// decode.
+	Undecoded map[string]uint64 `json:"undecoded,omitempty"`
+}
+
+// SummaryLatency holds latency percentiles in nanoseconds.
+type SummaryLatency struct {
+	P2_5  int64 `json:"p2_5"`
+	P50   int64 `json:"p50"`
+	P97_5 int64 `json:"p97_5"`
+	P99   int64 `json:"p99"`
+	Avg   int64 `json:"avg"`
+	Stdev int64 `json:"stdev"`
+	Max   int64 `json:"max"`
+}
+
+// SummarySize holds response body size percentiles of successful requests
+// in bytes; percentiles are within about 3%, Avg and Max are exact.
+type SummarySize struct {
+	Count uint64  `json:"count"`
+	P2_5  uint64  `json:"p2_5"`
+	P50   uint64  `json:"p50"`
+	P97_5 uint64  `json:"p97_5"`
+	P99   uint64  `json:"p99"`
+	Avg   float64 `json:"avg"`
+	Stdev float64 `json:"stdev"`
+	Max   uint64  `json:"max"`
+}
+
+// SummaryRate holds requests/sec and bytes/sec statistics from 1s buckets.
+type SummaryRate struct {
+	RPSAvg       float64 `json:"rps_avg"`
+	RPSP1        float64 `json:"rps_p1"`
+	RPSP2_5      float64 `json:"rps_p2_5"`
+	RPSP50       float64 `json:"rps_p50"`
+	RPSP97_5     float64 `json:"rps_p97_5"`
+	RPSStdev     float64 `json:"rps_stdev"`
+	RPSMin       float64 `json:"rps_min"`
+	BytesPerSAvg float64 `json:"bytes_per_s_avg"`
+}
+
+func newSummaryErrors(s stats.Snapshot) SummaryErrors {
+	out := SummaryErrors{
+		Total:      s.Errors,
+		Categories: make(map[string]SummaryErrorCategory),
+	}
+	for _, cat := range stats.ErrorCategories() {
+		samples := s.ErrorSamples[cat]
+		if samples == nil {
+			samples = []string{}
+		}
+		out.Categories[string(cat)] = SummaryErrorCategory{
+			Count:   s.ErrorsByCategory[cat],
+			Samples: samples,
+		}
+	}
+	return out
+}
+
+// NewSummary converts a Report into its JSON document form.
+func NewSummary(r Report) Summary {
+	s := r.Snapshot
+	return Summary{
+		SchemaVersion: SchemaVersion,
+		StartedAt:     r.Meta.StartedAt,
+		Target: SummaryTarget{
+			Method:      r.Meta.Method,
+			URL:         r.Meta.URL,
+			Connections: r.Meta.Connections,
+			Workers:     r.Meta.Workers,
+			Pipeline:    r.Meta.Pipeline,
+			DurationNs:  r.Meta.Duration.Nanoseconds(),
+			RunID:       s.RunID,
+		},
+		Requests: SummaryRequests{
+			Total:          s.TotalRequests,
+			Successes:      s.Successes,
+			Errors:         s.Errors,
+			BytesSent:      s.TotalBytesSent,
+			BytesRecv:      s.TotalBytesRecv,
+			ElapsedNs:      s.Duration.Nanoseconds(),
+			LatencySamples: s.LatencySampleCount,
+			ConnRotations:  s.ConnRotations,
+			Retries:        s.Retries,
+			Discarded:      s.Discarded,
+			NotModified:    s.NotModified,
+			RateLimited:    s.RateLimited,
+			StatusCodes:    s.StatusCounts,
+			Undecoded:      s.Undecoded,
+		},
+		Latency: SummaryLatency{
+			P2_5:  s.LatencyP25.Nanoseconds(),
+			P50:   s.LatencyP50.Nanoseconds(),
+			P97_5: s.LatencyP975.Nanoseconds(),
+			P99:   s.LatencyP99.Nanoseconds(),
+			Avg:   s.LatencyAvg.Nanoseconds(),
+			Stdev: s.LatencyStdev.Nanoseconds(),
+			Max:   s.LatencyMax.Nanoseconds(),
+		},
+		Throughput: SummaryRate{
+			RPSAvg:       s.RequestsPerSAvg,
+			RPSP1:        s.RPSP01,
+			RPSP2_5:      s.RPSP025,
+			RPSP50:       s.RPSP50,
+			RPSP97_5:     s.RPSP975,
+			RPSStdev:     s.RPSStdev,
+			RPSMin:       s.RPSMin,
+			BytesPerSAvg: s.BytesPerSAvg,
+		},
+		ResponseSize: SummarySize{
+			Count: s.RespSizeCount,
+			P2_5:  s.RespSizeP25,
+			P50:   s.RespSizeP50,
+			P97_5: s.RespSizeP975,
+			P99:   s.RespSizeP99,
+			Avg:   s.RespSizeAvg,
+			Stdev: s.RespSizeStdev,
+			Max:   s.RespSizeMax,
+		},
+		Errors:     newSummaryErrors(s),
+		Suspect:    s.Suspect,
+		Preflight:  newSummaryPreflight(s.Preflight),
+		Steps:      newSummarySteps(s.LoadSteps),
+		SLO:        r.Meta.SLOs,
+		TimeSeries: newSummaryBuckets(r.TimeSeries),
+	}
+}
+
+func newSummaryPreflight(checks []stats.PreflightCheck) []SummaryPreflight {
+	if len(checks) == 0 {
+		return nil
+	}
+	out := make([]SummaryPreflight, len(checks))
+	for i, c := range checks {
+		out[i] = SummaryPreflight{Name: c.Name, Status: string(c.Status), Detail: c.Detail}
+	}
+	return out
+}
+
+func newSummarySteps(steps []stats.LoadStep) []SummaryStep {
+	if len(steps) == 0 {
+		return nil
+	}
+	out := make([]SummaryStep, len(steps))
+	for i, st := range steps {
+		out[i] = SummaryStep{
+			Concurrency:  st.Concurrency,
+			DurationNs:   st.Duration.Nanoseconds(),
+			Requests:     st.Requests,
+			Errors:       st.Errors,
+			RPS:          st.RequestsPerS,
+			LatencyP50Ns: st.LatencyP50.Nanoseconds(),
+			LatencyP99Ns: st.LatencyP99.Nanoseconds(),
+		}
+	}
+	return out
+}
+
+func newSummaryBuckets(buckets []stats.Bucket) []SummaryBucket {
+	if len(buckets) == 0 {
+		return nil
+	}
+	out := make([]SummaryBucket, len(buckets))
+	for i, b := range buckets {
+		out[i] = SummaryBucket{
+			StartNs:    b.Start.Nanoseconds(),
+			DurationNs: b.Duration.Nanoseconds(),
+			Requests:   b.Requests,
+			Successes:  b.Successes,
+			Errors:     b.Errors,
+			RPS:        b.RPS,
+		}
+	}
+	return out
+}
+
+// WriteJSON writes the run summary as indented JSON.
+func WriteJSON(w io.Writer, r Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewSummary(r))
+}
@@ -0,0 +1,69 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// RawColumns are the columns of the per-request log written by RawWriter.
+// Like TSVColumns they are part of the output format.
+var RawColumns = []string{
+	"start", "start_unix_ns", "latency_ms", "status", "success", "error", "target", "retries",
+}
+
+// RawWriter logs one CSV row per recorded request, with its absolute start
+// time, to line benchmark spikes up with server logs and traces. Record is
+// safe for concurrent use and fits engine.Config.OnResult; rows appear in
+// completion order, so with more than one slot their start times can
+// interleave.
+type RawWriter struct {
+	mu  sync.Mutex
+	cw  *csv.Writer
+	err error
+}
+
+// NewRawWriter writes the header row to w and returns the writer.
+func NewRawWriter(w io.Writer) *RawWriter {
+	rw := &RawWriter{cw: csv.NewWriter(w)}
+	rw.err = rw.cw.Write(RawColumns)
+	return rw
+}
+
+// Record logs r. Abandoned and discarded requests are skipped, as in the
+// stats. The first write error stops the log; Flush returns it.
+func (rw *RawWriter) Record(r stats.Result) {
+	if r.Abandoned || r.Discarded {
+		return
+	}
+	row := []string{
+		r.Start.UTC().Format(time.RFC3339Nano),
+		strconv.FormatInt(r.Start.UnixNano(), 10),
+		strconv.FormatFloat(float64(r.Latency)/float64(time.Millisecond), 'f', 3, 64),
+		strconv.Itoa(r.Status),
+		strconv.FormatBool(r.Success),
+		string(r.ErrorCategory),
+		strconv.Itoa(r.Target),
+		strconv.Itoa(r.Retries),
+	}
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.err == nil {
+		rw.err = rw.cw.Write(row)
+	}
+}
+
+// Flush writes out buffered rows and returns the first error.
+func (rw *RawWriter) Flush() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	rw.cw.Flush()
+	if rw.err == nil {
+		rw.err = rw.cw.Error()
+	}
+	return rw.err
+}
@@ -0,0 +1,49 @@
+package export
+
+import (
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// Meta describes the benchmark that produced a Report.
+type Meta struct {
+	Method      string
+	URL         string
+	Connections int
+	Workers     int
+	Pipeline    int
+	Duration    time.Duration
+	StartedAt   time.Time
+	// SLOs holds the outcome of each pass/fail threshold the run was given,
+	// keyed by name (e.g. "min_rps").
+	SLOs map[string]SLO
+}
+
+// SLO is the outcome of one run threshold such as --min-rps.
+type SLO struct {
+	Target   float64 `json:"target"`
+	Measured float64 `json:"measured"`
+	// Evaluated is false when the run gave nothing to measure (e.g. it ended
+	// within the warm-up); such a threshold passes.
+	Evaluated bool `json:"evaluated"`
+	Passed    bool `json:"passed"`
+}
+
+// Report is everything the exporters need about a finished run.
+type Report struct {
+	Meta       Meta
+	Snapshot   stats.Snapshot
+	TimeSeries []stats.Bucket
+	Latencies  []time.Duration // sorted ascending
+}
+
+// NewReport builds a Report from a finished run's collector and final snapshot.
+func NewReport(meta Meta, snap stats.Snapshot, c *stats.Collector) Report {
+	r := Report{Meta: meta, Snapshot: snap}
+	if c != nil {
+		r.TimeSeries = c.TimeSeries()
+		r.Latencies = c.LatencySamples()
+	}
+	return r
+}
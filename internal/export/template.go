@@ -0,0 +1,54 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/ui"
+)
+
+// templateFuncs are the helpers available to --output template besides the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	// humanizeBytes formats a byte count the way the report does: 1.50 MB.
+	"humanizeBytes": func(v any) (string, error) {
+		switch n := v.(type) {
+		case uint64:
+			return ui.HumanizeBytes(n), nil
+		case int:
+			return ui.HumanizeBytes(uint64(max(n, 0))), nil
+		case int64:
+			return ui.HumanizeBytes(uint64(max(n, 0))), nil
+		case float64:
+			return ui.HumanizeBytes(uint64(max(n, 0))), nil
+		}
+		return "", fmt.Errorf("humanizeBytes: want a number, got %T", v)
+	},
+	// ms formats a duration in milliseconds with three decimals: 1.500.
+	"ms": func(d time.Duration) string {
+		return strconv.FormatFloat(float64(d)/1e6, 'f', 3, 64)
+	},
+}
+
+// ParseTemplate parses a --output template and executes it once against an
+// empty Report, so a syntax error or a misspelt field is reported before the
+// run rather than after it. name is used in error messages.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Execute(io.Discard, Report{}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// WriteTemplate executes t with r as its data: .Meta and .Snapshot hold the
+// run's description and final results.
+func WriteTemplate(w io.Writer, t *template.Template, r Report) error {
+	return t.Execute(w, r)
+}
@@ -0,0 +1,55 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+func TestWriteTemplate_RendersReport(t *testing.T) {
+	tmpl, err := ParseTemplate("test", `{{.Meta.Method}} {{.Meta.URL}} x{{.Meta.Connections}}
+total={{.Snapshot.TotalRequests}} errors={{.Snapshot.Errors}} rps={{printf "%.1f" .Snapshot.RequestsPerSAvg}}
+p50={{ms .Snapshot.LatencyP50}}ms p99={{ms .Snapshot.LatencyP99}}ms
+recv={{humanizeBytes .Snapshot.TotalBytesRecv}} avg={{humanizeBytes .Snapshot.RespSizeAvg}}
+{{range $name, $slo := .Meta.SLOs}}{{$name}} passed={{$slo.Passed}}{{end}}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Report{
+		Meta: Meta{
+			Method: "GET", URL: "http://127.0.0.1:8080/", Connections: 8, Duration: 10 * time.Second,
+			SLOs: map[string]SLO{"min_rps": {Target: 100, Measured: 150, Evaluated: true, Passed: true}},
+		},
+		Snapshot: stats.Snapshot{
+			TotalRequests:   1500,
+			Errors:          3,
+			RequestsPerSAvg: 150,
+			LatencyP50:      1500 * time.Microsecond,
+			LatencyP99:      42 * time.Millisecond,
+			TotalBytesRecv:  2_500_000,
+			RespSizeAvg:     1500,
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteTemplate(&buf, tmpl, r); err != nil {
+		t.Fatal(err)
+	}
+	want := `GET http://127.0.0.1:8080/ x8
+total=1500 errors=3 rps=150.0
+p50=1.500ms p99=42.000ms
+recv=2.50 MB avg=1.50 KB
+min_rps passed=true
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestParseTemplate_RejectsUnknownField(t *testing.T) {
+	if _, err := ParseTemplate("test", "{{.Snapshot.NoSuchField}}"); err == nil {
+		t.Error("ParseTemplate with an unknown field succeeded")
+	}
+}
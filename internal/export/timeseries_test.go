@@ -0,0 +1,39 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+func TestTimeSeries_ErrorCounts(t *testing.T) {
+	r := sampleReport()
+	r.TimeSeries = []stats.Bucket{
+		{Start: 0, Duration: time.Second, Requests: 3, Successes: 3},
+		{Start: time.Second, Duration: time.Second, Requests: 4, Successes: 1, Errors: 3},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTimeSeriesCSV(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	col := map[string]int{}
+	for i, name := range rows[0] {
+		col[name] = i
+	}
+	if got := rows[2][col["errors"]] + "/" + rows[2][col["successes"]]; got != "3/1" {
+		t.Errorf("second CSV bucket errors/successes = %s, want 3/1", got)
+	}
+
+	ts := NewSummary(r).TimeSeries
+	if len(ts) != 2 || ts[1].Errors != 3 || ts[1].Successes != 1 || ts[1].StartNs != int64(time.Second) {
+		t.Errorf("JSON time series = %+v", ts)
+	}
+}
@@ -0,0 +1,35 @@
+package export
+
+// TrendPoint is one run of a trend with its change from the run before.
+type TrendPoint struct {
+	Run HistoryRun
+	// RPSChange and P99Change are relative to the previous run, e.g. -0.12
+	// for 12% lower; 0 for the first run or when the previous value is 0.
+	RPSChange float64
+	P99Change float64
+	// Regressed is set when req/s fell or p99 rose by more than the
+	// threshold.
+	Regressed bool
+}
+
+// Trend compares each run with the one before it and flags those whose
+// req/s dropped or p99 latency grew by more than threshold (0.1 = 10%).
+// runs must be oldest first, as ReadHistory returns them.
+func Trend(runs []HistoryRun, threshold float64) []TrendPoint {
+	points := make([]TrendPoint, len(runs))
+	for i, run := range runs {
+		points[i].Run = run
+		if i == 0 {
+			continue
+		}
+		prev := runs[i-1]
+		if prev.RPS > 0 {
+			points[i].RPSChange = (run.RPS - prev.RPS) / prev.RPS
+		}
+		if prev.P99 > 0 {
+			points[i].P99Change = float64(run.P99-prev.P99) / float64(prev.P99)
+		}
+		points[i].Regressed = points[i].RPSChange < -threshold || points[i].P99Change > threshold
+	}
+	return points
+}
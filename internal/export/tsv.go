@@ -0,0 +1,40 @@
+package export
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// TSVColumns are the columns of the one-row summary written by WriteTSV. They
+// are part of the output format: append new ones at the end, never reorder.
+var TSVColumns = []string{
+	"method", "url", "connections", "duration_s", "total", "rps", "p50_ms", "p99_ms", "errors", "bytes",
+}
+
+// WriteTSV writes a header row and a single tab-separated data row of key
+// metrics, for pasting a run into a spreadsheet. bytes is sent + received.
+func WriteTSV(w io.Writer, r Report) error {
+	s := r.Snapshot
+	ms := func(ns int64) string { return strconv.FormatFloat(float64(ns)/1e6, 'f', 3, 64) }
+	row := []string{
+		tsvField(r.Meta.Method),
+		tsvField(r.Meta.URL),
+		strconv.Itoa(r.Meta.Connections),
+		strconv.FormatFloat(r.Meta.Duration.Seconds(), 'f', 3, 64),
+		strconv.FormatUint(s.TotalRequests, 10),
+		strconv.FormatFloat(s.RequestsPerSAvg, 'f', 2, 64),
+		ms(s.LatencyP50.Nanoseconds()),
+		ms(s.LatencyP99.Nanoseconds()),
+		strconv.FormatUint(s.Errors, 10),
+		strconv.FormatUint(s.TotalBytesSent+s.TotalBytesRecv, 10),
+	}
+	_, err := io.WriteString(w, strings.Join(TSVColumns, "\t")+"\n"+strings.Join(row, "\t")+"\n")
+	return err
+}
+
+// tsvField keeps a value on one cell: TSV has no quoting, so tabs and line
+// breaks become spaces.
+func tsvField(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
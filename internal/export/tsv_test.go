@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+func TestWriteTSV_RowParsesBack(t *testing.T) {
+	r := Report{
+		Meta: Meta{Method: "POST", URL: "http://127.0.0.1:8080/a\tb", Connections: 12, Duration: 30 * time.Second},
+		Snapshot: stats.Snapshot{
+			TotalRequests:   4500,
+			Errors:          7,
+			RequestsPerSAvg: 150,
+			LatencyP50:      1500 * time.Microsecond,
+			LatencyP99:      42 * time.Millisecond,
+			TotalBytesSent:  1000,
+			TotalBytesRecv:  24,
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteTSV(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want header + one row:\n%s", len(lines), buf.String())
+	}
+	header, row := strings.Split(lines[0], "\t"), strings.Split(lines[1], "\t")
+	if len(row) != len(header) {
+		t.Fatalf("row has %d fields, header %d", len(row), len(header))
+	}
+	got := map[string]string{}
+	for i, col := range header {
+		got[col] = row[i]
+	}
+	want := map[string]string{
+		"method": "POST", "url": "http://127.0.0.1:8080/a b", "connections": "12", "duration_s": "30.000",
+		"total": "4500", "rps": "150.00", "p50_ms": "1.500", "p99_ms": "42.000", "errors": "7", "bytes": "1024",
+	}
+	for col, v := range want {
+		if got[col] != v {
+			t.Errorf("%s = %q, want %q", col, got[col], v)
+		}
+	}
+}
@@ -1,35 +1,175 @@
 package stats
 
 import (
+	"maps"
 	"math"
+	"net/http"
+	"slices"
 	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// Default retention limits; a memory budget replaces them (see RetentionFor).
 const maxLatencySamples = 50000
 const maxBucketSamples = 600 // ~10 min at 1s buckets
 
+// recentBuckets is the window, in 1s buckets, of Snapshot.RecentErrorRate.
+const recentBuckets = 5
+
 // Snapshot represents a point-in-time view of collected metrics.
 type Snapshot struct {
-	TotalRequests   uint64
-	Successes       uint64
-	Errors          uint64
-	TotalBytesSent  uint64
-	TotalBytesRecv  uint64
+	TotalRequests  uint64
+	Successes      uint64
+	Errors         uint64
+	TotalBytesSent uint64
+	TotalBytesRecv uint64
+	// ChunkedResponses counts responses without a Content-Length (chunked or
+	// streamed until close); their bytes are what was actually drained.
+	ChunkedResponses uint64
+	// Undecoded counts, by Content-Encoding, responses --decode left
+	// encoded because it does not support the encoding.
+	Undecoded map[string]uint64
+	// Abandoned counts requests still in flight when the drain timeout expired.
+	Abandoned uint64
+	// Discarded counts warm-up requests left out of every other count except
+	// bytes (see --discard-first-per-conn).
+	Discarded uint64
+	// ConnRotations counts requests sent with Connection: close to force a
+	// fresh connection (see --max-requests-per-conn).
+	ConnRotations uint64
+	// Retries counts requests re-sent after a failed attempt (see --retries);
+	// the failed attempts themselves are not in TotalRequests.
+	Retries uint64
+	// NotModified counts 304 responses, i.e. conditional requests the server
+	// answered from the client's copy. They are also counted as successes.
+	NotModified uint64
+	// RateLimited counts 429 responses counted on their own (see
+	// --count-429): they are in TotalRequests but neither Successes nor
+	// Errors.
+	RateLimited uint64
+	// StatusCounts counts responses by HTTP status code; 0 holds failed
+	// requests that got no response. nil until a status is seen.
+	StatusCounts map[int]uint64
+	// InFlight is the number of requests in progress when the snapshot was taken.
+	InFlight        int64
 	Duration        time.Duration
 	RequestsPerSAvg float64
 	BytesPerSAvg    float64
+	// RecentErrorRate is the fraction of requests that failed over the last
+	// recentBuckets flushed buckets, so the HUD can tell current errors from
+	// ones that only happened earlier in the run.
+	RecentErrorRate float64
+
+	// Phases breaks the run's wall-clock time down by stage. Only the final
+	// snapshot has it; the orchestrator fills it in once the run is over.
+	Phases []Phase
+	// Preflight lists the checks made before the run started, in order;
+	// set like Phases.
+	Preflight []PreflightCheck
+	// RunID is Config.RunID; set like Phases.
+	RunID string
+	// Suspect, if set, says why the run's numbers are likely not a valid
+	// measurement (few requests, mostly failed); set like Phases.
+	Suspect string
+	// LoadSteps has one entry per Config.ConcurrencySteps level; set like
+	// Phases.
+	LoadSteps []LoadStep
+	// Paused is set on live snapshots while Config.Controls has paused the
+	// run.
+	Paused bool
+	// With Config.BackoffOnErrors, ActiveSlots of the run's Slots may send
+	// right now; set on live snapshots like Paused and on the final one,
+	// which also has LowestSlots, the fewest that were active at any time.
+	// All three are 0 without it.
+	Slots       int
+	ActiveSlots int
+	LowestSlots int
+	// OpenLoop marks a run whose requests were paced at a set rate rather
+	// than sent as soon as the previous response arrived (closed loop). Like
+	// Phases, the orchestrator sets it on the final snapshot.
+	OpenLoop bool
+	// ConnsOpened is how many connections the client dialed; with
+	// TotalRequests it tells how well connections were reused. Like Phases,
+	// the orchestrator sets it on the final snapshot.
+	ConnsOpened uint64
+	// DNSLookups is how many host lookups those dials made when httpcl
+	// resolves names itself (--dns-cache); set like ConnsOpened.
+	DNSLookups uint64
+
+	// ErrorsByCategory breaks Errors down by cause; ErrorSamples keeps a few
+	// distinct messages for each category seen.
+	ErrorsByCategory map[ErrorCategory]uint64
+	ErrorSamples     map[ErrorCategory][]string
+
+	// Latency (ms) – percentiles and stats. Only the first Retention().LatencySamples
+	// requests are sampled; LatencySampleCount says how many contributed.
+	LatencySampleCount uint64
+	LatencyP25         time.Duration
+	LatencyP50         time.Duration
+	LatencyP975        time.Duration
+	LatencyP99         time.Duration
+	LatencyAvg         time.Duration
+	LatencyStdev       time.Duration
+	LatencyMax         time.Duration
+
+	// Connection-acquire wait, sampled like latency. Only tracked (see
+	// WithConnWait) when the connection pool is capped, since that is the
+	// only case where requests queue for a connection.
+	ConnWaitTracked bool
+	ConnWaitP50     time.Duration
+	ConnWaitP99     time.Duration
+	ConnWaitMax     time.Duration
+	// ConnWaitTotal is the summed wait of all requests. ConnWaitShare, set
+	// by the engine on the final snapshot, is its share of the slots' time
+	// in the load phase; a high share means the pool limited the rate.
+	ConnWaitTotal time.Duration
+	ConnWaitShare float64
 
-	// Latency (ms) – percentiles and stats
-	LatencyP25  time.Duration
-	LatencyP50  time.Duration
-	LatencyP975 time.Duration
-	LatencyP99  time.Duration
-	LatencyAvg  time.Duration
-	LatencyStdev time.Duration
-	LatencyMax  time.Duration
+	// Connection setup, only tracked with WithHandshakes: connections and
+	// TLS handshakes made, their rates over the run, and percentiles of the
+	// time to set up a connection (TCP connect plus TLS handshake).
+	HandshakesTracked bool
+	Connects          uint64
+	TLSHandshakes     uint64
+	ConnectsPerS      float64
+	TLSHandshakesPerS float64
+	HandshakeP50      time.Duration
+	HandshakeP90      time.Duration
+	HandshakeP99      time.Duration
+	HandshakeMax      time.Duration
+
+	// Response body sizes of successful requests, in bytes: percentiles
+	// from a histogram (see sizeHistogram), exact average and maximum.
+	RespSizeCount uint64
+	RespSizeP25   uint64
+	RespSizeP50   uint64
+	RespSizeP975  uint64
+	RespSizeP99   uint64
+	RespSizeAvg   float64
+	RespSizeStdev float64
+	RespSizeMax   uint64
+
+	// Conns holds per-connection counts, worst first (see ConnStats); nil
+	// unless the collector counts per connection (see WithPerConn).
+	Conns []ConnStats
+	// RemoteAddrs counts requests per server address connected to, busiest
+	// first; nil unless WithRemoteAddrs. ResolvedAddrs is what the target
+	// resolved to before the run; the collector never sets it.
+	RemoteAddrs   []AddrCount
+	ResolvedAddrs []string
+	// BodySends counts the requests sent with each body of a --body-cycle
+	// corpus, in corpus order; the collector never sets it.
+	BodySends []uint64
+	// Steps breaks transactions down by step, in order; nil unless the
+	// collector counts steps (see WithSteps). TotalRequests and the latency
+	// percentiles then count whole transactions.
+	Steps []StepStats
+	// Targets breaks requests down by target URL, in the order given, in the
+	// same form as Steps; nil unless the collector counts targets (see
+	// WithTargets).
+	Targets []StepStats
 
 	// Throughput (Req/Sec and Bytes/Sec) – percentiles from 1s buckets
 	RPSP01   float64
@@ -47,52 +187,297 @@ type Snapshot struct {
 	BytesPerSMin   float64
 }
 
+// Bucket is one flushed interval of the throughput time series. Start is the
+// offset of the interval from the beginning of the run.
+type Bucket struct {
+	Start     time.Duration
+	Duration  time.Duration
+	Requests  uint64
+	Successes uint64
+	Errors    uint64
+	BytesSent uint64
+	BytesRecv uint64
+	RPS       float64
+	BytesPerS float64
+	// PeakInFlight is the highest number of concurrent requests seen during
+	// the interval.
+	PeakInFlight int64
+}
+
+// Phase is one stage of a run and how long it took.
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// LoadStep is the outcome of one concurrency level of a step load test:
+// the requests that completed while it was held.
+type LoadStep struct {
+	Concurrency  int
+	Duration     time.Duration
+	Requests     uint64
+	Errors       uint64
+	RequestsPerS float64
+	LatencyP50   time.Duration
+	LatencyP99   time.Duration
+}
+
+// PreflightStatus is the outcome of a preflight check.
+type PreflightStatus string
+
+const (
+	PreflightOK      PreflightStatus = "ok"
+	PreflightWarning PreflightStatus = "warning"
+	PreflightFailed  PreflightStatus = "failed"  // the run will likely fail
+	PreflightSkipped PreflightStatus = "skipped" // the check did not apply
+)
+
+// PreflightCheck is one check made before the run (DNS, TLS, ulimit) and
+// what it found, e.g. {"TLS", PreflightFailed, "certificate expired on
+// 2024-01-31"}.
+type PreflightCheck struct {
+	Name   string
+	Status PreflightStatus
+	Detail string
+}
+
+// Result describes the outcome of a single request.
+type Result struct {
+	// Start is when the request (its final attempt, if retried) was sent.
+	// The collector ignores it; it is for other consumers of results.
+	Start   time.Time
+	Latency time.Duration
+	Success bool
+	// Status is the HTTP status code, 0 if no response was received. The
+	// collector counts it in Snapshot.StatusCounts and NotModified.
+	Status    int
+	BytesSent uint64
+	BytesRecv uint64
+	Chunked   bool // response had no Content-Length
+	// RateLimited marks a 429 counted on its own rather than as a success
+	// or an error; Success is false.
+	RateLimited bool
+	// Undecoded is the Content-Encoding of a response that --decode could
+	// not decode, so its body was checked and counted as received.
+	Undecoded string
+	// Abandoned marks a request cancelled by the drain timeout. It is counted
+	// separately and contributes nothing to latency or success/error totals.
+	Abandoned bool
+	// Discarded marks a warm-up request left out of the stats (see
+	// --discard-first-per-conn). Only its bytes and the discard are counted.
+	Discarded bool
+	// Rotated marks a request that closed its connection on purpose so the
+	// next one has to dial.
+	Rotated bool
+	// Retries is how many times the request was re-sent before this, its
+	// final attempt (see --retries).
+	Retries int
+	// ConnLocal and ConnRemote are the client and server addresses of the
+	// connection the request was sent on. Ignored unless the collector
+	// counts per connection or remote address (see WithPerConn and
+	// WithRemoteAddrs).
+	ConnLocal  string
+	ConnRemote string
+	// ConnWait is how long the request waited to get a connection; it is
+	// part of Latency. Ignored unless the collector tracks it.
+	ConnWait time.Duration
+	// Connect and TLSHandshake are how long the request spent setting up a
+	// new connection: the TCP connect and the TLS handshake, each zero if
+	// it did not happen. Ignored unless the collector tracks handshakes.
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	// Target is 1 + the index of the target the request was sent to.
+	// Ignored unless the collector counts targets (see WithTargets).
+	Target int
+	// ErrorCategory and ErrorMessage describe a failed request.
+	ErrorCategory ErrorCategory
+	ErrorMessage  string
+}
+
 // Collector aggregates metrics from workers in a thread-safe way.
 type Collector struct {
+	// now is the clock (see WithClock); time.Now by default. Its readings carry the
+	// monotonic clock, so intervals are immune to wall-clock steps, but the
+	// bucket code still guards against non-positive intervals.
+	now       func() time.Time
 	startTime time.Time
 
-	totalRequests   uint64
+	totalRequests  uint64
 	successes      uint64
 	errors         uint64
 	totalBytesSent uint64
 	totalBytesRecv uint64
+	chunked        uint64
+	abandoned      uint64
+	discarded      uint64
+	rotations      uint64
+	retries        uint64
+	notModified    uint64
+	rateLimited    uint64
+	connWaitTotal  int64
+	inFlight       int64
+	peakInFlight   int64 // since the last bucket flush
+
+	samples       *sampleStore
+	trackConnWait bool
+	handshakes    *handshakeCounts // nil unless WithHandshakes
+	sizes         sizeHistogram
+	statuses      statusCounts
 
-	mu           sync.Mutex
-	latencySamples []time.Duration
+	// mu guards the fields below. The hot path of RecordResult only takes
+	// it for results that need them: errors, per-connection or per-target
+	// counts.
+	mu              sync.Mutex
+	perConn         map[string]*ConnStats // keyed by ConnLocal; nil unless WithPerConn
+	remoteAddrs     map[string]uint64     // requests by ConnRemote; nil unless WithRemoteAddrs
+	stepNames       []string
+	steps           []stepCounts // by transaction step; nil unless WithSteps
+	targetNames     []string
+	targets         []stepCounts // by Result.Target; nil unless WithTargets
+	memoryBudget    uint64
+	retention       Retention
+	errorCounts     map[ErrorCategory]uint64
+	errorSamples    map[ErrorCategory][]string
+	undecoded       map[string]uint64 // by Result.Undecoded
 	lastBucketTime  time.Time
 	lastBucketReqs  uint64
+	lastBucketErrs  uint64
+	lastBucketSuccs uint64
 	lastBucketSent  uint64
 	lastBucketRecv  uint64
-	rpsBuckets      []float64
-	bytesPerSBuckets []float64
+	buckets         []Bucket
+}
+
+// Option configures a Collector.
+type Option func(*Collector)
+
+// WithClock makes the collector read time from now instead of time.Now, so
+// bucketing and elapsed time can be driven deterministically in tests.
+func WithClock(now func() time.Time) Option {
+	return func(c *Collector) {
+		if now != nil {
+			c.now = now
+		}
+	}
+}
+
+// WithConnWait makes the collector sample Result.ConnWait and report its
+// percentiles.
+func WithConnWait() Option {
+	return func(c *Collector) { c.trackConnWait = true }
 }
 
 // NewCollector creates a new Collector instance.
-func NewCollector() *Collector {
-	return &Collector{
-		startTime:     time.Now(),
-		lastBucketTime: time.Now(),
-		latencySamples: make([]time.Duration, 0, maxLatencySamples),
-		rpsBuckets:      make([]float64, 0, maxBucketSamples),
-		bytesPerSBuckets: make([]float64, 0, maxBucketSamples),
+func NewCollector(opts ...Option) *Collector {
+	c := &Collector{
+		now:          time.Now,
+		errorCounts:  make(map[ErrorCategory]uint64),
+		errorSamples: make(map[ErrorCategory][]string),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	c.retention = RetentionFor(c.memoryBudget, c.trackConnWait)
+	c.samples = newSampleStore(c.retention.LatencySamples, c.trackConnWait)
+	if c.handshakes != nil {
+		c.handshakes.samples = newSampleStore(c.retention.LatencySamples, false)
+	}
+	c.buckets = make([]Bucket, 0, c.retention.Buckets)
+	c.startTime = c.now()
+	c.lastBucketTime = c.startTime
+	return c
 }
 
 // Record records the outcome of a single request and bytes sent/received.
 func (c *Collector) Record(latency time.Duration, success bool, bytesSent, bytesRecv uint64) {
+	c.RecordResult(Result{Latency: latency, Success: success, BytesSent: bytesSent, BytesRecv: bytesRecv})
+}
+
+// RequestStarted marks a request as in flight. Every call must be paired with
+// RequestFinished once the response has been read (or the request failed).
+func (c *Collector) RequestStarted() {
+	n := atomic.AddInt64(&c.inFlight, 1)
+	for {
+		peak := atomic.LoadInt64(&c.peakInFlight)
+		if n <= peak || atomic.CompareAndSwapInt64(&c.peakInFlight, peak, n) {
+			return
+		}
+	}
+}
+
+// RequestFinished marks a request started with RequestStarted as done.
+func (c *Collector) RequestFinished() {
+	atomic.AddInt64(&c.inFlight, -1)
+}
+
+// RecordResult records the outcome of a single request.
+func (c *Collector) RecordResult(r Result) {
+	if r.Abandoned {
+		atomic.AddUint64(&c.abandoned, 1)
+		atomic.AddUint64(&c.totalBytesSent, r.BytesSent)
+		return
+	}
+	if r.Discarded {
+		atomic.AddUint64(&c.discarded, 1)
+		atomic.AddUint64(&c.totalBytesSent, r.BytesSent)
+		atomic.AddUint64(&c.totalBytesRecv, r.BytesRecv)
+		return
+	}
 	atomic.AddUint64(&c.totalRequests, 1)
-	atomic.AddUint64(&c.totalBytesSent, bytesSent)
-	atomic.AddUint64(&c.totalBytesRecv, bytesRecv)
-	if success {
+	atomic.AddUint64(&c.totalBytesSent, r.BytesSent)
+	atomic.AddUint64(&c.totalBytesRecv, r.BytesRecv)
+	switch {
+	case r.RateLimited:
+		atomic.AddUint64(&c.rateLimited, 1)
+	case r.Success:
 		atomic.AddUint64(&c.successes, 1)
-	} else {
+		c.sizes.add(r.BytesRecv)
+	default:
 		atomic.AddUint64(&c.errors, 1)
 	}
+	if r.Chunked {
+		atomic.AddUint64(&c.chunked, 1)
+	}
+	if r.Rotated {
+		atomic.AddUint64(&c.rotations, 1)
+	}
+	if r.Retries > 0 {
+		atomic.AddUint64(&c.retries, uint64(r.Retries))
+	}
+	if r.Status == http.StatusNotModified {
+		atomic.AddUint64(&c.notModified, 1)
+	}
+	failed := !r.Success && !r.RateLimited
+	c.statuses.add(r, failed)
 
+	c.samples.add(r.Latency, r.ConnWait)
+	if c.trackConnWait && r.ConnWait > 0 {
+		atomic.AddInt64(&c.connWaitTotal, int64(r.ConnWait))
+	}
+	if c.handshakes != nil {
+		c.handshakes.add(r)
+	}
+
+	// perConn, remoteAddrs and targets are set up by options and never
+	// replaced, so they can be checked without the lock.
+	target := r.Target > 0 && r.Target <= len(c.targets)
+	if !failed && !target && c.perConn == nil && c.remoteAddrs == nil && r.Undecoded == "" {
+		return
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if len(c.latencySamples) < maxLatencySamples {
-		c.latencySamples = append(c.latencySamples, latency)
+	if failed {
+		c.recordError(r.ErrorCategory, r.ErrorMessage)
+	}
+	if r.Undecoded != "" {
+		if c.undecoded == nil {
+			c.undecoded = make(map[string]uint64)
+		}
+		c.undecoded[r.Undecoded]++
+	}
+	c.recordConn(r)
+	if target {
+		c.targets[r.Target-1].add(r.Latency, !failed)
 	}
 }
 
@@ -166,7 +551,10 @@ func avgStdevMinFloat(s []float64) (avg, stdev, min float64) {
 
 // Snapshot returns a full snapshot including percentiles and throughput buckets.
 func (c *Collector) Snapshot() Snapshot {
-	elapsed := time.Since(c.startTime)
+	elapsed := c.now().Sub(c.startTime)
+	if elapsed < 0 {
+		elapsed = 0
+	}
 	elapsedSec := elapsed.Seconds()
 	if elapsedSec < 0.001 {
 		elapsedSec = 0.001
@@ -175,49 +563,110 @@ func (c *Collector) Snapshot() Snapshot {
 	totalReqs := atomic.LoadUint64(&c.totalRequests)
 	totalSent := atomic.LoadUint64(&c.totalBytesSent)
 	totalRecv := atomic.LoadUint64(&c.totalBytesRecv)
+	totalErrs := atomic.LoadUint64(&c.errors)
+	totalSuccs := atomic.LoadUint64(&c.successes)
 
 	c.mu.Lock()
-	// Flush a 1s bucket if enough time has passed
-	now := time.Now()
-	if now.Sub(c.lastBucketTime) >= time.Second && totalReqs > c.lastBucketReqs {
+	// Flush a 1s bucket if enough time has passed. Idle intervals are flushed
+	// too, so paced and bursty runs show their gaps in the time series.
+	now := c.now()
+	interval := now.Sub(c.lastBucketTime)
+	if interval < 0 {
+		// The clock went backwards: re-anchor without flushing, so the pending
+		// counts land in the next well-formed bucket instead of a garbage one.
+		c.lastBucketTime = now
+	} else if interval >= time.Second {
 		reqDelta := totalReqs - c.lastBucketReqs
 		sentDelta := totalSent - c.lastBucketSent
 		recvDelta := totalRecv - c.lastBucketRecv
-		secs := now.Sub(c.lastBucketTime).Seconds()
+		secs := interval.Seconds()
 		if secs > 0 {
-			c.rpsBuckets = append(c.rpsBuckets, float64(reqDelta)/secs)
-			bytesPerS := float64(sentDelta+recvDelta) / secs
-			c.bytesPerSBuckets = append(c.bytesPerSBuckets, bytesPerS)
-			if len(c.rpsBuckets) > maxBucketSamples {
-				c.rpsBuckets = c.rpsBuckets[1:]
-				c.bytesPerSBuckets = c.bytesPerSBuckets[1:]
+			c.buckets = append(c.buckets, Bucket{
+				Start:     max(c.lastBucketTime.Sub(c.startTime), 0),
+				Duration:  interval,
+				Requests:  reqDelta,
+				Successes: totalSuccs - c.lastBucketSuccs,
+				Errors:    totalErrs - c.lastBucketErrs,
+				BytesSent: sentDelta,
+				BytesRecv: recvDelta,
+				RPS:       float64(reqDelta) / secs,
+				BytesPerS: float64(sentDelta+recvDelta) / secs,
+				// Reset the peak to the current level for the next interval.
+				PeakInFlight: atomic.SwapInt64(&c.peakInFlight, atomic.LoadInt64(&c.inFlight)),
+			})
+			if len(c.buckets) > c.retention.Buckets {
+				c.buckets = c.buckets[1:]
 			}
 		}
 		c.lastBucketTime = now
 		c.lastBucketReqs = totalReqs
+		c.lastBucketErrs = totalErrs
+		c.lastBucketSuccs = totalSuccs
 		c.lastBucketSent = totalSent
 		c.lastBucketRecv = totalRecv
 	}
 
-	latencySamples := make([]time.Duration, len(c.latencySamples))
-	copy(latencySamples, c.latencySamples)
-	rpsBuckets := make([]float64, len(c.rpsBuckets))
-	copy(rpsBuckets, c.rpsBuckets)
-	bytesBuckets := make([]float64, len(c.bytesPerSBuckets))
-	copy(bytesBuckets, c.bytesPerSBuckets)
+	conns := c.connStats()
+	remoteAddrs := c.remoteAddrStats()
+	steps := breakdown(c.stepNames, c.steps)
+	targets := breakdown(c.targetNames, c.targets)
+	errorsByCategory := make(map[ErrorCategory]uint64, len(c.errorCounts))
+	for k, v := range c.errorCounts {
+		errorsByCategory[k] = v
+	}
+	errorSamples := make(map[ErrorCategory][]string, len(c.errorSamples))
+	for k, v := range c.errorSamples {
+		errorSamples[k] = append([]string(nil), v...)
+	}
+	var undecoded map[string]uint64
+	if len(c.undecoded) > 0 {
+		undecoded = maps.Clone(c.undecoded)
+	}
+	var recentReqs, recentErrs uint64
+	for _, b := range c.buckets[max(len(c.buckets)-recentBuckets, 0):] {
+		recentReqs += b.Requests
+		recentErrs += b.Errors
+	}
+	rpsBuckets := make([]float64, len(c.buckets))
+	bytesBuckets := make([]float64, len(c.buckets))
+	for i, b := range c.buckets {
+		rpsBuckets[i] = b.RPS
+		bytesBuckets[i] = b.BytesPerS
+	}
 	c.mu.Unlock()
+	latencySamples, connWait := c.samples.merged()
 
 	snap := Snapshot{
-		TotalRequests:   totalReqs,
-		Successes:       atomic.LoadUint64(&c.successes),
-		Errors:          atomic.LoadUint64(&c.errors),
-		TotalBytesSent:  totalSent,
-		TotalBytesRecv:  totalRecv,
-		Duration:        elapsed,
-		RequestsPerSAvg: float64(totalReqs) / elapsedSec,
-		BytesPerSAvg:    float64(totalSent+totalRecv) / elapsedSec,
+		TotalRequests:    totalReqs,
+		Successes:        atomic.LoadUint64(&c.successes),
+		Errors:           totalErrs,
+		TotalBytesSent:   totalSent,
+		TotalBytesRecv:   totalRecv,
+		ChunkedResponses: atomic.LoadUint64(&c.chunked),
+		Abandoned:        atomic.LoadUint64(&c.abandoned),
+		Discarded:        atomic.LoadUint64(&c.discarded),
+		ConnRotations:    atomic.LoadUint64(&c.rotations),
+		Retries:          atomic.LoadUint64(&c.retries),
+		NotModified:      atomic.LoadUint64(&c.notModified),
+		RateLimited:      atomic.LoadUint64(&c.rateLimited),
+		StatusCounts:     c.statuses.counts(),
+		InFlight:         atomic.LoadInt64(&c.inFlight),
+		ErrorsByCategory: errorsByCategory,
+		ErrorSamples:     errorSamples,
+		Undecoded:        undecoded,
+		Conns:            conns,
+		RemoteAddrs:      remoteAddrs,
+		Steps:            steps,
+		Targets:          targets,
+		Duration:         elapsed,
+		RequestsPerSAvg:  float64(totalReqs) / elapsedSec,
+		BytesPerSAvg:     float64(totalSent+totalRecv) / elapsedSec,
 	}
 
+	if recentReqs > 0 {
+		snap.RecentErrorRate = float64(recentErrs) / float64(recentReqs)
+	}
+	snap.LatencySampleCount = uint64(len(latencySamples))
 	if len(latencySamples) > 0 {
 		sort.Slice(latencySamples, func(i, j int) bool { return latencySamples[i] < latencySamples[j] })
 		snap.LatencyP25 = percentileDuration(latencySamples, 2.5)
@@ -228,6 +677,20 @@ func (c *Collector) Snapshot() Snapshot {
 		snap.LatencyMax = latencySamples[len(latencySamples)-1]
 	}
 
+	snap.ConnWaitTracked = c.trackConnWait
+	snap.ConnWaitTotal = time.Duration(atomic.LoadInt64(&c.connWaitTotal))
+	if len(connWait) > 0 {
+		slices.Sort(connWait)
+		snap.ConnWaitP50 = percentileDuration(connWait, 50)
+		snap.ConnWaitP99 = percentileDuration(connWait, 99)
+		snap.ConnWaitMax = connWait[len(connWait)-1]
+	}
+
+	if c.handshakes != nil {
+		c.handshakes.fill(&snap)
+	}
+	c.sizes.fill(&snap)
+
 	if len(rpsBuckets) > 0 {
 		sort.Float64s(rpsBuckets)
 		snap.RPSP01, snap.RPSP025, snap.RPSP50, snap.RPSP975 = percentileFloat(rpsBuckets, 1), percentileFloat(rpsBuckets, 2.5), percentileFloat(rpsBuckets, 50), percentileFloat(rpsBuckets, 97.5)
@@ -242,3 +705,21 @@ func (c *Collector) Snapshot() Snapshot {
 
 	return snap
 }
+
+// TimeSeries returns a copy of the flushed 1s buckets in chronological order.
+// Buckets are flushed by Snapshot, so the series is only as fresh as the last
+// Snapshot call.
+func (c *Collector) TimeSeries() []Bucket {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]Bucket, len(c.buckets))
+	copy(out, c.buckets)
+	return out
+}
+
+// LatencySamples returns a sorted copy of the retained latency samples.
+func (c *Collector) LatencySamples() []time.Duration {
+	out, _ := c.samples.merged()
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
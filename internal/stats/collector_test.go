@@ -1,6 +1,9 @@
 package stats
 
 import (
+	"fmt"
+	"maps"
+	"math"
 	"sync"
 	"testing"
 	"time"
@@ -96,3 +99,387 @@ func TestSnapshot_EmptyCollector(t *testing.T) {
 		t.Errorf("latency should be zero: P50=%v Max=%v", snap.LatencyP50, snap.LatencyMax)
 	}
 }
+
+func TestRecordResult_Chunked(t *testing.T) {
+	c := NewCollector()
+	c.RecordResult(Result{Latency: time.Millisecond, Success: true, BytesRecv: 42, Chunked: true})
+	c.RecordResult(Result{Latency: time.Millisecond, Success: true, BytesRecv: 10})
+	snap := c.Snapshot()
+	if snap.ChunkedResponses != 1 {
+		t.Errorf("ChunkedResponses: got %d, want 1", snap.ChunkedResponses)
+	}
+	if snap.TotalBytesRecv != 52 {
+		t.Errorf("TotalBytesRecv: got %d, want 52", snap.TotalBytesRecv)
+	}
+}
+
+func TestRecordResult_RateLimited(t *testing.T) {
+	c := NewCollector()
+	c.RecordResult(Result{Latency: time.Millisecond, Success: true, Status: 200})
+	c.RecordResult(Result{Latency: time.Millisecond, Status: 429, RateLimited: true})
+	c.RecordResult(Result{Latency: time.Millisecond, ErrorCategory: ErrHTTP5xx})
+	snap := c.Snapshot()
+	if snap.TotalRequests != 3 || snap.Successes != 1 || snap.Errors != 1 || snap.RateLimited != 1 {
+		t.Errorf("total %d, successes %d, errors %d, rate limited %d; want 3, 1, 1, 1",
+			snap.TotalRequests, snap.Successes, snap.Errors, snap.RateLimited)
+	}
+	if len(snap.ErrorsByCategory) != 1 || snap.ErrorsByCategory[ErrHTTP5xx] != 1 {
+		t.Errorf("ErrorsByCategory = %v, want only the 5xx", snap.ErrorsByCategory)
+	}
+}
+
+func TestRecordResult_StatusCounts(t *testing.T) {
+	c := NewCollector()
+	if snap := c.Snapshot(); snap.StatusCounts != nil {
+		t.Errorf("empty collector: StatusCounts = %v, want nil", snap.StatusCounts)
+	}
+	for range 3 {
+		c.RecordResult(Result{Success: true, Status: 200})
+	}
+	c.RecordResult(Result{Success: true, Status: 404})
+	c.RecordResult(Result{Status: 503, ErrorCategory: ErrHTTP5xx})
+	c.RecordResult(Result{Status: 429, RateLimited: true})
+	c.RecordResult(Result{ErrorCategory: ErrConnect})    // no response
+	c.RecordResult(Result{Success: true})                // simulated: no status to count
+	c.RecordResult(Result{Status: 200, Abandoned: true}) // not a completed request
+
+	want := map[int]uint64{200: 3, 404: 1, 503: 1, 429: 1, 0: 1}
+	if got := c.Snapshot().StatusCounts; !maps.Equal(got, want) {
+		t.Errorf("StatusCounts = %v, want %v", got, want)
+	}
+}
+
+func TestRecordResult_ErrorCategoriesAndSamples(t *testing.T) {
+	c := NewCollector()
+	for i := 0; i < 5; i++ {
+		c.RecordResult(Result{ErrorCategory: ErrConnect, ErrorMessage: fmt.Sprintf("refused %d", i%4)})
+	}
+	c.RecordResult(Result{ErrorCategory: ErrConnect, ErrorMessage: "refused 0"})
+	c.RecordResult(Result{ErrorCategory: ErrHTTP5xx, ErrorMessage: "HTTP 500"})
+	c.RecordResult(Result{ErrorMessage: "unclassified"})
+	c.RecordResult(Result{Success: true})
+
+	snap := c.Snapshot()
+	if snap.Errors != 8 {
+		t.Errorf("Errors: got %d, want 8", snap.Errors)
+	}
+	if snap.ErrorsByCategory[ErrConnect] != 6 || snap.ErrorsByCategory[ErrHTTP5xx] != 1 || snap.ErrorsByCategory[ErrOther] != 1 {
+		t.Errorf("unexpected breakdown: %v", snap.ErrorsByCategory)
+	}
+	if got := snap.ErrorSamples[ErrConnect]; len(got) != maxErrorSamples || got[0] != "refused 0" {
+		t.Errorf("samples should be the first %d distinct messages: %v", maxErrorSamples, got)
+	}
+}
+
+func TestInFlight_PeakPerBucket(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	c := NewCollector(WithClock(clock.now))
+	for i := 0; i < 3; i++ {
+		c.RequestStarted()
+	}
+	c.RequestFinished()
+	c.RequestFinished()
+	if got := c.Snapshot().InFlight; got != 1 {
+		t.Errorf("InFlight: got %d, want 1", got)
+	}
+
+	// Force a flush: the bucket carries the peak, and the next interval starts
+	// from the current level.
+	clock.advance(time.Second)
+	c.Snapshot()
+	c.RequestFinished()
+	clock.advance(time.Second)
+	c.Snapshot()
+
+	ts := c.TimeSeries()
+	if len(ts) != 2 {
+		t.Fatalf("idle intervals should still be flushed: got %d buckets", len(ts))
+	}
+	if ts[0].PeakInFlight != 3 || ts[1].PeakInFlight != 1 {
+		t.Errorf("PeakInFlight: got %d, %d; want 3, 1", ts[0].PeakInFlight, ts[1].PeakInFlight)
+	}
+	if ts[1].Requests != 0 {
+		t.Errorf("second bucket should be empty: %+v", ts[1])
+	}
+}
+
+// fakeClock is a controllable clock for bucket tests.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (f *fakeClock) now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.t
+}
+
+func (f *fakeClock) advance(d time.Duration) {
+	f.mu.Lock()
+	f.t = f.t.Add(d)
+	f.mu.Unlock()
+}
+
+func TestSnapshot_ClockSkewKeepsBucketsSane(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewCollector(WithClock(clock.now))
+
+	c.Record(time.Millisecond, true, 0, 100)
+	clock.advance(time.Second)
+	c.Snapshot() // first bucket: 1 request over 1s
+
+	// Step the clock back, as an NTP correction would without a monotonic
+	// reading, then record more traffic.
+	clock.advance(-30 * time.Second)
+	c.Record(time.Millisecond, true, 0, 100)
+	snap := c.Snapshot()
+	if snap.Duration < 0 || math.IsInf(snap.RequestsPerSAvg, 0) || snap.RequestsPerSAvg < 0 {
+		t.Errorf("skewed snapshot: duration=%v rps=%v", snap.Duration, snap.RequestsPerSAvg)
+	}
+	if n := len(c.TimeSeries()); n != 1 {
+		t.Fatalf("a backwards step must not flush a bucket: got %d buckets", n)
+	}
+
+	// Sub-second progress after re-anchoring does not flush either.
+	clock.advance(500 * time.Millisecond)
+	c.Snapshot()
+	clock.advance(500 * time.Millisecond)
+	c.Snapshot()
+
+	ts := c.TimeSeries()
+	if len(ts) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(ts))
+	}
+	for i, b := range ts {
+		if b.Duration < time.Second || b.Start < 0 || b.RPS < 0 || math.IsInf(b.RPS, 0) || math.IsNaN(b.RPS) {
+			t.Errorf("bucket %d is not sane: %+v", i, b)
+		}
+	}
+	if ts[1].Requests != 1 {
+		t.Errorf("traffic recorded across the skew should land in the next bucket: %+v", ts[1])
+	}
+}
+
+// TestSnapshot_BucketsCountErrors checks that each bucket carries the
+// successes and errors of its own interval, so error onset can be located.
+func TestSnapshot_BucketsCountErrors(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewCollector(WithClock(clock.now))
+
+	// A healthy second, a failing one, then a partial recovery.
+	for _, sec := range []struct{ ok, failed int }{{5, 0}, {0, 4}, {2, 3}} {
+		for i := 0; i < sec.ok; i++ {
+			c.RecordResult(Result{Latency: time.Millisecond, Success: true})
+		}
+		for i := 0; i < sec.failed; i++ {
+			c.RecordResult(Result{Latency: time.Millisecond, ErrorCategory: ErrHTTP5xx})
+		}
+		clock.advance(time.Second)
+		c.Snapshot()
+	}
+
+	ts := c.TimeSeries()
+	if len(ts) != 3 {
+		t.Fatalf("expected 3 buckets, got %d", len(ts))
+	}
+	want := []struct{ ok, failed uint64 }{{5, 0}, {0, 4}, {2, 3}}
+	for i, b := range ts {
+		if b.Successes != want[i].ok || b.Errors != want[i].failed || b.Requests != want[i].ok+want[i].failed {
+			t.Errorf("bucket %d: %d requests, %d successes, %d errors; want %d successes, %d errors",
+				i, b.Requests, b.Successes, b.Errors, want[i].ok, want[i].failed)
+		}
+	}
+}
+
+func TestSnapshot_BucketsWithFakeClock(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewCollector(WithClock(clock.now))
+
+	// Seconds 0-2 carry 10, 20 and 30 requests; seconds 3-4 are idle.
+	for _, n := range []int{10, 20, 30, 0, 0} {
+		for i := 0; i < n; i++ {
+			c.Record(time.Millisecond, true, 10, 90)
+		}
+		clock.advance(time.Second)
+		c.Snapshot()
+	}
+
+	ts := c.TimeSeries()
+	if len(ts) != 5 {
+		t.Fatalf("expected 5 buckets, got %d", len(ts))
+	}
+	wantRPS := []float64{10, 20, 30, 0, 0}
+	for i, b := range ts {
+		if b.Start != time.Duration(i)*time.Second || b.Duration != time.Second {
+			t.Errorf("bucket %d: start=%v duration=%v", i, b.Start, b.Duration)
+		}
+		if b.RPS != wantRPS[i] || b.BytesPerS != wantRPS[i]*100 {
+			t.Errorf("bucket %d: rps=%v bytes/s=%v, want %v and %v", i, b.RPS, b.BytesPerS, wantRPS[i], wantRPS[i]*100)
+		}
+	}
+
+	snap := c.Snapshot()
+	if snap.Duration != 5*time.Second {
+		t.Errorf("Duration: got %v, want 5s", snap.Duration)
+	}
+	if snap.RequestsPerSAvg != 12 {
+		t.Errorf("RequestsPerSAvg: got %v, want 12", snap.RequestsPerSAvg)
+	}
+	if snap.RPSMin != 0 || snap.RPSP50 != 10 {
+		t.Errorf("idle seconds should count towards RPS percentiles: min=%v p50=%v", snap.RPSMin, snap.RPSP50)
+	}
+}
+
+func TestSnapshot_BucketRetentionWindow(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewCollector(WithClock(clock.now))
+	for i := 0; i < maxBucketSamples+5; i++ {
+		c.Record(time.Millisecond, true, 0, 0)
+		clock.advance(time.Second)
+		c.Snapshot()
+	}
+	ts := c.TimeSeries()
+	if len(ts) != maxBucketSamples {
+		t.Fatalf("retained %d buckets, want %d", len(ts), maxBucketSamples)
+	}
+	if ts[0].Start != 5*time.Second {
+		t.Errorf("oldest buckets should be dropped first: first start=%v", ts[0].Start)
+	}
+}
+
+func TestSnapshot_LatencySampleCountReflectsCap(t *testing.T) {
+	c := NewCollector()
+	for i := 0; i < 10; i++ {
+		c.Record(time.Millisecond, true, 0, 0)
+	}
+	if got := c.Snapshot().LatencySampleCount; got != 10 {
+		t.Errorf("below the cap every request is sampled: got %d, want 10", got)
+	}
+
+	for i := 0; i < maxLatencySamples; i++ {
+		c.Record(time.Millisecond, true, 0, 0)
+	}
+	snap := c.Snapshot()
+	if snap.LatencySampleCount != maxLatencySamples {
+		t.Errorf("LatencySampleCount: got %d, want the cap %d", snap.LatencySampleCount, maxLatencySamples)
+	}
+	if snap.TotalRequests != maxLatencySamples+10 {
+		t.Errorf("TotalRequests must keep counting past the cap: got %d", snap.TotalRequests)
+	}
+}
+
+func TestSnapshot_RecentErrorRateFollowsRecentBuckets(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewCollector(WithClock(clock.now))
+	second := func(ok, failed int) Snapshot {
+		for i := 0; i < ok; i++ {
+			c.Record(time.Millisecond, true, 0, 0)
+		}
+		for i := 0; i < failed; i++ {
+			c.Record(time.Millisecond, false, 0, 0)
+		}
+		clock.advance(time.Second)
+		return c.Snapshot()
+	}
+
+	// An early error burst...
+	if snap := second(0, 10); snap.RecentErrorRate != 1 {
+		t.Errorf("during the burst: got %v, want 1", snap.RecentErrorRate)
+	}
+	// ...is still visible while it is inside the window...
+	if snap := second(10, 0); snap.RecentErrorRate != 0.5 {
+		t.Errorf("one clean second later: got %v, want 0.5", snap.RecentErrorRate)
+	}
+	// ...and drops out once the window has moved past it.
+	var snap Snapshot
+	for i := 0; i < recentBuckets; i++ {
+		snap = second(10, 0)
+	}
+	if snap.RecentErrorRate != 0 {
+		t.Errorf("after the window: got %v, want 0", snap.RecentErrorRate)
+	}
+	if snap.Errors != 10 {
+		t.Errorf("cumulative errors must not change: got %d", snap.Errors)
+	}
+
+	// A fresh burst shows up again immediately.
+	if snap := second(5, 5); snap.RecentErrorRate <= 0 {
+		t.Errorf("new errors should raise the rate: got %v", snap.RecentErrorRate)
+	}
+	if ts := c.TimeSeries(); ts[0].Errors != 10 || ts[len(ts)-1].Errors != 5 {
+		t.Errorf("per-bucket errors: first=%d last=%d", ts[0].Errors, ts[len(ts)-1].Errors)
+	}
+}
+
+func TestMemoryBudget_SmallerBudgetRetainsLess(t *testing.T) {
+	// fill records ten requests a second for n seconds and returns what was
+	// kept.
+	fill := func(budget uint64, n int) (samples uint64, buckets int) {
+		clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		c := NewCollector(WithClock(clock.now), WithMemoryBudget(budget))
+		for i := 0; i < n; i++ {
+			for j := 0; j < 10; j++ {
+				c.Record(time.Millisecond, true, 0, 0)
+			}
+			clock.advance(time.Second)
+			c.Snapshot()
+		}
+		return c.Snapshot().LatencySampleCount, len(c.TimeSeries())
+	}
+
+	const n = 1000
+	smallSamples, smallBuckets := fill(8<<10, n)
+	largeSamples, largeBuckets := fill(64<<10, n)
+	if smallSamples >= largeSamples || smallBuckets >= largeBuckets {
+		t.Errorf("8KiB kept %d samples/%d buckets, 64KiB kept %d/%d; want the smaller budget to keep less",
+			smallSamples, smallBuckets, largeSamples, largeBuckets)
+	}
+	for budget, kept := range map[uint64][2]int{8 << 10: {int(smallSamples), smallBuckets}, 64 << 10: {int(largeSamples), largeBuckets}} {
+		r := RetentionFor(budget, false)
+		if kept[0] != r.LatencySamples || kept[1] != r.Buckets {
+			t.Errorf("budget %d: kept %v, want the RetentionFor limits %+v", budget, kept, r)
+		}
+		if used := uint64(r.LatencySamples)*sampleBytes + uint64(r.Buckets)*bucketBytes; used > budget {
+			t.Errorf("budget %d: limits %+v need %d bytes", budget, r, used)
+		}
+	}
+
+	if got := NewCollector().Retention(); got != DefaultRetention {
+		t.Errorf("no budget: retention %+v, want the defaults %+v", got, DefaultRetention)
+	}
+}
+
+func TestSnapshot_ResponseSizePercentiles(t *testing.T) {
+	c := NewCollector()
+	// 1..1000 bytes, plus one 1 MB outlier and a failure that must not count.
+	for i := uint64(1); i <= 1000; i++ {
+		c.Record(time.Millisecond, true, 0, i)
+	}
+	c.Record(time.Millisecond, true, 0, 1_000_000)
+	c.Record(time.Millisecond, false, 0, 5_000_000)
+	snap := c.Snapshot()
+
+	if snap.RespSizeCount != 1001 || snap.RespSizeMax != 1_000_000 {
+		t.Fatalf("count/max: %d / %d", snap.RespSizeCount, snap.RespSizeMax)
+	}
+	near := func(name string, got, want uint64) {
+		t.Helper()
+		if math.Abs(float64(got)-float64(want)) > float64(want)/32+1 {
+			t.Errorf("%s: got %d, want about %d", name, got, want)
+		}
+	}
+	near("p2.5", snap.RespSizeP25, 26)
+	near("p50", snap.RespSizeP50, 501)
+	near("p99", snap.RespSizeP99, 991)
+	if want := (500500.0 + 1_000_000) / 1001; math.Abs(snap.RespSizeAvg-want) > 1e-9 {
+		t.Errorf("avg: got %.2f, want %.2f", snap.RespSizeAvg, want)
+	}
+
+	for _, v := range []uint64{0, 63, 64, 1000, 1 << 40, math.MaxUint64} {
+		lo, hi := sizeBounds(sizeBucket(v))
+		if v < lo || v > hi {
+			t.Errorf("%d outside its bucket [%d, %d]", v, lo, hi)
+		}
+	}
+}
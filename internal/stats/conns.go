@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"cmp"
+	"slices"
+)
+
+// ConnStats is the request and error count of one connection.
+type ConnStats struct {
+	// ID numbers connections from 1 in the order their first request
+	// completed.
+	ID       int
+	Local    string // client address, which identifies the connection
+	Remote   string // server address it is connected to
+	Requests uint64
+	Errors   uint64
+}
+
+// ErrorRate is the fraction of the connection's requests that failed.
+func (s ConnStats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+// WithPerConn makes the collector count requests and errors per connection,
+// as identified by Result.ConnLocal, and report them in Snapshot.Conns.
+func WithPerConn() Option {
+	return func(c *Collector) { c.perConn = make(map[string]*ConnStats) }
+}
+
+// AddrCount is how many requests went to one remote address.
+type AddrCount struct {
+	Addr     string
+	Requests uint64
+}
+
+// WithRemoteAddrs makes the collector count requests per Result.ConnRemote
+// and report them in Snapshot.RemoteAddrs.
+func WithRemoteAddrs() Option {
+	return func(c *Collector) { c.remoteAddrs = make(map[string]uint64) }
+}
+
+// recordConn attributes r to its connection. Requests that never got a
+// connection (e.g. dial failures) are not attributed. Callers must hold c.mu.
+func (c *Collector) recordConn(r Result) {
+	if c.remoteAddrs != nil && r.ConnRemote != "" {
+		c.remoteAddrs[r.ConnRemote]++
+	}
+	if c.perConn == nil || r.ConnLocal == "" {
+		return
+	}
+	s := c.perConn[r.ConnLocal]
+	if s == nil {
+		s = &ConnStats{ID: len(c.perConn) + 1, Local: r.ConnLocal, Remote: r.ConnRemote}
+		c.perConn[r.ConnLocal] = s
+	}
+	s.Requests++
+	if !r.Success && !r.RateLimited {
+		s.Errors++
+	}
+}
+
+// connStats returns per-connection counts, worst first: most errors, then
+// most requests. Callers must hold c.mu.
+func (c *Collector) connStats() []ConnStats {
+	if c.perConn == nil {
+		return nil
+	}
+	out := make([]ConnStats, 0, len(c.perConn))
+	for _, s := range c.perConn {
+		out = append(out, *s)
+	}
+	slices.SortFunc(out, func(a, b ConnStats) int {
+		return cmp.Or(cmp.Compare(b.Errors, a.Errors), cmp.Compare(b.Requests, a.Requests), cmp.Compare(a.ID, b.ID))
+	})
+	return out
+}
+
+// remoteAddrStats returns request counts per remote address, busiest first.
+// Callers must hold c.mu.
+func (c *Collector) remoteAddrStats() []AddrCount {
+	if c.remoteAddrs == nil {
+		return nil
+	}
+	out := make([]AddrCount, 0, len(c.remoteAddrs))
+	for addr, n := range c.remoteAddrs {
+		out = append(out, AddrCount{Addr: addr, Requests: n})
+	}
+	slices.SortFunc(out, func(a, b AddrCount) int {
+		return cmp.Or(cmp.Compare(b.Requests, a.Requests), cmp.Compare(a.Addr, b.Addr))
+	})
+	return out
+}
@@ -0,0 +1,52 @@
+package stats
+
+// ErrorCategory classifies why a request failed.
+type ErrorCategory string
+
+// Error categories, from the earliest failure point to the latest.
+const (
+	ErrDNS        ErrorCategory = "dns"        // host lookup failed
+	ErrConnect    ErrorCategory = "connect"    // TCP connect refused/unreachable
+	ErrTLS        ErrorCategory = "tls"        // handshake or certificate failure
+	ErrTimeout    ErrorCategory = "timeout"    // any timeout or deadline
+	ErrRead       ErrorCategory = "read"       // connection reset/EOF while reading
+	ErrHTTP5xx    ErrorCategory = "http_5xx"   // server answered with a 5xx status
+	ErrHTTP429    ErrorCategory = "http_429"   // server answered 429 Too Many Requests (--count-429 error)
+	ErrGRPC       ErrorCategory = "grpc"       // gRPC call ended with a non-OK grpc-status
+	ErrH2Reset    ErrorCategory = "h2_reset"   // HTTP/2 server sent GOAWAY or reset the stream (REFUSED_STREAM, ENHANCE_YOUR_CALM, ...)
+	ErrProtocol   ErrorCategory = "protocol"   // malformed HTTP or HTTP/2 protocol error
+	ErrRedirect   ErrorCategory = "redirect"   // redirect loop, or more redirects than the client follows
+	ErrValidation ErrorCategory = "validation" // response failed a user-supplied check
+	ErrHeader     ErrorCategory = "header"     // response headers failed --expect-header/--reject-header
+	ErrOther      ErrorCategory = "other"      // anything not classified above
+)
+
+// ErrorCategories lists every category in display order.
+func ErrorCategories() []ErrorCategory {
+	return []ErrorCategory{ErrDNS, ErrConnect, ErrTLS, ErrTimeout, ErrRead, ErrHTTP5xx, ErrHTTP429, ErrGRPC, ErrH2Reset, ErrProtocol, ErrRedirect, ErrValidation, ErrHeader, ErrOther}
+}
+
+// maxErrorSamples is how many distinct messages are kept per category.
+const maxErrorSamples = 3
+
+// recordError counts a failed request by category and keeps the first few
+// distinct messages. Callers must hold c.mu.
+func (c *Collector) recordError(cat ErrorCategory, msg string) {
+	if cat == "" {
+		cat = ErrOther
+	}
+	c.errorCounts[cat]++
+	if msg == "" {
+		return
+	}
+	samples := c.errorSamples[cat]
+	if len(samples) >= maxErrorSamples {
+		return
+	}
+	for _, s := range samples {
+		if s == msg {
+			return
+		}
+	}
+	c.errorSamples[cat] = append(samples, msg)
+}
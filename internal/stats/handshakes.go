@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// handshakeCounts tracks connection setup for WithHandshakes.
+type handshakeCounts struct {
+	connects      uint64
+	tlsHandshakes uint64
+	samples       *sampleStore // setup times: connect plus TLS handshake
+}
+
+// WithHandshakes makes the collector count the connections requests had to
+// set up (Result.Connect and Result.TLSHandshake) and sample how long that
+// took, for measuring connection throughput rather than request throughput.
+func WithHandshakes() Option {
+	return func(c *Collector) { c.handshakes = &handshakeCounts{} }
+}
+
+// add records the setup of r's connection, if it had to make one.
+func (h *handshakeCounts) add(r Result) {
+	if r.Connect <= 0 && r.TLSHandshake <= 0 {
+		return
+	}
+	if r.Connect > 0 {
+		atomic.AddUint64(&h.connects, 1)
+	}
+	if r.TLSHandshake > 0 {
+		atomic.AddUint64(&h.tlsHandshakes, 1)
+	}
+	h.samples.add(r.Connect+r.TLSHandshake, 0)
+}
+
+// fill sets the handshake fields of snap, whose Duration must be set.
+func (h *handshakeCounts) fill(snap *Snapshot) {
+	snap.HandshakesTracked = true
+	snap.Connects = atomic.LoadUint64(&h.connects)
+	snap.TLSHandshakes = atomic.LoadUint64(&h.tlsHandshakes)
+	if secs := snap.Duration.Seconds(); secs > 0 {
+		snap.ConnectsPerS = float64(snap.Connects) / secs
+		snap.TLSHandshakesPerS = float64(snap.TLSHandshakes) / secs
+	}
+	setup, _ := h.samples.merged()
+	if len(setup) == 0 {
+		return
+	}
+	slices.Sort(setup)
+	snap.HandshakeP50 = percentileDuration(setup, 50)
+	snap.HandshakeP90 = percentileDuration(setup, 90)
+	snap.HandshakeP99 = percentileDuration(setup, 99)
+	snap.HandshakeMax = setup[len(setup)-1]
+}
@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"time"
+	"unsafe"
+)
+
+// Sizes used to turn a memory budget into retention limits.
+const (
+	sampleBytes = uint64(unsafe.Sizeof(time.Duration(0)))
+	bucketBytes = uint64(unsafe.Sizeof(Bucket{}))
+)
+
+// minMemoryBudget is the smallest budget RetentionFor accepts: enough for a
+// handful of buckets and a useful number of latency samples.
+const minMemoryBudget = 4 << 10
+
+// Retention is how many latency samples and throughput buckets a collector
+// keeps.
+type Retention struct {
+	LatencySamples int
+	Buckets        int
+}
+
+// DefaultRetention is the retention without a memory budget.
+var DefaultRetention = Retention{LatencySamples: maxLatencySamples, Buckets: maxBucketSamples}
+
+// RetentionFor derives retention limits from a memory budget in bytes; 0
+// means no budget and yields DefaultRetention. Buckets get up to a tenth of
+// the budget (never more than the default window), latency samples the rest.
+// With connWait, conn-wait samples are kept alongside latency samples and
+// share their part of the budget.
+func RetentionFor(budget uint64, connWait bool) Retention {
+	if budget == 0 {
+		return DefaultRetention
+	}
+	budget = max(budget, minMemoryBudget)
+	buckets := min(uint64(maxBucketSamples), max(1, budget/10/bucketBytes))
+	perSample := sampleBytes
+	if connWait {
+		perSample *= 2
+	}
+	samples := (budget - buckets*bucketBytes) / perSample
+	return Retention{LatencySamples: int(samples), Buckets: int(buckets)}
+}
+
+// WithMemoryBudget bounds the memory the collector uses for retained
+// latency samples and buckets to about budget bytes (see RetentionFor).
+func WithMemoryBudget(budget uint64) Option {
+	return func(c *Collector) { c.memoryBudget = budget }
+}
+
+// Retention returns the limits the collector was created with.
+func (c *Collector) Retention() Retention {
+	return c.retention
+}
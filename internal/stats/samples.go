@@ -0,0 +1,89 @@
+package stats
+
+import (
+	mathrand "math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sampleShard is one lock's worth of retained samples, up to limit. The
+// padding keeps neighbouring shards' locks off the same cache line.
+type sampleShard struct {
+	mu       sync.Mutex
+	latency  []time.Duration
+	connWait []time.Duration
+	limit    int
+	_        [64]byte
+}
+
+// sampleStore keeps latency (and conn-wait) samples in shards so that
+// concurrent RecordResult calls rarely wait on the same lock: each sample
+// goes to a random shard, and a snapshot merges them. Percentiles sort the
+// samples anyway, so their order is not kept. Each shard holds its share of
+// the limit; a sample for a full shard goes to the next one with room, so
+// the store retains exactly limit samples once that many were added.
+type sampleStore struct {
+	shards   []sampleShard
+	mask     uint32      // len(shards) - 1
+	full     atomic.Bool // every shard is full; add is a no-op
+	connWait bool
+}
+
+func newSampleStore(limit int, connWait bool) *sampleStore {
+	// A power of two, so a shard is picked with a mask.
+	n := 1
+	for n < runtime.GOMAXPROCS(0) && n < limit {
+		n <<= 1
+	}
+	s := &sampleStore{shards: make([]sampleShard, n), mask: uint32(n - 1), connWait: connWait}
+	s.full.Store(limit <= 0)
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.limit = limit / n
+		if i < limit%n {
+			sh.limit++
+		}
+		// Large budgets grow on demand rather than being allocated up front.
+		sh.latency = make([]time.Duration, 0, min(sh.limit, maxLatencySamples/n+1))
+	}
+	return s
+}
+
+// add retains a sample unless the store is full.
+func (s *sampleStore) add(latency, connWait time.Duration) {
+	if s.full.Load() {
+		return
+	}
+	start := mathrand.Uint32()
+	for i := range uint32(len(s.shards)) {
+		sh := &s.shards[(start+i)&s.mask]
+		sh.mu.Lock()
+		if len(sh.latency) < sh.limit {
+			sh.latency = append(sh.latency, latency)
+			if s.connWait {
+				sh.connWait = append(sh.connWait, connWait)
+			}
+			sh.mu.Unlock()
+			return
+		}
+		sh.mu.Unlock()
+	}
+	s.full.Store(true)
+}
+
+// merged returns copies of all retained samples, unordered.
+func (s *sampleStore) merged() (latency, connWait []time.Duration) {
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		latency = append(latency, sh.latency...)
+		connWait = append(connWait, sh.connWait...)
+		sh.mu.Unlock()
+	}
+	if latency == nil {
+		latency = []time.Duration{}
+	}
+	return latency, connWait
+}
@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSampleStore_ConcurrentAddsKeepTheLimit(t *testing.T) {
+	s := newSampleStore(1000, true)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 1; i <= 500; i++ {
+				s.add(time.Duration(i), time.Duration(-i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	latency, connWait := s.merged()
+	if len(latency) != 1000 || len(connWait) != 1000 {
+		t.Fatalf("retained %d latency and %d conn-wait samples, want the limit 1000", len(latency), len(connWait))
+	}
+	slices.Sort(latency)
+	if latency[0] < 1 || latency[len(latency)-1] > 500 {
+		t.Errorf("samples out of range: %v .. %v", latency[0], latency[len(latency)-1])
+	}
+}
+
+func TestSnapshot_ShardedSamplesMergeIntoPercentiles(t *testing.T) {
+	c := NewCollector()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := g; i < 100; i += 4 {
+				c.Record(time.Duration(i+1)*time.Millisecond, true, 0, 0)
+			}
+		}()
+	}
+	wg.Wait()
+	snap := c.Snapshot()
+	if snap.LatencySampleCount != 100 || snap.LatencyMax != 100*time.Millisecond {
+		t.Errorf("got %d samples, max %v", snap.LatencySampleCount, snap.LatencyMax)
+	}
+	if snap.LatencyP50 != 50*time.Millisecond && snap.LatencyP50 != 51*time.Millisecond {
+		t.Errorf("p50 = %v", snap.LatencyP50)
+	}
+	if got := c.LatencySamples(); len(got) != 100 || !slices.IsSorted(got) {
+		t.Errorf("LatencySamples: %d samples, sorted=%v", len(got), slices.IsSorted(got))
+	}
+}
+
+// mutexSamples is how samples were kept before sharding: one slice behind
+// one lock shared by every slot. It is the baseline for the benchmarks.
+type mutexSamples struct {
+	mu      sync.Mutex
+	latency []time.Duration
+	limit   int
+}
+
+func (s *mutexSamples) add(latency time.Duration) {
+	s.mu.Lock()
+	if len(s.latency) < s.limit {
+		s.latency = append(s.latency, latency)
+	}
+	s.mu.Unlock()
+}
+
+// Compare with: go test ./internal/stats -run '^$' -bench Samples -cpu 1,4,16
+func BenchmarkSamples_SingleMutex(b *testing.B) {
+	s := &mutexSamples{limit: b.N}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.add(time.Millisecond)
+		}
+	})
+}
+
+func BenchmarkSamples_Sharded(b *testing.B) {
+	s := newSampleStore(b.N, false)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.add(time.Millisecond, 0)
+		}
+	})
+}
+
+func BenchmarkRecordResult_Parallel(b *testing.B) {
+	c := NewCollector(WithMemoryBudget(1 << 30))
+	r := Result{Latency: time.Millisecond, Success: true, Status: 200, BytesSent: 100, BytesRecv: 1000}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.RecordResult(r)
+		}
+	})
+}
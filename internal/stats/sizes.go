@@ -0,0 +1,106 @@
+package stats
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+// Response sizes are counted in a log-linear histogram rather than sampled
+// like latencies: sizes below sizeSub bytes get a bucket each, larger ones
+// share a bucket with values within 1/32 of them, so percentiles are within
+// about 3% without keeping a slice per request.
+const (
+	sizeSubBits = 6
+	sizeSub     = 1 << sizeSubBits
+	sizeHalf    = sizeSub / 2
+	sizeBuckets = sizeSub + (64-sizeSubBits)*sizeHalf
+)
+
+// sizeHistogram counts response sizes for the "Response size" percentiles.
+type sizeHistogram struct {
+	counts [sizeBuckets]atomic.Uint64
+	sum    atomic.Uint64
+	max    atomic.Uint64
+}
+
+// sizeBucket returns the histogram bucket v falls in.
+func sizeBucket(v uint64) int {
+	if v < sizeSub {
+		return int(v)
+	}
+	k := bits.Len64(v)
+	shift := k - sizeSubBits
+	return sizeSub + (k-sizeSubBits-1)*sizeHalf + int(v>>shift) - sizeHalf
+}
+
+// sizeBounds returns the smallest and largest value bucket i holds.
+func sizeBounds(i int) (lo, hi uint64) {
+	if i < sizeSub {
+		return uint64(i), uint64(i)
+	}
+	j := i - sizeSub
+	shift := j/sizeHalf + 1
+	lo = uint64(j%sizeHalf+sizeHalf) << shift
+	return lo, lo + 1<<shift - 1
+}
+
+func (h *sizeHistogram) add(v uint64) {
+	h.counts[sizeBucket(v)].Add(1)
+	h.sum.Add(v)
+	for {
+		m := h.max.Load()
+		if v <= m || h.max.CompareAndSwap(m, v) {
+			return
+		}
+	}
+}
+
+// fill sets the response size fields of snap. A percentile is the largest
+// value of the bucket holding it, capped at the largest size seen; the
+// average and maximum are exact.
+func (h *sizeHistogram) fill(snap *Snapshot) {
+	var counts [sizeBuckets]uint64
+	var n uint64
+	for i := range h.counts {
+		counts[i] = h.counts[i].Load()
+		n += counts[i]
+	}
+	if n == 0 {
+		return
+	}
+	maxSize := h.max.Load()
+	snap.RespSizeCount = n
+	snap.RespSizeMax = maxSize
+	// Mid-run the sum may be a response ahead of the counts or behind
+	// them; the final snapshot is exact.
+	snap.RespSizeAvg = float64(h.sum.Load()) / float64(n)
+
+	percentile := func(p float64) uint64 {
+		rank := uint64(math.Round(p / 100 * float64(n-1)))
+		var seen uint64
+		for i, c := range counts {
+			seen += c
+			if seen > rank {
+				_, hi := sizeBounds(i)
+				return min(hi, maxSize)
+			}
+		}
+		return maxSize
+	}
+	snap.RespSizeP25 = percentile(2.5)
+	snap.RespSizeP50 = percentile(50)
+	snap.RespSizeP975 = percentile(97.5)
+	snap.RespSizeP99 = percentile(99)
+
+	var sq float64
+	for i, c := range counts {
+		if c == 0 {
+			continue
+		}
+		lo, hi := sizeBounds(i)
+		d := (float64(lo)+float64(min(hi, maxSize)))/2 - snap.RespSizeAvg
+		sq += d * d * float64(c)
+	}
+	snap.RespSizeStdev = math.Sqrt(sq / float64(n))
+}
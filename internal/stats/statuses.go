@@ -0,0 +1,35 @@
+package stats
+
+import "sync/atomic"
+
+// maxStatus bounds the status codes counted; net/http only accepts three
+// digit codes, so every real response fits.
+const maxStatus = 1000
+
+// statusCounts counts responses by HTTP status code without taking the
+// collector's lock, so successful results stay on the lock-free path.
+type statusCounts [maxStatus]atomic.Uint64
+
+// add counts r under its status code. A failed request that got no response
+// counts under 0; a successful one without a status (--simulate-latency
+// sends nothing) is not counted.
+func (s *statusCounts) add(r Result, failed bool) {
+	if r.Status < 0 || r.Status >= maxStatus || r.Status == 0 && !failed {
+		return
+	}
+	s[r.Status].Add(1)
+}
+
+// counts returns the non-zero counts by code, or nil if there are none.
+func (s *statusCounts) counts() map[int]uint64 {
+	var m map[int]uint64
+	for code := range s {
+		if n := s[code].Load(); n > 0 {
+			if m == nil {
+				m = make(map[int]uint64)
+			}
+			m[code] = n
+		}
+	}
+	return m
+}
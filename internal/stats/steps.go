@@ -0,0 +1,78 @@
+package stats
+
+import "time"
+
+// StepStats is the request count, error count and latency of one step of a
+// transaction (see WithSteps) or one target (see WithTargets).
+type StepStats struct {
+	Name       string
+	Requests   uint64
+	Errors     uint64
+	LatencyAvg time.Duration
+	LatencyMax time.Duration
+}
+
+// stepCounts accumulates one step or target between snapshots.
+type stepCounts struct {
+	requests   uint64
+	errors     uint64
+	latencySum time.Duration
+	latencyMax time.Duration
+}
+
+// WithSteps makes the collector count the steps of a transaction, named in
+// order by names and recorded with RecordStep, and report them in
+// Snapshot.Steps.
+func WithSteps(names []string) Option {
+	return func(c *Collector) {
+		c.stepNames = names
+		c.steps = make([]stepCounts, len(names))
+	}
+}
+
+// WithTargets makes the collector break requests down by target, named in
+// order by names and identified by Result.Target, and report them in
+// Snapshot.Targets.
+func WithTargets(names []string) Option {
+	return func(c *Collector) {
+		c.targetNames = names
+		c.targets = make([]stepCounts, len(names))
+	}
+}
+
+// RecordStep records one request of step i. Steps after a failed one are
+// not sent, so later steps can have fewer requests than earlier ones. It is
+// a no-op without WithSteps.
+func (c *Collector) RecordStep(i int, latency time.Duration, success bool) {
+	if i < 0 || i >= len(c.steps) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.steps[i].add(latency, success)
+}
+
+func (s *stepCounts) add(latency time.Duration, success bool) {
+	s.requests++
+	if !success {
+		s.errors++
+	}
+	s.latencySum += latency
+	s.latencyMax = max(s.latencyMax, latency)
+}
+
+// breakdown returns the stats of counts in order, named by names, or nil
+// if nothing is counted. Callers must hold the collector's mu.
+func breakdown(names []string, counts []stepCounts) []StepStats {
+	if counts == nil {
+		return nil
+	}
+	out := make([]StepStats, len(counts))
+	for i, s := range counts {
+		out[i] = StepStats{Name: names[i], Requests: s.requests, Errors: s.errors, LatencyMax: s.latencyMax}
+		if s.requests > 0 {
+			out[i].LatencyAvg = s.latencySum / time.Duration(s.requests)
+		}
+	}
+	return out
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !freebsd
+
+package term
+
+import (
+	"errors"
+	"os"
+)
+
+// KeyMode is not implemented on this platform; runs go without keyboard
+// controls.
+func KeyMode(*os.File) (restore func() error, err error) {
+	return nil, errors.ErrUnsupported
+}
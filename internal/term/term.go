@@ -0,0 +1,30 @@
+// Package term answers the two questions the UI asks about the terminal:
+// how wide it is and whether output goes to one at all. KeyMode lets a run
+// read single keypresses from it.
+package term
+
+import (
+	"os"
+	"strconv"
+)
+
+// DefaultWidth is assumed when the width cannot be determined, e.g. when
+// output is redirected.
+const DefaultWidth = 80
+
+// Width returns the terminal width in columns: $COLUMNS if it is a positive
+// integer, else the size of the terminal on stdout, else DefaultWidth.
+func Width() int {
+	if v, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && v > 0 {
+		return v
+	}
+	if w := ttyWidth(os.Stdout); w > 0 {
+		return w
+	}
+	return DefaultWidth
+}
+
+// IsTerminal reports whether f is a terminal.
+func IsTerminal(f *os.File) bool {
+	return ttyWidth(f) >= 0
+}
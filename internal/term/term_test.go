@@ -0,0 +1,38 @@
+package term
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWidth_Columns(t *testing.T) {
+	t.Setenv("COLUMNS", "123")
+	if got := Width(); got != 123 {
+		t.Errorf("Width() with COLUMNS=123 = %d", got)
+	}
+}
+
+func TestWidth_InvalidColumnsFallsBack(t *testing.T) {
+	want := DefaultWidth
+	if w := ttyWidth(os.Stdout); w > 0 {
+		want = w
+	}
+	for _, v := range []string{"", "abc", "0", "-5"} {
+		t.Setenv("COLUMNS", v)
+		if got := Width(); got != want {
+			t.Errorf("Width() with COLUMNS=%q = %d, want %d", v, got, want)
+		}
+	}
+}
+
+func TestIsTerminal_RegularFile(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if IsTerminal(f) {
+		t.Error("a regular file is not a terminal")
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package term
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// winsize mirrors the struct used by TIOCGWINSZ.
+type winsize struct {
+	rows    uint16
+	cols    uint16
+	xpixels uint16
+	ypixels uint16
+}
+
+// ttyWidth returns the column count of the terminal f refers to, 0 if it
+// is a terminal of unknown size, or -1 if it is not a terminal.
+func ttyWidth(f *os.File) int {
+	ws := &winsize{}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL,
+		f.Fd(),
+		uintptr(syscall.TIOCGWINSZ),
+		uintptr(unsafe.Pointer(ws)),
+	)
+	if errno != 0 {
+		return -1
+	}
+	return int(ws.cols)
+}
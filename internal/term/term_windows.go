@@ -0,0 +1,11 @@
+//go:build windows
+
+package term
+
+import "os"
+
+// ttyWidth is not implemented on Windows yet: output is treated as not a
+// terminal, so Width falls back to $COLUMNS or DefaultWidth.
+func ttyWidth(*os.File) int {
+	return -1
+}
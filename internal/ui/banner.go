@@ -1,11 +1,14 @@
 package ui
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 // PrintIntroBanner renders a big ASCII "HTTPCL" banner similar in spirit to
 // classic TUI splash screens. It uses only ASCII characters to respect the
 // project's constraints.
-func PrintIntroBanner() {
+func PrintIntroBanner(w io.Writer) {
 	logo := []string{
 		"██╗  ██╗████████╗████████╗██████╗  ██████╗██╗     ",
 		"██║  ██║╚══██╔══╝╚══██╔══╝██╔══██╗██╔════╝██║     ",
@@ -15,10 +18,10 @@ func PrintIntroBanner() {
 		"╚═╝  ╚═╝   ╚═╝      ╚═╝   ╚═╝     ╚══════╝╚══════╝",
 	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 	for _, line := range logo {
-		fmt.Println(line)
+		fmt.Fprintln(w, line)
 	}
-	fmt.Println()
-	fmt.Println()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w)
 }
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// ComparedRun is one labelled phase of a comparison, e.g. "HTTP/1.1".
+type ComparedRun struct {
+	Label string
+	Snap  stats.Snapshot
+}
+
+// connReuse is the fraction of requests that went over an already open
+// connection, or -1 if nothing was sent.
+func connReuse(snap stats.Snapshot) float64 {
+	if snap.TotalRequests == 0 {
+		return -1
+	}
+	if snap.ConnsOpened >= snap.TotalRequests {
+		return 0
+	}
+	return 1 - float64(snap.ConnsOpened)/float64(snap.TotalRequests)
+}
+
+// percentChange formats b relative to a, or "-" when a is zero.
+func percentChange(a, b float64) string {
+	if a == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", (b-a)/a*100)
+}
+
+// PrintComparison prints two runs side by side with the second one's change
+// relative to the first. Latencies of both use the unit resolved for a.
+func PrintComparison(unit LatencyUnit, a, b ComparedRun) {
+	lat := unit.resolve(a.Snap.LatencyP50).format
+	reuse := func(snap stats.Snapshot) string {
+		if r := connReuse(snap); r >= 0 {
+			return fmt.Sprintf("%.1f%%", r*100)
+		}
+		return "n/a"
+	}
+	reuseDelta := "-"
+	if ra, rb := connReuse(a.Snap), connReuse(b.Snap); ra >= 0 && rb >= 0 {
+		reuseDelta = fmt.Sprintf("%+.1f pt", (rb-ra)*100)
+	}
+
+	cw := []int{16, 16, 16, 14}
+	line := func(l, m, r string) {
+		parts := make([]string, len(cw))
+		for i, w := range cw {
+			parts[i] = strings.Repeat("─", w)
+		}
+		fmt.Fprintf(os.Stdout, "%s%s%s\n", l, strings.Join(parts, m), r)
+	}
+	row := func(cells ...string) {
+		for i, c := range cells {
+			c = "  " + c
+			if pad := cw[i] - visibleLen(c); pad > 0 {
+				c += strings.Repeat(" ", pad)
+			}
+			fmt.Fprintf(os.Stdout, "│%s", c)
+		}
+		fmt.Fprintln(os.Stdout, "│")
+	}
+
+	fmt.Fprintln(os.Stdout)
+	fmt.Fprintf(os.Stdout, "%sComparison%s %s(%s vs %s)%s\n", colorBold, colorReset, colorDim, b.Label, a.Label, colorReset)
+	line("┌", "┬", "┐")
+	row(colorCyan+"Stat"+colorReset, colorCyan+truncateToWidth(a.Label, 12)+colorReset,
+		colorCyan+truncateToWidth(b.Label, 12)+colorReset, colorCyan+"Change"+colorReset)
+	line("├", "┼", "┤")
+	row("Req/sec", fmt.Sprintf("%.1f", a.Snap.RequestsPerSAvg), fmt.Sprintf("%.1f", b.Snap.RequestsPerSAvg),
+		percentChange(a.Snap.RequestsPerSAvg, b.Snap.RequestsPerSAvg))
+	row("Latency p99", lat(a.Snap.LatencyP99), lat(b.Snap.LatencyP99),
+		percentChange(float64(a.Snap.LatencyP99), float64(b.Snap.LatencyP99)))
+	row("Conns opened", fmt.Sprintf("%d", a.Snap.ConnsOpened), fmt.Sprintf("%d", b.Snap.ConnsOpened), "")
+	row("Conn reuse", reuse(a.Snap), reuse(b.Snap), reuseDelta)
+	row("Errors", fmt.Sprintf("%d", a.Snap.Errors), fmt.Sprintf("%d", b.Snap.Errors),
+		fmt.Sprintf("%+d", int64(b.Snap.Errors)-int64(a.Snap.Errors)))
+	line("└", "┴", "┘")
+}
+
+// PrintComparisonTable prints one row per run with its throughput, latency
+// and errors, and its req/sec change relative to the first run. Latencies
+// use the unit resolved for the first run.
+func PrintComparisonTable(unit LatencyUnit, runs []ComparedRun) {
+	if len(runs) == 0 {
+		return
+	}
+	base := runs[0]
+	lat := unit.resolve(base.Snap.LatencyP50).format
+
+	cw := []int{12, 12, 12, 12, 10, 12}
+	line := func(l, m, r string) {
+		parts := make([]string, len(cw))
+		for i, w := range cw {
+			parts[i] = strings.Repeat("─", w)
+		}
+		fmt.Fprintf(os.Stdout, "%s%s%s\n", l, strings.Join(parts, m), r)
+	}
+	row := func(cells ...string) {
+		for i, c := range cells {
+			c = "  " + c
+			if pad := cw[i] - visibleLen(c); pad > 0 {
+				c += strings.Repeat(" ", pad)
+			}
+			fmt.Fprintf(os.Stdout, "│%s", c)
+		}
+		fmt.Fprintln(os.Stdout, "│")
+	}
+
+	labels := make([]string, len(runs))
+	for i, r := range runs {
+		labels[i] = r.Label
+	}
+	fmt.Fprintln(os.Stdout)
+	fmt.Fprintf(os.Stdout, "%sComparison%s %s(%s)%s\n", colorBold, colorReset, colorDim, strings.Join(labels, ", "), colorReset)
+	line("┌", "┬", "┐")
+	row(colorCyan+"Run"+colorReset, colorCyan+"Req/sec"+colorReset, colorCyan+"p50"+colorReset,
+		colorCyan+"p99"+colorReset, colorCyan+"Errors"+colorReset, colorCyan+"vs "+truncateToWidth(base.Label, 7)+colorReset)
+	line("├", "┼", "┤")
+	for i, r := range runs {
+		change := "-"
+		if i > 0 {
+			change = percentChange(base.Snap.RequestsPerSAvg, r.Snap.RequestsPerSAvg)
+		}
+		row(truncateToWidth(r.Label, 9), fmt.Sprintf("%.1f", r.Snap.RequestsPerSAvg), lat(r.Snap.LatencyP50),
+			lat(r.Snap.LatencyP99), fmt.Sprintf("%d", r.Snap.Errors), change)
+	}
+	line("└", "┴", "┘")
+}
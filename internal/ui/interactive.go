@@ -3,6 +3,7 @@ package ui
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -20,13 +21,23 @@ type WizardConfig struct {
 	Duration    time.Duration
 	Workers     int
 	Pipeline    int
+	// Headers are the "Key: Value" lines entered, as given to -H.
+	Headers []string
 }
 
 // RunInteractiveWizard collects configuration from the user for `httpcl start`.
-func RunInteractiveWizard() (*WizardConfig, error) {
-	reader := bufio.NewReader(os.Stdin)
+// fullWidth lets the header span the terminal (see --full-width). checkURL,
+// if non-nil, vets the target URL as soon as it is entered; on error the
+// wizard shows the problem and asks again instead of failing after the last
+// question. checkHeader does the same for each request header.
+func RunInteractiveWizard(fullWidth bool, checkURL, checkHeader func(string) error) (*WizardConfig, error) {
+	return runWizard(os.Stdin, fullWidth, checkURL, checkHeader)
+}
+
+func runWizard(in io.Reader, fullWidth bool, checkURL, checkHeader func(string) error) (*WizardConfig, error) {
+	reader := bufio.NewReader(in)
 
-	printWizardHeader()
+	printWizardHeader(fullWidth)
 
 	// Prompt: bold label, then dim "(required)" or "[default: X]", then ": "
 	promptWithDefault := func(label, def string, required bool) (string, error) {
@@ -46,12 +57,22 @@ func RunInteractiveWizard() (*WizardConfig, error) {
 		return text, nil
 	}
 
-	url, err := promptWithDefault("Target URL", "", true)
-	if err != nil {
-		return nil, err
-	}
-	if url == "" {
-		return nil, fmt.Errorf("url is required")
+	var url string
+	for {
+		var err error
+		url, err = promptWithDefault("Target URL", "", true)
+		if err != nil {
+			return nil, err
+		}
+		if url == "" {
+			err = fmt.Errorf("url is required")
+		} else if checkURL != nil {
+			err = checkURL(url)
+		}
+		if err == nil {
+			break
+		}
+		fmt.Printf("  %s%v%s\n", colorRed, err, colorReset)
 	}
 
 	method, err := promptWithDefault("HTTP method (GET, POST, PUT, DELETE)", "GET", false)
@@ -107,6 +128,25 @@ func RunInteractiveWizard() (*WizardConfig, error) {
 		}
 	}
 
+	// Headers are optional: one per answer until a blank one.
+	var headers []string
+	for {
+		header, err := promptWithDefault("Header (Key: Value, optional; blank to finish)", "", false)
+		if err != nil {
+			return nil, err
+		}
+		if header == "" {
+			break
+		}
+		if checkHeader != nil {
+			if err := checkHeader(header); err != nil {
+				fmt.Printf("  %s%v%s\n", colorRed, err, colorReset)
+				continue
+			}
+		}
+		headers = append(headers, header)
+	}
+
 	cfg := &WizardConfig{
 		Method:      method,
 		URL:         url,
@@ -115,6 +155,7 @@ func RunInteractiveWizard() (*WizardConfig, error) {
 		Duration:    dur,
 		Workers:     workers,
 		Pipeline:    pipeline,
+		Headers:     headers,
 	}
 
 	return cfg, nil
@@ -130,14 +171,10 @@ func methodHasBody(m string) bool {
 }
 
 // printWizardHeader renders a simple, responsive ASCII header for the wizard.
-func printWizardHeader() {
-	width := 80
-	if w := os.Getenv("COLUMNS"); w != "" {
-		if v, err := strconv.Atoi(w); err == nil && v > 20 {
-			width = v
-		}
-	}
-	if width > 64 {
+func printWizardHeader(fullWidth bool) {
+	// Widths too narrow for the header get the usual size.
+	width := boxWidth(64, fullWidth)
+	if width <= 20 {
 		width = 64
 	}
 	inner := width - 2
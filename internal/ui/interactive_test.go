@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRunWizard_RepromptsForBadURL drives the wizard with a URL the check
+// rejects, then a good one, and takes defaults for the rest.
+func TestRunWizard_RepromptsForBadURL(t *testing.T) {
+	var checked []string
+	check := func(u string) error {
+		checked = append(checked, u)
+		if strings.Contains(u, "bad") {
+			return errors.New("host does not resolve")
+		}
+		return nil
+	}
+	in := strings.NewReader("http://bad.invalid/\nhttp://127.0.0.1:8080/\n\n\n\n\n\n\n")
+
+	var cfg *WizardConfig
+	var err error
+	out := captureStdout(t, func() {
+		cfg, err = runWizard(in, false, check, nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.URL != "http://127.0.0.1:8080/" {
+		t.Errorf("URL = %q, want the second answer", cfg.URL)
+	}
+	if len(checked) != 2 {
+		t.Errorf("check ran on %q, want both URLs", checked)
+	}
+	if !strings.Contains(out, "host does not resolve") {
+		t.Errorf("output does not show the check error:\n%s", out)
+	}
+	if strings.Count(out, "Target URL") != 2 {
+		t.Errorf("want the URL prompt twice:\n%s", out)
+	}
+	if cfg.Method != "GET" || cfg.Connections != 50 || cfg.Duration != 10*time.Second {
+		t.Errorf("defaults = %s %d %v", cfg.Method, cfg.Connections, cfg.Duration)
+	}
+}
+
+// TestRunWizard_EOFDuringReprompt checks that running out of input while
+// re-prompting ends the wizard instead of looping.
+func TestRunWizard_EOFDuringReprompt(t *testing.T) {
+	in := strings.NewReader("\n")
+	captureStdout(t, func() {
+		if _, err := runWizard(in, false, nil, nil); err == nil {
+			t.Error("want an error once input runs out")
+		}
+	})
+}
+
+// TestRunWizard_Headers enters a header, one the check rejects and another,
+// and checks the good ones are kept in order.
+func TestRunWizard_Headers(t *testing.T) {
+	check := func(h string) error {
+		if !strings.Contains(h, ":") {
+			return errors.New("invalid header")
+		}
+		return nil
+	}
+	in := strings.NewReader("http://127.0.0.1:8080/\n\n\n\n\n\n" +
+		"Authorization: Bearer abc\nno colon\nX-Trace: 1\n\n")
+
+	var cfg *WizardConfig
+	var err error
+	out := captureStdout(t, func() {
+		cfg, err = runWizard(in, false, nil, check)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Authorization: Bearer abc", "X-Trace: 1"}
+	if strings.Join(cfg.Headers, "|") != strings.Join(want, "|") {
+		t.Errorf("Headers = %q, want %q", cfg.Headers, want)
+	}
+	if !strings.Contains(out, "invalid header") {
+		t.Errorf("output does not show the check error:\n%s", out)
+	}
+}
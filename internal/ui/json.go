@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// jsonRenderer prints nothing while the run is in progress and the final
+// snapshot as one JSON document (--output json), for scripts and CI. Status
+// snapshots and soak reports asked for during the run go to stderr as text,
+// so w only ever holds the JSON.
+type jsonRenderer struct {
+	w     io.Writer
+	notes *asciiRenderer
+}
+
+// NewJSONRenderer returns a renderer that writes the final snapshot to w as
+// indented JSON. Keys are the stats.Snapshot field names; durations are
+// integer nanoseconds and maps keyed by status code have string keys. opts
+// apply to the status snapshots and soak reports printed to stderr.
+func NewJSONRenderer(w io.Writer, opts ...RendererOption) Renderer {
+	notes := NewRenderer(opts...).(*asciiRenderer)
+	notes.out = os.Stderr
+	return &jsonRenderer{w: w, notes: notes}
+}
+
+func (r *jsonRenderer) Render(stats.Snapshot) {}
+
+func (r *jsonRenderer) RenderStatus(snap stats.Snapshot) {
+	r.notes.RenderStatus(snap)
+}
+
+func (r *jsonRenderer) RenderSoak(snap stats.Snapshot, rt RuntimeStats) {
+	r.notes.RenderSoak(snap, rt)
+}
+
+func (r *jsonRenderer) RenderFinal(snap stats.Snapshot) {
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(snap); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: json output: %v\n", err)
+	}
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+func TestJSONRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewJSONRenderer(&buf)
+	snap := stats.Snapshot{
+		TotalRequests: 10,
+		Successes:     9,
+		Errors:        1,
+		Duration:      2 * time.Second,
+		LatencyP99:    1500 * time.Microsecond,
+		StatusCounts:  map[int]uint64{200: 9, 0: 1},
+		ErrorsByCategory: map[stats.ErrorCategory]uint64{
+			stats.ErrConnect: 1,
+		},
+	}
+	r.Render(snap)
+	if buf.Len() != 0 {
+		t.Fatalf("Render wrote %q, want nothing during the run", buf.String())
+	}
+
+	r.RenderFinal(snap)
+	var got struct {
+		TotalRequests    uint64
+		Duration         int64
+		LatencyP99       int64
+		StatusCounts     map[string]uint64
+		ErrorsByCategory map[string]uint64
+	}
+	dec := json.NewDecoder(&buf)
+	if err := dec.Decode(&got); err != nil {
+		t.Fatalf("final output is not JSON: %v\n%s", err, buf.String())
+	}
+	if dec.More() {
+		t.Error("more than one JSON document written")
+	}
+	if got.TotalRequests != 10 || got.Duration != 2e9 || got.LatencyP99 != 1_500_000 {
+		t.Errorf("got %+v, want 10 requests, durations in ns", got)
+	}
+	if got.StatusCounts["200"] != 9 || got.StatusCounts["0"] != 1 || got.ErrorsByCategory["connect"] != 1 {
+		t.Errorf("maps: status %v, errors %v", got.StatusCounts, got.ErrorsByCategory)
+	}
+}
+
+func TestJSONRenderer_StatusAndSoakOnStderr(t *testing.T) {
+	var buf, notes bytes.Buffer
+	r := NewJSONRenderer(&buf)
+	r.(*jsonRenderer).notes.out = &notes
+	snap := stats.Snapshot{TotalRequests: 10, Successes: 10, Duration: 5 * time.Second}
+
+	sr, ok := r.(StatusRenderer)
+	if !ok {
+		t.Fatal("json renderer does not print status snapshots")
+	}
+	sr.RenderStatus(snap)
+	soak, ok := r.(SoakRenderer)
+	if !ok {
+		t.Fatal("json renderer does not print soak reports")
+	}
+	soak.RenderSoak(snap, RuntimeStats{Report: 1})
+
+	if buf.Len() != 0 {
+		t.Errorf("status and soak report wrote %q to the JSON output", buf.String())
+	}
+	for _, want := range []string{"Status", "Soak report 1", "Summary"} {
+		if !strings.Contains(notes.String(), want) {
+			t.Errorf("stderr lacks %q:\n%s", want, notes.String())
+		}
+	}
+}
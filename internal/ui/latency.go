@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LatencyUnit selects how the renderer prints latencies.
+type LatencyUnit string
+
+// Latency units. LatencyAuto picks one per snapshot from the p50 latency, so
+// sub-millisecond runs no longer print as "0 ms".
+const (
+	LatencyAuto LatencyUnit = "auto"
+	LatencyNs   LatencyUnit = "ns"
+	LatencyUs   LatencyUnit = "us"
+	LatencyMs   LatencyUnit = "ms"
+	LatencyS    LatencyUnit = "s"
+)
+
+// ParseLatencyUnit validates a --latency-unit value.
+func ParseLatencyUnit(s string) (LatencyUnit, error) {
+	switch u := LatencyUnit(strings.ToLower(strings.TrimSpace(s))); u {
+	case LatencyAuto, LatencyNs, LatencyUs, LatencyMs, LatencyS:
+		return u, nil
+	case "":
+		return LatencyAuto, nil
+	}
+	return "", fmt.Errorf("--latency-unit must be one of auto, ns, us, ms, s; got %q", s)
+}
+
+// resolve returns the concrete unit to use for a snapshot with the given p50.
+func (u LatencyUnit) resolve(p50 time.Duration) LatencyUnit {
+	if u != LatencyAuto && u != "" {
+		return u
+	}
+	switch {
+	case p50 < time.Microsecond:
+		return LatencyNs
+	case p50 < time.Millisecond:
+		return LatencyUs
+	case p50 < time.Second:
+		return LatencyMs
+	}
+	return LatencyS
+}
+
+// format prints d in the unit, which must already be resolved.
+func (u LatencyUnit) format(d time.Duration) string {
+	switch u {
+	case LatencyNs:
+		return fmt.Sprintf("%d ns", d.Nanoseconds())
+	case LatencyUs:
+		return fmt.Sprintf("%.1f us", float64(d)/float64(time.Microsecond))
+	case LatencyS:
+		return fmt.Sprintf("%.3f s", d.Seconds())
+	}
+	return fmt.Sprintf("%.2f ms", float64(d)/float64(time.Millisecond))
+}
+
+// formatPrec is format with prec decimal places; a negative prec keeps the
+// unit's usual precision. Nanoseconds are always whole.
+func (u LatencyUnit) formatPrec(d time.Duration, prec int) string {
+	switch {
+	case prec < 0:
+		return u.format(d)
+	case u == LatencyNs:
+		return fmt.Sprintf("%d ns", d.Nanoseconds())
+	case u == LatencyUs:
+		return fmt.Sprintf("%.*f us", prec, float64(d)/float64(time.Microsecond))
+	case u == LatencyS:
+		return fmt.Sprintf("%.*f s", prec, d.Seconds())
+	}
+	return fmt.Sprintf("%.*f ms", prec, float64(d)/float64(time.Millisecond))
+}
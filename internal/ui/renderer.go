@@ -2,14 +2,17 @@ package ui
 
 import (
 	"fmt"
+	"io"
+	"maps"
 	"math"
 	"os"
+	"slices"
 	"strings"
-	"syscall"
 	"time"
-	"unsafe"
+	"unicode/utf8"
 
 	"github.com/thetangentline/httpcl/internal/stats"
+	"github.com/thetangentline/httpcl/internal/term"
 )
 
 // humanizeBytes formats bytes as KB, MB, or GB.
@@ -29,6 +32,11 @@ func humanizeBytes(b float64) string {
 	}
 }
 
+// HumanizeBytes formats a byte count the same way the report does.
+func HumanizeBytes(b uint64) string {
+	return humanizeBytes(float64(b))
+}
+
 // ANSI color helpers (8/16-color safe).
 const (
 	colorReset = "\033[0m"
@@ -47,69 +55,165 @@ type Renderer interface {
 	RenderFinal(snap stats.Snapshot)
 }
 
+// StatusRenderer is implemented by renderers that can print a live snapshot on
+// request (e.g. on SIGQUIT) without ending the run.
+type StatusRenderer interface {
+	RenderStatus(snap stats.Snapshot)
+}
+
+// SoakRenderer is implemented by renderers that can print a full interim
+// report during a long run (see --soak-report) without ending it.
+type SoakRenderer interface {
+	RenderSoak(snap stats.Snapshot, rt RuntimeStats)
+}
+
+// RuntimeStats describes the client process at a soak report, with changes
+// since the previous report, to spot leaks in long runs.
+type RuntimeStats struct {
+	Report         int // 1 for the first report
+	HeapAlloc      uint64
+	HeapDelta      int64
+	Goroutines     int
+	GoroutineDelta int
+	NumGC          uint32 // completed GC cycles since the previous report
+}
+
 // asciiRenderer is a simple ANSI/ASCII renderer that prints a single-line summary.
 type asciiRenderer struct {
 	lastLineLen int
 	headerShown bool
+	latencyUnit LatencyUnit
+	precision   int // decimal places for latency and req/s; <0 for defaults
+	fullWidth   bool
+	keys        bool // p/r/q keypresses control the run
+	out         io.Writer
+}
+
+// RendererOption configures the renderer returned by NewRenderer.
+type RendererOption func(*asciiRenderer)
+
+// WithLatencyUnit sets the unit latencies are printed in (default auto).
+func WithLatencyUnit(u LatencyUnit) RendererOption {
+	return func(r *asciiRenderer) { r.latencyUnit = u }
+}
+
+// WithPrecision sets the decimal places for latencies and request rates. A
+// negative n keeps the defaults, which vary by value.
+func WithPrecision(n int) RendererOption {
+	return func(r *asciiRenderer) { r.precision = n }
+}
+
+// WithFullWidth lets boxes and rules span the whole terminal instead of
+// stopping at maxBoxWidth columns.
+func WithFullWidth() RendererOption {
+	return func(r *asciiRenderer) { r.fullWidth = true }
+}
+
+// WithKeyControls mentions the pause, resume and stop keys in the controls
+// hint, for runs that read them from the terminal.
+func WithKeyControls() RendererOption {
+	return func(r *asciiRenderer) { r.keys = true }
 }
 
 // NewRenderer creates a new ASCII renderer.
-func NewRenderer() Renderer {
-	return &asciiRenderer{}
+func NewRenderer(opts ...RendererOption) Renderer {
+	r := &asciiRenderer{latencyUnit: LatencyAuto, precision: -1, out: os.Stdout}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-// winsize mirrors the struct used by TIOCGWINSZ.
-type winsize struct {
-	rows    uint16
-	cols    uint16
-	xpixels uint16
-	ypixels uint16
+// latencyFormatter returns the formatter for snap's latencies, resolving the
+// auto unit from its p50.
+func (r *asciiRenderer) latencyFormatter(snap stats.Snapshot) func(time.Duration) string {
+	unit := r.latencyUnit.resolve(snap.LatencyP50)
+	return func(d time.Duration) string { return unit.formatPrec(d, r.precision) }
 }
 
-// termWidth returns the current terminal width, or a sensible default.
-func termWidth() int {
-	ws := &winsize{}
-	_, _, err := syscall.Syscall(syscall.SYS_IOCTL,
-		uintptr(os.Stdout.Fd()),
-		uintptr(syscall.TIOCGWINSZ),
-		uintptr(unsafe.Pointer(ws)),
-	)
-	if err != 0 || ws.cols == 0 {
-		return 80
+// rate formats a request rate with the configured precision, or def decimal
+// places if none was set.
+func (r *asciiRenderer) rate(v float64, def int) string {
+	if r.precision >= 0 {
+		def = r.precision
+	}
+	return fmt.Sprintf("%.*f", def, v)
+}
+
+// escapeLen returns the length of the ANSI CSI escape sequence starting at
+// s[i], or 0 if none does.
+func escapeLen(s string, i int) int {
+	if s[i] != '\033' || i+1 >= len(s) || s[i+1] != '[' {
+		return 0
+	}
+	j := i + 2
+	for j < len(s) && (s[j] < 0x40 || s[j] == ';') {
+		j++
+	}
+	if j < len(s) {
+		j++
 	}
-	return int(ws.cols)
+	return j - i
 }
 
 // visibleLen returns the rune length of s without ANSI escape sequences.
 func visibleLen(s string) int {
 	n := 0
-	i := 0
-	for i < len(s) {
-		if s[i] == '\033' && i+1 < len(s) && s[i+1] == '[' {
-			i += 2
-			for i < len(s) && (s[i] < 0x40 || s[i] == ';') {
-				i++
-			}
-			if i < len(s) {
-				i++
-			}
+	for i := 0; i < len(s); {
+		if e := escapeLen(s, i); e > 0 {
+			i += e
 			continue
 		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
 		n++
-		i++
 	}
 	return n
 }
 
-// truncateToWidth ensures the line fits in the current terminal width.
+// maxBoxWidth caps boxes and rules so reports stay compact on wide
+// terminals, unless full width is asked for.
+const maxBoxWidth = 72
+
+// boxWidth returns the terminal width, capped at limit unless full.
+func boxWidth(limit int, full bool) int {
+	width := term.Width()
+	if width > limit && !full {
+		width = limit
+	}
+	return width
+}
+
+// truncateToWidth ensures the line fits in the current terminal width,
+// counted like visibleLen. A longer line is cut on a rune boundary and ends
+// in "..." (when width leaves room for it); escape sequences before the cut
+// are kept whole, and if there were any the cut resets the color.
 func truncateToWidth(s string, width int) string {
-	if width <= 0 || len(s) <= width {
+	if width <= 0 || visibleLen(s) <= width {
 		return s
 	}
+	keep, tail := width-3, "..."
 	if width <= 3 {
-		return s[:width]
+		keep, tail = width, ""
+	}
+	var b strings.Builder
+	colored := false
+	for i, n := 0, 0; i < len(s) && n < keep; {
+		if e := escapeLen(s, i); e > 0 {
+			b.WriteString(s[i : i+e])
+			colored = true
+			i += e
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		b.WriteString(s[i : i+size])
+		i += size
+		n++
 	}
-	return s[:width-3] + "..."
+	if colored {
+		b.WriteString(colorReset)
+	}
+	return b.String() + tail
 }
 
 // clearLine clears the current line in the terminal using ANSI escape codes.
@@ -118,7 +222,7 @@ func (r *asciiRenderer) clearLine() {
 		return
 	}
 	// Carriage return + clear line.
-	fmt.Fprint(os.Stdout, "\r\033[2K")
+	fmt.Fprint(r.out, "\r\033[2K")
 }
 
 func (r *asciiRenderer) Render(snap stats.Snapshot) {
@@ -126,41 +230,95 @@ func (r *asciiRenderer) Render(snap stats.Snapshot) {
 
 	// Show a one-time header and footer hint for controls.
 	if !r.headerShown {
-		width := termWidth()
-		if width <= 0 {
-			width = 80
-		}
-		if width > 72 {
-			width = 72
-		}
+		width := boxWidth(maxBoxWidth, r.fullWidth)
 		border := strings.Repeat("─", width)
 
 		title := fmt.Sprintf("%s%sHTTPCL benchmark%s", colorBold, colorCyan, colorReset)
-		fmt.Fprintf(os.Stdout, "%s\n%s\n", title, border)
-		fmt.Fprintf(os.Stdout, "%sControls:%s Ctrl+C to stop\n\n", colorDim, colorReset)
+		fmt.Fprintf(r.out, "%s\n%s\n", title, border)
+		stop := "Ctrl+C to stop"
+		if r.keys {
+			stop = "p to pause, r to resume, q to stop (Ctrl+C aborts)"
+		}
+		fmt.Fprintf(r.out, "%sControls:%s %s, Ctrl+\\ for a status snapshot\n\n", colorDim, colorReset, stop)
 		r.headerShown = true
 	}
 
-	// Color-coded, single-line HUD.
+	// Rolling error rate: red while errors are happening now, dim otherwise.
+	rateColor := colorDim
+	if snap.RecentErrorRate > 0 {
+		rateColor = colorRed
+	}
+
+	// Color-coded, single-line HUD. A connection benchmark shows its
+	// headline rate, new connections per second, in place of requests.
+	rateName, rate := "rps", snap.RequestsPerSAvg
+	if snap.HandshakesTracked {
+		rateName, rate = "conn/s", snap.ConnectsPerS
+	}
 	line := fmt.Sprintf(
-		"%s[httpcl]%s total=%d %sok=%d%s %serr=%d%s rps=%.1f avg=%s",
+		"%s[httpcl]%s total=%d %sok=%d%s %serr=%d%s %serr/5s=%.1f%%%s %s=%s p50=%s",
 		colorCyan, colorReset,
 		snap.TotalRequests,
 		colorGreen, snap.Successes, colorReset,
 		colorRed, snap.Errors, colorReset,
-		snap.RequestsPerSAvg,
-		snap.LatencyP50.Truncate(10*time.Microsecond),
+		rateColor, snap.RecentErrorRate*100, colorReset,
+		rateName, r.rate(rate, 1),
+		r.latencyFormatter(snap)(snap.LatencyP50),
 	)
 
-	line = truncateToWidth(line, termWidth())
+	if snap.ActiveSlots < snap.Slots {
+		line = fmt.Sprintf("%s[backoff %d/%d]%s %s", colorYellow, snap.ActiveSlots, snap.Slots, colorReset, line)
+	}
+	if snap.Paused {
+		line = colorYellow + "[paused]" + colorReset + " " + line
+	}
+
+	// Keep the HUD on one terminal row; redirected output has no rows to wrap.
+	if f, ok := r.out.(*os.File); ok && term.IsTerminal(f) {
+		line = truncateToWidth(line, term.Width())
+	}
 
-	fmt.Fprint(os.Stdout, line)
+	fmt.Fprint(r.out, line)
 	r.lastLineLen = len(line)
 }
 
+// RenderStatus prints a one-off status block above the live HUD line, which is
+// redrawn on the next tick.
+func (r *asciiRenderer) RenderStatus(snap stats.Snapshot) {
+	r.clearLine()
+	ms := r.latencyFormatter(snap)
+	fmt.Fprintf(r.out, "%s%sStatus%s at %s\n", colorBold, colorCyan, colorReset, snap.Duration.Truncate(100*time.Millisecond))
+	fmt.Fprintf(r.out, "  requests  total=%d %sok=%d%s %serr=%d%s abandoned=%d\n",
+		snap.TotalRequests, colorGreen, snap.Successes, colorReset, colorRed, snap.Errors, colorReset, snap.Abandoned)
+	fmt.Fprintf(r.out, "  latency   p50=%s p97.5=%s p99=%s max=%s\n",
+		ms(snap.LatencyP50), ms(snap.LatencyP975), ms(snap.LatencyP99), ms(snap.LatencyMax))
+	fmt.Fprintf(r.out, "  rate      %s req/s, %s/s\n", r.rate(snap.RequestsPerSAvg, 1), humanizeBytes(snap.BytesPerSAvg))
+	r.lastLineLen = 0
+}
+
+// RenderSoak prints the full report so far, headed by the process's memory
+// and goroutine counts, above the live HUD line, which is redrawn on the
+// next tick.
+func (r *asciiRenderer) RenderSoak(snap stats.Snapshot, rt RuntimeStats) {
+	r.clearLine()
+	fmt.Fprintf(r.out, "\n%s%sSoak report %d%s at %s\n", colorBold, colorCyan, rt.Report, colorReset, snap.Duration.Truncate(time.Second))
+	fmt.Fprintf(r.out, "  runtime   heap=%s (%s) goroutines=%d (%+d) gc=%d\n",
+		HumanizeBytes(rt.HeapAlloc), signedBytes(rt.HeapDelta), rt.Goroutines, rt.GoroutineDelta, rt.NumGC)
+	r.RenderFinal(snap)
+	r.lastLineLen = 0
+}
+
+// signedBytes formats a byte delta with its sign, e.g. "+1.2 MB".
+func signedBytes(d int64) string {
+	if d < 0 {
+		return "-" + HumanizeBytes(uint64(-d))
+	}
+	return "+" + HumanizeBytes(uint64(d))
+}
+
 func (r *asciiRenderer) RenderFinal(snap stats.Snapshot) {
 	r.clearLine()
-	fmt.Fprintln(os.Stdout)
+	fmt.Fprintln(r.out)
 
 	padTo := func(s string, n int) string {
 		need := n - visibleLen(s)
@@ -174,84 +332,356 @@ func (r *asciiRenderer) RenderFinal(snap stats.Snapshot) {
 		inner := strings.Repeat(" ", cellPad) + s
 		return padTo(inner, w)
 	}
-	latMs := func(d time.Duration) string { return fmt.Sprintf("%d ms", d.Milliseconds()) }
+	latMs := r.latencyFormatter(snap)
 
 	// Grid column widths: Stat, then 7 metric columns
 	cw := []int{12, 12, 12, 12, 12, 12, 12, 12}
 	gridTop := func() {
-		fmt.Fprintf(os.Stdout, "┌%s┬%s┬%s┬%s┬%s┬%s┬%s┬%s┐\n",
+		fmt.Fprintf(r.out, "┌%s┬%s┬%s┬%s┬%s┬%s┬%s┬%s┐\n",
 			strings.Repeat("─", cw[0]), strings.Repeat("─", cw[1]), strings.Repeat("─", cw[2]),
 			strings.Repeat("─", cw[3]), strings.Repeat("─", cw[4]), strings.Repeat("─", cw[5]),
 			strings.Repeat("─", cw[6]), strings.Repeat("─", cw[7]))
 	}
 	gridMid := func() {
-		fmt.Fprintf(os.Stdout, "├%s┼%s┼%s┼%s┼%s┼%s┼%s┼%s┤\n",
+		fmt.Fprintf(r.out, "├%s┼%s┼%s┼%s┼%s┼%s┼%s┼%s┤\n",
 			strings.Repeat("─", cw[0]), strings.Repeat("─", cw[1]), strings.Repeat("─", cw[2]),
 			strings.Repeat("─", cw[3]), strings.Repeat("─", cw[4]), strings.Repeat("─", cw[5]),
 			strings.Repeat("─", cw[6]), strings.Repeat("─", cw[7]))
 	}
 	gridBot := func() {
-		fmt.Fprintf(os.Stdout, "└%s┴%s┴%s┴%s┴%s┴%s┴%s┴%s┘\n",
+		fmt.Fprintf(r.out, "└%s┴%s┴%s┴%s┴%s┴%s┴%s┴%s┘\n",
 			strings.Repeat("─", cw[0]), strings.Repeat("─", cw[1]), strings.Repeat("─", cw[2]),
 			strings.Repeat("─", cw[3]), strings.Repeat("─", cw[4]), strings.Repeat("─", cw[5]),
 			strings.Repeat("─", cw[6]), strings.Repeat("─", cw[7]))
 	}
 	gridRow := func(a1, a2, a3, a4, a5, a6, a7, a8 string) {
-		fmt.Fprintf(os.Stdout, "│%s│%s│%s│%s│%s│%s│%s│%s│\n",
+		fmt.Fprintf(r.out, "│%s│%s│%s│%s│%s│%s│%s│%s│\n",
 			cell(a1, cw[0]), cell(a2, cw[1]), cell(a3, cw[2]), cell(a4, cw[3]),
 			cell(a5, cw[4]), cell(a6, cw[5]), cell(a7, cw[6]), cell(a8, cw[7]))
 	}
 
-	fmt.Fprintf(os.Stdout, "%s%s%s\n", colorBold, "Latency (ms)", colorReset)
+	if snap.HandshakesTracked {
+		fmt.Fprintf(r.out, "%sConnections%s %s(new connection per request)%s\n", colorBold, colorReset, colorDim, colorReset)
+		fmt.Fprintf(r.out, "  %s%s conn/s%s, %s TLS handshakes/s  %s(%d connections, %d handshakes)%s\n",
+			colorCyan, r.rate(snap.ConnectsPerS, 1), colorReset, r.rate(snap.TLSHandshakesPerS, 1),
+			colorDim, snap.Connects, snap.TLSHandshakes, colorReset)
+		fmt.Fprintf(r.out, "  setup     p50=%s p90=%s p99=%s max=%s\n",
+			latMs(snap.HandshakeP50), latMs(snap.HandshakeP90), latMs(snap.HandshakeP99), latMs(snap.HandshakeMax))
+		fmt.Fprintln(r.out)
+	}
+
+	fmt.Fprintf(r.out, "%s%s%s %s(from %d samples of %d requests)%s\n",
+		colorBold, "Latency", colorReset, colorDim, snap.LatencySampleCount, snap.TotalRequests, colorReset)
 	gridTop()
 	gridRow(colorCyan+"Stat"+colorReset, colorCyan+"2.5%"+colorReset, colorCyan+"50%"+colorReset, colorCyan+"97.5%"+colorReset, colorCyan+"99%"+colorReset, colorCyan+"Avg"+colorReset, colorCyan+"Stdev"+colorReset, colorCyan+"Max"+colorReset)
 	gridMid()
 	gridRow("Latency", latMs(snap.LatencyP25), latMs(snap.LatencyP50), latMs(snap.LatencyP975), latMs(snap.LatencyP99), latMs(snap.LatencyAvg), latMs(snap.LatencyStdev), latMs(snap.LatencyMax))
 	gridBot()
-	fmt.Fprintln(os.Stdout)
+	if hint := samplingHint(snap); hint != "" {
+		fmt.Fprintf(r.out, "%s%s%s\n", colorYellow, hint, colorReset)
+	}
+	if note := loadModelNote(snap); note != "" {
+		fmt.Fprintf(r.out, "%s%s%s\n", colorDim, note, colorReset)
+	}
+	fmt.Fprintln(r.out)
 
-	fmt.Fprintf(os.Stdout, "%s%s%s\n", colorBold, "Throughput", colorReset)
+	fmt.Fprintf(r.out, "%s%s%s\n", colorBold, "Throughput", colorReset)
 	gridTop()
 	gridRow(colorCyan+"Stat"+colorReset, colorCyan+"1%"+colorReset, colorCyan+"2.5%"+colorReset, colorCyan+"50%"+colorReset, colorCyan+"97.5%"+colorReset, colorCyan+"Avg"+colorReset, colorCyan+"Stdev"+colorReset, colorCyan+"Min"+colorReset)
 	gridMid()
-	gridRow("Req/Sec", fmt.Sprintf("%.0f", snap.RPSP01), fmt.Sprintf("%.0f", snap.RPSP025), fmt.Sprintf("%.0f", snap.RPSP50), fmt.Sprintf("%.0f", snap.RPSP975), fmt.Sprintf("%.2f", snap.RequestsPerSAvg), fmt.Sprintf("%.0f", snap.RPSStdev), fmt.Sprintf("%.0f", snap.RPSMin))
+	gridRow("Req/Sec", r.rate(snap.RPSP01, 0), r.rate(snap.RPSP025, 0), r.rate(snap.RPSP50, 0), r.rate(snap.RPSP975, 0), r.rate(snap.RequestsPerSAvg, 2), r.rate(snap.RPSStdev, 0), r.rate(snap.RPSMin, 0))
 	gridRow("Bytes/Sec", humanizeBytes(snap.BytesPerSP01), humanizeBytes(snap.BytesPerSP025), humanizeBytes(snap.BytesPerSP50), humanizeBytes(snap.BytesPerSP975), humanizeBytes(snap.BytesPerSAvg), humanizeBytes(snap.BytesPerSStdev), humanizeBytes(snap.BytesPerSMin))
 	gridBot()
-	fmt.Fprintln(os.Stdout)
+	fmt.Fprintln(r.out)
+
+	if snap.RespSizeCount > 0 {
+		size := func(b uint64) string { return humanizeBytes(float64(b)) }
+		fmt.Fprintf(r.out, "%s%s%s %s(body of %d successful responses)%s\n",
+			colorBold, "Response size", colorReset, colorDim, snap.RespSizeCount, colorReset)
+		gridTop()
+		gridRow(colorCyan+"Stat"+colorReset, colorCyan+"2.5%"+colorReset, colorCyan+"50%"+colorReset, colorCyan+"97.5%"+colorReset, colorCyan+"99%"+colorReset, colorCyan+"Avg"+colorReset, colorCyan+"Stdev"+colorReset, colorCyan+"Max"+colorReset)
+		gridMid()
+		gridRow("Size", size(snap.RespSizeP25), size(snap.RespSizeP50), size(snap.RespSizeP975), size(snap.RespSizeP99), humanizeBytes(snap.RespSizeAvg), humanizeBytes(snap.RespSizeStdev), size(snap.RespSizeMax))
+		gridBot()
+		fmt.Fprintln(r.out)
+	}
 
-	width := termWidth()
-	if width <= 0 {
-		width = 80
+	if len(snap.StatusCounts) > 0 {
+		fmt.Fprintf(r.out, "%s%s%s\n", colorBold, "Status codes", colorReset)
+		scw := []int{16, 12, 10}
+		rule := func(left, mid, right string) {
+			fmt.Fprintf(r.out, "%s%s%s%s%s%s%s\n", left, strings.Repeat("─", scw[0]), mid, strings.Repeat("─", scw[1]), mid, strings.Repeat("─", scw[2]), right)
+		}
+		row := func(code, count, share string) {
+			fmt.Fprintf(r.out, "│%s│%s│%s│\n", cell(code, scw[0]), cell(count, scw[1]), cell(share, scw[2]))
+		}
+		rule("┌", "┬", "┐")
+		row(colorCyan+"Code"+colorReset, colorCyan+"Count"+colorReset, colorCyan+"Share"+colorReset)
+		rule("├", "┼", "┤")
+		for _, sr := range statusRows(snap.StatusCounts, snap.TotalRequests) {
+			row(sr.color+sr.label+colorReset, fmt.Sprintf("%d", sr.count), sr.share)
+		}
+		rule("└", "┴", "┘")
+		fmt.Fprintln(r.out)
 	}
-	if width > 72 {
-		width = 72
+
+	if len(snap.LoadSteps) > 0 {
+		fmt.Fprintf(r.out, "%s%s%s %s(requests completed while each level was held)%s\n",
+			colorBold, "Concurrency steps", colorReset, colorDim, colorReset)
+		gridTop()
+		gridRow(colorCyan+"Step"+colorReset, colorCyan+"Conc"+colorReset, colorCyan+"Held"+colorReset, colorCyan+"Requests"+colorReset, colorCyan+"Req/Sec"+colorReset, colorCyan+"p50"+colorReset, colorCyan+"p99"+colorReset, colorCyan+"Errors"+colorReset)
+		gridMid()
+		for i, s := range snap.LoadSteps {
+			errs := fmt.Sprintf("%d", s.Errors)
+			if s.Errors > 0 {
+				errs = colorRed + errs + colorReset
+			}
+			gridRow(fmt.Sprintf("%d", i+1), fmt.Sprintf("%d", s.Concurrency), s.Duration.Round(time.Millisecond).String(), fmt.Sprintf("%d", s.Requests),
+				r.rate(s.RequestsPerS, 0), latMs(s.LatencyP50), latMs(s.LatencyP99), errs)
+		}
+		gridBot()
+		fmt.Fprintln(r.out)
 	}
-	inner := width - 2
+
+	inner := boxWidth(maxBoxWidth, r.fullWidth) - 2
 	hLine := strings.Repeat("─", inner)
 
-	fmt.Fprintf(os.Stdout, "┌%s┐\n", hLine)
-	fmt.Fprintf(os.Stdout, "│%s│\n", padTo(" "+colorBold+"Summary"+colorReset, inner))
-	fmt.Fprintf(os.Stdout, "├%s┤\n", hLine)
+	fmt.Fprintf(r.out, "┌%s┐\n", hLine)
+	fmt.Fprintf(r.out, "│%s│\n", padTo(" "+colorBold+"Summary"+colorReset, inner))
+	fmt.Fprintf(r.out, "├%s┤\n", hLine)
 
 	summaryRow := func(label, value string, valueColor string) {
 		if valueColor == "" {
 			valueColor = colorReset
 		}
 		s := " " + colorBold + label + colorReset + " : " + valueColor + value + colorReset
-		fmt.Fprintf(os.Stdout, "│%s│\n", padTo(s, inner))
+		fmt.Fprintf(r.out, "│%s│\n", padTo(s, inner))
 	}
 	summaryRowColored := func(label, value string, rowColor string) {
 		s := " " + rowColor + colorBold + label + colorReset + rowColor + " : " + value + colorReset
-		fmt.Fprintf(os.Stdout, "│%s│\n", padTo(s, inner))
+		fmt.Fprintf(r.out, "│%s│\n", padTo(s, inner))
 	}
 
+	if snap.HandshakesTracked {
+		summaryRow("Conn/sec", fmt.Sprintf("%s (%s TLS handshakes/sec)", r.rate(snap.ConnectsPerS, 1), r.rate(snap.TLSHandshakesPerS, 1)), colorCyan)
+	}
 	summaryRow("Total Requests", fmt.Sprintf("%d", snap.TotalRequests), "")
 	summaryRowColored("Successes", fmt.Sprintf("%d", snap.Successes), colorGreen)
 	summaryRowColored("Errors", fmt.Sprintf("%d", snap.Errors), colorRed)
+	if snap.Errors > 0 {
+		var parts []string
+		for _, cat := range stats.ErrorCategories() {
+			if n := snap.ErrorsByCategory[cat]; n > 0 {
+				parts = append(parts, fmt.Sprintf("%s=%d", cat, n))
+			}
+		}
+		summaryRow("Error types", strings.Join(parts, " "), colorRed)
+		// Failed body checks are the user's own; show what they failed on.
+		for _, msg := range snap.ErrorSamples[stats.ErrValidation] {
+			summaryRow("  failed", truncateToWidth(msg, inner-14), colorRed)
+		}
+	}
 	summaryRow("Duration", snap.Duration.String(), "")
+	if snap.RunID != "" {
+		summaryRow("Run ID", snap.RunID, colorDim)
+	}
+	if len(snap.Phases) > 0 {
+		summaryRow("Phases", phasesString(snap.Phases), colorDim)
+	}
 	summaryRow("Data sent", humanizeBytes(float64(snap.TotalBytesSent)), colorCyan)
 	summaryRow("Data received", humanizeBytes(float64(snap.TotalBytesRecv)), colorCyan)
+	if snap.Abandoned > 0 {
+		summaryRowColored("Abandoned", fmt.Sprintf("%d (still in flight at drain timeout)", snap.Abandoned), colorYellow)
+	}
+	if snap.Discarded > 0 {
+		summaryRow("Discarded", fmt.Sprintf("%d warm-up requests (--discard-first-per-conn)", snap.Discarded), colorDim)
+	}
+	if snap.ConnWaitTracked {
+		value := fmt.Sprintf("p50 %s, p99 %s, max %s", latMs(snap.ConnWaitP50), latMs(snap.ConnWaitP99), latMs(snap.ConnWaitMax))
+		if snap.ConnWaitShare > 0 {
+			value += fmt.Sprintf(", %.0f%% of slot time", snap.ConnWaitShare*100)
+		}
+		summaryRow("Conn wait", value, colorCyan)
+	}
+	if snap.DNSLookups > 0 {
+		summaryRow("DNS lookups", fmt.Sprintf("%d for %d connections", snap.DNSLookups, snap.ConnsOpened), colorDim)
+	}
+	if snap.ConnRotations > 0 {
+		summaryRow("Rotations", fmt.Sprintf("%d connections closed by --max-requests-per-conn", snap.ConnRotations), colorDim)
+	}
+	if len(snap.ResolvedAddrs) > 0 {
+		summaryRow("Resolved", strings.Join(snap.ResolvedAddrs, ", "), colorDim)
+	}
+	if len(snap.RemoteAddrs) > 0 {
+		parts := make([]string, len(snap.RemoteAddrs))
+		for i, a := range snap.RemoteAddrs {
+			parts[i] = fmt.Sprintf("%s (%d req)", a.Addr, a.Requests)
+		}
+		summaryRow("Connected to", strings.Join(parts, ", "), colorDim)
+	}
+	if len(snap.Conns) > 0 {
+		shown := snap.Conns[:min(len(snap.Conns), maxConnRows)]
+		summaryRow("Per conn", fmt.Sprintf("%d connections, worst %d:", len(snap.Conns), len(shown)), colorDim)
+		for _, cs := range shown {
+			color := ""
+			if cs.Errors > 0 {
+				color = colorRed
+			}
+			summaryRow(fmt.Sprintf("  #%d", cs.ID), fmt.Sprintf("%s from %s  %d req, %d err (%.1f%%)",
+				cs.Remote, localPort(cs.Local), cs.Requests, cs.Errors, cs.ErrorRate()*100), color)
+		}
+	}
+	if snap.RateLimited > 0 {
+		summaryRow("Rate limited", fmt.Sprintf("%d 429 responses (%.1f%% of requests), neither successes nor errors", snap.RateLimited,
+			100*float64(snap.RateLimited)/float64(snap.TotalRequests)), colorYellow)
+	}
+	if snap.NotModified > 0 {
+		summaryRow("Not modified", fmt.Sprintf("%d 304 responses (%.1f%% of requests)", snap.NotModified,
+			100*float64(snap.NotModified)/float64(snap.TotalRequests)), colorCyan)
+	}
+	if len(snap.Steps) > 0 {
+		summaryRow("Steps", fmt.Sprintf("%d per transaction (totals above count transactions)", len(snap.Steps)), colorDim)
+		for i, s := range snap.Steps {
+			summaryRow(fmt.Sprintf("  %d", i+1), fmt.Sprintf("%s  %d req, %d err, avg %s, max %s",
+				truncateToWidth(s.Name, maxStepName), s.Requests, s.Errors, latMs(s.LatencyAvg), latMs(s.LatencyMax)), colorDim)
+		}
+	}
+	if len(snap.Targets) > 0 {
+		summaryRow("Targets", fmt.Sprintf("%d URLs, split by weight:", len(snap.Targets)), colorDim)
+		for i, t := range snap.Targets {
+			color := colorDim
+			if t.Errors > 0 {
+				color = colorRed
+			}
+			summaryRow(fmt.Sprintf("  %d", i+1), fmt.Sprintf("%s  %.1f%%, %d err, avg %s",
+				truncateToWidth(t.Name, maxTargetName), 100*float64(t.Requests)/float64(max(snap.TotalRequests, 1)), t.Errors, latMs(t.LatencyAvg)), color)
+		}
+	}
+	if len(snap.BodySends) > 0 {
+		summaryRow("Bodies", bodySendsString(snap.BodySends), colorDim)
+	}
+	if snap.Retries > 0 {
+		summaryRow("Retries", fmt.Sprintf("%d failed attempts re-sent", snap.Retries), colorDim)
+	}
+	if snap.ChunkedResponses > 0 {
+		summaryRow("Chunked", fmt.Sprintf("%d responses (no Content-Length)", snap.ChunkedResponses), colorDim)
+	}
+	if len(snap.Undecoded) > 0 {
+		var parts []string
+		for _, enc := range slices.Sorted(maps.Keys(snap.Undecoded)) {
+			parts = append(parts, fmt.Sprintf("%d %s", snap.Undecoded[enc], enc))
+		}
+		summaryRowColored("Undecoded", strings.Join(parts, ", ")+" (unsupported Content-Encoding, checked as received)", colorYellow)
+	}
+
+	fmt.Fprintf(r.out, "└%s┘\n", hLine)
+	fmt.Fprintf(r.out, "%sDone.%s\n", colorDim, colorReset)
+}
+
+// statusRow is one line of the "Status codes" table.
+type statusRow struct {
+	label, share, color string
+	count               uint64
+}
+
+// statusRows lists counts by status code in ascending order, with failed
+// requests that got no response (code 0) last. Codes are colored by class.
+func statusRows(counts map[int]uint64, total uint64) []statusRow {
+	codes := slices.Sorted(maps.Keys(counts))
+	if len(codes) > 0 && codes[0] == 0 {
+		codes = append(codes[1:], 0)
+	}
+	rows := make([]statusRow, 0, len(codes))
+	for _, code := range codes {
+		r := statusRow{label: fmt.Sprintf("%d", code), count: counts[code], share: "-"}
+		switch {
+		case code == 0:
+			r.label, r.color = "no response", colorRed
+		case code >= 500:
+			r.color = colorRed
+		case code >= 400:
+			r.color = colorYellow
+		case code >= 300:
+			r.color = colorCyan
+		default:
+			r.color = colorGreen
+		}
+		if total > 0 {
+			r.share = fmt.Sprintf("%.1f%%", 100*float64(r.count)/float64(total))
+		}
+		rows = append(rows, r)
+	}
+	return rows
+}
+
+// bodySendsString describes how evenly a --body-cycle corpus was sent, e.g.
+// "12 cycled in order, 83-84 sends each".
+func bodySendsString(sends []uint64) string {
+	lo, hi := slices.Min(sends), slices.Max(sends)
+	if lo == hi {
+		return fmt.Sprintf("%d cycled in order, %d sends each", len(sends), lo)
+	}
+	return fmt.Sprintf("%d cycled in order, %d-%d sends each", len(sends), lo, hi)
+}
+
+// sampledFractionWarn is the share of requests below which the latency
+// percentiles are flagged as covering only part of the run.
+const sampledFractionWarn = 0.9
 
-	fmt.Fprintf(os.Stdout, "└%s┘\n", hLine)
-	fmt.Fprintf(os.Stdout, "%sDone.%s\n", colorDim, colorReset)
+// samplingHint explains a truncated latency sample, or returns "" when the
+// percentiles cover (nearly) every request.
+func samplingHint(snap stats.Snapshot) string {
+	if snap.TotalRequests == 0 {
+		return ""
+	}
+	frac := float64(snap.LatencySampleCount) / float64(snap.TotalRequests)
+	if frac >= sampledFractionWarn {
+		return ""
+	}
+	return fmt.Sprintf("note: latency percentiles cover only the first %.0f%% of requests; raise --stats-memory to keep more samples", frac*100)
+}
+
+// loadModelNote says how to read the latencies of a closed- or open-loop
+// run, or returns "" when nothing was measured.
+func loadModelNote(snap stats.Snapshot) string {
+	if snap.TotalRequests == 0 {
+		return ""
+	}
+	if snap.OpenLoop {
+		return "open loop: requests were paced at a set rate (--rate or --adaptive-rate), not sent as responses arrived"
+	}
+	return "closed loop: each slot waits for its response before sending the next, so latency is service time\n" +
+		"and may understate tail latency under load; --rate or --adaptive-rate paces requests instead"
+}
+
+// phasesString renders run phases as "preflight 3ms, load 10s, drain 41ms".
+func phasesString(phases []stats.Phase) string {
+	parts := make([]string, len(phases))
+	for i, p := range phases {
+		d := p.Duration
+		if d >= time.Millisecond {
+			d = d.Round(time.Millisecond)
+		}
+		parts[i] = p.Name + " " + d.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// maxStepName is how much of a transaction step's "METHOD /path" the
+// summary shows.
+const maxStepName = 18
+
+// maxTargetName is how much of a target URL fits in a summary row.
+const maxTargetName = 28
+
+// maxConnRows is how many connections the --per-conn summary lists.
+const maxConnRows = 5
+
+// localPort shortens a connection's local address to ":port"; the host is
+// the same for every connection of a run.
+func localPort(addr string) string {
+	if i := strings.LastIndexByte(addr, ':'); i >= 0 {
+		return addr[i:]
+	}
+	return addr
 }
@@ -0,0 +1,337 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// captureStdout returns what fn printed to os.Stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- string(b)
+	}()
+	fn()
+	os.Stdout = orig
+	w.Close()
+	return <-done
+}
+
+func TestRenderFinal_SubMillisecondLatency(t *testing.T) {
+	snap := stats.Snapshot{
+		TotalRequests:      1000,
+		Successes:          1000,
+		LatencySampleCount: 1000,
+		LatencyP25:         120 * time.Microsecond,
+		LatencyP50:         250 * time.Microsecond,
+		LatencyP99:         900 * time.Microsecond,
+		LatencyMax:         1500 * time.Microsecond,
+	}
+
+	out := captureStdout(t, func() { NewRenderer().RenderFinal(snap) })
+	for _, want := range []string{"120.0 us", "250.0 us", "900.0 us", "1500.0 us"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("auto unit: report lacks %q", want)
+		}
+	}
+	if strings.Contains(out, "0 ms") {
+		t.Error("auto unit: sub-millisecond latency printed as 0 ms")
+	}
+
+	out = captureStdout(t, func() { NewRenderer(WithLatencyUnit(LatencyMs)).RenderFinal(snap) })
+	if !strings.Contains(out, "0.25 ms") || !strings.Contains(out, "1.50 ms") {
+		t.Errorf("ms unit: report lacks fractional milliseconds:\n%s", out)
+	}
+}
+
+func TestRenderFinal_Precision(t *testing.T) {
+	snap := stats.Snapshot{
+		TotalRequests:      1000,
+		Successes:          1000,
+		LatencySampleCount: 1000,
+		LatencyP50:         12345678 * time.Nanosecond,
+		LatencyMax:         40 * time.Millisecond,
+		RequestsPerSAvg:    812.3456,
+		RPSP50:             809.5,
+	}
+
+	out := captureStdout(t, func() { NewRenderer(WithPrecision(4)).RenderFinal(snap) })
+	for _, want := range []string{"12.3457 ms", "40.0000 ms", "812.3456", "809.5000"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("precision 4: report lacks %q", want)
+		}
+	}
+
+	out = captureStdout(t, func() { NewRenderer(WithPrecision(0)).RenderFinal(snap) })
+	for _, want := range []string{"12 ms", "40 ms", " 812 "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("precision 0: report lacks %q", want)
+		}
+	}
+
+	out = captureStdout(t, func() { NewRenderer(WithPrecision(3)).Render(snap) })
+	if !strings.Contains(out, "rps=812.346") || !strings.Contains(out, "p50=12.346 ms") {
+		t.Errorf("precision 3: HUD = %q", out)
+	}
+
+	// Without the option each value keeps its usual precision.
+	out = captureStdout(t, func() { NewRenderer().RenderFinal(snap) })
+	for _, want := range []string{"12.35 ms", "812.35", " 810 "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("default: report lacks %q", want)
+		}
+	}
+}
+
+func TestRenderFinal_ConnBench(t *testing.T) {
+	snap := stats.Snapshot{
+		TotalRequests:      300,
+		Successes:          300,
+		LatencySampleCount: 300,
+		LatencyP50:         2 * time.Millisecond,
+		HandshakesTracked:  true,
+		Connects:           300,
+		TLSHandshakes:      300,
+		ConnectsPerS:       150,
+		TLSHandshakesPerS:  150,
+		HandshakeP50:       1500 * time.Microsecond,
+		HandshakeMax:       4 * time.Millisecond,
+	}
+	out := captureStdout(t, func() { NewRenderer().RenderFinal(snap) })
+	for _, want := range []string{"150.0 conn/s", "150.0 TLS handshakes/s", "p50=1.50 ms", "Conn/sec"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "Connections") > strings.Index(out, "Latency") {
+		t.Error("connection rates should lead the report")
+	}
+}
+
+func TestLatencyUnit_AutoResolve(t *testing.T) {
+	cases := map[time.Duration]LatencyUnit{
+		500 * time.Nanosecond:   LatencyNs,
+		40 * time.Microsecond:   LatencyUs,
+		12 * time.Millisecond:   LatencyMs,
+		2500 * time.Millisecond: LatencyS,
+	}
+	for p50, want := range cases {
+		if got := LatencyAuto.resolve(p50); got != want {
+			t.Errorf("auto with p50 %s = %s, want %s", p50, got, want)
+		}
+	}
+	if got := LatencyUs.resolve(3 * time.Second); got != LatencyUs {
+		t.Errorf("explicit unit was overridden: %s", got)
+	}
+}
+
+func TestRenderFinal_SamplingHint(t *testing.T) {
+	snap := stats.Snapshot{TotalRequests: 200_000, Successes: 200_000, LatencySampleCount: 50_000}
+	out := captureStdout(t, func() { NewRenderer().RenderFinal(snap) })
+	if !strings.Contains(out, "first 25% of requests") || !strings.Contains(out, "--stats-memory") {
+		t.Errorf("truncated sample not explained:\n%s", out)
+	}
+
+	snap.LatencySampleCount = snap.TotalRequests
+	if out := captureStdout(t, func() { NewRenderer().RenderFinal(snap) }); strings.Contains(out, "--stats-memory") {
+		t.Errorf("full sample flagged as truncated:\n%s", out)
+	}
+}
+
+func TestRenderFinal_LoadModelNote(t *testing.T) {
+	snap := stats.Snapshot{TotalRequests: 10, Successes: 10, LatencySampleCount: 10}
+
+	out := captureStdout(t, func() { NewRenderer().RenderFinal(snap) })
+	if !strings.Contains(out, "closed loop:") || strings.Contains(out, "open loop:") {
+		t.Errorf("closed-loop run not labelled as such:\n%s", out)
+	}
+	if !strings.Contains(out, "--rate or --adaptive-rate paces") {
+		t.Errorf("closed-loop note does not point to --rate:\n%s", out)
+	}
+
+	// A --rate run, as the orchestrator reports it.
+	snap.OpenLoop = true
+	out = captureStdout(t, func() { NewRenderer().RenderFinal(snap) })
+	if !strings.Contains(out, "open loop:") || strings.Contains(out, "closed loop:") {
+		t.Errorf("open-loop run not labelled as such:\n%s", out)
+	}
+	if !strings.Contains(out, "(--rate or --adaptive-rate)") {
+		t.Errorf("open-loop note does not name --rate:\n%s", out)
+	}
+
+	if out := captureStdout(t, func() { NewRenderer().RenderFinal(stats.Snapshot{}) }); strings.Contains(out, " loop:") {
+		t.Errorf("empty run labelled with a load model:\n%s", out)
+	}
+}
+
+func TestRenderFinal_StatusCodes(t *testing.T) {
+	snap := stats.Snapshot{
+		TotalRequests: 200, Successes: 150, Errors: 50, LatencySampleCount: 200,
+		StatusCounts: map[int]uint64{0: 10, 503: 40, 200: 120, 404: 30},
+	}
+	out := captureStdout(t, func() { NewRenderer().RenderFinal(snap) })
+	if !strings.Contains(out, "Status codes") {
+		t.Fatalf("no status code table:\n%s", out)
+	}
+	var order []int
+	for _, label := range []string{"200", "404", "503", "no response"} {
+		i := strings.Index(out, label+colorReset)
+		if i < 0 {
+			t.Fatalf("no %q row:\n%s", label, out)
+		}
+		order = append(order, i)
+	}
+	if !slices.IsSorted(order) {
+		t.Errorf("rows not in code order with no response last:\n%s", out)
+	}
+	if !strings.Contains(out, "60.0%") || !strings.Contains(out, "5.0%") {
+		t.Errorf("shares missing:\n%s", out)
+	}
+
+	if out := captureStdout(t, func() { NewRenderer().RenderFinal(stats.Snapshot{}) }); strings.Contains(out, "Status codes") {
+		t.Errorf("status code table without any status:\n%s", out)
+	}
+}
+
+func TestPrintComparison(t *testing.T) {
+	a := ComparedRun{Label: "HTTP/1.1", Snap: stats.Snapshot{TotalRequests: 100, ConnsOpened: 10, RequestsPerSAvg: 100, LatencyP50: time.Millisecond, LatencyP99: 4 * time.Millisecond}}
+	b := ComparedRun{Label: "HTTP/2", Snap: stats.Snapshot{TotalRequests: 150, ConnsOpened: 1, RequestsPerSAvg: 150, LatencyP99: 2 * time.Millisecond, Errors: 2}}
+
+	out := captureStdout(t, func() { PrintComparison(LatencyAuto, a, b) })
+	for _, want := range []string{"HTTP/2 vs HTTP/1.1", "+50.0%", "4.00 ms", "2.00 ms", "-50.0%", "90.0%", "99.3%", "+9.3 pt", "+2"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("comparison lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintComparisonTable(t *testing.T) {
+	runs := []ComparedRun{
+		{Label: "GET", Snap: stats.Snapshot{RequestsPerSAvg: 200, LatencyP50: time.Millisecond, LatencyP99: 3 * time.Millisecond}},
+		{Label: "POST", Snap: stats.Snapshot{RequestsPerSAvg: 150, LatencyP50: 2 * time.Millisecond, LatencyP99: 6 * time.Millisecond, Errors: 4}},
+		{Label: "PUT", Snap: stats.Snapshot{RequestsPerSAvg: 100, LatencyP50: 2 * time.Millisecond, LatencyP99: 8 * time.Millisecond}},
+	}
+
+	out := captureStdout(t, func() { PrintComparisonTable(LatencyAuto, runs) })
+	for _, want := range []string{"GET, POST, PUT", "vs GET", "200.0", "3.00 ms", "6.00 ms", "-25.0%", "-50.0%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("comparison table lacks %q:\n%s", want, out)
+		}
+	}
+	if rows := strings.Count(out, "\n│"); rows != 4 {
+		t.Errorf("table has %d rows, want a header and one per run:\n%s", rows, out)
+	}
+}
+
+func TestRenderFinal_FullWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "120")
+	snap := stats.Snapshot{TotalRequests: 10, Successes: 10, LatencySampleCount: 10}
+
+	border := func(out string) int {
+		for _, l := range strings.Split(out, "\n") {
+			if strings.HasPrefix(l, "┌─") && !strings.Contains(l, "┬") {
+				return utf8.RuneCountInString(l)
+			}
+		}
+		t.Fatalf("no summary box in:\n%s", out)
+		return 0
+	}
+	if got := border(captureStdout(t, func() { NewRenderer().RenderFinal(snap) })); got != maxBoxWidth {
+		t.Errorf("default summary box is %d columns, want %d", got, maxBoxWidth)
+	}
+	if got := border(captureStdout(t, func() { NewRenderer(WithFullWidth()).RenderFinal(snap) })); got != 120 {
+		t.Errorf("full-width summary box is %d columns, want 120", got)
+	}
+}
+
+func TestRenderSoak(t *testing.T) {
+	snap := stats.Snapshot{TotalRequests: 10, Successes: 10, LatencySampleCount: 10, Duration: 5 * time.Minute}
+	rt := RuntimeStats{Report: 2, HeapAlloc: 3_000_000, HeapDelta: -500_000, Goroutines: 40, GoroutineDelta: 3, NumGC: 7}
+
+	out := captureStdout(t, func() { NewRenderer().(SoakRenderer).RenderSoak(snap, rt) })
+	for _, want := range []string{"Soak report 2", "at 5m0s", "goroutines=40 (+3)", "gc=7", "-500", "Latency"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("soak report lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestVisibleLen(t *testing.T) {
+	tests := map[string]int{
+		"":                                 0,
+		"plain":                            5,
+		"https://例え.jp/パス":                 16,
+		colorRed + "µs" + colorReset:       2,
+		"──" + colorDim + "a" + colorReset: 3,
+		"\033[38;5;208mx":                  1,
+	}
+	for s, want := range tests {
+		if got := visibleLen(s); got != want {
+			t.Errorf("visibleLen(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestTruncateToWidth(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"https://example.com/long", 10, "https:/..."},
+		{"https://例え.jp/パス/長い", 12, "https://例..."},
+		{"ünïcödé", 3, "ünï"},
+		{"ab", 0, "ab"},
+		// Escapes take no width and are never split; the cut resets color.
+		{colorRed + "error: 接続が拒否されました" + colorReset, 10, colorRed + "error: " + colorReset + "..."},
+		{"ok " + colorGreen + "done" + colorReset, 7, "ok " + colorGreen + "done" + colorReset},
+		{"ok " + colorGreen + "finished" + colorReset, 7, "ok " + colorGreen + "f" + colorReset + "..."},
+	}
+	for _, tt := range tests {
+		got := truncateToWidth(tt.s, tt.width)
+		if got != tt.want {
+			t.Errorf("truncateToWidth(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateToWidth(%q, %d) = %q, not valid UTF-8", tt.s, tt.width, got)
+		}
+		if tt.width > 0 && visibleLen(got) > tt.width {
+			t.Errorf("truncateToWidth(%q, %d) is %d wide", tt.s, tt.width, visibleLen(got))
+		}
+	}
+}
+
+func TestPrintTrend(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	rows := []TrendRow{
+		{StartedAt: start, RPS: 1000, P99: 20 * time.Millisecond},
+		{StartedAt: start.Add(time.Hour), RPS: 700, P99: 21 * time.Millisecond, RPSChange: -0.3, P99Change: 0.05, Regressed: true},
+		{StartedAt: start.Add(2 * time.Hour), RPS: 1000, P99: 19 * time.Millisecond, RPSChange: 0.4286, P99Change: -0.0952},
+	}
+	out := captureStdout(t, func() { PrintTrend(LatencyAuto, "checkout", 0.1, rows) })
+	for _, want := range []string{"checkout, 3 runs, regression past 10%", "2026-03-01 10:00", "-30.0%", "21.00 ms", "regressed", "1 of 3 runs regressed", "█▁█"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trend lacks %q:\n%s", want, out)
+		}
+	}
+	if rows := strings.Count(out, "\n│"); rows != 4 {
+		t.Errorf("table has %d rows, want a header and one per run:\n%s", rows, out)
+	}
+}
@@ -1,27 +1,60 @@
 package ui
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 // PrintStepResult prints a preflight step result (e.g. DNS: OK) before the run header.
-func PrintStepResult(name, value string, ok bool) {
+func PrintStepResult(w io.Writer, name, value string, ok bool) {
 	if ok {
-		fmt.Printf("  %s%s%s : %s%s%s\n", colorDim, name, colorReset, colorGreen, value, colorReset)
+		fmt.Fprintf(w, "  %s%s%s : %s%s%s\n", colorDim, name, colorReset, colorGreen, value, colorReset)
 	} else {
-		fmt.Printf("  %s%s%s : %s%s%s\n", colorDim, name, colorReset, colorYellow, value, colorReset)
+		fmt.Fprintf(w, "  %s%s%s : %s%s%s\n", colorDim, name, colorReset, colorYellow, value, colorReset)
 	}
 }
 
+// PrintStepFailure prints a preflight step that found a problem the run will
+// likely hit (red), where PrintStepResult's warning is only worth a look.
+func PrintStepFailure(w io.Writer, name, value string) {
+	fmt.Fprintf(w, "  %s%s%s : %s%s%s\n", colorDim, name, colorReset, colorRed, value, colorReset)
+}
+
 // PrintRunHeader renders a colorful header for a single benchmark run.
-func PrintRunHeader(url string, workers, connections, pipeline int, duration string) {
-	fmt.Println()
-	fmt.Printf("%s%sStarting HTTPCL benchmark%s\n", colorBold, colorCyan, colorReset)
-	fmt.Printf(" Target   : %s\n", url)
-	fmt.Printf(" %s[workers:%s %s%d%s]  %s[connections:%s %s%d%s]  %s[pipeline:%s %s%d%s]  %s[duration:%s %s%s%s]\n",
+// An empty runID is left out.
+func PrintRunHeader(w io.Writer, url, runID string, workers, connections, pipeline int, duration string) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s%sStarting HTTPCL benchmark%s\n", colorBold, colorCyan, colorReset)
+	fmt.Fprintf(w, " Target   : %s\n", url)
+	if runID != "" {
+		fmt.Fprintf(w, " Run ID   : %s\n", runID)
+	}
+	fmt.Fprintf(w, " %s[workers:%s %s%d%s]  %s[connections:%s %s%d%s]  %s[pipeline:%s %s%d%s]  %s[duration:%s %s%s%s]\n",
 		colorDim, colorReset, colorCyan, workers, colorReset,
 		colorDim, colorReset, colorCyan, connections, colorReset,
 		colorDim, colorReset, colorCyan, pipeline, colorReset,
 		colorDim, colorReset, colorCyan, duration, colorReset,
 	)
-	fmt.Println()
+	fmt.Fprintln(w)
+}
+
+// ConfigItem is one label/value line of the --verbose configuration dump.
+type ConfigItem struct {
+	Label string
+	Value string
 }
 
+// PrintResolvedConfig prints the effective configuration below the run header.
+func PrintResolvedConfig(w io.Writer, items []ConfigItem) {
+	width := 0
+	for _, it := range items {
+		if len(it.Label) > width {
+			width = len(it.Label)
+		}
+	}
+	fmt.Fprintf(w, "%sEffective configuration%s\n", colorBold, colorReset)
+	for _, it := range items {
+		fmt.Fprintf(w, "  %s%-*s%s : %s\n", colorDim, width, it.Label, colorReset, it.Value)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// TrendRow is one stored run of a trend, with its change from the run
+// before (see export.Trend).
+type TrendRow struct {
+	StartedAt time.Time
+	RPS       float64
+	P99       time.Duration
+	RPSChange float64
+	P99Change float64
+	Regressed bool
+}
+
+// sparkLevels are the bar heights of a sparkline, lowest first.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws vals as one bar each, scaled between their minimum and
+// maximum.
+func sparkline(vals []float64) string {
+	if len(vals) == 0 {
+		return ""
+	}
+	lo, hi := vals[0], vals[0]
+	for _, v := range vals {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range vals {
+		i := len(sparkLevels) / 2
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(sparkLevels)-1))
+		}
+		b.WriteRune(sparkLevels[i])
+	}
+	return b.String()
+}
+
+// PrintTrend prints the runs of a stored benchmark, oldest first, with the
+// change of each from the one before and the regressions past threshold,
+// then sparklines of req/sec and p99. Latencies use the unit resolved for
+// the first run's p99.
+func PrintTrend(unit LatencyUnit, name string, threshold float64, rows []TrendRow) {
+	if len(rows) == 0 {
+		return
+	}
+	lat := unit.resolve(rows[0].P99).format
+	change := func(i int, c float64) string {
+		if i == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%+.1f%%", c*100)
+	}
+
+	cw := []int{20, 12, 10, 12, 10, 13}
+	line := func(l, m, r string) {
+		parts := make([]string, len(cw))
+		for i, w := range cw {
+			parts[i] = strings.Repeat("─", w)
+		}
+		fmt.Fprintf(os.Stdout, "%s%s%s\n", l, strings.Join(parts, m), r)
+	}
+	row := func(cells ...string) {
+		for i, c := range cells {
+			c = "  " + c
+			if pad := cw[i] - visibleLen(c); pad > 0 {
+				c += strings.Repeat(" ", pad)
+			}
+			fmt.Fprintf(os.Stdout, "│%s", c)
+		}
+		fmt.Fprintln(os.Stdout, "│")
+	}
+
+	fmt.Fprintln(os.Stdout)
+	fmt.Fprintf(os.Stdout, "%sTrend%s %s(%s, %d runs, regression past %.0f%%)%s\n",
+		colorBold, colorReset, colorDim, name, len(rows), threshold*100, colorReset)
+	line("┌", "┬", "┐")
+	row(colorCyan+"Run"+colorReset, colorCyan+"Req/sec"+colorReset, colorCyan+"Change"+colorReset,
+		colorCyan+"p99"+colorReset, colorCyan+"Change"+colorReset, "")
+	line("├", "┼", "┤")
+	regressions := 0
+	rps := make([]float64, len(rows))
+	p99 := make([]float64, len(rows))
+	for i, r := range rows {
+		flag := ""
+		if r.Regressed {
+			flag = colorRed + "regressed" + colorReset
+			regressions++
+		}
+		row(r.StartedAt.Local().Format("2006-01-02 15:04"), fmt.Sprintf("%.1f", r.RPS), change(i, r.RPSChange),
+			lat(r.P99), change(i, r.P99Change), flag)
+		rps[i], p99[i] = r.RPS, float64(r.P99)
+	}
+	line("└", "┴", "┘")
+	fmt.Fprintf(os.Stdout, "  %-8s %s\n", "Req/sec", sparkline(rps))
+	fmt.Fprintf(os.Stdout, "  %-8s %s\n", "p99", sparkline(p99))
+
+	switch last := rows[len(rows)-1]; {
+	case last.Regressed:
+		fmt.Fprintf(os.Stdout, "%sLatest run regressed%s: req/sec %s, p99 %s vs the run before\n",
+			colorRed, colorReset, change(len(rows)-1, last.RPSChange), change(len(rows)-1, last.P99Change))
+	case regressions > 0:
+		fmt.Fprintf(os.Stdout, "%d of %d runs regressed; the latest did not\n", regressions, len(rows))
+	default:
+		fmt.Fprintf(os.Stdout, "%sNo regressions%s\n", colorGreen, colorReset)
+	}
+}
@@ -0,0 +1,171 @@
+// Package benchmark runs httpcl load tests from other Go programs.
+//
+// It is the supported library surface of httpcl: Config, Run and Result
+// (with Latency) are kept compatible, while everything under internal/ may
+// change between releases. Fields are only added, never renamed or removed.
+// The httpcl CLI does not go through this package and offers many more
+// options; ask for one here before depending on internal packages.
+//
+//	res, err := benchmark.Run(ctx, benchmark.Config{
+//		URL:         "http://localhost:8080/health",
+//		Connections: 50,
+//		Duration:    30 * time.Second,
+//	})
+//
+// Run prints the same preflight checks and start banner to standard output
+// as the CLI, but no live display or report. It installs no signal
+// handlers: cancel ctx to stop a run early.
+package benchmark
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// Config describes a benchmark. Zero fields take the CLI's defaults.
+type Config struct {
+	Method string      // default GET
+	URL    string      // required
+	Header http.Header // sent with every request
+	Body   []byte      // sent with every request, e.g. for POST
+
+	// Connections is the number of connections to open (default Workers×10).
+	// Workers goroutines (default the number of CPUs) each keep Pipeline
+	// requests (default 1) in flight.
+	Connections int
+	Workers     int
+	Pipeline    int
+	// Duration is how long requests are started for (default 10s); requests
+	// in flight at the end are waited for.
+	Duration time.Duration
+	// Rate, if positive, paces request starts at this many per second across
+	// all slots instead of sending each slot's next request as soon as the
+	// previous response arrived.
+	Rate float64
+	// HTTPVersion pins the protocol to "1.1" or "2"; empty negotiates.
+	HTTPVersion string
+	// Insecure skips TLS certificate verification.
+	Insecure bool
+	// Seed drives every random choice; 0 picks one, reported in Result.Seed.
+	Seed uint64
+	// RequestContext, if set, derives each request's context from base,
+	// e.g. to carry an OpenTelemetry span, baggage or an
+	// httptrace.ClientTrace with every request. base is cancelled when ctx is;
+	// return a context derived from it. It is called concurrently from all
+	// connections.
+	RequestContext func(base context.Context) context.Context
+}
+
+// Result is the outcome of a run, mirroring the CLI's final report.
+type Result struct {
+	Requests  uint64 // completed requests: successes, errors and rate limited
+	Successes uint64 // responses with a status below 500, except 429
+	Errors    uint64
+	// RateLimited counts 429 Too Many Requests responses, which are neither
+	// successes nor errors.
+	RateLimited uint64
+	// StatusCounts counts responses by HTTP status code; 0 counts failed
+	// requests that got no response.
+	StatusCounts map[int]uint64
+	// ErrorsByCategory breaks Errors down by cause: "dns", "connect",
+	// "tls", "timeout", "read", "http_5xx" and so on.
+	ErrorsByCategory map[string]uint64
+	BytesSent        uint64
+	BytesReceived    uint64
+	// ConnsOpened is how many connections were dialed, including redials.
+	ConnsOpened uint64
+
+	Duration       time.Duration // from the start of the load to its drained end
+	RequestsPerSec float64
+	BytesPerSec    float64
+	Latency        Latency
+
+	// StopReason says why the run ended before its duration, or is empty.
+	StopReason string
+	// Seed is the seed the run used; pass it in Config to repeat the run.
+	Seed uint64
+}
+
+// Latency summarizes request latencies over the run.
+type Latency struct {
+	P2_5   time.Duration
+	P50    time.Duration
+	P97_5  time.Duration
+	P99    time.Duration
+	Mean   time.Duration
+	Stdev  time.Duration
+	Max    time.Duration
+	Sample uint64 // latencies the percentiles were computed from
+}
+
+// Run benchmarks cfg.URL until cfg.Duration is over or ctx is done, and
+// returns what was measured. Cancelling ctx is not an error: the result
+// covers the requests made until then. An error means the run could not
+// start, e.g. for an invalid config or a host that does not resolve.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	o := engine.NewOrchestrator(engine.Config{
+		Method:         cfg.Method,
+		URL:            cfg.URL,
+		Headers:        cfg.Header,
+		Body:           cfg.Body,
+		Connections:    cfg.Connections,
+		Workers:        cfg.Workers,
+		Pipeline:       cfg.Pipeline,
+		Duration:       cfg.Duration,
+		Rate:           cfg.Rate,
+		HTTPVersion:    cfg.HTTPVersion,
+		Insecure:       cfg.Insecure,
+		Seed:           cfg.Seed,
+		RequestContext: cfg.RequestContext,
+		StopSignals:    []os.Signal{},
+		StatusSignals:  []os.Signal{},
+	}, quietRenderer{})
+	if err := o.RunContext(ctx); err != nil {
+		return Result{}, err
+	}
+	return newResult(o.FinalSnapshot(), o.StopReason(), o.Config().Seed), nil
+}
+
+func newResult(s stats.Snapshot, stopReason string, seed uint64) Result {
+	r := Result{
+		Requests:         s.TotalRequests,
+		Successes:        s.Successes,
+		Errors:           s.Errors,
+		RateLimited:      s.RateLimited,
+		StatusCounts:     s.StatusCounts,
+		ErrorsByCategory: make(map[string]uint64, len(s.ErrorsByCategory)),
+		BytesSent:        s.TotalBytesSent,
+		BytesReceived:    s.TotalBytesRecv,
+		ConnsOpened:      s.ConnsOpened,
+		Duration:         s.Duration,
+		RequestsPerSec:   s.RequestsPerSAvg,
+		BytesPerSec:      s.BytesPerSAvg,
+		Latency: Latency{
+			P2_5:   s.LatencyP25,
+			P50:    s.LatencyP50,
+			P97_5:  s.LatencyP975,
+			P99:    s.LatencyP99,
+			Mean:   s.LatencyAvg,
+			Stdev:  s.LatencyStdev,
+			Max:    s.LatencyMax,
+			Sample: s.LatencySampleCount,
+		},
+		StopReason: stopReason,
+		Seed:       seed,
+	}
+	for c, n := range s.ErrorsByCategory {
+		r.ErrorsByCategory[string(c)] = n
+	}
+	return r
+}
+
+// quietRenderer drops the live display and report; the caller has Result.
+type quietRenderer struct{}
+
+func (quietRenderer) Render(stats.Snapshot)      {}
+func (quietRenderer) RenderFinal(stats.Snapshot) {}
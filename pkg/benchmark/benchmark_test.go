@@ -0,0 +1,103 @@
+package benchmark_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/pkg/benchmark"
+)
+
+func TestRun_AgainstTestServer(t *testing.T) {
+	var hits atomic.Uint64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if b, _ := io.ReadAll(r.Body); string(b) != "ping" || r.Header.Get("X-Test") != "1" {
+			http.Error(w, "bad request", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("pong"))
+	}))
+	defer srv.Close()
+
+	res, err := benchmark.Run(context.Background(), benchmark.Config{
+		Method:      "POST",
+		URL:         srv.URL + "/",
+		Header:      http.Header{"X-Test": {"1"}},
+		Body:        []byte("ping"),
+		Connections: 2,
+		Workers:     1,
+		Pipeline:    2,
+		Duration:    200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Requests == 0 || res.Requests != hits.Load() {
+		t.Fatalf("result has %d requests, the server saw %d", res.Requests, hits.Load())
+	}
+	if res.Errors != 0 || res.Successes != res.Requests {
+		t.Errorf("%d successes and %d errors (%v) of %d requests, want all successes", res.Successes, res.Errors, res.ErrorsByCategory, res.Requests)
+	}
+	if res.BytesReceived < 4*res.Requests || res.RequestsPerSec <= 0 {
+		t.Errorf("received %d bytes at %.1f req/s", res.BytesReceived, res.RequestsPerSec)
+	}
+	if res.Latency.P50 <= 0 || res.Latency.Max < res.Latency.P99 || res.Seed == 0 {
+		t.Errorf("latency %+v, seed %d", res.Latency, res.Seed)
+	}
+}
+
+func TestRun_CancelReturnsPartialResult(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	res, err := benchmark.Run(ctx, benchmark.Config{URL: srv.URL + "/", Connections: 1, Workers: 1, Duration: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if took := time.Since(start); took > 2*time.Second {
+		t.Errorf("run took %s after the context ended at 150ms", took)
+	}
+	if res.Requests == 0 {
+		t.Error("no requests in the result of the cancelled run")
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	if _, err := benchmark.Run(context.Background(), benchmark.Config{}); err == nil {
+		t.Error("a config without a URL should fail")
+	}
+}
+
+func TestRun_RequestContextReachesTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	// A client trace stands in for an instrumented transport reading the
+	// request's context.
+	var wrote atomic.Uint64
+	trace := &httptrace.ClientTrace{WroteRequest: func(httptrace.WroteRequestInfo) { wrote.Add(1) }}
+	res, err := benchmark.Run(context.Background(), benchmark.Config{
+		URL:      srv.URL + "/",
+		Workers:  1,
+		Pipeline: 2,
+		Duration: 100 * time.Millisecond,
+		RequestContext: func(base context.Context) context.Context {
+			return httptrace.WithClientTrace(base, trace)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Requests == 0 || wrote.Load() != res.Requests {
+		t.Errorf("trace saw %d requests written, result has %d", wrote.Load(), res.Requests)
+	}
+}
@@ -1,28 +1,53 @@
 package netutil
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
 	"syscall"
 )
 
-// PreflightDNS validates that the URL is well-formed and its host resolves.
-func PreflightDNS(rawURL string) error {
+// HostResolver is the part of *net.Resolver used by the DNS preflight. Tests
+// can pass a stub; callers can pass a custom resolver (e.g. one dialing a
+// specific DNS server).
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// ErrDNSResolution is wrapped by preflight errors caused by a failed lookup, as
+// opposed to a malformed URL.
+var ErrDNSResolution = errors.New("dns resolution failed")
+
+// PreflightDNS validates that the URL is well-formed and its host resolves
+// using the default resolver. It returns the addresses the host resolved to.
+func PreflightDNS(rawURL string) ([]string, error) {
+	return PreflightDNSWithResolver(rawURL, nil)
+}
+
+// PreflightDNSWithResolver is PreflightDNS with an explicit resolver; a nil
+// resolver means net.DefaultResolver.
+func PreflightDNSWithResolver(rawURL string, resolver HostResolver) ([]string, error) {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
-		return fmt.Errorf("invalid url: %w", err)
+		return nil, fmt.Errorf("invalid url: %w", err)
 	}
 
 	host := parsed.Hostname()
 	if host == "" {
-		return fmt.Errorf("missing host in url")
+		return nil, fmt.Errorf("missing host in url")
 	}
 
-	if _, err := net.LookupHost(host); err != nil {
-		return fmt.Errorf("dns resolution failed for host %q: %w", host, err)
+	addrs, err := resolver.LookupHost(context.Background(), host)
+	if err != nil {
+		return nil, fmt.Errorf("%w for host %q: %w", ErrDNSResolution, host, err)
 	}
-	return nil
+	return addrs, nil
 }
 
 // CheckUlimitWarning inspects the soft RLIMIT_NOFILE and returns a warning
@@ -45,5 +70,3 @@ func CheckUlimitWarning(requestedConns int) error {
 
 	return nil
 }
-
-
@@ -1,12 +1,16 @@
 package netutil
 
 import (
+	"context"
+	"errors"
+	"net"
+	"slices"
 	"strings"
 	"testing"
 )
 
 func TestPreflightDNS_InvalidURL(t *testing.T) {
-	err := PreflightDNS("://no-scheme")
+	_, err := PreflightDNS("://no-scheme")
 	if err == nil {
 		t.Fatal("expected error for invalid URL")
 	}
@@ -16,7 +20,7 @@ func TestPreflightDNS_InvalidURL(t *testing.T) {
 }
 
 func TestPreflightDNS_MissingHost(t *testing.T) {
-	err := PreflightDNS("http://")
+	_, err := PreflightDNS("http://")
 	if err == nil {
 		t.Fatal("expected error for missing host")
 	}
@@ -28,16 +32,18 @@ func TestPreflightDNS_MissingHost(t *testing.T) {
 func TestPreflightDNS_ValidResolvableHost(t *testing.T) {
 	// 127.0.0.1 and localhost typically resolve
 	for _, url := range []string{"http://127.0.0.1/", "http://localhost/"} {
-		err := PreflightDNS(url)
+		addrs, err := PreflightDNS(url)
 		if err != nil {
 			t.Errorf("PreflightDNS(%q): %v", url, err)
+		} else if len(addrs) == 0 {
+			t.Errorf("PreflightDNS(%q) returned no addresses", url)
 		}
 	}
 }
 
 func TestPreflightDNS_UnresolvableHost(t *testing.T) {
 	// Use a TLD that is reserved for "no such host" by RFC 6761
-	err := PreflightDNS("http://nonexistent.invalid/")
+	_, err := PreflightDNS("http://nonexistent.invalid/")
 	if err == nil {
 		t.Skip("in some environments .invalid may resolve; skipping")
 	}
@@ -76,3 +82,57 @@ func TestCheckUlimitWarning_SmallRequest(t *testing.T) {
 	_ = CheckUlimitWarning(10)
 	// On normal systems this does not error; if limit is very low we get an error (acceptable).
 }
+
+// stubResolver answers LookupHost from a fixed table.
+type stubResolver struct {
+	hosts map[string][]string
+	calls []string
+}
+
+func (s *stubResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	s.calls = append(s.calls, host)
+	if addrs, ok := s.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestPreflightDNSWithResolver_Success(t *testing.T) {
+	r := &stubResolver{hosts: map[string][]string{"api.example.test": {"192.0.2.10"}}}
+	r.hosts["api.example.test"] = append(r.hosts["api.example.test"], "2001:db8::10")
+	addrs, err := PreflightDNSWithResolver("https://api.example.test:8443/v1", r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"192.0.2.10", "2001:db8::10"}; !slices.Equal(addrs, want) {
+		t.Errorf("addresses = %v, want %v", addrs, want)
+	}
+	if len(r.calls) != 1 || r.calls[0] != "api.example.test" {
+		t.Errorf("resolver should be asked for the bare hostname, got %v", r.calls)
+	}
+}
+
+func TestPreflightDNSWithResolver_Failure(t *testing.T) {
+	r := &stubResolver{}
+	_, err := PreflightDNSWithResolver("http://missing.example.test/", r)
+	if err == nil {
+		t.Fatal("expected error from stub resolver")
+	}
+	if !strings.Contains(err.Error(), "dns resolution failed") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("resolver error should be wrapped: %v", err)
+	}
+}
+
+func TestPreflightDNSWithResolver_InvalidURLSkipsLookup(t *testing.T) {
+	r := &stubResolver{}
+	if _, err := PreflightDNSWithResolver("http://", r); err == nil {
+		t.Fatal("expected error for missing host")
+	}
+	if len(r.calls) != 0 {
+		t.Errorf("resolver should not be called for an invalid URL, got %v", r.calls)
+	}
+}
@@ -0,0 +1,51 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_AdaptiveRateConverges uses a handler whose latency grows with the
+// number of concurrent requests (2ms plus 2ms per request in flight), so p99
+// only stays low below some rate. The adaptive scheduler must settle on a
+// sustained rate well above its start rate that still meets the target.
+func TestRun_AdaptiveRateConverges(t *testing.T) {
+	var inFlight atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		time.Sleep(2*time.Millisecond + time.Duration(n)*2*time.Millisecond)
+	}))
+	defer srv.Close()
+
+	target := 25 * time.Millisecond
+	cfg := engine.Config{
+		Method:           "GET",
+		URL:              srv.URL + "/",
+		Duration:         3 * time.Second,
+		Workers:          2,
+		Pipeline:         32,
+		AdaptiveRate:     true,
+		TargetP99:        target,
+		AdaptiveInterval: 200 * time.Millisecond,
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	rate := orch.SustainedRate()
+	t.Logf("sustained %.1f req/s, p99 %v, total %d", rate, orch.FinalSnapshot().LatencyP99, orch.FinalSnapshot().TotalRequests)
+	if rate < 50 {
+		t.Errorf("rate should have climbed well above the 10 req/s start: got %.1f", rate)
+	}
+	// Beyond ~1/2ms = 500 req/s the handler queues without bound.
+	if rate > 500 {
+		t.Errorf("rate should stay below the handler's saturation point: got %.1f", rate)
+	}
+}
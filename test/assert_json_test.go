@@ -0,0 +1,65 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_AssertJSON checks that responses whose JSON fails --assert-json
+// count as validation errors, with the failing body among the samples.
+func TestRun_AssertJSON(t *testing.T) {
+	var n atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := "ok"
+		if n.Add(1)%3 == 0 {
+			status = "degraded"
+		}
+		fmt.Fprintf(w, `{"data":{"items":[1,2]},"status":%q}`, status)
+	}))
+	defer srv.Close()
+
+	var asserts []engine.JSONAssert
+	for _, s := range []string{"$.data.items[1]==2", "$.status==ok"} {
+		a, err := engine.ParseJSONAssert(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		asserts = append(asserts, a)
+	}
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		AssertJSON:  asserts,
+		Connections: 1,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if snap.Successes == 0 || snap.Errors == 0 {
+		t.Fatalf("successes = %d, errors = %d; want both", snap.Successes, snap.Errors)
+	}
+	if got := snap.ErrorsByCategory[stats.ErrValidation]; got != snap.Errors {
+		t.Errorf("validation errors = %d of %d errors", got, snap.Errors)
+	}
+	if want := snap.TotalRequests / 3; snap.Errors < want-1 || snap.Errors > want+1 {
+		t.Errorf("errors = %d of %d requests, want every third", snap.Errors, snap.TotalRequests)
+	}
+	samples := snap.ErrorSamples[stats.ErrValidation]
+	if len(samples) == 0 || !strings.Contains(samples[0], `got "degraded"`) || !strings.Contains(samples[0], `\"status\":\"degraded\"`) {
+		t.Errorf("samples = %q, want the failing body", samples)
+	}
+}
@@ -0,0 +1,58 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_BackoffOnErrors runs 20 slots against a server that drops the
+// connection of every request beyond 5 in flight, and checks the run backs
+// off to what the server accepts instead of piling on.
+func TestRun_BackoffOnErrors(t *testing.T) {
+	const capacity = 5
+	var inFlight, dropped, served atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer inFlight.Add(-1)
+		over := inFlight.Add(1) > capacity
+		time.Sleep(5 * time.Millisecond)
+		if over {
+			dropped.Add(1)
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		served.Add(1)
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:          "GET",
+		URL:             srv.URL + "/",
+		Connections:     20,
+		Duration:        2 * time.Second,
+		Workers:         1,
+		Pipeline:        20,
+		BackoffOnErrors: true,
+		BackoffInterval: 100 * time.Millisecond,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if snap.Slots != 20 || snap.LowestSlots > capacity {
+		t.Fatalf("active slots went down to %d of %d, want at most %d", snap.LowestSlots, snap.Slots, capacity)
+	}
+	// Without the back-off three in four requests would be dropped.
+	if d, s := dropped.Load(), served.Load(); d > s {
+		t.Errorf("server dropped %d requests and served %d, want far fewer dropped", d, s)
+	}
+}
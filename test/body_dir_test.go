@@ -0,0 +1,118 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_BodyCorpusSendsVariedBodies checks that requests draw their bodies
+// from the corpus, that several different entries are used, and that each
+// request's Content-Length matches the body it carries.
+func TestRun_BodyCorpusSendsVariedBodies(t *testing.T) {
+	corpus := [][]byte{[]byte(`{"id":1}`), []byte(`{"id":22}`), []byte(`{"id":333}`)}
+	allowed := map[string]bool{}
+	for _, b := range corpus {
+		allowed[string(b)] = true
+	}
+
+	var mu sync.Mutex
+	seen := map[string]int{}
+	var bad []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if !allowed[string(body)] || r.Header.Get("Content-Length") != strconv.Itoa(len(body)) {
+			bad = append(bad, r.Header.Get("Content-Length")+" "+string(body))
+		}
+		seen[string(body)]++
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "POST",
+		URL:         srv.URL + "/",
+		BodyCorpus:  corpus,
+		Connections: 2,
+		Duration:    150 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    2,
+		Seed:        7,
+	}
+	if err := engine.NewOrchestrator(cfg, NewNoopRenderer()).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bad) > 0 {
+		t.Errorf("unexpected bodies or Content-Length: %q", bad)
+	}
+	if len(seen) < 2 {
+		t.Errorf("expected several different bodies, got %v", seen)
+	}
+}
+
+// TestRun_BodyCycleSendsInOrder checks that BodyCycle sends the corpus in
+// order, wrapping around, and reports an even split.
+func TestRun_BodyCycleSendsInOrder(t *testing.T) {
+	corpus := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+
+	var mu sync.Mutex
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got = append(got, string(body))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "POST",
+		URL:         srv.URL + "/",
+		BodyCorpus:  corpus,
+		BodyCycle:   true,
+		Connections: 1,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) < 2*len(corpus) {
+		t.Fatalf("only %d requests arrived", len(got))
+	}
+	for i, body := range got {
+		if want := string(corpus[i%len(corpus)]); body != want {
+			t.Fatalf("request %d sent %q, want %q (sequence %q)", i, body, want, got)
+		}
+	}
+
+	sends := o.FinalSnapshot().BodySends
+	if len(sends) != len(corpus) {
+		t.Fatalf("BodySends = %v, want one count per body", sends)
+	}
+	var total uint64
+	for _, n := range sends {
+		total += n
+		if n < sends[0]-1 || n > sends[0] {
+			t.Errorf("BodySends = %v, want an even split", sends)
+		}
+	}
+	if total < uint64(len(got)) || total > uint64(len(got))+1 {
+		t.Errorf("BodySends total %d, server saw %d requests", total, len(got))
+	}
+}
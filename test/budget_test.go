@@ -0,0 +1,48 @@
+package test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_MaxBytesStopsEarly verifies the run ends on the byte budget long
+// before its configured duration.
+func TestRun_MaxBytesStopsEarly(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 64*1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(payload)
+	}))
+	defer srv.Close()
+
+	const budget = 1 << 20
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 2,
+		Duration:    10 * time.Second,
+		Workers:     1,
+		Pipeline:    2,
+		MaxBytes:    budget,
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	start := time.Now()
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("run should stop on the byte budget, took %v", elapsed)
+	}
+	snap := orch.FinalSnapshot()
+	if got := snap.TotalBytesSent + snap.TotalBytesRecv; got < budget {
+		t.Errorf("stopped before the budget was used: %d bytes", got)
+	}
+	if !strings.Contains(orch.StopReason(), "byte budget") {
+		t.Errorf("StopReason: got %q", orch.StopReason())
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_BurstReleasesSpikes checks that --burst produces discrete spikes of
+// exactly Burst concurrent requests separated by idle gaps, rather than a
+// steady stream.
+func TestRun_BurstReleasesSpikes(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		arrivals []time.Time
+		inFlight int
+		peak     int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(30 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	const burst = 4
+	interval := 300 * time.Millisecond
+	cfg := engine.Config{
+		Method:        "GET",
+		URL:           srv.URL + "/",
+		Duration:      1100 * time.Millisecond,
+		Workers:       1,
+		Burst:         burst,
+		BurstInterval: interval,
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if got := orch.Config().Pipeline; got < burst {
+		t.Fatalf("pipeline should be raised to fit the burst: got %d", got)
+	}
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Group arrivals separated by more than half an interval.
+	var groups []int
+	for i, at := range arrivals {
+		if i == 0 || at.Sub(arrivals[i-1]) > interval/2 {
+			groups = append(groups, 0)
+		}
+		groups[len(groups)-1]++
+	}
+	if len(groups) < 3 {
+		t.Fatalf("expected at least 3 bursts, got %d (%v)", len(groups), groups)
+	}
+	for i, n := range groups {
+		if n != burst {
+			t.Errorf("burst %d: got %d requests, want %d (all: %v)", i, n, burst, groups)
+		}
+	}
+	if peak != burst {
+		t.Errorf("peak server concurrency: got %d, want %d", peak, burst)
+	}
+	if snap := orch.FinalSnapshot(); snap.TotalRequests != uint64(len(arrivals)) {
+		t.Errorf("TotalRequests %d != server arrivals %d", snap.TotalRequests, len(arrivals))
+	}
+}
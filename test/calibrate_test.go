@@ -0,0 +1,68 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_CalibratedRate checks that --rate auto:F paces the run at F times
+// the throughput the calibration phase measured, and that calibration
+// requests stay out of the run's stats.
+func TestRun_CalibratedRate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:       "GET",
+		URL:          srv.URL + "/",
+		Connections:  4,
+		Duration:     600 * time.Millisecond,
+		Workers:      1,
+		Pipeline:     4,
+		Calibrate:    300 * time.Millisecond,
+		RateFraction: 0.25,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	maxRPS, rate := o.Calibration()
+	if maxRPS <= 0 {
+		t.Fatalf("calibration measured %.1f req/s", maxRPS)
+	}
+	if rate != maxRPS*0.25 {
+		t.Errorf("rate %.1f is not 25%% of the calibrated max %.1f", rate, maxRPS)
+	}
+
+	snap := o.FinalSnapshot()
+	if !snap.OpenLoop {
+		t.Error("a paced run should be reported as open loop")
+	}
+	var load time.Duration
+	var calibrated bool
+	for _, p := range snap.Phases {
+		switch p.Name {
+		case "load":
+			load = p.Duration
+		case "calibrate":
+			calibrated = p.Duration >= cfg.Calibrate
+		}
+	}
+	if !calibrated {
+		t.Errorf("no calibrate phase of at least %s in %v", cfg.Calibrate, snap.Phases)
+	}
+	// The run sent at most the paced rate, give or take the first request.
+	if got := float64(snap.TotalRequests) / load.Seconds(); got > rate*1.2+1/load.Seconds() {
+		t.Errorf("run achieved %.1f req/s, above the calibrated rate %.1f", got, rate)
+	}
+	if maxCount := uint64(maxRPS * cfg.Calibrate.Seconds()); snap.TotalRequests >= maxCount {
+		t.Errorf("run recorded %d requests, as many as calibration sent (%d)", snap.TotalRequests, maxCount)
+	}
+}
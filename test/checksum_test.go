@@ -0,0 +1,64 @@
+package test
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+func runWithExpectedHash(t *testing.T, url string, sum []byte) stats.Snapshot {
+	t.Helper()
+	cfg := engine.Config{
+		Method:       "GET",
+		URL:          url,
+		Connections:  2,
+		Duration:     150 * time.Millisecond,
+		Workers:      1,
+		Pipeline:     2,
+		ExpectSHA256: sum,
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+	snap := orch.FinalSnapshot()
+	if snap.TotalRequests == 0 {
+		t.Fatal("expected some requests")
+	}
+	return snap
+}
+
+// TestRun_ExpectSHA256 checks that matching bodies succeed and a wrong digest
+// turns every response into a validation error.
+func TestRun_ExpectSHA256(t *testing.T) {
+	content := strings.Repeat("integrity ", 1000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	good := sha256.Sum256([]byte(content))
+	snap := runWithExpectedHash(t, srv.URL+"/", good[:])
+	if snap.Errors != 0 || snap.Successes != snap.TotalRequests {
+		t.Errorf("matching digest: %d errors out of %d (%v)", snap.Errors, snap.TotalRequests, snap.ErrorSamples)
+	}
+
+	bad := sha256.Sum256([]byte("something else"))
+	snap = runWithExpectedHash(t, srv.URL+"/", bad[:])
+	if snap.Successes != 0 || snap.ErrorsByCategory[stats.ErrValidation] != snap.TotalRequests {
+		t.Errorf("wrong digest: successes=%d validation=%d total=%d",
+			snap.Successes, snap.ErrorsByCategory[stats.ErrValidation], snap.TotalRequests)
+	}
+	if s := snap.ErrorSamples[stats.ErrValidation]; len(s) != 1 || !strings.Contains(s[0], "SHA-256 mismatch") {
+		t.Errorf("validation samples: %v", s)
+	}
+	if snap.TotalBytesRecv != snap.TotalRequests*uint64(len(content)) {
+		t.Errorf("hashing must still count every body byte: got %d", snap.TotalBytesRecv)
+	}
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_ChunkedWithTrailers checks that chunked responses with trailers are
+// fully drained (so bytes are exact) and that connections are reused.
+func TestRun_ChunkedWithTrailers(t *testing.T) {
+	payload := []byte("chunk-one|chunk-two")
+	var newConns int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(payload[:10])
+		w.(http.Flusher).Flush() // forces chunked transfer encoding
+		_, _ = w.Write(payload[10:])
+		w.Header().Set("X-Checksum", "abc")
+	}))
+	srv.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt64(&newConns, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 2,
+		Duration:    150 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    2,
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+	snap := orch.FinalSnapshot()
+	if snap.TotalRequests < 4 {
+		t.Fatalf("expected several requests, got %d", snap.TotalRequests)
+	}
+	if snap.ChunkedResponses != snap.TotalRequests {
+		t.Errorf("ChunkedResponses: got %d, want %d", snap.ChunkedResponses, snap.TotalRequests)
+	}
+	if want := snap.TotalRequests * uint64(len(payload)); snap.TotalBytesRecv != want {
+		t.Errorf("TotalBytesRecv: got %d, want %d", snap.TotalBytesRecv, want)
+	}
+	if n := atomic.LoadInt64(&newConns); n > 2 {
+		t.Errorf("expected at most 2 connections (one per slot), got %d", n)
+	}
+}
+
+// TestRun_NoBodyResponsesNotChunked ensures 204 responses are not reported as chunked.
+func TestRun_NoBodyResponsesNotChunked(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 1,
+		Duration:    80 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+	snap := orch.FinalSnapshot()
+	if snap.ChunkedResponses != 0 || snap.TotalBytesRecv != 0 {
+		t.Errorf("204 responses: chunked=%d bytes=%d, want 0/0", snap.ChunkedResponses, snap.TotalBytesRecv)
+	}
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// etagServer serves a body with ETag "v1" and answers 304 to requests that
+// already have it.
+func etagServer(full *atomic.Int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		_, _ = w.Write([]byte("full body"))
+	}))
+}
+
+func conditionalConfig(url string) engine.Config {
+	return engine.Config{
+		Method:      "GET",
+		URL:         url,
+		Connections: 1,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+}
+
+// TestRun_IfNoneMatchCountsNotModified checks that a matching If-None-Match
+// turns every response into a 304, counted as a success and separately.
+func TestRun_IfNoneMatchCountsNotModified(t *testing.T) {
+	var full atomic.Int64
+	srv := etagServer(&full)
+	defer srv.Close()
+
+	cfg := conditionalConfig(srv.URL + "/")
+	cfg.IfNoneMatch = `"v1"`
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	snap := o.FinalSnapshot()
+	if snap.TotalRequests == 0 || snap.NotModified != snap.TotalRequests || snap.Successes != snap.TotalRequests {
+		t.Errorf("total %d, not modified %d, successes %d; want all 304 successes", snap.TotalRequests, snap.NotModified, snap.Successes)
+	}
+	if full.Load() != 0 {
+		t.Errorf("server sent %d full responses", full.Load())
+	}
+}
+
+// TestRun_ChainETagRevalidates checks that with ChainETag only the first
+// request fetches the body and the rest revalidate the captured ETag.
+func TestRun_ChainETagRevalidates(t *testing.T) {
+	var full atomic.Int64
+	srv := etagServer(&full)
+	defer srv.Close()
+
+	cfg := conditionalConfig(srv.URL + "/")
+	cfg.ChainETag = true
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	snap := o.FinalSnapshot()
+	if full.Load() != 1 {
+		t.Errorf("server sent %d full responses, want 1", full.Load())
+	}
+	if snap.TotalRequests < 2 || snap.NotModified != snap.TotalRequests-1 {
+		t.Errorf("total %d, not modified %d; want all but the first to be 304", snap.TotalRequests, snap.NotModified)
+	}
+}
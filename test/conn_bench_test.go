@@ -0,0 +1,61 @@
+package test
+
+import (
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_ConnBenchOverTLS checks that ConnBench sets up a new TLS
+// connection per request and reports connection and handshake metrics.
+func TestRun_ConnBenchOverTLS(t *testing.T) {
+	var conns atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Insecure:    true,
+		ConnBench:   true,
+		Connections: 2,
+		Duration:    200 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if !snap.HandshakesTracked || snap.TotalRequests < 4 {
+		t.Fatalf("tracked = %v, requests = %d", snap.HandshakesTracked, snap.TotalRequests)
+	}
+	if snap.Connects != snap.TotalRequests || snap.TLSHandshakes != snap.TotalRequests {
+		t.Errorf("connects = %d, TLS handshakes = %d, want one each per request (%d)", snap.Connects, snap.TLSHandshakes, snap.TotalRequests)
+	}
+	if got := uint64(conns.Load()); got < snap.TotalRequests {
+		t.Errorf("server saw %d connections for %d requests", got, snap.TotalRequests)
+	}
+	if snap.ConnectsPerS <= 0 || snap.TLSHandshakesPerS <= 0 {
+		t.Errorf("conn/s = %.1f, TLS handshakes/s = %.1f", snap.ConnectsPerS, snap.TLSHandshakesPerS)
+	}
+	if snap.HandshakeP50 <= 0 || snap.HandshakeP99 < snap.HandshakeP50 || snap.HandshakeMax < snap.HandshakeP99 {
+		t.Errorf("setup p50 = %s, p99 = %s, max = %s", snap.HandshakeP50, snap.HandshakeP99, snap.HandshakeMax)
+	}
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_MaxRequestsPerConnRotates checks that with one slot and
+// MaxRequestsPerConn=K the server sees a new connection every K requests,
+// and that the rotations are reported.
+func TestRun_MaxRequestsPerConnRotates(t *testing.T) {
+	const perConn = 5
+	var requests, dials atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			dials.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:             "GET",
+		URL:                srv.URL + "/",
+		Connections:        1,
+		Duration:           200 * time.Millisecond,
+		Workers:            1,
+		Pipeline:           1,
+		MaxRequestsPerConn: perConn,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	n, d := requests.Load(), dials.Load()
+	if n < 2*perConn {
+		t.Fatalf("only %d requests; need several rotations to compare", n)
+	}
+	if want := (n + perConn - 1) / perConn; d != want {
+		t.Errorf("%d requests made %d dials, want %d (one per %d requests)", n, d, want, perConn)
+	}
+	if got, want := o.FinalSnapshot().ConnRotations, uint64(n/perConn); got != want {
+		t.Errorf("ConnRotations = %d, want %d", got, want)
+	}
+}
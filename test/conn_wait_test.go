@@ -0,0 +1,101 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_CapConnectionsReportsConnWait runs eight slots over one capped
+// connection against a slow handler, so most requests queue for the
+// connection, and checks that the wait is reported and the cap respected.
+func TestRun_CapConnectionsReportsConnWait(t *testing.T) {
+	var mu sync.Mutex
+	conns := map[string]bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns[r.RemoteAddr] = true
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:         "GET",
+		URL:            srv.URL + "/",
+		Connections:    1,
+		CapConnections: true,
+		Duration:       200 * time.Millisecond,
+		Workers:        1,
+		Pipeline:       8,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if !snap.ConnWaitTracked {
+		t.Fatal("conn wait not tracked with a connection cap")
+	}
+	if snap.ConnWaitP50 <= 0 || snap.ConnWaitMax < snap.ConnWaitP50 {
+		t.Errorf("conn wait p50 = %s, max = %s; want queueing behind one connection", snap.ConnWaitP50, snap.ConnWaitMax)
+	}
+	if snap.ConnWaitP50 > snap.LatencyP50 {
+		t.Errorf("conn wait p50 %s exceeds latency p50 %s it is part of", snap.ConnWaitP50, snap.LatencyP50)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(conns) != 1 {
+		t.Errorf("server saw %d connections, want 1", len(conns))
+	}
+}
+
+// TestRun_ExhaustedPoolFailsWithStrict runs sixteen slots over two capped
+// connections, so the slots mostly wait for a connection, and checks the
+// pool is reported as exhausted and fails the run with Strict, while a
+// pool with a connection per slot passes.
+func TestRun_ExhaustedPoolFailsWithStrict(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	for _, conns := range []int{2, 16} {
+		cfg := engine.Config{
+			Method:         "GET",
+			URL:            srv.URL + "/",
+			Connections:    conns,
+			CapConnections: true,
+			Strict:         true,
+			Duration:       200 * time.Millisecond,
+			Workers:        1,
+			Pipeline:       16,
+		}
+		o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+		err := o.Run()
+		snap := o.FinalSnapshot()
+
+		var poolErr *engine.PoolExhaustedError
+		if conns == 2 {
+			if !errors.As(err, &poolErr) {
+				t.Fatalf("2 connections for 16 slots: err = %v, want a *PoolExhaustedError", err)
+			}
+			if snap.ConnWaitShare < 0.5 || poolErr.WaitShare != snap.ConnWaitShare || poolErr.Connections != 2 || poolErr.Slots != 16 {
+				t.Errorf("2 connections: share %.2f, error %+v; want most slot time spent waiting", snap.ConnWaitShare, poolErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("16 connections for 16 slots: %v", err)
+		}
+		if snap.ConnWaitShare > 0.25 {
+			t.Errorf("16 connections: slots waited %.2f of the run, want little", snap.ConnWaitShare)
+		}
+	}
+}
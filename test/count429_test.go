@@ -0,0 +1,75 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_Count429 runs against a server that rate-limits every other
+// request and checks each --count-429 mode counts the 429s as asked.
+func TestRun_Count429(t *testing.T) {
+	var n atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.Add(1)%2 == 0 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	run := func(mode string) stats.Snapshot {
+		t.Helper()
+		cfg := engine.Config{
+			Method:      "GET",
+			URL:         srv.URL + "/",
+			Connections: 1,
+			Duration:    100 * time.Millisecond,
+			Workers:     1,
+			Pipeline:    1,
+			Count429:    mode,
+		}
+		o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+		if err := o.Run(); err != nil {
+			t.Fatal(err)
+		}
+		snap := o.FinalSnapshot()
+		if snap.TotalRequests < 2 {
+			t.Fatalf("%s: only %d requests", mode, snap.TotalRequests)
+		}
+		return snap
+	}
+
+	// The default is separate.
+	for _, mode := range []string{"", engine.Count429Separate} {
+		snap := run(mode)
+		if snap.RateLimited == 0 || snap.Errors != 0 || snap.Successes+snap.RateLimited != snap.TotalRequests {
+			t.Errorf("%q: total %d, successes %d, rate limited %d, errors %d; want the 429s on their own",
+				mode, snap.TotalRequests, snap.Successes, snap.RateLimited, snap.Errors)
+		}
+	}
+
+	snap := run(engine.Count429Success)
+	if snap.Successes != snap.TotalRequests || snap.RateLimited != 0 {
+		t.Errorf("success: total %d, successes %d, rate limited %d; want all successes", snap.TotalRequests, snap.Successes, snap.RateLimited)
+	}
+
+	snap = run(engine.Count429Error)
+	if snap.Errors == 0 || snap.ErrorsByCategory[stats.ErrHTTP429] != snap.Errors || snap.RateLimited != 0 ||
+		snap.Successes+snap.Errors != snap.TotalRequests {
+		t.Errorf("error: total %d, successes %d, errors %d (%v), rate limited %d; want the 429s as http_429 errors",
+			snap.TotalRequests, snap.Successes, snap.Errors, snap.ErrorsByCategory, snap.RateLimited)
+	}
+	if samples := snap.ErrorSamples[stats.ErrHTTP429]; len(samples) == 0 || samples[0] != "HTTP 429 Too Many Requests" {
+		t.Errorf("error samples: %v", samples)
+	}
+
+	o := engine.NewOrchestrator(engine.Config{Method: "GET", URL: srv.URL, Duration: time.Millisecond, Count429: "ignore"}, NewNoopRenderer())
+	if err := o.Run(); err == nil {
+		t.Error("an unknown mode was accepted")
+	}
+}
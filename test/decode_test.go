@@ -0,0 +1,77 @@
+//go:build !nozstd
+
+package test
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_DecodeZstd serves a zstd-encoded JSON body, which the transport
+// passes through as is, and checks --decode lets the body checks and the
+// byte counts see the decoded JSON. Every fourth response uses an encoding
+// --decode does not know; those are noted rather than failed by the decode.
+func TestRun_DecodeZstd(t *testing.T) {
+	const body = `{"status":"ok","padding":"` + "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx" + `"}`
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := enc.EncodeAll([]byte(body), nil)
+
+	var n int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "zstd") {
+			http.Error(w, "zstd not accepted", http.StatusNotAcceptable)
+			return
+		}
+		n++
+		if n%4 == 0 {
+			w.Header().Set("Content-Encoding", "x-custom")
+			w.Write([]byte(body))
+			return
+		}
+		w.Header().Set("Content-Encoding", "zstd")
+		w.Write(encoded)
+	}))
+	defer srv.Close()
+
+	assert, err := engine.ParseJSONAssert("$.status==ok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(body))
+	cfg := engine.Config{
+		Method:       "GET",
+		URL:          srv.URL + "/",
+		Connections:  1,
+		Duration:     200 * time.Millisecond,
+		Workers:      1,
+		Pipeline:     1,
+		Decode:       true,
+		AssertJSON:   []engine.JSONAssert{assert},
+		ExpectSHA256: sum[:],
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if snap.TotalRequests < 8 || snap.Errors != 0 {
+		t.Fatalf("%d requests, %d errors (%v), want all to pass the checks", snap.TotalRequests, snap.Errors, snap.ErrorSamples)
+	}
+	if want := snap.TotalRequests * uint64(len(body)); snap.TotalBytesRecv != want {
+		t.Errorf("received %d bytes, want the decoded %d", snap.TotalBytesRecv, want)
+	}
+	if got, want := snap.Undecoded["x-custom"], snap.TotalRequests/4; got != want {
+		t.Errorf("Undecoded = %v, want %d x-custom", snap.Undecoded, want)
+	}
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+type connCountKey struct{}
+
+// TestRun_DiscardFirstPerConn checks that the first K requests on every
+// connection are left out of the stats: the server makes them slow, so any
+// that were recorded would show up in the latency.
+func TestRun_DiscardFirstPerConn(t *testing.T) {
+	const discard, perConn = 2, 4
+	const slow = 40 * time.Millisecond
+	var requests atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Context().Value(connCountKey{}).(*atomic.Int64).Add(1) <= discard {
+			time.Sleep(slow)
+		}
+	}))
+	srv.Config.ConnContext = func(ctx context.Context, _ net.Conn) context.Context {
+		return context.WithValue(ctx, connCountKey{}, new(atomic.Int64))
+	}
+	srv.Start()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:              "GET",
+		URL:                 srv.URL + "/",
+		Connections:         1,
+		Duration:            400 * time.Millisecond,
+		Workers:             1,
+		Pipeline:            1,
+		MaxRequestsPerConn:  perConn,
+		DiscardFirstPerConn: discard,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	n := uint64(requests.Load())
+	if n < 2*perConn {
+		t.Fatalf("only %d requests; need several connections to compare", n)
+	}
+	conns := (n + perConn - 1) / perConn
+	want := (conns-1)*discard + min(discard, n-(conns-1)*perConn)
+	if snap.Discarded != want {
+		t.Errorf("Discarded = %d, want %d for %d requests on %d connections", snap.Discarded, want, n, conns)
+	}
+	if snap.TotalRequests+snap.Discarded != n {
+		t.Errorf("recorded %d + discarded %d != %d sent", snap.TotalRequests, snap.Discarded, n)
+	}
+	if snap.LatencyMax >= slow {
+		t.Errorf("max latency %v: a slow warm-up request was recorded", snap.LatencyMax)
+	}
+}
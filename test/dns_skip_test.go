@@ -0,0 +1,74 @@
+package test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_PinnedTargetSkipsDNSFailure runs against a host that cannot resolve
+// (.invalid is reserved) but is pinned to a local server: the DNS preflight
+// failure must only warn, and requests must reach the pinned address with the
+// original Host header.
+func TestRun_PinnedTargetSkipsDNSFailure(t *testing.T) {
+	var hits atomic.Int64
+	var badHost atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !strings.HasPrefix(r.Host, "bench-target.invalid:") {
+			badHost.Store(r.Host)
+		}
+	}))
+	defer srv.Close()
+
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         "http://bench-target.invalid:" + port + "/",
+		Connections: 2,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+		Resolve:     map[string]string{"bench-target.invalid:" + port: srv.Listener.Addr().String()},
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := orch.Run(); err != nil {
+		t.Fatalf("pinned target should not fail on DNS: %v", err)
+	}
+	snap := orch.FinalSnapshot()
+	if snap.Successes == 0 || snap.Errors != 0 {
+		t.Errorf("expected only successes: ok=%d err=%d %v", snap.Successes, snap.Errors, snap.ErrorSamples)
+	}
+	if hits.Load() == 0 {
+		t.Error("pinned server received no requests")
+	}
+	if h := badHost.Load(); h != nil {
+		t.Errorf("Host header should keep the original name, got %q", h)
+	}
+}
+
+func TestRun_DNSFailureStillFailsByDefault(t *testing.T) {
+	cfg := engine.Config{URL: "http://bench-target.invalid/", Duration: 100 * time.Millisecond}
+	if err := engine.NewOrchestrator(cfg, NewNoopRenderer()).Run(); err == nil {
+		t.Fatal("expected the DNS preflight to fail without an override")
+	}
+
+	// With the check skipped the run starts; lookups then fail (or hang until
+	// the drain timeout, depending on the resolver) per request.
+	cfg.SkipDNSCheck = true
+	cfg.DrainTimeout = 200 * time.Millisecond
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := orch.Run(); err != nil {
+		t.Fatalf("SkipDNSCheck should let the run start: %v", err)
+	}
+	snap := orch.FinalSnapshot()
+	if snap.Successes != 0 || snap.ErrorsByCategory["dns"] != snap.Errors {
+		t.Errorf("requests should only fail with dns errors: ok=%d %v", snap.Successes, snap.ErrorsByCategory)
+	}
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_DrainTimeoutAbandonsHungRequests verifies a handler that never
+// answers cannot hold the run open past duration + drain timeout, and that the
+// hung requests are reported as abandoned rather than as errors.
+func TestRun_DrainTimeoutAbandonsHungRequests(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cfg := engine.Config{
+		Method:       "GET",
+		URL:          srv.URL + "/",
+		Connections:  2,
+		Duration:     100 * time.Millisecond,
+		DrainTimeout: 200 * time.Millisecond,
+		Workers:      1,
+		Pipeline:     2,
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	start := time.Now()
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("run should end shortly after the drain timeout, took %v", elapsed)
+	}
+	snap := orch.FinalSnapshot()
+	if snap.Abandoned != 2 {
+		t.Errorf("Abandoned: got %d, want 2 (one per slot)", snap.Abandoned)
+	}
+	if snap.Errors != 0 || snap.TotalRequests != 0 {
+		t.Errorf("abandoned requests must not count as completed: total=%d errors=%d", snap.TotalRequests, snap.Errors)
+	}
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/export"
+)
+
+type errorsDoc struct {
+	SchemaVersion int `json:"schema_version"`
+	Errors        struct {
+		Total      uint64 `json:"total"`
+		Categories map[string]struct {
+			Count   uint64   `json:"count"`
+			Samples []string `json:"samples"`
+		} `json:"categories"`
+	} `json:"errors"`
+}
+
+func runAndSummarize(t *testing.T, url string) errorsDoc {
+	t.Helper()
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         url,
+		Connections: 1,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := export.WriteJSON(&buf, export.NewReport(export.Meta{URL: url}, orch.FinalSnapshot(), orch.Collector())); err != nil {
+		t.Fatal(err)
+	}
+	var doc errorsDoc
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	return doc
+}
+
+// TestJSON_ErrorTaxonomy forces 5xx and connection-refused failures and checks
+// the structured breakdown in the JSON summary.
+func TestJSON_ErrorTaxonomy(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+
+	doc := runAndSummarize(t, srv.URL+"/fail500")
+	if doc.SchemaVersion != export.SchemaVersion {
+		t.Errorf("schema_version: got %d", doc.SchemaVersion)
+	}
+	fiveXX := doc.Errors.Categories["http_5xx"]
+	if fiveXX.Count == 0 || fiveXX.Count != doc.Errors.Total {
+		t.Errorf("http_5xx: count=%d total=%d", fiveXX.Count, doc.Errors.Total)
+	}
+	if len(fiveXX.Samples) == 0 || fiveXX.Samples[0] != "HTTP 500 Internal Server Error" {
+		t.Errorf("http_5xx samples: %v", fiveXX.Samples)
+	}
+	for _, cat := range []string{"dns", "connect", "tls", "timeout", "read", "protocol", "validation", "other"} {
+		if _, ok := doc.Errors.Categories[cat]; !ok {
+			t.Errorf("category %q missing from JSON", cat)
+		}
+	}
+
+	// A port nobody listens on: every request is refused.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedURL := "http://" + ln.Addr().String() + "/"
+	_ = ln.Close()
+
+	doc = runAndSummarize(t, closedURL)
+	connect := doc.Errors.Categories["connect"]
+	if connect.Count == 0 || connect.Count != doc.Errors.Total {
+		t.Errorf("connect: count=%d total=%d (categories %+v)", connect.Count, doc.Errors.Total, doc.Errors.Categories)
+	}
+	if len(connect.Samples) == 0 {
+		t.Error("connect category should carry a sample message")
+	}
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/export"
+)
+
+// TestRun_OutDirArtifacts runs a short benchmark and bundles every artifact
+// the way `run --out-dir` does.
+func TestRun_OutDirArtifacts(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 1,
+		Duration:    1100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	startedAt := time.Now()
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := export.NewRunDir(t.TempDir(), startedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := export.NewReport(export.Meta{URL: cfg.URL, StartedAt: startedAt}, orch.FinalSnapshot(), orch.Collector())
+	if err := export.WriteBundle(dir, report, nil); err != nil {
+		t.Fatalf("WriteBundle: %v", err)
+	}
+	for _, name := range []string{"summary.json", "timeseries.csv", "latency_cdf.csv", "report.html"} {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("missing %s: %v", name, err)
+			continue
+		}
+		if fi.Size() == 0 {
+			t.Errorf("%s is empty", name)
+		}
+	}
+	if report.Snapshot.TotalRequests == 0 {
+		t.Error("expected the final snapshot to carry requests")
+	}
+}
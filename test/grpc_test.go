@@ -0,0 +1,87 @@
+package test
+
+import (
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_GRPCUnary runs against a minimal gRPC handler served over h2c. The
+// handler answers "ping" with grpc-status 0 and anything else with 14
+// (unavailable), so both outcomes are classified from the trailer.
+func TestRun_GRPCUnary(t *testing.T) {
+	var mu sync.Mutex
+	var bad []string
+	fail := func(reason string) {
+		mu.Lock()
+		bad = append(bad, reason)
+		mu.Unlock()
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.ProtoMajor != 2:
+			fail("proto " + r.Proto)
+		case r.Method != http.MethodPost:
+			fail("method " + r.Method)
+		case r.Header.Get("Content-Type") != "application/grpc":
+			fail("content-type " + r.Header.Get("Content-Type"))
+		case r.Header.Get("TE") != "trailers":
+			fail("te " + r.Header.Get("TE"))
+		}
+		frame, _ := io.ReadAll(r.Body)
+		if len(frame) < 5 || frame[0] != 0 || int(binary.BigEndian.Uint32(frame[1:5])) != len(frame)-5 {
+			fail("bad frame")
+			return
+		}
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		_, _ = w.Write([]byte{0, 0, 0, 0, 0}) // empty response message
+		if string(frame[5:]) == "ping" {
+			w.Header().Set("Grpc-Status", "0")
+		} else {
+			w.Header().Set("Grpc-Status", "14")
+			w.Header().Set("Grpc-Message", "backend%20down")
+		}
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		URL:         srv.URL + "/echo.Echo/Ping",
+		BodyCorpus:  [][]byte{[]byte("ping"), []byte("boom")},
+		GRPC:        true,
+		Connections: 1,
+		Duration:    150 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    2,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bad) > 0 {
+		t.Fatalf("server rejected %d requests, first: %s", len(bad), bad[0])
+	}
+	snap := o.FinalSnapshot()
+	if snap.Successes == 0 {
+		t.Error("no call succeeded with grpc-status 0")
+	}
+	if got := snap.ErrorsByCategory[stats.ErrGRPC]; got == 0 || got != snap.Errors {
+		t.Errorf("errors = %d, grpc = %d; want every error classified as grpc", snap.Errors, got)
+	}
+	if s := snap.ErrorSamples[stats.ErrGRPC]; len(s) == 0 || s[0] != "grpc-status 14: backend down" {
+		t.Errorf("grpc error samples = %q", s)
+	}
+}
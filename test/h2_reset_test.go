@@ -0,0 +1,50 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_H2ResetsClassified checks that streams the HTTP/2 server resets
+// are counted as h2_reset errors rather than generic ones.
+func TestRun_H2ResetsClassified(t *testing.T) {
+	var calls atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every other stream is reset, as an overloaded server would.
+		if calls.Add(1)%2 == 0 {
+			panic(http.ErrAbortHandler)
+		}
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Config.HTTP2 = &http.HTTP2Config{MaxConcurrentStreams: 2}
+	srv.Start()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		URL:         srv.URL,
+		Connections: 4,
+		Duration:    200 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    4,
+		HTTPVersion: "2",
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if snap.ErrorsByCategory[stats.ErrH2Reset] == 0 {
+		t.Fatalf("no h2_reset errors; errors by category %v, samples %v", snap.ErrorsByCategory, snap.ErrorSamples)
+	}
+	if other := snap.ErrorsByCategory[stats.ErrOther] + snap.ErrorsByCategory[stats.ErrProtocol]; other > 0 {
+		t.Errorf("%d resets counted as other or protocol errors: %v", other, snap.ErrorSamples)
+	}
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_RejectHeaderFailsOK checks that a 200 carrying X-Error: true is
+// counted as a header error while other 200s still succeed.
+func TestRun_RejectHeaderFailsOK(t *testing.T) {
+	var n atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.Add(1)%2 == 0 {
+			w.Header().Set("X-Error", "true")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:        "GET",
+		URL:           srv.URL + "/",
+		RejectHeaders: []engine.HeaderMatch{{Name: "X-Error", Value: "true"}},
+		Connections:   1,
+		Duration:      100 * time.Millisecond,
+		Workers:       1,
+		Pipeline:      1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if snap.Successes == 0 || snap.Errors == 0 {
+		t.Fatalf("successes = %d, errors = %d; want both", snap.Successes, snap.Errors)
+	}
+	if got := snap.ErrorsByCategory[stats.ErrHeader]; got != snap.Errors {
+		t.Errorf("header errors = %d of %d errors", got, snap.Errors)
+	}
+	if diff := int64(snap.Errors) - int64(snap.Successes); diff < -1 || diff > 1 {
+		t.Errorf("successes = %d, errors = %d; want every other response rejected", snap.Successes, snap.Errors)
+	}
+}
+
+// TestRun_ExpectHeaderMissing checks that a required header missing from a
+// 200 response fails the request.
+func TestRun_ExpectHeaderMissing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:        "GET",
+		URL:           srv.URL + "/",
+		ExpectHeaders: []engine.HeaderMatch{{Name: "X-Request-Id"}},
+		Connections:   1,
+		Duration:      50 * time.Millisecond,
+		Workers:       1,
+		Pipeline:      1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	snap := o.FinalSnapshot()
+	if snap.Successes != 0 || snap.ErrorsByCategory[stats.ErrHeader] == 0 {
+		t.Errorf("successes = %d, header errors = %d; want every request to fail", snap.Successes, snap.ErrorsByCategory[stats.ErrHeader])
+	}
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_TemplatedHeaderPerRequest verifies a {{uuid}} header value is
+// expanded for every request while static headers stay constant.
+func TestRun_TemplatedHeaderPerRequest(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]int{}
+	var total int
+	var badAuth int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.Header.Get("X-Cache-Bust")]++
+		total++
+		if r.Header.Get("Authorization") != "Bearer token" {
+			badAuth++
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method: "GET",
+		URL:    srv.URL + "/",
+		Headers: http.Header{
+			"X-Cache-Bust":  {"{{uuid}}"},
+			"Authorization": {"Bearer token"},
+		},
+		Connections: 2,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    2,
+	}
+	if err := engine.NewOrchestrator(cfg, NewNoopRenderer()).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if total < 2 {
+		t.Fatalf("expected several requests, got %d", total)
+	}
+	if len(seen) != total {
+		t.Errorf("expected %d distinct X-Cache-Bust values, got %d", total, len(seen))
+	}
+	if _, ok := seen[""]; ok {
+		t.Error("some requests were missing the templated header")
+	}
+	if badAuth != 0 {
+		t.Errorf("%d requests were missing the static Authorization header", badAuth)
+	}
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestManifest_RoundTrip writes the manifest of a finished run and reads it
+// back: the config of the new run must match the effective config of the
+// first, seed and parsed assertions included, without the signing secrets.
+func TestManifest_RoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	assert, err := engine.ParseJSONAssert("$.status==ok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := engine.Config{
+		Method:      "POST",
+		URL:         srv.URL + "/",
+		Body:        []byte("hello"),
+		Headers:     http.Header{"X-Test": {"1"}},
+		Connections: 2,
+		Duration:    200 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+		Rate:        50,
+		AssertJSON:  []engine.JSONAssert{assert},
+		AWSSigV4: &engine.SigV4{
+			Region:          "eu-west-1",
+			Service:         "execute-api",
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		},
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	startedAt := time.Now()
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.WriteManifest(&buf, engine.NewManifest(o, "test", startedAt)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "AKIDEXAMPLE") || strings.Contains(buf.String(), "EXAMPLEKEY") {
+		t.Fatalf("manifest holds the signing credentials:\n%s", buf.String())
+	}
+	m, err := engine.ReadManifest(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Build != "test" || !m.StartedAt.Equal(startedAt) {
+		t.Errorf("build %q started at %v, want test at %v", m.Build, m.StartedAt, startedAt)
+	}
+	if m.Result.TotalRequests != o.FinalSnapshot().TotalRequests {
+		t.Errorf("result has %d requests, the run made %d", m.Result.TotalRequests, o.FinalSnapshot().TotalRequests)
+	}
+
+	want := o.Config()
+	if want.Seed == 0 || m.Config.Seed != want.Seed {
+		t.Errorf("seed = %d, want %d", m.Config.Seed, want.Seed)
+	}
+	if m.Config.AWSSigV4 == nil || m.Config.AWSSigV4.Region != "eu-west-1" || m.Config.AWSSigV4.AccessKeyID != "" {
+		t.Errorf("SigV4 = %+v, want the region and service only", m.Config.AWSSigV4)
+	}
+	m.Config.AWSSigV4, want.AWSSigV4 = nil, nil
+	// Signals, callbacks and the log writer are not recorded; the new run
+	// sets its own.
+	want.StopSignals, want.StatusSignals, want.OnResult, want.Log = nil, nil, nil, nil
+	if len(m.Config.AssertJSON) != 1 || !reflect.DeepEqual(m.Config.AssertJSON[0], want.AssertJSON[0]) {
+		t.Errorf("AssertJSON = %+v, want %+v", m.Config.AssertJSON, want.AssertJSON)
+	}
+	if !reflect.DeepEqual(m.Config, want) {
+		t.Errorf("config read back differs:\n got %+v\nwant %+v", m.Config, want)
+	}
+}
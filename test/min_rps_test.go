@@ -0,0 +1,73 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// slowServer answers each request after delay, capping one slot at about
+// 1/delay requests per second.
+func slowServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+	}))
+}
+
+// TestRun_MinRPSFloorFails checks that a rate-limited server fails a floor
+// it cannot reach once the warm-up is over.
+func TestRun_MinRPSFloorFails(t *testing.T) {
+	srv := slowServer(20 * time.Millisecond)
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:       "GET",
+		URL:          srv.URL + "/",
+		Connections:  1,
+		Duration:     2500 * time.Millisecond,
+		Workers:      1,
+		Pipeline:     1,
+		MinRPS:       500,
+		MinRPSWarmup: 300 * time.Millisecond,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	err := o.Run()
+	var slowErr *engine.ThroughputError
+	if !errors.As(err, &slowErr) {
+		t.Fatalf("expected *engine.ThroughputError, got %T: %v", err, err)
+	}
+	if slowErr.RPS <= 0 || slowErr.RPS > 60 {
+		t.Errorf("sustained rate %.1f req/s, want about 50", slowErr.RPS)
+	}
+	if rps, ok := o.SustainedRPS(); !ok || rps != slowErr.RPS {
+		t.Errorf("SustainedRPS() = %.1f, %v; want %.1f, true", rps, ok, slowErr.RPS)
+	}
+}
+
+// TestRun_MinRPSNotEvaluatedDuringWarmup checks that a run ending inside
+// the warm-up is not failed for its ramp-up rate.
+func TestRun_MinRPSNotEvaluatedDuringWarmup(t *testing.T) {
+	srv := slowServer(20 * time.Millisecond)
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 1,
+		Duration:    300 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+		MinRPS:      500,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatalf("run within the warm-up should pass: %v", err)
+	}
+	if _, ok := o.SustainedRPS(); ok {
+		t.Error("SustainedRPS reported a rate measured inside the warm-up")
+	}
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_OnResultSeesEveryRequest checks that the hook is called once per
+// recorded request with the request's status, bytes, latency and start time.
+func TestRun_OnResultSeesEveryRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var results []stats.Result
+	began := time.Now()
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 2,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    2,
+		OnResult: func(r stats.Result) {
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+		},
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	ended := time.Now()
+
+	snap := o.FinalSnapshot()
+	if snap.TotalRequests == 0 || uint64(len(results)) != snap.TotalRequests {
+		t.Fatalf("hook called %d times for %d requests", len(results), snap.TotalRequests)
+	}
+	for _, r := range results {
+		if r.Status != http.StatusServiceUnavailable || r.Success || r.BytesRecv != 5 || r.Latency <= 0 {
+			t.Fatalf("unexpected result %+v", r)
+		}
+		if r.Start.Before(began) || r.Start.After(ended) {
+			t.Fatalf("start %v outside the run (%v to %v)", r.Start, began, ended)
+		}
+	}
+	if snap.Errors != snap.TotalRequests {
+		t.Errorf("collector counted %d of %d requests as errors", snap.Errors, snap.TotalRequests)
+	}
+}
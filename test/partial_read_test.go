@@ -0,0 +1,49 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_PartialBodyIsReadError runs against a server that promises 1000
+// bytes with a 200, sends 100 and drops the connection, and checks every
+// request fails as a read error while the 100 bytes still count.
+func TestRun_PartialBodyIsReadError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1000")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer srv.Close()
+
+	o := engine.NewOrchestrator(engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 1,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	snap := o.FinalSnapshot()
+	if snap.TotalRequests == 0 || snap.Successes != 0 || snap.ErrorsByCategory[stats.ErrRead] != snap.TotalRequests {
+		t.Errorf("total %d, successes %d, errors %v; want every request a read error",
+			snap.TotalRequests, snap.Successes, snap.ErrorsByCategory)
+	}
+	if snap.TotalBytesRecv < 100*snap.TotalRequests {
+		t.Errorf("received %d bytes over %d requests, want the 100 partial bytes of each", snap.TotalBytesRecv, snap.TotalRequests)
+	}
+}
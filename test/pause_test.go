@@ -0,0 +1,75 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_PauseResumeStop drives a run through Config.Controls: no request
+// starts while it is paused, requests resume after, and stop ends the run
+// long before its duration.
+func TestRun_PauseResumeStop(t *testing.T) {
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(time.Millisecond)
+	}))
+	defer srv.Close()
+
+	controls := make(chan engine.Control)
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 2,
+		Duration:    10 * time.Second,
+		Workers:     1,
+		Pipeline:    1,
+		Controls:    controls,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() { done <- o.Run() }()
+
+	time.Sleep(200 * time.Millisecond)
+	controls <- engine.ControlPause
+	time.Sleep(50 * time.Millisecond) // let in-flight requests finish
+	paused := calls.Load()
+	if paused == 0 {
+		t.Fatal("no requests before the pause")
+	}
+	time.Sleep(300 * time.Millisecond)
+	if n := calls.Load(); n != paused {
+		t.Errorf("%d requests started while paused", n-paused)
+	}
+
+	controls <- engine.ControlResume
+	time.Sleep(200 * time.Millisecond)
+	if n := calls.Load(); n <= paused {
+		t.Error("no requests after resuming")
+	}
+
+	controls <- engine.ControlStop
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not stop")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("run took %s; stop should end it early", elapsed)
+	}
+	if got := o.StopReason(); got != "stop requested" {
+		t.Errorf("StopReason = %q", got)
+	}
+	if snap := o.FinalSnapshot(); snap.TotalRequests != uint64(calls.Load()) {
+		t.Errorf("recorded %d requests, server saw %d", snap.TotalRequests, calls.Load())
+	}
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_PerConnAttributesRequests checks that with PerConn every request
+// is counted against the connection the server saw it on, and that errors
+// from one bad connection are pinned to it.
+func TestRun_PerConnAttributesRequests(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]uint64{} // client address -> requests
+	var bad string              // the first connection answers 500s
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.RemoteAddr]++
+		if bad == "" {
+			bad = r.RemoteAddr
+		}
+		fail := r.RemoteAddr == bad
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 3,
+		Duration:    200 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    3,
+		PerConn:     true,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if len(snap.Conns) != len(seen) {
+		t.Fatalf("%d connections reported, server saw %d", len(snap.Conns), len(seen))
+	}
+	if len(seen) < 2 {
+		t.Fatalf("only %d connection(s); need several to compare", len(seen))
+	}
+	var total uint64
+	for _, cs := range snap.Conns {
+		if cs.Requests != seen[cs.Local] {
+			t.Errorf("conn #%d (%s): %d requests, server saw %d", cs.ID, cs.Local, cs.Requests, seen[cs.Local])
+		}
+		total += cs.Requests
+	}
+	if total != snap.TotalRequests {
+		t.Errorf("per-connection requests sum to %d, want %d", total, snap.TotalRequests)
+	}
+	worst := snap.Conns[0]
+	if worst.Local != bad || worst.Errors != worst.Requests || worst.Errors != snap.Errors {
+		t.Errorf("worst connection %+v, want %s with all %d errors", worst, bad, snap.Errors)
+	}
+}
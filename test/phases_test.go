@@ -0,0 +1,59 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_PhasesSumToWallTime checks that the preflight, load and drain
+// phases of the final snapshot account for the wall time of Run.
+func TestRun_PhasesSumToWallTime(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(40 * time.Millisecond) // leave requests in flight to drain
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 2,
+		Duration:    150 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    2,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	start := time.Now()
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	wall := time.Since(start)
+
+	phases := o.FinalSnapshot().Phases
+	want := []string{"preflight", "load", "drain"}
+	if len(phases) != len(want) {
+		t.Fatalf("phases = %+v, want %v", phases, want)
+	}
+	var sum time.Duration
+	for i, p := range phases {
+		if p.Name != want[i] {
+			t.Errorf("phase %d = %q, want %q", i, p.Name, want[i])
+		}
+		if p.Duration < 0 {
+			t.Errorf("phase %q has negative duration %s", p.Name, p.Duration)
+		}
+		sum += p.Duration
+	}
+	if sum > wall || wall-sum > 50*time.Millisecond {
+		t.Errorf("phases sum to %s, wall time was %s", sum, wall)
+	}
+	if load := phases[1].Duration; load < cfg.Duration {
+		t.Errorf("load phase %s shorter than the %s duration", load, cfg.Duration)
+	}
+	if drain := phases[2].Duration; drain <= 0 {
+		t.Errorf("drain phase = %s, want > 0 with slow responses in flight", drain)
+	}
+}
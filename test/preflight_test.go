@@ -0,0 +1,91 @@
+package test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/export"
+)
+
+// TestRun_FailedPreflightInJSON runs against a server whose certificate has
+// expired and checks the JSON summary says which preflight step failed and
+// why, alongside the ones that passed.
+func TestRun_FailedPreflightInJSON(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "expired.test"},
+		NotBefore:    now.Add(-48 * time.Hour),
+		NotAfter:     now.Add(-24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // every handshake is rejected
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	srv.StartTLS()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:         "GET",
+		URL:            srv.URL + "/",
+		Connections:    1,
+		Duration:       200 * time.Millisecond,
+		Workers:        1,
+		Pipeline:       1,
+		CertExpiryWarn: 24 * time.Hour,
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	var runErr *engine.RunError
+	if err := orch.Run(); err != nil && !errors.As(err, &runErr) {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	report := export.NewReport(export.Meta{URL: cfg.URL}, orch.FinalSnapshot(), nil)
+	if err := export.WriteJSON(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	var summary struct {
+		Preflight []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+			Detail string `json:"detail"`
+		} `json:"preflight"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Preflight) < 2 {
+		t.Fatalf("expected the DNS and TLS checks, got %+v", summary.Preflight)
+	}
+	if dns := summary.Preflight[0]; dns.Name != "DNS" || dns.Status != "ok" {
+		t.Errorf("DNS check: %+v", dns)
+	}
+	tls := summary.Preflight[1]
+	if tls.Name != "TLS" || tls.Status != "failed" || !strings.Contains(tls.Detail, "certificate expired on") {
+		t.Errorf("TLS check: %+v", tls)
+	}
+}
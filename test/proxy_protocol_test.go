@@ -0,0 +1,133 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// proxyListener strips and parses the PROXY protocol header at the start of
+// every accepted connection, recording what it announced (or why it was
+// invalid) before handing the rest of the stream to the HTTP server.
+type proxyListener struct {
+	net.Listener
+	mu      sync.Mutex
+	headers []string // "src -> dst" or "error: ..."
+}
+
+type proxiedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *proxiedConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+func (l *proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	got, perr := parseProxyHeader(r)
+	if perr != nil {
+		got = "error: " + perr.Error()
+	}
+	l.mu.Lock()
+	l.headers = append(l.headers, got)
+	l.mu.Unlock()
+	return &proxiedConn{Conn: conn, r: r}, nil
+}
+
+// parseProxyHeader reads a v1 or v2 header for TCP over IPv4 and returns
+// "src -> dst".
+func parseProxyHeader(r *bufio.Reader) (string, error) {
+	sig, err := r.Peek(12)
+	if err != nil {
+		return "", err
+	}
+	if bytes.HasPrefix(sig, []byte("PROXY ")) {
+		line, err := r.ReadString('\n')
+		if err != nil || !strings.HasSuffix(line, "\r\n") {
+			return "", fmt.Errorf("v1 header not CRLF terminated: %q", line)
+		}
+		f := strings.Fields(line)
+		if len(f) != 6 || f[1] != "TCP4" {
+			return "", fmt.Errorf("bad v1 header %q", line)
+		}
+		return fmt.Sprintf("v1 %s:%s -> %s:%s", f[2], f[4], f[3], f[5]), nil
+	}
+	var hdr [16]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return "", err
+	}
+	if !bytes.Equal(hdr[:12], []byte("\r\n\r\n\x00\r\nQUIT\n")) || hdr[12] != 0x21 || hdr[13] != 0x11 {
+		return "", fmt.Errorf("bad v2 header % x", hdr)
+	}
+	body := make([]byte, binary.BigEndian.Uint16(hdr[14:]))
+	if _, err := io.ReadFull(r, body); err != nil || len(body) != 12 {
+		return "", fmt.Errorf("bad v2 address block of %d bytes", len(body))
+	}
+	src := netip.AddrPortFrom(netip.AddrFrom4([4]byte(body[0:4])), binary.BigEndian.Uint16(body[8:]))
+	dst := netip.AddrPortFrom(netip.AddrFrom4([4]byte(body[4:8])), binary.BigEndian.Uint16(body[10:]))
+	return fmt.Sprintf("v2 %s -> %s", src, dst), nil
+}
+
+// TestRun_ProxyProtocol checks that both header versions reach the server
+// ahead of the HTTP request, announcing the spoofed source and the real
+// destination, and that the requests behind them still succeed.
+func TestRun_ProxyProtocol(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			pl := &proxyListener{Listener: srv.Listener}
+			srv.Listener = pl
+			srv.Start()
+			defer srv.Close()
+
+			src := netip.MustParseAddrPort("203.0.113.7:4242")
+			cfg := engine.Config{
+				Method:        "GET",
+				URL:           srv.URL + "/",
+				ProxyProtocol: version,
+				ProxySource:   src,
+				Connections:   2,
+				Duration:      80 * time.Millisecond,
+				Workers:       1,
+				Pipeline:      2,
+			}
+			o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+			if err := o.Run(); err != nil {
+				t.Fatal(err)
+			}
+
+			snap := o.FinalSnapshot()
+			if snap.Successes == 0 || snap.Errors != 0 {
+				t.Errorf("successes = %d, errors = %d", snap.Successes, snap.Errors)
+			}
+			dst := strings.TrimPrefix(srv.URL, "http://")
+			want := fmt.Sprintf("v%d %s -> %s", version, src, dst)
+			pl.mu.Lock()
+			defer pl.mu.Unlock()
+			if len(pl.headers) == 0 {
+				t.Fatal("no connections accepted")
+			}
+			for _, got := range pl.headers {
+				if got != want {
+					t.Errorf("PROXY header = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
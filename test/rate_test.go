@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_RateHoldsAcrossSlots paces eight slots at 100 req/s for a second
+// and checks the run as a whole, not each slot, kept to the rate.
+func TestRun_RateHoldsAcrossSlots(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	const rate = 100
+	o := engine.NewOrchestrator(engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 8,
+		Duration:    time.Second,
+		Workers:     2,
+		Pipeline:    4,
+		Rate:        rate,
+	}, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	snap := o.FinalSnapshot()
+	// The first start is immediate, so a second holds rate+1 of them. Allow
+	// 15% either way for timer jitter on a loaded machine.
+	const want = rate + 1
+	if lo, hi := uint64(want*85/100), uint64(want*115/100); snap.TotalRequests < lo || snap.TotalRequests > hi {
+		t.Errorf("%d requests in 1s at --rate %d, want %d-%d", snap.TotalRequests, rate, lo, hi)
+	}
+	if !snap.OpenLoop {
+		t.Error("a paced run should be reported as open loop")
+	}
+}
+
+// TestRunContext_CancelDuringRateWait checks that cancelling the run while
+// every slot waits for its next start ends it promptly.
+func TestRunContext_CancelDuringRateWait(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	o := engine.NewOrchestrator(engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 2,
+		Duration:    time.Minute,
+		Workers:     1,
+		Pipeline:    2,
+		Rate:        0.5, // one start every two seconds
+	}, NewNoopRenderer())
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(150*time.Millisecond, cancel)
+
+	start := time.Now()
+	if err := o.RunContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if took := time.Since(start); took > time.Second {
+		t.Errorf("run took %s after a cancel at 150ms", took)
+	}
+	if n := o.FinalSnapshot().TotalRequests; n != 1 {
+		t.Errorf("%d requests, want only the first start before the cancel", n)
+	}
+}
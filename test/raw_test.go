@@ -0,0 +1,77 @@
+package test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/export"
+)
+
+// TestRun_RawExportTimestamps checks that the raw log has a row per request
+// whose wall-clock start times lie within the run and, with a single slot
+// sending one request after another, never go backwards.
+func TestRun_RawExportTimestamps(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	raw := export.NewRawWriter(&buf)
+	began := time.Now()
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 1,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+		OnResult:    raw.Record,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	ended := time.Now()
+	if err := raw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) < 2 || len(rows[0]) != len(export.RawColumns) {
+		t.Fatalf("got %d rows, header %v", len(rows), rows[0])
+	}
+	if n := uint64(len(rows) - 1); n != o.FinalSnapshot().TotalRequests {
+		t.Fatalf("%d rows for %d requests", n, o.FinalSnapshot().TotalRequests)
+	}
+	var prev time.Time
+	for _, row := range rows[1:] {
+		start, err := time.Parse(time.RFC3339Nano, row[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		ns, err := strconv.ParseInt(row[1], 10, 64)
+		if err != nil || ns != start.UnixNano() {
+			t.Fatalf("start_unix_ns %q does not match start %q", row[1], row[0])
+		}
+		if start.Before(began) || start.After(ended) {
+			t.Fatalf("start %v outside the run (%v to %v)", start, began, ended)
+		}
+		if start.Before(prev) {
+			t.Fatalf("start %v before the previous request's %v", start, prev)
+		}
+		prev = start
+		if row[3] != "200" || row[4] != "true" || row[5] != "" {
+			t.Fatalf("unexpected row %v", row)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_RedirectLoopAborts checks that a target redirecting to itself is
+// reported as a redirect error and stops the run after the first request.
+func TestRun_RedirectLoopAborts(t *testing.T) {
+	var hits atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Redirect(w, r, r.URL.Path, http.StatusFound)
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/loop",
+		Connections: 1,
+		Duration:    5 * time.Second,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	start := time.Now()
+	err := o.Run()
+	if err == nil || !strings.Contains(err.Error(), "redirect loop") {
+		t.Fatalf("Run() = %v, want a redirect loop error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("run took %s, want an early abort", elapsed)
+	}
+
+	snap := o.FinalSnapshot()
+	if snap.Errors == 0 || snap.ErrorsByCategory[stats.ErrRedirect] != snap.Errors {
+		t.Errorf("errors = %d, redirect errors = %d", snap.Errors, snap.ErrorsByCategory[stats.ErrRedirect])
+	}
+	if h := hits.Load(); h > 4 {
+		t.Errorf("server saw %d requests, want the loop cut at its first repeat", h)
+	}
+}
+
+// TestRun_RedirectFollowed checks that a redirect to another path is still
+// followed normally.
+func TestRun_RedirectFollowed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/old",
+		Connections: 1,
+		Duration:    50 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if snap := o.FinalSnapshot(); snap.Successes == 0 || snap.Errors != 0 {
+		t.Errorf("successes = %d, errors = %d", snap.Successes, snap.Errors)
+	}
+}
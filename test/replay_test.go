@@ -0,0 +1,112 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_ReplaySendsRequestsInOrder checks that replayed requests go out
+// in file order with their own method, headers and body, then wrap around.
+func TestRun_ReplaySendsRequestsInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		seen = append(seen, r.Method+" "+r.URL.Path+" "+r.Header.Get("X-Id")+" "+string(body))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		URL:         srv.URL + "/",
+		Connections: 1,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+		Replay: []engine.ReplayRequest{
+			{Method: "GET", URL: srv.URL + "/a"},
+			{Method: "POST", URL: srv.URL + "/b", Header: http.Header{"X-Id": {"2"}}, Body: []byte("two")},
+			{Method: "DELETE", URL: srv.URL + "/c", Header: http.Header{"X-Id": {"3"}}},
+		},
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"GET /a  ", "POST /b 2 two", "DELETE /c 3 "}
+	if len(seen) < 4 {
+		t.Fatalf("server saw %d requests, want the file to repeat: %q", len(seen), seen)
+	}
+	for i, got := range seen {
+		if got != want[i%len(want)] {
+			t.Fatalf("request %d = %q, want %q (all: %q)", i+1, got, want[i%len(want)], seen)
+		}
+	}
+	if snap := o.FinalSnapshot(); snap.TotalRequests != uint64(len(seen)) {
+		t.Errorf("recorded %d requests, server saw %d", snap.TotalRequests, len(seen))
+	}
+}
+
+// TestRun_ReplaySpeedScalesTiming replays three requests captured 100ms
+// apart and checks the gaps the server sees follow --replay-speed: about
+// 200ms from first to last at 1x, 100ms at 2x and none at speed 0.
+func TestRun_ReplaySpeedScalesTiming(t *testing.T) {
+	for _, tt := range []struct {
+		speed    float64
+		min, max time.Duration
+	}{
+		{1, 190 * time.Millisecond, 280 * time.Millisecond},
+		{2, 95 * time.Millisecond, 170 * time.Millisecond},
+		{0, 0, 60 * time.Millisecond},
+	} {
+		var mu sync.Mutex
+		var arrivals []time.Time
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			arrivals = append(arrivals, time.Now())
+			mu.Unlock()
+		}))
+
+		cfg := engine.Config{
+			URL:         srv.URL + "/",
+			Connections: 2,
+			Duration:    260 * time.Millisecond,
+			Workers:     2,
+			Pipeline:    1,
+			ReplaySpeed: tt.speed,
+			Replay: []engine.ReplayRequest{
+				{Method: "GET", URL: srv.URL + "/a"},
+				{Method: "GET", URL: srv.URL + "/b", At: 100 * time.Millisecond},
+				{Method: "GET", URL: srv.URL + "/c", At: 200 * time.Millisecond},
+			},
+		}
+		o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+		err := o.Run()
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mu.Lock()
+		if len(arrivals) < 3 {
+			t.Fatalf("speed %g: server saw %d requests, want at least 3", tt.speed, len(arrivals))
+		}
+		if gap := arrivals[2].Sub(arrivals[0]); gap < tt.min || gap > tt.max {
+			t.Errorf("speed %g: first to third request took %s, want %s-%s", tt.speed, gap, tt.min, tt.max)
+		}
+		if tt.speed == 1 && len(arrivals) > 3 {
+			t.Errorf("speed 1: server saw %d requests in 260ms, want the timing to hold back the next pass", len(arrivals))
+		}
+		mu.Unlock()
+	}
+}
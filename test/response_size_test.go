@@ -0,0 +1,55 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_ResponseSizePercentiles serves mostly small responses with a
+// large one every fiftieth request and checks the size percentiles tell
+// them apart.
+func TestRun_ResponseSizePercentiles(t *testing.T) {
+	small, large := strings.Repeat("s", 1000), strings.Repeat("L", 100_000)
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%50 == 0 {
+			_, _ = w.Write([]byte(large))
+			return
+		}
+		_, _ = w.Write([]byte(small))
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 1,
+		Duration:    300 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if snap.RespSizeCount < 200 {
+		t.Fatalf("only %d responses; need enough for a p99", snap.RespSizeCount)
+	}
+	if snap.RespSizeP50 < 1000 || snap.RespSizeP50 > 1000+1000/32 {
+		t.Errorf("p50 = %d, want about 1000", snap.RespSizeP50)
+	}
+	if snap.RespSizeP99 < 100_000-100_000/32 {
+		t.Errorf("p99 = %d, want about 100000", snap.RespSizeP99)
+	}
+	if snap.RespSizeMax != 100_000 {
+		t.Errorf("max = %d, want 100000", snap.RespSizeMax)
+	}
+}
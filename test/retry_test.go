@@ -0,0 +1,106 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_RetriesRecoverFromTransientFailures checks that with Retries a
+// request failing with a 5xx is re-sent and only its final attempt counts.
+func TestRun_RetriesRecoverFromTransientFailures(t *testing.T) {
+	// Every request fails twice and then succeeds.
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:       "POST",
+		URL:          srv.URL + "/",
+		Body:         []byte("payload"),
+		Connections:  1,
+		Duration:     200 * time.Millisecond,
+		Workers:      1,
+		Pipeline:     1,
+		Retries:      2,
+		RetryDelay:   time.Millisecond,
+		RetryBackoff: engine.BackoffExponential,
+		RetryJitter:  true,
+		// POST is only retried when asked to.
+		RetryNonIdempotent: true,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if snap.TotalRequests == 0 {
+		t.Fatal("no requests recorded")
+	}
+	// The duration may end during a backoff, leaving one request failed.
+	if snap.Errors > 1 {
+		t.Errorf("%d errors; retries should have absorbed the 503s", snap.Errors)
+	}
+	if snap.Retries < 2*snap.Successes {
+		t.Errorf("Retries = %d, want at least %d for %d successes", snap.Retries, 2*snap.Successes, snap.Successes)
+	}
+	if got := uint64(calls.Load()); got != snap.TotalRequests+snap.Retries {
+		t.Errorf("server saw %d calls, want requests+retries = %d", got, snap.TotalRequests+snap.Retries)
+	}
+}
+
+// TestRun_RetriesSkipNonIdempotent checks that a POST answered with a 5xx
+// is not re-sent by default, while a GET is.
+func TestRun_RetriesSkipNonIdempotent(t *testing.T) {
+	var posts, gets atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts.Add(1)
+		} else {
+			gets.Add(1)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	run := func(method string) uint64 {
+		cfg := engine.Config{
+			Method:      method,
+			URL:         srv.URL + "/",
+			Connections: 1,
+			Duration:    100 * time.Millisecond,
+			Workers:     1,
+			Pipeline:    1,
+			Retries:     2,
+			RetryDelay:  time.Millisecond,
+		}
+		o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+		if err := o.Run(); err != nil {
+			t.Fatal(err)
+		}
+		snap := o.FinalSnapshot()
+		if snap.TotalRequests == 0 {
+			t.Fatalf("%s: no requests recorded", method)
+		}
+		return snap.Retries
+	}
+
+	if retries := run("POST"); retries != 0 {
+		t.Errorf("POST was retried %d times without RetryNonIdempotent", retries)
+	}
+	if retries := run("GET"); retries == 0 {
+		t.Error("GET answered with 503 was not retried")
+	}
+	if posts.Load() == 0 || gets.Load() == 0 {
+		t.Fatalf("server saw %d POSTs and %d GETs", posts.Load(), gets.Load())
+	}
+}
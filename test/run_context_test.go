@@ -0,0 +1,54 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRunContext_CancelStopsRun checks that cancelling the caller's context
+// ends a long run promptly and still reports what was measured.
+func TestRunContext_CancelStopsRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 2,
+		Duration:    time.Minute,
+		Workers:     1,
+		Pipeline:    2,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(150*time.Millisecond, cancel)
+
+	start := time.Now()
+	if err := o.RunContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if took := time.Since(start); took > 2*time.Second {
+		t.Errorf("run took %s after a cancel at 150ms", took)
+	}
+	if snap := o.FinalSnapshot(); snap.TotalRequests == 0 {
+		t.Error("no requests recorded before the cancel")
+	}
+}
+
+// TestRunContext_AlreadyCancelled checks that a done context fails fast
+// without starting the run.
+func TestRunContext_AlreadyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	o := engine.NewOrchestrator(engine.Config{URL: "http://127.0.0.1:1/", Duration: time.Minute}, NewNoopRenderer())
+	if err := o.RunContext(ctx); err != context.Canceled {
+		t.Fatalf("RunContext with a cancelled context = %v, want context.Canceled", err)
+	}
+}
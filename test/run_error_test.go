@@ -0,0 +1,68 @@
+package test
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_UnhealthyRunReturnsRunError points a run at a closed port with an
+// error-rate threshold and checks that Run reports the failure breakdown.
+func TestRun_UnhealthyRunReturnsRunError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + ln.Addr().String() + "/"
+	_ = ln.Close()
+
+	cfg := engine.Config{
+		Method:       "GET",
+		URL:          url,
+		Connections:  1,
+		Duration:     100 * time.Millisecond,
+		Workers:      1,
+		Pipeline:     1,
+		MaxErrorRate: 0.1,
+	}
+	orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	err = orch.Run()
+
+	var runErr *engine.RunError
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected *engine.RunError, got %T: %v", err, err)
+	}
+	if runErr.Requests == 0 || runErr.Errors != runErr.Requests {
+		t.Errorf("every request should have failed: %d of %d", runErr.Errors, runErr.Requests)
+	}
+	if runErr.ByCategory[stats.ErrConnect] != runErr.Errors {
+		t.Errorf("failures should all be connect errors: %v", runErr.ByCategory)
+	}
+	if cat, share := runErr.Dominant(); cat != stats.ErrConnect || share != 1 {
+		t.Errorf("Dominant: got %s %.2f", cat, share)
+	}
+	if orch.FinalSnapshot().TotalRequests != runErr.Requests {
+		t.Error("the final snapshot should still be available after an unhealthy run")
+	}
+}
+
+func TestRun_HealthyRunWithThresholdReturnsNil(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+	cfg := engine.Config{
+		Method:       "GET",
+		URL:          srv.URL + "/",
+		Connections:  1,
+		Duration:     100 * time.Millisecond,
+		Workers:      1,
+		Pipeline:     1,
+		MaxErrorRate: 0.01,
+	}
+	if err := engine.NewOrchestrator(cfg, NewNoopRenderer()).Run(); err != nil {
+		t.Fatalf("healthy run should return nil: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/export"
+)
+
+// TestRun_RunIDHeader checks every request carries the run ID as
+// X-Benchmark-Run, and the final snapshot and JSON summary echo it.
+func TestRun_RunIDHeader(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.Header.Get(engine.RunIDHeader)]++
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 2,
+		Duration:    100 * time.Millisecond,
+		Workers:     2,
+		Pipeline:    2,
+		RunID:       "nightly-42",
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	mu.Lock()
+	if len(seen) != 1 || uint64(seen["nightly-42"]) != snap.TotalRequests || snap.TotalRequests == 0 {
+		t.Errorf("server saw run IDs %v over %d requests, want nightly-42 on all", seen, snap.TotalRequests)
+	}
+	mu.Unlock()
+	if snap.RunID != "nightly-42" {
+		t.Errorf("snapshot RunID = %q", snap.RunID)
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteJSON(&buf, export.NewReport(export.Meta{URL: cfg.URL}, snap, nil)); err != nil {
+		t.Fatal(err)
+	}
+	var summary struct {
+		Target struct {
+			RunID string `json:"run_id"`
+		} `json:"target"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Target.RunID != "nightly-42" {
+		t.Errorf("JSON target.run_id = %q, want nightly-42", summary.Target.RunID)
+	}
+}
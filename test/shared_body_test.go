@@ -0,0 +1,60 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_StaticBodyArrivesWhole sends a large static body, which slots
+// rewind rather than rebuild, and checks the server gets all of it every
+// time, including on retries and on rotated connections.
+func TestRun_StaticBodyArrivesWhole(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 16<<10) // 256 KiB
+	var calls, bad atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, err := io.ReadAll(r.Body)
+		if err != nil || !bytes.Equal(got, payload) {
+			bad.Add(1)
+		}
+		if calls.Add(1)%2 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:             "POST",
+		URL:                srv.URL + "/",
+		Body:               payload,
+		Connections:        2,
+		Duration:           300 * time.Millisecond,
+		Workers:            1,
+		Pipeline:           2,
+		MaxRequestsPerConn: 3,
+		Retries:            1,
+		RetryDelay:         time.Millisecond,
+		RetryNonIdempotent: true,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if snap.TotalRequests < 4 || snap.Retries == 0 {
+		t.Fatalf("want several requests with retries, got %d requests and %d retries", snap.TotalRequests, snap.Retries)
+	}
+	if n := bad.Load(); n != 0 {
+		t.Errorf("%d of %d bodies arrived incomplete or corrupted", n, calls.Load())
+	}
+	if want := (snap.TotalRequests + snap.Retries) * uint64(len(payload)); snap.TotalBytesSent != want {
+		t.Errorf("BytesSent = %d, want %d", snap.TotalBytesSent, want)
+	}
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_ShowAddrsReportsResolvedAndUsed checks that ShowAddrs surfaces
+// what localhost resolved to and the address requests actually went to.
+func TestRun_ShowAddrsReportsResolvedAndUsed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	addr := srv.Listener.Addr().String() // 127.0.0.1:port
+	_, port, _ := strings.Cut(addr, ":")
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         "http://localhost:" + port + "/",
+		Connections: 2,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    2,
+		ShowAddrs:   true,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if !slices.Contains(snap.ResolvedAddrs, "127.0.0.1") {
+		t.Errorf("ResolvedAddrs = %v, want it to include 127.0.0.1", snap.ResolvedAddrs)
+	}
+	var total uint64
+	for _, a := range snap.RemoteAddrs {
+		total += a.Requests
+	}
+	if total != snap.TotalRequests || snap.TotalRequests == 0 {
+		t.Errorf("RemoteAddrs %v account for %d of %d requests", snap.RemoteAddrs, total, snap.TotalRequests)
+	}
+	// localhost may also resolve to ::1, where nothing listens; every
+	// request that succeeded went to the server.
+	if i := slices.IndexFunc(snap.RemoteAddrs, func(a stats.AddrCount) bool { return a.Addr == addr }); i < 0 || snap.RemoteAddrs[i].Requests != snap.Successes {
+		t.Errorf("RemoteAddrs = %v, want %d requests to %s", snap.RemoteAddrs, snap.Successes, addr)
+	}
+}
@@ -0,0 +1,128 @@
+//go:build unix
+
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// statusRenderer raises the status signal on its first live tick and records
+// the status snapshots it is asked to print.
+type statusRenderer struct {
+	once     sync.Once
+	mu       sync.Mutex
+	statuses []stats.Snapshot
+}
+
+func (r *statusRenderer) Render(snap stats.Snapshot) {
+	r.once.Do(func() { _ = syscall.Kill(syscall.Getpid(), syscall.SIGQUIT) })
+}
+
+func (r *statusRenderer) RenderFinal(snap stats.Snapshot) {}
+
+func (r *statusRenderer) RenderStatus(snap stats.Snapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, snap)
+}
+
+// TestRun_StatusSignalPrintsSnapshot sends SIGQUIT mid-run and checks that a
+// live snapshot is rendered while the run continues to its full duration.
+func TestRun_StatusSignalPrintsSnapshot(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 2,
+		Duration:    800 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	r := &statusRenderer{}
+	orch := engine.NewOrchestrator(cfg, r)
+	start := time.Now()
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.statuses) != 1 {
+		t.Fatalf("expected one status snapshot, got %d", len(r.statuses))
+	}
+	if r.statuses[0].TotalRequests == 0 {
+		t.Error("status snapshot should include requests made so far")
+	}
+	if elapsed := time.Since(start); elapsed < cfg.Duration {
+		t.Errorf("status signal must not stop the run: ended after %v", elapsed)
+	}
+	if final := orch.FinalSnapshot(); final.TotalRequests <= r.statuses[0].TotalRequests {
+		t.Errorf("run should keep going after the status snapshot: final=%d status=%d",
+			final.TotalRequests, r.statuses[0].TotalRequests)
+	}
+}
+
+// stopRenderer raises SIGUSR1 on its first live tick and keeps the snapshot
+// it is given for the final report.
+type stopRenderer struct {
+	once  sync.Once
+	final stats.Snapshot
+}
+
+func (r *stopRenderer) Render(snap stats.Snapshot) {
+	r.once.Do(func() { _ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1) })
+}
+
+func (r *stopRenderer) RenderFinal(snap stats.Snapshot) { r.final = snap }
+
+// TestRun_StopSignalFinalSnapshotComplete stops a run while slow requests
+// are in flight and checks that every result, including those recorded as
+// the workers unwound, is in the final snapshot: nothing reaches the
+// collector after it is taken.
+func TestRun_StopSignalFinalSnapshotComplete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 8,
+		Duration:    5 * time.Second,
+		Workers:     2,
+		Pipeline:    4,
+		StopSignals: []os.Signal{syscall.SIGUSR1},
+	}
+	r := &stopRenderer{}
+	orch := engine.NewOrchestrator(cfg, r)
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+	// Give any straggler a chance to record late before comparing.
+	time.Sleep(50 * time.Millisecond)
+
+	final := orch.FinalSnapshot()
+	after := orch.Collector().Snapshot()
+	if final.TotalRequests == 0 {
+		t.Fatal("no requests recorded before the stop signal")
+	}
+	if after.TotalRequests != final.TotalRequests || after.Abandoned != final.Abandoned {
+		t.Errorf("recorded after the final snapshot: final=%d+%d abandoned, later=%d+%d abandoned",
+			final.TotalRequests, final.Abandoned, after.TotalRequests, after.Abandoned)
+	}
+	if r.final.TotalRequests != final.TotalRequests {
+		t.Errorf("RenderFinal got %d requests, FinalSnapshot has %d", r.final.TotalRequests, final.TotalRequests)
+	}
+}
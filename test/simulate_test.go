@@ -0,0 +1,60 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_SimulatedLatencyPercentiles runs without a server and checks that
+// the reported percentiles match the injected distributions.
+func TestRun_SimulatedLatencyPercentiles(t *testing.T) {
+	run := func(dist engine.LatencyDist) engine.Config {
+		return engine.Config{
+			SimulateLatency: &dist,
+			Duration:        300 * time.Millisecond,
+			Workers:         2,
+			Pipeline:        16,
+		}
+	}
+
+	t.Run("const", func(t *testing.T) {
+		o := engine.NewOrchestrator(run(engine.LatencyDist{Kind: "const", A: 2 * time.Millisecond}), NewNoopRenderer())
+		if err := o.Run(); err != nil {
+			t.Fatal(err)
+		}
+		snap := o.FinalSnapshot()
+		if snap.TotalRequests == 0 || snap.Errors != 0 {
+			t.Fatalf("requests = %d, errors = %d", snap.TotalRequests, snap.Errors)
+		}
+		for name, got := range map[string]time.Duration{"p50": snap.LatencyP50, "p99": snap.LatencyP99, "max": snap.LatencyMax} {
+			if got != 2*time.Millisecond {
+				t.Errorf("%s = %s, want exactly 2ms", name, got)
+			}
+		}
+	})
+
+	t.Run("uniform", func(t *testing.T) {
+		lo, hi := 1*time.Millisecond, 3*time.Millisecond
+		o := engine.NewOrchestrator(run(engine.LatencyDist{Kind: "uniform", A: lo, B: hi}), NewNoopRenderer())
+		if err := o.Run(); err != nil {
+			t.Fatal(err)
+		}
+		snap := o.FinalSnapshot()
+		if snap.LatencySampleCount < 1000 {
+			t.Fatalf("only %d samples", snap.LatencySampleCount)
+		}
+		near := func(name string, got, want time.Duration) {
+			if d := got - want; d < -150*time.Microsecond || d > 150*time.Microsecond {
+				t.Errorf("%s = %s, want about %s", name, got, want)
+			}
+		}
+		near("p50", snap.LatencyP50, 2*time.Millisecond)
+		near("p2.5", snap.LatencyP25, lo+(hi-lo)*25/1000)
+		near("p99", snap.LatencyP99, lo+(hi-lo)*99/100)
+		if snap.LatencyMax > hi {
+			t.Errorf("max = %s exceeds the %s upper bound", snap.LatencyMax, hi)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+	"github.com/thetangentline/httpcl/internal/ui"
+)
+
+// soakRenderer records the interim reports it is asked to print.
+type soakRenderer struct {
+	mu      sync.Mutex
+	snaps   []stats.Snapshot
+	runtime []ui.RuntimeStats
+}
+
+func (r *soakRenderer) Render(snap stats.Snapshot) {}
+
+func (r *soakRenderer) RenderFinal(snap stats.Snapshot) {}
+
+func (r *soakRenderer) RenderSoak(snap stats.Snapshot, rt ui.RuntimeStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.snaps = append(r.snaps, snap)
+	r.runtime = append(r.runtime, rt)
+}
+
+// TestRun_SoakReports checks that a short soak interval produces several
+// interim reports, numbered in order and each seeing more requests, while
+// the run goes on to its full duration.
+func TestRun_SoakReports(t *testing.T) {
+	srv := testServer()
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 2,
+		Duration:    700 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+		SoakReport:  150 * time.Millisecond,
+	}
+	r := &soakRenderer{}
+	orch := engine.NewOrchestrator(cfg, r)
+	if err := orch.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.snaps) < 2 {
+		t.Fatalf("expected several soak reports, got %d", len(r.snaps))
+	}
+	for i, rt := range r.runtime {
+		if rt.Report != i+1 || rt.Goroutines == 0 || rt.HeapAlloc == 0 {
+			t.Errorf("report %d: unexpected runtime stats %+v", i, rt)
+		}
+		if i > 0 && r.snaps[i].TotalRequests < r.snaps[i-1].TotalRequests {
+			t.Errorf("report %d saw fewer requests than the one before", i+1)
+		}
+	}
+	if final := orch.FinalSnapshot(); final.TotalRequests <= r.snaps[0].TotalRequests {
+		t.Errorf("run should keep going after a soak report: final=%d first report=%d",
+			final.TotalRequests, r.snaps[0].TotalRequests)
+	}
+}
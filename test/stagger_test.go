@@ -0,0 +1,54 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_StaggerStartSpreadsFirstRequests checks that slots do not all
+// send their first request at once. Each request outlasts the run, so every
+// slot sends exactly one and its start is the slot's first fire.
+func TestRun_StaggerStartSpreadsFirstRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var starts []time.Time
+	cfg := engine.Config{
+		Method:       "GET",
+		URL:          srv.URL + "/",
+		Connections:  8,
+		Duration:     250 * time.Millisecond,
+		Workers:      1,
+		Pipeline:     8,
+		StaggerStart: 200 * time.Millisecond,
+		Seed:         1,
+		OnResult: func(r stats.Result) {
+			mu.Lock()
+			starts = append(starts, r.Start)
+			mu.Unlock()
+		},
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(starts) != 8 {
+		t.Fatalf("got %d requests, want one per slot", len(starts))
+	}
+	first := slices.MinFunc(starts, func(a, b time.Time) int { return a.Compare(b) })
+	last := slices.MaxFunc(starts, func(a, b time.Time) int { return a.Compare(b) })
+	if spread := last.Sub(first); spread < 50*time.Millisecond {
+		t.Errorf("first requests spread over %s, want them staggered across the 200ms window", spread)
+	}
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_StatsDLines checks that a run with StatsD sends well-formed,
+// tagged lines whose request counters add up to the run's total.
+func TestRun_StatsDLines(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		URL:         srv.URL,
+		Connections: 2,
+		Duration:    1200 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+		StatsD:      &engine.StatsD{Addr: pc.LocalAddr().String(), Prefix: "bench", Tags: []string{"env:ci", "canary"}},
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	lineRE := regexp.MustCompile(`^bench\.([a-z0-9_.]+):(-?[0-9.]+)\|(c|g)\|#env:ci,canary$`)
+	var packets int
+	var requests uint64
+	seen := map[string]bool{}
+	buf := make([]byte, 64<<10)
+	for {
+		_ = pc.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+		packets++
+		for _, line := range strings.Split(string(buf[:n]), "\n") {
+			m := lineRE.FindStringSubmatch(line)
+			if m == nil {
+				t.Fatalf("malformed StatsD line %q", line)
+			}
+			seen[m[1]] = true
+			if m[1] == "requests" {
+				v, err := strconv.ParseUint(m[2], 10, 64)
+				if err != nil {
+					t.Fatalf("requests counter %q: %v", m[2], err)
+				}
+				requests += v
+			}
+		}
+	}
+
+	// One send a second during the run plus the final one.
+	if packets < 2 {
+		t.Errorf("got %d packets, want a live one and the final one", packets)
+	}
+	for _, name := range []string{"requests", "errors", "rps", "latency.p50_ms", "latency.p99_ms", "in_flight"} {
+		if !seen[name] {
+			t.Errorf("no %s metric sent", name)
+		}
+	}
+	if total := o.FinalSnapshot().TotalRequests; requests != total {
+		t.Errorf("request counters add up to %d, want the run's %d", requests, total)
+	}
+}
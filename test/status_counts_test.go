@@ -0,0 +1,51 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_StatusCounts runs against a server answering 200, 404 and 503 in
+// turn and checks every request is counted under the status it got.
+func TestRun_StatusCounts(t *testing.T) {
+	codes := []int{http.StatusOK, http.StatusNotFound, http.StatusServiceUnavailable}
+	var n atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(codes[(n.Add(1)-1)%int64(len(codes))])
+	}))
+	defer srv.Close()
+
+	o := engine.NewOrchestrator(engine.Config{
+		Method:      "GET",
+		URL:         srv.URL + "/",
+		Connections: 1,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	snap := o.FinalSnapshot()
+	if snap.TotalRequests < 3 {
+		t.Fatalf("only %d requests", snap.TotalRequests)
+	}
+	var sum uint64
+	for code, count := range snap.StatusCounts {
+		if code != 200 && code != 404 && code != 503 {
+			t.Errorf("unexpected status %d counted %d times", code, count)
+		}
+		sum += count
+	}
+	if sum != snap.TotalRequests {
+		t.Errorf("status counts %v sum to %d, want all %d requests", snap.StatusCounts, sum, snap.TotalRequests)
+	}
+	if snap.StatusCounts[503] != snap.Errors || snap.StatusCounts[200]+snap.StatusCounts[404] != snap.Successes {
+		t.Errorf("status counts %v do not match %d successes and %d errors", snap.StatusCounts, snap.Successes, snap.Errors)
+	}
+}
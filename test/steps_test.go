@@ -0,0 +1,96 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_ConcurrencySteps holds three concurrency levels against a server
+// with a fixed service time and checks each step is reported on its own,
+// for its duration, with the concurrency the server actually saw.
+func TestRun_ConcurrencySteps(t *testing.T) {
+	var mu sync.Mutex
+	inFlight := 0
+	var seen []int // peak in-flight requests per 100ms since the first request
+	var first time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if first.IsZero() {
+			first = time.Now()
+		}
+		inFlight++
+		i := int(time.Since(first) / (100 * time.Millisecond))
+		for len(seen) <= i {
+			seen = append(seen, 0)
+		}
+		seen[i] = max(seen[i], inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:  "GET",
+		URL:     srv.URL + "/",
+		Workers: 1,
+		ConcurrencySteps: []engine.ConcurrencyStep{
+			{Concurrency: 1, Duration: 400 * time.Millisecond},
+			{Concurrency: 4, Duration: 400 * time.Millisecond},
+			{Concurrency: 8, Duration: 400 * time.Millisecond},
+		},
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if got := o.Config(); got.Duration != 1200*time.Millisecond || got.Pipeline != 8 {
+		t.Fatalf("duration %s with %d slots, want the steps' 1.2s and 8 slots", got.Duration, got.Pipeline)
+	}
+	start := time.Now()
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if took := time.Since(start); took > 2*time.Second {
+		t.Errorf("run took %s for 1.2s of steps", took)
+	}
+
+	steps := o.FinalSnapshot().LoadSteps
+	if len(steps) != 3 {
+		t.Fatalf("got %d steps, want 3: %+v", len(steps), steps)
+	}
+	var total uint64
+	for i, s := range steps {
+		if s.Concurrency != cfg.ConcurrencySteps[i].Concurrency {
+			t.Errorf("step %d: concurrency %d, want %d", i+1, s.Concurrency, cfg.ConcurrencySteps[i].Concurrency)
+		}
+		if s.Duration < 350*time.Millisecond || s.Duration > 550*time.Millisecond {
+			t.Errorf("step %d held for %s, want about 400ms", i+1, s.Duration)
+		}
+		if s.LatencyP50 < 10*time.Millisecond || s.Errors != 0 {
+			t.Errorf("step %d: p50 %s with %d errors", i+1, s.LatencyP50, s.Errors)
+		}
+		if i > 0 && s.RequestsPerS < 1.5*steps[i-1].RequestsPerS {
+			t.Errorf("step %d: %.0f req/s, want well above step %d's %.0f", i+1, s.RequestsPerS, i, steps[i-1].RequestsPerS)
+		}
+		total += s.Requests
+	}
+	if snap := o.FinalSnapshot(); total != snap.TotalRequests {
+		t.Errorf("steps add up to %d requests, the run made %d", total, snap.TotalRequests)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// The most in flight per 100ms window; 0 leaves the windows around each
+	// step change out, for timer slack.
+	limits := []int{1, 1, 1, 0, 4, 4, 4, 0, 8, 8, 8}
+	for i, peak := range seen[:min(len(seen), len(limits))] {
+		if limits[i] > 0 && peak > limits[i] {
+			t.Errorf("%d requests in flight %dms into the run, want at most %d", peak, i*100, limits[i])
+		}
+	}
+}
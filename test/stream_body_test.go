@@ -0,0 +1,53 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+)
+
+// TestRun_GeneratedBodyStreams checks that a BodySize body arrives whole
+// with every request without being buffered in Config.Body.
+func TestRun_GeneratedBodyStreams(t *testing.T) {
+	const size = 64 << 20
+	var requests, short atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		requests.Add(1)
+		if n != size || r.ContentLength != size {
+			short.Add(1)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:      "POST",
+		URL:         srv.URL + "/",
+		BodySize:    size,
+		BodyRandom:  true,
+		Connections: 1,
+		Duration:    200 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if n := requests.Load(); n == 0 || short.Load() > 0 {
+		t.Fatalf("%d of %d requests arrived with the wrong body size", short.Load(), n)
+	}
+	if snap.Errors > 0 {
+		t.Errorf("%d errors", snap.Errors)
+	}
+	if want := snap.TotalRequests * size; snap.TotalBytesSent != want {
+		t.Errorf("TotalBytesSent = %d, want %d", snap.TotalBytesSent, want)
+	}
+}
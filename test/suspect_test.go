@@ -0,0 +1,65 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/export"
+)
+
+// TestRun_SuspectResults runs against a server that answers slowly with 503
+// and checks the results are flagged, with the cause, in the snapshot and
+// the JSON summary; a healthy server is not flagged.
+func TestRun_SuspectResults(t *testing.T) {
+	run := func(h http.HandlerFunc) string {
+		t.Helper()
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+		cfg := engine.Config{
+			Method:      "GET",
+			URL:         srv.URL + "/",
+			Connections: 2,
+			Duration:    600 * time.Millisecond,
+			Workers:     2,
+			Pipeline:    1,
+			LowRPSWarn:  5,
+		}
+		orch := engine.NewOrchestrator(cfg, NewNoopRenderer())
+		if err := orch.Run(); err != nil {
+			t.Fatal(err)
+		}
+		snap := orch.FinalSnapshot()
+
+		var buf bytes.Buffer
+		if err := export.WriteJSON(&buf, export.NewReport(export.Meta{URL: cfg.URL}, snap, nil)); err != nil {
+			t.Fatal(err)
+		}
+		var summary struct {
+			Suspect string `json:"suspect"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+			t.Fatal(err)
+		}
+		if summary.Suspect != snap.Suspect {
+			t.Errorf("JSON suspect = %q, snapshot has %q", summary.Suspect, snap.Suspect)
+		}
+		return snap.Suspect
+	}
+
+	got := run(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(250 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	if !strings.Contains(got, "http_5xx") {
+		t.Errorf("slow failing server: Suspect = %q, want it to name http_5xx", got)
+	}
+	if got := run(func(w http.ResponseWriter, r *http.Request) {}); got != "" {
+		t.Errorf("healthy server: Suspect = %q, want none", got)
+	}
+}
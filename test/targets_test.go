@@ -0,0 +1,115 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// TestRun_WeightedTargets checks that requests split across targets roughly
+// by weight and that the per-target stats match what each server saw.
+func TestRun_WeightedTargets(t *testing.T) {
+	var usCalls, euCalls atomic.Uint64
+	us := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { usCalls.Add(1) }))
+	defer us.Close()
+	eu := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { euCalls.Add(1) }))
+	defer eu.Close()
+
+	cfg := engine.Config{
+		Targets:     []engine.Target{{URL: us.URL + "/us", Weight: 7}, {URL: eu.URL + "/eu", Weight: 3}},
+		Connections: 4,
+		Duration:    300 * time.Millisecond,
+		Workers:     2,
+		Pipeline:    1,
+		Seed:        1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if len(snap.Targets) != 2 {
+		t.Fatalf("got %d target rows, want 2", len(snap.Targets))
+	}
+	if snap.TotalRequests < 200 {
+		t.Skipf("only %d requests; too few to judge the split", snap.TotalRequests)
+	}
+	usReqs, euReqs := snap.Targets[0].Requests, snap.Targets[1].Requests
+	if usReqs+euReqs != snap.TotalRequests {
+		t.Errorf("targets account for %d of %d requests", usReqs+euReqs, snap.TotalRequests)
+	}
+	if usReqs != usCalls.Load() || euReqs != euCalls.Load() {
+		t.Errorf("target stats %d/%d, servers saw %d/%d", usReqs, euReqs, usCalls.Load(), euCalls.Load())
+	}
+	if share := float64(usReqs) / float64(snap.TotalRequests); share < 0.6 || share > 0.8 {
+		t.Errorf("first target got %.1f%% of requests, want about 70%%", share*100)
+	}
+	if snap.Targets[0].Name != us.URL+"/us" {
+		t.Errorf("first target named %q", snap.Targets[0].Name)
+	}
+}
+
+// TestRun_ShardKeyTargets checks that with a shard key a constant key
+// sends every request to one target, and a per-request key spreads the
+// requests about evenly, with the per-target stats matching the servers.
+func TestRun_ShardKeyTargets(t *testing.T) {
+	var calls [3]atomic.Uint64
+	targets := make([]engine.Target, len(calls))
+	for i := range calls {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls[i].Add(1) }))
+		defer srv.Close()
+		targets[i] = engine.Target{URL: srv.URL + "/", Weight: 1}
+	}
+	run := func(key string) stats.Snapshot {
+		t.Helper()
+		for i := range calls {
+			calls[i].Store(0)
+		}
+		cfg := engine.Config{
+			Targets:     targets,
+			ShardKey:    key,
+			Connections: 3,
+			Duration:    200 * time.Millisecond,
+			Workers:     1,
+			Pipeline:    3,
+		}
+		o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+		if err := o.Run(); err != nil {
+			t.Fatal(err)
+		}
+		snap := o.FinalSnapshot()
+		for i, ts := range snap.Targets {
+			if ts.Requests != calls[i].Load() {
+				t.Errorf("key %q: target %d recorded %d requests, its server saw %d", key, i, ts.Requests, calls[i].Load())
+			}
+		}
+		return snap
+	}
+
+	snap := run("user-42")
+	hit := 0
+	for _, ts := range snap.Targets {
+		if ts.Requests > 0 {
+			hit++
+		}
+	}
+	if hit != 1 || snap.TotalRequests == 0 {
+		t.Errorf("constant key spread %d requests over %d targets, want one", snap.TotalRequests, hit)
+	}
+
+	snap = run("user-{{seq}}")
+	if snap.TotalRequests < 300 {
+		t.Skipf("only %d requests; too few to judge the spread", snap.TotalRequests)
+	}
+	for i, ts := range snap.Targets {
+		if share := float64(ts.Requests) / float64(snap.TotalRequests); share < 0.2 || share > 0.47 {
+			t.Errorf("target %d got %.1f%% of keyed requests, want about a third", i, share*100)
+		}
+	}
+}
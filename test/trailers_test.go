@@ -0,0 +1,83 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// trailerServer records the X-Checksum request trailer it last saw and
+// answers with a Grpc-Status: 0 response trailer.
+func trailerServer(seen *atomic.Value) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		seen.Store(r.Trailer.Get("X-Checksum"))
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+		w.Header().Set("Grpc-Status", "0")
+	}))
+}
+
+// TestRun_Trailers checks that request trailers reach the server and that an
+// expected response trailer lets requests succeed.
+func TestRun_Trailers(t *testing.T) {
+	var seen atomic.Value
+	srv := trailerServer(&seen)
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:         "POST",
+		URL:            srv.URL + "/",
+		Body:           []byte("payload"),
+		Trailers:       http.Header{"X-Checksum": {"abc123"}},
+		ExpectTrailers: []engine.HeaderMatch{{Name: "Grpc-Status", Value: "0"}},
+		Connections:    1,
+		Duration:       50 * time.Millisecond,
+		Workers:        1,
+		Pipeline:       1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	snap := o.FinalSnapshot()
+	if snap.Successes == 0 || snap.Errors != 0 {
+		t.Fatalf("successes = %d, errors = %d; want only successes", snap.Successes, snap.Errors)
+	}
+	if got, _ := seen.Load().(string); got != "abc123" {
+		t.Errorf("request trailer X-Checksum = %q, want abc123", got)
+	}
+}
+
+// TestRun_ExpectTrailerMismatch checks that a response trailer with the
+// wrong value counts as a header error.
+func TestRun_ExpectTrailerMismatch(t *testing.T) {
+	var seen atomic.Value
+	srv := trailerServer(&seen)
+	defer srv.Close()
+
+	cfg := engine.Config{
+		Method:         "GET",
+		URL:            srv.URL + "/",
+		ExpectTrailers: []engine.HeaderMatch{{Name: "Grpc-Status", Value: "13"}},
+		Connections:    1,
+		Duration:       50 * time.Millisecond,
+		Workers:        1,
+		Pipeline:       1,
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	snap := o.FinalSnapshot()
+	if snap.Successes != 0 || snap.ErrorsByCategory[stats.ErrHeader] == 0 {
+		t.Errorf("successes = %d, header errors = %d; want every request to fail", snap.Successes, snap.ErrorsByCategory[stats.ErrHeader])
+	}
+}
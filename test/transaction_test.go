@@ -0,0 +1,117 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thetangentline/httpcl/internal/engine"
+	"github.com/thetangentline/httpcl/internal/stats"
+)
+
+// stepServer delays each path by its configured latency.
+func stepServer(delays map[string]time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, ok := delays[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		time.Sleep(d)
+	}))
+}
+
+// TestRun_TransactionAggregatesSteps checks that a three-step transaction
+// is recorded once per sequence with the summed latency of its steps.
+func TestRun_TransactionAggregatesSteps(t *testing.T) {
+	srv := stepServer(map[string]time.Duration{
+		"/login":  5 * time.Millisecond,
+		"/list":   10 * time.Millisecond,
+		"/detail": 15 * time.Millisecond,
+	})
+	defer srv.Close()
+
+	cfg := engine.Config{
+		URL:         srv.URL + "/login",
+		Connections: 1,
+		Duration:    300 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+		Transaction: []engine.ReplayRequest{
+			{Method: "POST", URL: srv.URL + "/login", Body: []byte("user=a")},
+			{Method: "GET", URL: srv.URL + "/list"},
+			{Method: "GET", URL: srv.URL + "/detail"},
+		},
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if snap.TotalRequests == 0 || snap.Errors != 0 {
+		t.Fatalf("%d transactions, %d errors", snap.TotalRequests, snap.Errors)
+	}
+	if len(snap.Steps) != 3 {
+		t.Fatalf("got %d steps, want 3", len(snap.Steps))
+	}
+	var stepAvgs time.Duration
+	for i, s := range snap.Steps {
+		if s.Requests != snap.TotalRequests {
+			t.Errorf("step %d (%s) sent %d requests for %d transactions", i+1, s.Name, s.Requests, snap.TotalRequests)
+		}
+		stepAvgs += s.LatencyAvg
+	}
+	if snap.Steps[0].Name != "POST /login" || snap.Steps[2].Name != "GET /detail" {
+		t.Errorf("step names %q, %q", snap.Steps[0].Name, snap.Steps[2].Name)
+	}
+	// The transaction latency is the sum of its steps: at least 30ms, and
+	// on average what the step averages add up to.
+	if snap.LatencyP50 < 30*time.Millisecond {
+		t.Errorf("transaction p50 %s, want at least the 30ms the steps sleep", snap.LatencyP50)
+	}
+	if diff := snap.LatencyAvg - stepAvgs; diff < -time.Millisecond || diff > time.Millisecond {
+		t.Errorf("transaction avg %s, step averages sum to %s", snap.LatencyAvg, stepAvgs)
+	}
+}
+
+// TestRun_TransactionStopsAtFailedStep checks that a failing step fails the
+// transaction and the steps after it are not sent.
+func TestRun_TransactionStopsAtFailedStep(t *testing.T) {
+	srv := stepServer(map[string]time.Duration{"/login": 0, "/detail": 0})
+	defer srv.Close()
+
+	cfg := engine.Config{
+		URL:         srv.URL + "/login",
+		Connections: 1,
+		Duration:    100 * time.Millisecond,
+		Workers:     1,
+		Pipeline:    1,
+		Transaction: []engine.ReplayRequest{
+			{Method: "GET", URL: srv.URL + "/login"},
+			{Method: "GET", URL: srv.URL + "/list"}, // 500
+			{Method: "GET", URL: srv.URL + "/detail"},
+		},
+	}
+	o := engine.NewOrchestrator(cfg, NewNoopRenderer())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := o.FinalSnapshot()
+	if snap.TotalRequests == 0 || snap.Errors != snap.TotalRequests {
+		t.Fatalf("%d transactions, %d errors; want all failed", snap.TotalRequests, snap.Errors)
+	}
+	if s := snap.Steps[1]; s.Errors != snap.TotalRequests {
+		t.Errorf("step 2 errors %d, want %d", s.Errors, snap.TotalRequests)
+	}
+	if s := snap.Steps[2]; s.Requests != 0 {
+		t.Errorf("step 3 sent %d requests after step 2 failed", s.Requests)
+	}
+	samples := snap.ErrorSamples[stats.ErrHTTP5xx]
+	if len(samples) == 0 || !strings.HasPrefix(samples[0], "step 2: ") {
+		t.Errorf("error samples %q, want them to name step 2", samples)
+	}
+}